@@ -166,13 +166,8 @@ func TestAPIError(t *testing.T) {
 		t.Fatalf("Unexpected API error status code: %d", apiErr.HTTPStatusCode)
 	}
 
-	switch v := apiErr.Code.(type) {
-	case string:
-		if v != "invalid_api_key" {
-			t.Fatalf("Unexpected API error code: %s", v)
-		}
-	default:
-		t.Fatalf("Unexpected API error code type: %T", v)
+	if apiErr.Code != "invalid_api_key" {
+		t.Fatalf("Unexpected API error code: %s", apiErr.Code)
 	}
 
 	if apiErr.Error() == "" {
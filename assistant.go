@@ -14,20 +14,22 @@ const (
 )
 
 type Assistant struct {
-	ID             string                 `json:"id"`
-	Object         string                 `json:"object"`
-	CreatedAt      int64                  `json:"created_at"`
-	Name           *string                `json:"name,omitempty"`
-	Description    *string                `json:"description,omitempty"`
-	Model          string                 `json:"model"`
-	Instructions   *string                `json:"instructions,omitempty"`
-	Tools          []AssistantTool        `json:"tools"`
-	ToolResources  *AssistantToolResource `json:"tool_resources,omitempty"`
-	FileIDs        []string               `json:"file_ids,omitempty"` // Deprecated in v2
-	Metadata       map[string]any         `json:"metadata,omitempty"`
-	Temperature    *float32               `json:"temperature,omitempty"`
-	TopP           *float32               `json:"top_p,omitempty"`
-	ResponseFormat any                    `json:"response_format,omitempty"`
+	ID            string                 `json:"id"`
+	Object        string                 `json:"object"`
+	CreatedAt     int64                  `json:"created_at"`
+	Name          *string                `json:"name,omitempty"`
+	Description   *string                `json:"description,omitempty"`
+	Model         string                 `json:"model"`
+	Instructions  *string                `json:"instructions,omitempty"`
+	Tools         []AssistantTool        `json:"tools"`
+	ToolResources *AssistantToolResource `json:"tool_resources,omitempty"`
+	// Deprecated: FileIDs is only set by the v1 API, replaced by ToolResources
+	// in v2.
+	FileIDs        []string       `json:"file_ids,omitempty"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+	Temperature    *float32       `json:"temperature,omitempty"`
+	TopP           *float32       `json:"top_p,omitempty"`
+	ResponseFormat any            `json:"response_format,omitempty"`
 
 	httpHeader
 }
@@ -59,17 +61,32 @@ type AssistantToolResource struct {
 	CodeInterpreter *AssistantToolCodeInterpreter `json:"code_interpreter,omitempty"`
 }
 
+// AssistantToolResourceFromFileIDs converts the file IDs of a v1 assistant to v2
+// tool resources, making them available to the code interpreter. Files used for
+// retrieval must instead be added to a vector store, whose ID is set in
+// FileSearch.VectorStoreIDs.
+func AssistantToolResourceFromFileIDs(fileIDs []string) *AssistantToolResource {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	return &AssistantToolResource{
+		CodeInterpreter: &AssistantToolCodeInterpreter{FileIDs: fileIDs},
+	}
+}
+
 // AssistantRequest provides the assistant request parameters.
 // When modifying the tools the API functions as the following:
 // If Tools is undefined, no changes are made to the Assistant's tools.
 // If Tools is empty slice it will effectively delete all of the Assistant's tools.
 // If Tools is populated, it will replace all of the existing Assistant's tools with the provided tools.
 type AssistantRequest struct {
-	Model          string                 `json:"model"`
-	Name           *string                `json:"name,omitempty"`
-	Description    *string                `json:"description,omitempty"`
-	Instructions   *string                `json:"instructions,omitempty"`
-	Tools          []AssistantTool        `json:"-"`
+	Model        string          `json:"model"`
+	Name         *string         `json:"name,omitempty"`
+	Description  *string         `json:"description,omitempty"`
+	Instructions *string         `json:"instructions,omitempty"`
+	Tools        []AssistantTool `json:"-"`
+	// Deprecated: FileIDs is not supported by the v2 API. Set ToolResources
+	// with AssistantToolResourceFromFileIDs instead.
 	FileIDs        []string               `json:"file_ids,omitempty"`
 	Metadata       map[string]any         `json:"metadata,omitempty"`
 	ToolResources  *AssistantToolResource `json:"tool_resources,omitempty"`
@@ -203,26 +220,15 @@ func (c *Client) ListAssistants(
 	after *string,
 	before *string,
 ) (response AssistantsList, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if order != nil {
-		urlValues.Add("order", *order)
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	if before != nil {
-		urlValues.Add("before", *before)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
+	return c.ListAssistantsWithPagination(ctx, Pagination{Limit: limit, Order: order, After: after, Before: before})
+}
 
-	urlSuffix := fmt.Sprintf("%s%s", assistantsSuffix, encodedValues)
+// ListAssistantsWithPagination lists a page of assistants.
+func (c *Client) ListAssistantsWithPagination(
+	ctx context.Context,
+	pagination Pagination,
+) (response AssistantsList, err error) {
+	urlSuffix := assistantsSuffix + encodeQuery(paginationValues(pagination))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
 	if err != nil {
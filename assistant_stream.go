@@ -0,0 +1,433 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// AssistantStreamEventType is the type of an event of an AssistantStream.
+type AssistantStreamEventType string
+
+const (
+	AssistantStreamEventThreadCreated     AssistantStreamEventType = "thread.created"
+	AssistantStreamEventRunCreated        AssistantStreamEventType = "thread.run.created"
+	AssistantStreamEventRunQueued         AssistantStreamEventType = "thread.run.queued"
+	AssistantStreamEventRunInProgress     AssistantStreamEventType = "thread.run.in_progress"
+	AssistantStreamEventRunRequiresAction AssistantStreamEventType = "thread.run.requires_action"
+	AssistantStreamEventRunCompleted      AssistantStreamEventType = "thread.run.completed"
+	AssistantStreamEventRunIncomplete     AssistantStreamEventType = "thread.run.incomplete"
+	AssistantStreamEventRunFailed         AssistantStreamEventType = "thread.run.failed"
+	AssistantStreamEventRunCancelling     AssistantStreamEventType = "thread.run.cancelling"
+	AssistantStreamEventRunCancelled      AssistantStreamEventType = "thread.run.cancelled"
+	AssistantStreamEventRunExpired        AssistantStreamEventType = "thread.run.expired"
+	AssistantStreamEventRunStepCreated    AssistantStreamEventType = "thread.run.step.created"
+	AssistantStreamEventRunStepInProgress AssistantStreamEventType = "thread.run.step.in_progress"
+	AssistantStreamEventRunStepDelta      AssistantStreamEventType = "thread.run.step.delta"
+	AssistantStreamEventRunStepCompleted  AssistantStreamEventType = "thread.run.step.completed"
+	AssistantStreamEventRunStepFailed     AssistantStreamEventType = "thread.run.step.failed"
+	AssistantStreamEventRunStepCancelled  AssistantStreamEventType = "thread.run.step.cancelled"
+	AssistantStreamEventRunStepExpired    AssistantStreamEventType = "thread.run.step.expired"
+	AssistantStreamEventMessageCreated    AssistantStreamEventType = "thread.message.created"
+	AssistantStreamEventMessageInProgress AssistantStreamEventType = "thread.message.in_progress"
+	AssistantStreamEventMessageDelta      AssistantStreamEventType = "thread.message.delta"
+	AssistantStreamEventMessageCompleted  AssistantStreamEventType = "thread.message.completed"
+	AssistantStreamEventMessageIncomplete AssistantStreamEventType = "thread.message.incomplete"
+	AssistantStreamEventError             AssistantStreamEventType = "error"
+	AssistantStreamEventDone              AssistantStreamEventType = "done"
+)
+
+const (
+	assistantStreamEventRunStepPrefix = "thread.run.step."
+	assistantStreamEventRunPrefix     = "thread.run."
+	assistantStreamEventMessagePrefix = "thread.message."
+)
+
+// AssistantStreamEvent is an event of an AssistantStream. The field matching
+// the type of the event is set; events of unknown types only have Data.
+type AssistantStreamEvent struct {
+	Event        AssistantStreamEventType
+	Thread       *Thread
+	Run          *Run
+	RunStep      *RunStep
+	RunStepDelta *RunStepDelta
+	Message      *Message
+	MessageDelta *MessageDelta
+	// Data is the raw data of the event.
+	Data json.RawMessage
+}
+
+// MessageDelta is a fragment of a message being generated, sent with the
+// thread.message.delta events.
+type MessageDelta struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	Delta  struct {
+		Role    string                `json:"role,omitempty"`
+		Content []MessageDeltaContent `json:"content,omitempty"`
+	} `json:"delta"`
+}
+
+// MessageDeltaContent is a fragment of the content part of a message with the
+// given Index.
+type MessageDeltaContent struct {
+	Index     int               `json:"index"`
+	Type      string            `json:"type"`
+	Text      *MessageDeltaText `json:"text,omitempty"`
+	ImageFile *ImageFile        `json:"image_file,omitempty"`
+	ImageURL  *ImageURL         `json:"image_url,omitempty"`
+}
+
+type MessageDeltaText struct {
+	Value       string `json:"value,omitempty"`
+	Annotations []any  `json:"annotations,omitempty"`
+}
+
+// RunStepDelta is a fragment of a run step, sent with the thread.run.step.delta
+// events as tool calls are generated.
+type RunStepDelta struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	Delta  struct {
+		StepDetails RunStepDeltaDetails `json:"step_details"`
+	} `json:"delta"`
+}
+
+type RunStepDeltaDetails struct {
+	Type            RunStepType                 `json:"type"`
+	MessageCreation *StepDetailsMessageCreation `json:"message_creation,omitempty"`
+	ToolCalls       []RunStepToolCallDelta      `json:"tool_calls,omitempty"`
+}
+
+// RunStepToolCallDelta is a fragment of the tool call of a run step with the
+// given Index.
+type RunStepToolCallDelta struct {
+	Index           int                     `json:"index"`
+	ID              string                  `json:"id,omitempty"`
+	Type            RunStepToolCallType     `json:"type"`
+	CodeInterpreter *RunStepCodeInterpreter `json:"code_interpreter,omitempty"`
+	FileSearch      *RunStepFileSearch      `json:"file_search,omitempty"`
+	Function        *RunStepFunctionCall    `json:"function,omitempty"`
+}
+
+func decodeAssistantStreamEvent(
+	eventType AssistantStreamEventType,
+	data []byte,
+	rawEvents bool,
+) (AssistantStreamEvent, error) {
+	event := AssistantStreamEvent{Event: eventType, Data: data}
+	name := string(eventType)
+	var target any
+	switch {
+	case eventType == AssistantStreamEventThreadCreated:
+		event.Thread = &Thread{}
+		target = event.Thread
+	case eventType == AssistantStreamEventRunStepDelta:
+		event.RunStepDelta = &RunStepDelta{}
+		target = event.RunStepDelta
+	case strings.HasPrefix(name, assistantStreamEventRunStepPrefix):
+		event.RunStep = &RunStep{}
+		target = event.RunStep
+	case strings.HasPrefix(name, assistantStreamEventRunPrefix):
+		event.Run = &Run{}
+		target = event.Run
+	case eventType == AssistantStreamEventMessageDelta:
+		event.MessageDelta = &MessageDelta{}
+		target = event.MessageDelta
+	case strings.HasPrefix(name, assistantStreamEventMessagePrefix):
+		event.Message = &Message{}
+		target = event.Message
+	case eventType == AssistantStreamEventError:
+		var errRes ErrorResponse
+		if err := json.Unmarshal(data, &errRes); err == nil && errRes.Error != nil {
+			return event, errRes.Error
+		}
+		apiErr := &APIError{}
+		if err := json.Unmarshal(data, apiErr); err != nil {
+			return event, fmt.Errorf("invalid error event %s: %w", data, err)
+		}
+		return event, apiErr
+	default:
+		return event, nil
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		if rawEvents {
+			return AssistantStreamEvent{}, &RawStreamEvent{Event: name, Data: data, Err: err}
+		}
+		return event, fmt.Errorf("invalid %s event: %w", eventType, err)
+	}
+	return event, nil
+}
+
+// AssistantStream is the stream of events of a run created with stream set.
+// Recv returns the events in order, then io.EOF after the done event. An error
+// event is returned as an *APIError.
+type AssistantStream struct {
+	*streamReader[AssistantStreamEvent]
+}
+
+// Recv returns the next event of the stream.
+func (s *AssistantStream) Recv() (AssistantStreamEvent, error) {
+	return s.RecvContext(context.Background())
+}
+
+// RecvContext is Recv returning early with the error of ctx when it is done
+// before the next event arrives, leaving the stream as is.
+func (s *AssistantStream) RecvContext(ctx context.Context) (AssistantStreamEvent, error) {
+	if s.streamReader.events != nil {
+		return AssistantStreamEvent{}, ErrStreamEventsInUse
+	}
+	return s.recvContext(ctx)
+}
+
+// Events is the Events method of other streams, for the events of this stream.
+func (s *AssistantStream) Events() <-chan AssistantStreamEvent {
+	return s.startEvents(s.recvContext)
+}
+
+func (s *AssistantStream) recvContext(ctx context.Context) (AssistantStreamEvent, error) {
+	data, err := s.recvRawContext(ctx)
+	if err != nil {
+		return AssistantStreamEvent{}, err
+	}
+	return decodeAssistantStreamEvent(AssistantStreamEventType(s.event), data, s.rawEvents)
+}
+
+func (c *Client) createAssistantStream(ctx context.Context, urlSuffix string, request any) (*AssistantStream, error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(urlSuffix),
+		withBody(request),
+		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[AssistantStreamEvent](c, req)
+	if err != nil {
+		return nil, err
+	}
+	return &AssistantStream{streamReader: resp}, nil
+}
+
+// CreateRunStream creates a run and streams its events.
+func (c *Client) CreateRunStream(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+) (*AssistantStream, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	return c.createAssistantStream(ctx, fmt.Sprintf("/threads/%s/runs", threadID), request)
+}
+
+// CreateThreadAndRunStream creates a thread and a run, and streams the events of
+// the run.
+func (c *Client) CreateThreadAndRunStream(
+	ctx context.Context,
+	request CreateThreadAndRunRequest,
+) (*AssistantStream, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	return c.createAssistantStream(ctx, "/threads/runs", request)
+}
+
+// SubmitToolOutputsStream submits the outputs of the tool calls a run requires,
+// and streams the events of the resumed run.
+func (c *Client) SubmitToolOutputsStream(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	request SubmitToolOutputsRequest,
+) (*AssistantStream, error) {
+	request.Stream = true
+	return c.createAssistantStream(ctx,
+		fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", threadID, runID), request)
+}
+
+// ToolCallHandler returns the output of a tool call required by a run.
+type ToolCallHandler func(ctx context.Context, call ToolCall) (string, error)
+
+// RunAssistantStream reads stream until the run stops, calling onEvent, if not
+// nil, with every event. When the run requires action, the outputs of its tool
+// calls are computed with handleToolCall and submitted with
+// SubmitToolOutputsStream, and the resumed run is read in turn, for as many
+// rounds as the run requires action. The streams read are closed. It returns
+// the last state of the run.
+func (c *Client) RunAssistantStream(
+	ctx context.Context,
+	stream *AssistantStream,
+	handleToolCall ToolCallHandler,
+	onEvent func(AssistantStreamEvent),
+) (*Run, error) {
+	var run *Run
+	for {
+		var acc AssistantStreamAccumulator
+		err := readAssistantStream(stream, &acc, onEvent)
+		stream.Close()
+		if acc.Run() != nil {
+			run = acc.Run()
+		}
+		if err != nil {
+			return run, err
+		}
+		calls := acc.RequiredToolCalls()
+		if len(calls) == 0 {
+			return run, nil
+		}
+
+		request := SubmitToolOutputsRequest{ToolOutputs: make([]ToolOutput, 0, len(calls))}
+		for _, call := range calls {
+			output, err := handleToolCall(ctx, call)
+			if err != nil {
+				return run, fmt.Errorf("tool call %s: %w", call.ID, err)
+			}
+			request.ToolOutputs = append(request.ToolOutputs, ToolOutput{ToolCallID: call.ID, Output: output})
+		}
+		stream, err = c.SubmitToolOutputsStream(ctx, run.ThreadID, run.ID, request)
+		if err != nil {
+			return run, err
+		}
+	}
+}
+
+func readAssistantStream(
+	stream *AssistantStream,
+	acc *AssistantStreamAccumulator,
+	onEvent func(AssistantStreamEvent),
+) error {
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		acc.Add(event)
+		if onEvent != nil {
+			onEvent(event)
+		}
+	}
+}
+
+// AssistantStreamAccumulator merges the events of an AssistantStream into the
+// text of the messages generated and the last state of the run. Feed it every
+// event received with Add. The zero value is ready to use.
+//
+//	var acc openai.AssistantStreamAccumulator
+//	for {
+//		event, err := stream.Recv()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//		acc.Add(event)
+//	}
+//	if calls := acc.RequiredToolCalls(); len(calls) > 0 {
+//		// Submit the outputs of calls with SubmitToolOutputsStream.
+//	}
+type AssistantStreamAccumulator struct {
+	messageIDs []string
+	// texts are the text content parts of each message, by index.
+	texts map[string]map[int]*strings.Builder
+	run   *Run
+}
+
+// Add merges event into the accumulated messages and run.
+func (a *AssistantStreamAccumulator) Add(event AssistantStreamEvent) {
+	switch {
+	case event.Run != nil:
+		a.run = event.Run
+	case event.MessageDelta != nil:
+		for _, content := range event.MessageDelta.Delta.Content {
+			if content.Text != nil {
+				a.text(event.MessageDelta.ID, content.Index).WriteString(content.Text.Value)
+			}
+		}
+	case event.Message != nil && event.Event == AssistantStreamEventMessageCompleted:
+		// The completed message replaces its deltas.
+		a.text(event.Message.ID, 0)
+		a.texts[event.Message.ID] = nil
+		for i, content := range event.Message.Content {
+			if content.Text != nil {
+				a.text(event.Message.ID, i).WriteString(content.Text.Value)
+			}
+		}
+	case event.Message != nil:
+		a.text(event.Message.ID, 0)
+	}
+}
+
+func (a *AssistantStreamAccumulator) text(messageID string, index int) *strings.Builder {
+	if a.texts == nil {
+		a.texts = make(map[string]map[int]*strings.Builder)
+	}
+	parts, ok := a.texts[messageID]
+	if !ok {
+		a.messageIDs = append(a.messageIDs, messageID)
+	}
+	if parts == nil {
+		parts = make(map[int]*strings.Builder)
+		a.texts[messageID] = parts
+	}
+	if parts[index] == nil {
+		parts[index] = &strings.Builder{}
+	}
+	return parts[index]
+}
+
+// MessageIDs returns the IDs of the messages of the stream, in the order they
+// were created.
+func (a *AssistantStreamAccumulator) MessageIDs() []string {
+	return a.messageIDs
+}
+
+// MessageText returns the text generated so far for the message with the given
+// ID, with its text content parts concatenated.
+func (a *AssistantStreamAccumulator) MessageText(messageID string) string {
+	parts := a.texts[messageID]
+	indexes := make([]int, 0, len(parts))
+	for index := range parts {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	var text strings.Builder
+	for _, index := range indexes {
+		text.WriteString(parts[index].String())
+	}
+	return text.String()
+}
+
+// Text returns the text generated so far for the last message of the stream.
+func (a *AssistantStreamAccumulator) Text() string {
+	if len(a.messageIDs) == 0 {
+		return ""
+	}
+	return a.MessageText(a.messageIDs[len(a.messageIDs)-1])
+}
+
+// Run returns the last state of the run received, or nil.
+func (a *AssistantStreamAccumulator) Run() *Run {
+	return a.run
+}
+
+// RequiredToolCalls returns the tool calls whose outputs the run waits for when
+// its status is requires_action, or nil.
+func (a *AssistantStreamAccumulator) RequiredToolCalls() []ToolCall {
+	if a.run == nil || a.run.Status != RunStatusRequiresAction || a.run.RequiredAction == nil ||
+		a.run.RequiredAction.SubmitToolOutputs == nil {
+		return nil
+	}
+	return a.run.RequiredAction.SubmitToolOutputs.ToolCalls
+}
@@ -0,0 +1,203 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func writeAssistantStreamEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for i := 0; i+1 < len(events); i += 2 {
+		_, _ = w.Write([]byte("event: " + events[i] + "\ndata: " + events[i+1] + "\n\n"))
+	}
+}
+
+func TestCreateRunStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/threads/thread_abc123/runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("OpenAI-Beta") == "" {
+			t.Error("missing the assistants beta header")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		writeAssistantStreamEvents(w,
+			"thread.run.created", `{"id":"run_abc123","object":"thread.run","status":"queued"}`,
+			"thread.run.step.created", `{"id":"step_abc123","object":"thread.run.step","type":"message_creation"}`,
+			"thread.message.created", `{"id":"msg_abc123","object":"thread.message","role":"assistant","content":[]}`,
+			"thread.message.delta", `{"id":"msg_abc123","object":"thread.message.delta",`+
+				`"delta":{"content":[{"index":0,"type":"text","text":{"value":"Hello"}}]}}`,
+			"thread.message.delta", `{"id":"msg_abc123","object":"thread.message.delta",`+
+				`"delta":{"content":[{"index":0,"type":"text","text":{"value":" there!"}}]}}`,
+			"thread.run.completed", `{"id":"run_abc123","object":"thread.run","status":"completed"}`,
+			"done", "[DONE]",
+		)
+	})
+
+	stream, err := client.CreateRunStream(context.Background(), "thread_abc123", openai.RunRequest{
+		AssistantID: "asst_abc123",
+	})
+	checks.NoError(t, err, "CreateRunStream error")
+	defer stream.Close()
+
+	var (
+		acc   openai.AssistantStreamAccumulator
+		types []openai.AssistantStreamEventType
+	)
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		types = append(types, event.Event)
+		acc.Add(event)
+	}
+
+	if request["stream"] != true {
+		t.Errorf("stream should be set, got request %v", request)
+	}
+	if len(types) != 6 || types[1] != openai.AssistantStreamEventRunStepCreated ||
+		types[3] != openai.AssistantStreamEventMessageDelta {
+		t.Errorf("unexpected events %v", types)
+	}
+	if acc.Text() != "Hello there!" || acc.MessageText("msg_abc123") != "Hello there!" {
+		t.Errorf("unexpected text %q", acc.Text())
+	}
+	if run := acc.Run(); run == nil || run.Status != openai.RunStatusCompleted {
+		t.Errorf("unexpected run %+v", run)
+	}
+	if calls := acc.RequiredToolCalls(); calls != nil {
+		t.Errorf("unexpected tool calls %v", calls)
+	}
+}
+
+func TestSubmitToolOutputsStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/threads/runs", func(w http.ResponseWriter, _ *http.Request) {
+		writeAssistantStreamEvents(w,
+			"thread.created", `{"id":"thread_abc123","object":"thread"}`,
+			"thread.run.step.delta", `{"id":"step_abc123","object":"thread.run.step.delta","delta":{"step_details":`+
+				`{"type":"tool_calls","tool_calls":[{"index":0,"id":"call_abc123","type":"function",`+
+				`"function":{"name":"get_weather","arguments":"{}"}}]}}}`,
+			"thread.run.requires_action", `{"id":"run_abc123","object":"thread.run","status":"requires_action",`+
+				`"required_action":{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":`+
+				`[{"id":"call_abc123","type":"function","function":{"name":"get_weather","arguments":"{}"}}]}}}`,
+			"done", "[DONE]",
+		)
+	})
+	server.RegisterHandler("/v1/threads/thread_abc123/runs/run_abc123/submit_tool_outputs",
+		func(w http.ResponseWriter, _ *http.Request) {
+			writeAssistantStreamEvents(w,
+				"thread.message.delta", `{"id":"msg_abc123","object":"thread.message.delta",`+
+					`"delta":{"content":[{"index":0,"type":"text","text":{"value":"Sun"}}]}}`,
+				"thread.message.completed", `{"id":"msg_abc123","object":"thread.message","role":"assistant",`+
+					`"content":[{"type":"text","text":{"value":"Sunny","annotations":[]}}]}`,
+				"error", `{"message":"Server overloaded","type":"server_error","code":"server_error"}`,
+			)
+		})
+	ctx := context.Background()
+
+	stream, err := client.CreateThreadAndRunStream(ctx, openai.CreateThreadAndRunRequest{
+		RunRequest: openai.RunRequest{AssistantID: "asst_abc123"},
+	})
+	checks.NoError(t, err, "CreateThreadAndRunStream error")
+	var acc openai.AssistantStreamAccumulator
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		if event.Event == openai.AssistantStreamEventRunStepDelta &&
+			event.RunStepDelta.Delta.StepDetails.ToolCalls[0].Function.Name != "get_weather" {
+			t.Errorf("unexpected step delta %+v", event.RunStepDelta)
+		}
+		acc.Add(event)
+	}
+	stream.Close()
+	calls := acc.RequiredToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_abc123" {
+		t.Fatalf("unexpected tool calls %v", calls)
+	}
+
+	stream, err = client.SubmitToolOutputsStream(ctx, "thread_abc123", acc.Run().ID, openai.SubmitToolOutputsRequest{
+		ToolOutputs: []openai.ToolOutput{{ToolCallID: calls[0].ID, Output: "sunny"}},
+	})
+	checks.NoError(t, err, "SubmitToolOutputsStream error")
+	defer stream.Close()
+	acc = openai.AssistantStreamAccumulator{}
+	for i := 0; i < 2; i++ {
+		event, recvErr := stream.Recv()
+		checks.NoError(t, recvErr, "Recv error")
+		acc.Add(event)
+	}
+	if acc.Text() != "Sunny" || len(acc.MessageIDs()) != 1 {
+		t.Errorf("the completed message should replace its deltas, got %q", acc.Text())
+	}
+	_, err = stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Server overloaded" {
+		t.Errorf("expected the APIError of the error event, got %v", err)
+	}
+}
+
+func TestRunAssistantStreamNestedToolCalls(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	requiresAction := func(callID string) string {
+		return `{"id":"run_abc123","object":"thread.run","thread_id":"thread_abc123","status":"requires_action",` +
+			`"required_action":{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":` +
+			`[{"id":"` + callID + `","type":"function","function":{"name":"lookup","arguments":"{}"}}]}}}`
+	}
+	server.RegisterHandler("/v1/threads/thread_abc123/runs", func(w http.ResponseWriter, _ *http.Request) {
+		writeAssistantStreamEvents(w, "thread.run.requires_action", requiresAction("call_1"), "done", "[DONE]")
+	})
+	var submitted []string
+	server.RegisterHandler("/v1/threads/thread_abc123/runs/run_abc123/submit_tool_outputs",
+		func(w http.ResponseWriter, r *http.Request) {
+			var request openai.SubmitToolOutputsRequest
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			submitted = append(submitted, request.ToolOutputs[0].ToolCallID)
+			if len(submitted) == 1 {
+				// The resumed run immediately requires another tool call.
+				writeAssistantStreamEvents(w, "thread.run.requires_action", requiresAction("call_2"), "done", "[DONE]")
+				return
+			}
+			writeAssistantStreamEvents(w,
+				"thread.run.completed", `{"id":"run_abc123","object":"thread.run","status":"completed"}`,
+				"done", "[DONE]")
+		})
+	ctx := context.Background()
+
+	stream, err := client.CreateRunStream(ctx, "thread_abc123", openai.RunRequest{AssistantID: "asst_abc123"})
+	checks.NoError(t, err, "CreateRunStream error")
+	events := 0
+	run, err := client.RunAssistantStream(ctx, stream, func(_ context.Context, call openai.ToolCall) (string, error) {
+		return "result of " + call.ID, nil
+	}, func(openai.AssistantStreamEvent) { events++ })
+	checks.NoError(t, err, "RunAssistantStream error")
+
+	if run.Status != openai.RunStatusCompleted || events != 3 {
+		t.Errorf("unexpected run %+v after %d events", run, events)
+	}
+	if len(submitted) != 2 || submitted[0] != "call_1" || submitted[1] != "call_2" {
+		t.Errorf("unexpected submitted outputs %v", submitted)
+	}
+
+	stream, err = client.CreateRunStream(ctx, "thread_abc123", openai.RunRequest{AssistantID: "asst_abc123"})
+	checks.NoError(t, err, "CreateRunStream error")
+	toolErr := errors.New("tool failed")
+	_, err = client.RunAssistantStream(ctx, stream, func(context.Context, openai.ToolCall) (string, error) {
+		return "", toolErr
+	}, nil)
+	checks.ErrorIs(t, err, toolErr, "tool call errors should be returned")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"encoding/json"
@@ -445,3 +446,66 @@ When asked a question, write and run Python code to answer the question.`
 	err = client.DeleteAssistantFile(ctx, assistantID, assistantFileID)
 	checks.NoError(t, err, "DeleteAssistantFile error")
 }
+
+func TestAssistantBetaHeader(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	var beta []string
+	server.RegisterHandler("/v1/assistants", func(w http.ResponseWriter, r *http.Request) {
+		beta = append(beta, r.Header.Get("OpenAI-Beta"))
+		fmt.Fprint(w, `{"id":"asst_abc123","object":"assistant"}`)
+	})
+
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	_, err := openai.NewClientWithConfig(config).CreateAssistant(context.Background(), openai.AssistantRequest{})
+	checks.NoError(t, err, "CreateAssistant error")
+
+	config.AssistantVersion = ""
+	_, err = openai.NewClientWithConfig(config).CreateAssistant(context.Background(), openai.AssistantRequest{})
+	checks.NoError(t, err, "CreateAssistant error")
+
+	if len(beta) != 2 || beta[0] != "assistants=v2" || beta[1] != "" {
+		t.Errorf("unexpected OpenAI-Beta headers %q", beta)
+	}
+}
+
+func TestAzureAssistantBetaHeader(t *testing.T) {
+	client, server, teardown := setupAzureTestServer()
+	defer teardown()
+	var beta string
+	server.RegisterHandler("/openai/assistants", func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("OpenAI-Beta")
+		fmt.Fprint(w, `{"id":"asst_abc123","object":"assistant"}`)
+	})
+
+	_, err := client.CreateAssistant(context.Background(), openai.AssistantRequest{})
+	checks.NoError(t, err, "CreateAssistant error")
+	if beta != "assistants=v2" {
+		t.Errorf("unexpected OpenAI-Beta header %q", beta)
+	}
+}
+
+func TestAssistantV1Conversions(t *testing.T) {
+	resources := openai.AssistantToolResourceFromFileIDs([]string{"file-1", "file-2"})
+	data, _ := json.Marshal(openai.AssistantRequest{Model: openai.GPT4o, ToolResources: resources})
+	want := `{"model":"gpt-4o","tool_resources":{"code_interpreter":{"file_ids":["file-1","file-2"]}}}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+	if openai.AssistantToolResourceFromFileIDs(nil) != nil {
+		t.Error("no files should convert to no tool resources")
+	}
+
+	attachments := openai.NewThreadAttachments([]string{"file-1", "file-2"},
+		openai.AssistantToolTypeCodeInterpreter, openai.AssistantToolTypeFileSearch)
+	data, _ = json.Marshal(openai.MessageRequest{Role: "user", Content: "Hi", Attachments: attachments})
+	want = `{"role":"user","content":"Hi","attachments":[` +
+		`{"file_id":"file-1","tools":[{"type":"code_interpreter"},{"type":"file_search"}]},` +
+		`{"file_id":"file-2","tools":[{"type":"code_interpreter"},{"type":"file_search"}]}]}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -3,10 +3,12 @@ package openai
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
@@ -27,6 +29,37 @@ const (
 	AudioResponseFormatVTT         AudioResponseFormat = "vtt"
 )
 
+var (
+	// ErrAudioFileRequired is returned when an audio request has neither a Reader
+	// nor a FilePath to read the audio from.
+	ErrAudioFileRequired = errors.New("audio request must set Reader or FilePath")
+	// ErrAudioTimestampGranularities is returned when timestamp granularities are
+	// requested without the verbose_json response format, the only one with timestamps.
+	ErrAudioTimestampGranularities = errors.New("timestamp granularities require the verbose_json response format")
+	// ErrTranslationUnsupportedParam is returned for translation requests setting a
+	// field that only applies to transcriptions.
+	ErrTranslationUnsupportedParam = errors.New("translations do not support this parameter")
+	// ErrAudioFileTooLarge is returned, before anything is uploaded, for audio
+	// larger than ClientConfig.MaxAudioUploadSize.
+	ErrAudioFileTooLarge = errors.New("audio file is too large")
+	// ErrUnsupportedAudioFormat is returned, before anything is uploaded, for audio
+	// whose file extension is not one of SupportedAudioExtensions.
+	ErrUnsupportedAudioFormat = errors.New("unsupported audio format")
+)
+
+// DefaultMaxAudioUploadSize is the largest audio file accepted by the OpenAI
+// transcription and translation endpoints.
+const DefaultMaxAudioUploadSize = 25 << 20
+
+// SupportedAudioExtensions are the file extensions of the audio formats accepted
+// by the transcription and translation endpoints, which identify the format by
+// the extension of the uploaded filename.
+var SupportedAudioExtensions = []string{
+	".flac", ".m4a", ".mp3", ".mp4", ".mpeg", ".mpga", ".oga", ".ogg", ".wav", ".webm",
+}
+
+// TranscriptionTimestampGranularity selects the timestamps returned with a
+// verbose_json transcription: per segment, which is the default, per word, or both.
 type TranscriptionTimestampGranularity string
 
 const (
@@ -42,43 +75,107 @@ type AudioRequest struct {
 	FilePath string
 
 	// Reader is an optional io.Reader when you do not want to use an existing file.
+	// It takes precedence over FilePath.
 	Reader io.Reader
 
+	// FileName is the filename sent with the audio. Its extension tells the API,
+	// and the Content-Type of the upload, which format the audio is in. When
+	// empty, FilePath is used. Audio named without an extension is identified
+	// by its first bytes, and sent with the extension of its format.
+	FileName string
+
 	Prompt                 string
 	Temperature            float32
 	Language               string // Only for transcription.
 	Format                 AudioResponseFormat
 	TimestampGranularities []TranscriptionTimestampGranularity // Only for transcription.
+	Include                []TranscriptionInclude              // Only for transcription.
+
+	// Progress, if set, is called as the audio file is uploaded.
+	Progress UploadProgressFunc
 }
 
 // AudioResponse represents a response structure for audio API.
 type AudioResponse struct {
-	Task     string  `json:"task"`
-	Language string  `json:"language"`
-	Duration float64 `json:"duration"`
-	Segments []struct {
-		ID               int     `json:"id"`
-		Seek             int     `json:"seek"`
-		Start            float64 `json:"start"`
-		End              float64 `json:"end"`
-		Text             string  `json:"text"`
-		Tokens           []int   `json:"tokens"`
-		Temperature      float64 `json:"temperature"`
-		AvgLogprob       float64 `json:"avg_logprob"`
-		CompressionRatio float64 `json:"compression_ratio"`
-		NoSpeechProb     float64 `json:"no_speech_prob"`
-		Transient        bool    `json:"transient"`
-	} `json:"segments"`
-	Words []struct {
-		Word  string  `json:"word"`
-		Start float64 `json:"start"`
-		End   float64 `json:"end"`
-	} `json:"words"`
-	Text string `json:"text"`
+	Task     string                 `json:"task"`
+	Language string                 `json:"language"`
+	Duration float64                `json:"duration"`
+	Segments []TranscriptionSegment `json:"segments"`
+	Words    []TranscriptionWord    `json:"words"`
+	Text     string                 `json:"text"`
+	// Usage is the token usage of transcriptions with the gpt-4o models, or the
+	// duration of the audio billed for the other models. It is nil when the API
+	// does not report it.
+	Usage *TranscriptionUsage `json:"usage,omitempty"`
+
+	// Cues are the cues of an srt or vtt response, parsed from Text.
+	Cues []SubtitleCue `json:"-"`
+	// CueWarnings describe the malformed cues left out of Cues.
+	CueWarnings []string `json:"-"`
 
 	httpHeader
 }
 
+// TranscriptionSegment is a segment of a verbose_json transcription, with the
+// decoding statistics Whisper computed for it.
+type TranscriptionSegment struct {
+	ID    int     `json:"id"`
+	Seek  int     `json:"seek"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+	// Tokens are the IDs of the text tokens of the segment.
+	Tokens []int `json:"tokens"`
+	// Temperature is the sampling temperature the segment was finally decoded with,
+	// raised from the request's one when decoding failed at lower temperatures.
+	Temperature float64 `json:"temperature"`
+	// AvgLogprob is the average log probability of the tokens. Values below -1
+	// suggest the decoding failed.
+	AvgLogprob float64 `json:"avg_logprob"`
+	// CompressionRatio is the gzip compression ratio of the text. Values above 2.4
+	// suggest repetitive, likely hallucinated, text.
+	CompressionRatio float64 `json:"compression_ratio"`
+	// NoSpeechProb is the probability that the segment contains no speech.
+	NoSpeechProb float64 `json:"no_speech_prob"`
+	Transient    bool    `json:"transient"`
+}
+
+// SegmentThresholds are the limits above which SuspiciousSegments flags a
+// segment. A zero limit is not checked.
+type SegmentThresholds struct {
+	NoSpeechProb     float64
+	CompressionRatio float64
+}
+
+// DefaultSegmentThresholds are the limits Whisper itself uses to detect silent
+// and hallucinated segments.
+var DefaultSegmentThresholds = SegmentThresholds{
+	NoSpeechProb:     0.6,
+	CompressionRatio: 2.4,
+}
+
+// SuspiciousSegments returns the segments whose no-speech probability or
+// compression ratio exceed thresholds, which are likely to be hallucinated. Only
+// verbose_json responses have segments.
+func (r *AudioResponse) SuspiciousSegments(thresholds SegmentThresholds) []TranscriptionSegment {
+	var suspicious []TranscriptionSegment
+	for _, segment := range r.Segments {
+		if (thresholds.NoSpeechProb > 0 && segment.NoSpeechProb > thresholds.NoSpeechProb) ||
+			(thresholds.CompressionRatio > 0 && segment.CompressionRatio > thresholds.CompressionRatio) {
+			suspicious = append(suspicious, segment)
+		}
+	}
+	return suspicious
+}
+
+// TranscriptionWord is a transcribed word with its start and end in seconds,
+// returned when TranscriptionTimestampGranularityWord is requested.
+type TranscriptionWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
 type audioTextResponse struct {
 	Text string `json:"text"`
 
@@ -100,7 +197,9 @@ func (c *Client) CreateTranscription(
 	return c.callAudioAPI(ctx, request, "transcriptions")
 }
 
-// CreateTranslation — API call to translate audio into English.
+// CreateTranslation — API call to translate audio into English. Language,
+// TimestampGranularities and Include only apply to transcriptions and are
+// rejected with ErrTranslationUnsupportedParam.
 func (c *Client) CreateTranslation(
 	ctx context.Context,
 	request AudioRequest,
@@ -109,36 +208,33 @@ func (c *Client) CreateTranslation(
 }
 
 // callAudioAPI — API call to an audio endpoint.
+// Audio read from a file, or from a reader holding a large payload, is streamed
+// to the API rather than buffered in memory.
 func (c *Client) callAudioAPI(
 	ctx context.Context,
 	request AudioRequest,
 	endpointSuffix string,
 ) (response AudioResponse, err error) {
-	var formBody bytes.Buffer
-	builder := c.createFormBuilder(&formBody)
-
-	if err = audioMultipartForm(request, builder); err != nil {
+	if err = c.validateAudioRequest(&request, endpointSuffix); err != nil {
 		return AudioResponse{}, err
 	}
 
 	urlSuffix := fmt.Sprintf("/audio/%s", endpointSuffix)
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL(urlSuffix, withModel(request.Model)),
-		withBody(&formBody),
-		withContentType(builder.FormDataContentType()),
-	)
-	if err != nil {
-		return AudioResponse{}, err
+	url := c.fullURL(urlSuffix, withModel(request.Model))
+	stream := request.Reader == nil || shouldStreamUpload(request.Reader)
+	build := func(b utils.FormBuilder) error {
+		return audioMultipartForm(request, b)
 	}
 
 	if request.HasJSONResponse() {
-		err = c.sendRequest(req, &response)
+		err = c.sendMultipartRequest(ctx, url, stream, request.Progress, build, &response)
 	} else {
 		var textResponse audioTextResponse
-		err = c.sendRequest(req, &textResponse)
+		err = c.sendMultipartRequest(ctx, url, stream, request.Progress, build, &textResponse)
 		response = textResponse.ToAudioResponse()
+		if request.Format == AudioResponseFormatSRT || request.Format == AudioResponseFormatVTT {
+			response.Cues, response.CueWarnings = ParseSubtitles(response.Text)
+		}
 	}
 	if err != nil {
 		return AudioResponse{}, err
@@ -146,6 +242,123 @@ func (c *Client) callAudioAPI(
 	return
 }
 
+// validateAudioRequest rejects the audio requests the API would reject, before
+// their audio is uploaded. Unless ClientConfig.SkipAudioValidation is set, this
+// includes audio that is too large or whose filename has an unsupported extension.
+// The FileName of audio named without an extension is set from its format.
+func (c *Client) validateAudioRequest(request *AudioRequest, endpointSuffix string) error {
+	if request.Reader == nil && request.FilePath == "" {
+		return ErrAudioFileRequired
+	}
+	if endpointSuffix == "translations" {
+		switch {
+		case request.Language != "":
+			return fmt.Errorf("%w: language", ErrTranslationUnsupportedParam)
+		case len(request.TimestampGranularities) > 0:
+			return fmt.Errorf("%w: timestamp_granularities", ErrTranslationUnsupportedParam)
+		case len(request.Include) > 0:
+			return fmt.Errorf("%w: include", ErrTranslationUnsupportedParam)
+		}
+	}
+	if len(request.TimestampGranularities) > 0 && request.Format != AudioResponseFormatVerboseJSON {
+		return ErrAudioTimestampGranularities
+	}
+	if c.config.SkipAudioValidation {
+		return nil
+	}
+
+	filename, size := request.FileName, int64(-1)
+	if request.Reader != nil {
+		if filename == "" {
+			filename = request.FilePath
+		}
+		if f, ok := request.Reader.(interface{ Name() string }); ok && filename == "" {
+			filename = f.Name()
+		}
+		size = utils.ReaderSize(request.Reader)
+	} else {
+		filename = request.FilePath
+		// A file that cannot be stat'ed is reported when it is opened.
+		if info, err := os.Stat(request.FilePath); err == nil {
+			size = info.Size()
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		var err error
+		if ext, err = sniffAudioExtension(request); err != nil {
+			return err
+		}
+		if ext != "" {
+			name := "audio"
+			if filename != "" {
+				name = filepath.Base(filename)
+			}
+			request.FileName = name + ext
+		}
+	}
+	supported := false
+	for _, supportedExt := range SupportedAudioExtensions {
+		supported = supported || ext == supportedExt
+	}
+	if !supported {
+		return fmt.Errorf("%w: %q, must be one of %s", ErrUnsupportedAudioFormat, filepath.Base(filename),
+			strings.Join(SupportedAudioExtensions, ", "))
+	}
+
+	limit := c.config.MaxAudioUploadSize
+	if limit == 0 {
+		limit = DefaultMaxAudioUploadSize
+	}
+	if size > limit {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrAudioFileTooLarge, size, limit)
+	}
+	return nil
+}
+
+// sniffedAudioExtensions are the extensions of the formats recognized by
+// utils.SniffAudioContentType.
+var sniffedAudioExtensions = map[string]string{
+	"audio/mpeg": ".mp3",
+	"audio/flac": ".flac",
+	"audio/ogg":  ".ogg",
+	"audio/wav":  ".wav",
+}
+
+// sniffAudioExtension returns the extension of the format of the audio of
+// request, identified by its first bytes, or "" if it is not recognized. A
+// Reader that cannot seek back is replaced by one reading the same audio.
+func sniffAudioExtension(request *AudioRequest) (string, error) {
+	header := make([]byte, 512)
+	var (
+		n   int
+		err error
+	)
+	switch r := request.Reader.(type) {
+	case nil:
+		f, openErr := os.Open(request.FilePath)
+		if openErr != nil {
+			// The file is reported when it is opened for the upload.
+			return "", nil
+		}
+		defer f.Close()
+		n, err = io.ReadFull(f, header)
+	case io.ReadSeeker:
+		n, err = io.ReadFull(r, header)
+		if _, seekErr := r.Seek(int64(-n), io.SeekCurrent); seekErr != nil {
+			return "", seekErr
+		}
+	default:
+		n, err = io.ReadFull(r, header)
+		request.Reader = io.MultiReader(bytes.NewReader(header[:n]), r)
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("reading audio: %w", err)
+	}
+	return sniffedAudioExtensions[utils.SniffAudioContentType(header[:n])], nil
+}
+
 // HasJSONResponse returns true if the response format is JSON.
 func (r AudioRequest) HasJSONResponse() bool {
 	return r.Format == "" || r.Format == AudioResponseFormatJSON || r.Format == AudioResponseFormatVerboseJSON
@@ -196,6 +409,13 @@ func audioMultipartForm(request AudioRequest, b utils.FormBuilder) error {
 		}
 	}
 
+	for _, include := range request.Include {
+		err = b.WriteField("include[]", string(include))
+		if err != nil {
+			return fmt.Errorf("writing include[]: %w", err)
+		}
+	}
+
 	if len(request.TimestampGranularities) > 0 {
 		for _, tg := range request.TimestampGranularities {
 			err = b.WriteField("timestamp_granularities[]", string(tg))
@@ -212,7 +432,11 @@ func audioMultipartForm(request AudioRequest, b utils.FormBuilder) error {
 // createFileField creates the "file" form field from either an existing file or by using the reader.
 func createFileField(request AudioRequest, b utils.FormBuilder) error {
 	if request.Reader != nil {
-		err := b.CreateFormFileReader("file", request.Reader, request.FilePath)
+		filename := request.FileName
+		if filename == "" {
+			filename = request.FilePath
+		}
+		err := b.CreateFormFileReader("file", request.Reader, filename)
 		if err != nil {
 			return fmt.Errorf("creating form using reader: %w", err)
 		}
@@ -225,7 +449,13 @@ func createFileField(request AudioRequest, b utils.FormBuilder) error {
 	}
 	defer f.Close()
 
-	err = b.CreateFormFile("file", f)
+	if request.FileName != "" {
+		// The file is sent under FileName, with the Content-Type of its extension.
+		err = b.CreateFormFileReader("file", f, request.FileName)
+	} else {
+		// Whisper relies on the part's Content-Type to identify the audio container.
+		err = b.CreateFormFileContentType("file", f)
+	}
 	if err != nil {
 		return fmt.Errorf("creating form file: %w", err)
 	}
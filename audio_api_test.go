@@ -8,7 +8,10 @@ import (
 	"mime"
 	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -72,16 +75,19 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 	server.RegisterHandler("/v1/audio/translations", handleAudioEndpoint)
 
 	testcases := []struct {
-		name     string
-		createFn func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		name          string
+		createFn      func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		transcription bool
 	}{
 		{
 			"transcribe",
 			client.CreateTranscription,
+			true,
 		},
 		{
 			"translate",
 			client.CreateTranslation,
+			false,
 		},
 	}
 
@@ -97,12 +103,14 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 				Model:       "whisper-3",
 				Prompt:      "用简体中文",
 				Temperature: 0.5,
-				Language:    "zh",
-				Format:      openai.AudioResponseFormatSRT,
-				TimestampGranularities: []openai.TranscriptionTimestampGranularity{
+				Format:      openai.AudioResponseFormatVerboseJSON,
+			}
+			if tc.transcription {
+				req.Language = "zh"
+				req.TimestampGranularities = []openai.TranscriptionTimestampGranularity{
 					openai.TranscriptionTimestampGranularitySegment,
 					openai.TranscriptionTimestampGranularityWord,
-				},
+				}
 			}
 			_, err := tc.createFn(ctx, req)
 			checks.NoError(t, err, "audio API error")
@@ -110,6 +118,275 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 	}
 }
 
+func TestTranscriptionWordTimestamps(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		granularities := r.MultipartForm.Value["timestamp_granularities[]"]
+		if len(granularities) != 2 || granularities[0] != "word" || granularities[1] != "segment" {
+			http.Error(w, "unexpected granularities", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"task":"transcribe","text":"Hello world","segments":[{"id":0,"start":0,"end":1.2}],` +
+			`"words":[{"word":"Hello","start":0,"end":0.5},{"word":"world","start":0.6,"end":1.2}]}`))
+	})
+
+	req := openai.AudioRequest{
+		FilePath: "hello.mp3",
+		Reader:   bytes.NewBufferString("some mp3 data"),
+		Model:    openai.Whisper1,
+		Format:   openai.AudioResponseFormatVerboseJSON,
+		TimestampGranularities: []openai.TranscriptionTimestampGranularity{
+			openai.TranscriptionTimestampGranularityWord,
+			openai.TranscriptionTimestampGranularitySegment,
+		},
+	}
+	resp, err := client.CreateTranscription(context.Background(), req)
+	checks.NoError(t, err, "CreateTranscription error")
+	want := []openai.TranscriptionWord{{Word: "Hello", Start: 0, End: 0.5}, {Word: "world", Start: 0.6, End: 1.2}}
+	if len(resp.Words) != len(want) || resp.Words[0] != want[0] || resp.Words[1] != want[1] || len(resp.Segments) != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	req.Format = openai.AudioResponseFormatJSON
+	_, err = client.CreateTranscription(context.Background(), req)
+	checks.ErrorIs(t, err, openai.ErrAudioTimestampGranularities, "granularities should require verbose_json")
+}
+
+func TestTranscriptionVerboseJSON(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"task":"transcribe","language":"english","duration":4.5,"text":"Hi. Thanks thanks thanks.",` +
+			`"segments":[{"id":0,"seek":0,"start":0,"end":1,"text":"Hi.","tokens":[50364,2421],"temperature":0,` +
+			`"avg_logprob":-0.2,"compression_ratio":0.8,"no_speech_prob":0.01},` +
+			`{"id":1,"seek":100,"start":1,"end":4.5,"text":"Thanks thanks thanks.","tokens":[50414,1044],` +
+			`"temperature":0.4,"avg_logprob":-1.3,"compression_ratio":3.1,"no_speech_prob":0.2},` +
+			`{"id":2,"seek":450,"start":4.5,"end":4.5,"text":"","tokens":[],"temperature":0,` +
+			`"avg_logprob":-0.9,"compression_ratio":1,"no_speech_prob":0.9}]}`))
+	})
+
+	resp, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		FilePath: "hello.mp3",
+		Reader:   bytes.NewBufferString("some mp3 data"),
+		Model:    openai.Whisper1,
+		Format:   openai.AudioResponseFormatVerboseJSON,
+	})
+	checks.NoError(t, err, "CreateTranscription error")
+	if resp.Language != "english" || resp.Duration != 4.5 || len(resp.Segments) != 3 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	segment := resp.Segments[1]
+	if segment.Seek != 100 || segment.Temperature != 0.4 || segment.AvgLogprob != -1.3 ||
+		len(segment.Tokens) != 2 || segment.Tokens[1] != 1044 {
+		t.Errorf("unexpected segment %+v", segment)
+	}
+
+	suspicious := resp.SuspiciousSegments(openai.DefaultSegmentThresholds)
+	if len(suspicious) != 2 || suspicious[0].ID != 1 || suspicious[1].ID != 2 {
+		t.Errorf("unexpected suspicious segments %+v", suspicious)
+	}
+	suspicious = resp.SuspiciousSegments(openai.SegmentThresholds{CompressionRatio: 2.4})
+	if len(suspicious) != 1 || suspicious[0].ID != 1 {
+		t.Errorf("unexpected suspicious segments %+v", suspicious)
+	}
+}
+
+func TestTranscriptionTextFormats(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	const srt = "1\n00:00:00,000 --> 00:00:01,000\n{\"not\": \"json\"}\n"
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(srt))
+	})
+
+	for _, format := range []openai.AudioResponseFormat{
+		openai.AudioResponseFormatText, openai.AudioResponseFormatSRT, openai.AudioResponseFormatVTT,
+	} {
+		resp, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+			FilePath: "hello.mp3",
+			Reader:   bytes.NewBufferString("some mp3 data"),
+			Model:    openai.Whisper1,
+			Format:   format,
+		})
+		checks.NoError(t, err, "CreateTranscription error")
+		if resp.Text != srt || resp.Segments != nil || resp.Language != "" {
+			t.Errorf("%s: unexpected response %+v", format, resp)
+		}
+		if wantCues := format != openai.AudioResponseFormatText; (len(resp.Cues) == 1) != wantCues ||
+			(wantCues && resp.Cues[0].Text != `{"not": "json"}`) {
+			t.Errorf("%s: unexpected cues %+v", format, resp.Cues)
+		}
+	}
+}
+
+func TestAudioFromReader(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		file := r.MultipartForm.File["file"][0]
+		if file.Filename != "memo.m4a" || file.Header.Get("Content-Type") != "audio/mp4" {
+			http.Error(w, "unexpected file "+file.Filename+" "+file.Header.Get("Content-Type"), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"hello"}`))
+	}
+	server.RegisterHandler("/v1/audio/transcriptions", handler)
+	server.RegisterHandler("/v1/audio/translations", handler)
+
+	// The reader wins over FilePath, which does not even need to exist.
+	req := openai.AudioRequest{
+		FilePath: "does/not/exist.wav",
+		Reader:   bytes.NewBufferString("some m4a data"),
+		FileName: "memo.m4a",
+		Model:    openai.Whisper1,
+	}
+	resp, err := client.CreateTranscription(context.Background(), req)
+	checks.NoError(t, err, "CreateTranscription error")
+	if resp.Text != "hello" {
+		t.Errorf("unexpected text %q", resp.Text)
+	}
+	req.Reader = bytes.NewBufferString("some m4a data")
+	_, err = client.CreateTranslation(context.Background(), req)
+	checks.NoError(t, err, "CreateTranslation error")
+
+	_, err = client.CreateTranslation(context.Background(), openai.AudioRequest{Model: openai.Whisper1})
+	checks.ErrorIs(t, err, openai.ErrAudioFileRequired, "request without audio should be rejected")
+}
+
+func TestAudioUploadValidation(t *testing.T) {
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = "http://localhost:0/v1" // never reached
+	config.MaxAudioUploadSize = 10
+	client := openai.NewClientWithConfig(config)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "large.wav")
+	checks.NoError(t, os.WriteFile(path, make([]byte, 11), 0o600), "WriteFile error")
+	_, err := client.CreateTranscription(ctx, openai.AudioRequest{FilePath: path, Model: openai.Whisper1})
+	checks.ErrorIs(t, err, openai.ErrAudioFileTooLarge, "large file should be rejected")
+
+	_, err = client.CreateTranslation(ctx, openai.AudioRequest{
+		Reader:   bytes.NewReader(make([]byte, 11)),
+		FileName: "large.mp3",
+		Model:    openai.Whisper1,
+	})
+	checks.ErrorIs(t, err, openai.ErrAudioFileTooLarge, "large reader should be rejected")
+
+	for _, name := range []string{"notes.txt", "audio", "video.MOV"} {
+		_, err = client.CreateTranscription(ctx, openai.AudioRequest{
+			Reader:   strings.NewReader("x"),
+			FileName: name,
+			Model:    openai.Whisper1,
+		})
+		checks.ErrorIs(t, err, openai.ErrUnsupportedAudioFormat, name+" should be rejected")
+	}
+
+	// Readers of unknown size pass the size check, and the check of extensions
+	// ignores their case.
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"text":"ok"}`))
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	config.BaseURL = ts.URL + "/v1"
+	client = openai.NewClientWithConfig(config)
+	_, err = client.CreateTranscription(ctx, openai.AudioRequest{
+		Reader:   io.MultiReader(bytes.NewReader(make([]byte, 11))),
+		FileName: "LOUD.MP3",
+		Model:    openai.Whisper1,
+	})
+	checks.NoError(t, err, "reader of unknown size should be accepted")
+
+	config.SkipAudioValidation = true
+	client = openai.NewClientWithConfig(config)
+	_, err = client.CreateTranscription(ctx, openai.AudioRequest{
+		Reader:   bytes.NewReader(make([]byte, 11)),
+		FileName: "custom.aiff",
+		Model:    openai.Whisper1,
+	})
+	checks.NoError(t, err, "SkipAudioValidation should disable the checks")
+}
+
+func TestAudioMultipartFields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fields := map[string][]string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		var names []string
+		for name := range r.MultipartForm.Value {
+			names = append(names, name)
+		}
+		for name := range r.MultipartForm.File {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields[r.URL.Path] = names
+		_, _ = w.Write([]byte(`{"task":"translate","text":"Hello","segments":[{"id":0,"text":"Hello"}]}`))
+	}
+	server.RegisterHandler("/v1/audio/transcriptions", handler)
+	server.RegisterHandler("/v1/audio/translations", handler)
+	ctx := context.Background()
+
+	request := openai.AudioRequest{
+		Reader:      bytes.NewBufferString("some mp3 data"),
+		FileName:    "hello.mp3",
+		Model:       openai.Whisper1,
+		Prompt:      "Greetings.",
+		Temperature: 0.2,
+		Format:      openai.AudioResponseFormatVerboseJSON,
+	}
+	resp, err := client.CreateTranslation(ctx, request)
+	checks.NoError(t, err, "CreateTranslation error")
+	if len(resp.Segments) != 1 {
+		t.Errorf("unexpected translation %+v", resp)
+	}
+
+	request.Reader = bytes.NewBufferString("some mp3 data")
+	request.Language = "fr"
+	request.TimestampGranularities = []openai.TranscriptionTimestampGranularity{
+		openai.TranscriptionTimestampGranularityWord,
+	}
+	request.Include = []openai.TranscriptionInclude{openai.TranscriptionIncludeLogprobs}
+	_, err = client.CreateTranscription(ctx, request)
+	checks.NoError(t, err, "CreateTranscription error")
+
+	want := map[string][]string{
+		"/v1/audio/translations": {"file", "model", "prompt", "response_format", "temperature"},
+		"/v1/audio/transcriptions": {"file", "include[]", "language", "model", "prompt", "response_format",
+			"temperature", "timestamp_granularities[]"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	// Transcription-only fields are rejected for translations instead of being
+	// ignored.
+	for _, field := range []string{"language", "timestamp_granularities", "include"} {
+		translation := openai.AudioRequest{
+			Reader:   bytes.NewBufferString("some mp3 data"),
+			FileName: "hello.mp3",
+			Model:    openai.Whisper1,
+			Format:   openai.AudioResponseFormatVerboseJSON,
+		}
+		switch field {
+		case "language":
+			translation.Language = "fr"
+		case "timestamp_granularities":
+			translation.TimestampGranularities = request.TimestampGranularities
+		case "include":
+			translation.Include = request.Include
+		}
+		_, err = client.CreateTranslation(ctx, translation)
+		checks.ErrorIs(t, err, openai.ErrTranslationUnsupportedParam, field+" should be rejected")
+	}
+}
+
 // handleAudioEndpoint Handles the completion endpoint by the test server.
 func handleAudioEndpoint(w http.ResponseWriter, r *http.Request) {
 	var err error
@@ -158,3 +435,58 @@ func handleAudioEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func TestTranscriptionUsage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"text":"Hello world","usage":{"type":"tokens","input_tokens":14,` +
+			`"input_token_details":{"text_tokens":0,"audio_tokens":14},"output_tokens":45,"total_tokens":59}}`))
+	})
+
+	resp, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		FilePath: "hello.mp3",
+		Reader:   bytes.NewBufferString("some mp3 data"),
+		Model:    "gpt-4o-transcribe",
+		Format:   openai.AudioResponseFormatJSON,
+	})
+	checks.NoError(t, err, "CreateTranscription error")
+	if resp.Usage == nil || resp.Usage.TotalTokens != 59 || resp.Usage.InputTokenDetails == nil ||
+		resp.Usage.InputTokenDetails.AudioTokens != 14 {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
+
+func TestAudioFormatSniffing(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var filename, contentType, content string
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		checks.NoError(t, err, "FormFile error")
+		data, _ := io.ReadAll(file)
+		filename, contentType, content = header.Filename, header.Header.Get("Content-Type"), string(data)
+		_, _ = w.Write([]byte(`{"text":"ok"}`))
+	})
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "recording")
+	checks.NoError(t, os.WriteFile(path, []byte("fLaC flac data"), 0o600), "WriteFile error")
+	_, err := client.CreateTranscription(ctx, openai.AudioRequest{FilePath: path, Model: openai.Whisper1})
+	checks.NoError(t, err, "a file without an extension should be identified by its content")
+	if filename != "recording.flac" || contentType != "audio/flac" || content != "fLaC flac data" {
+		t.Errorf("unexpected upload %q, %q: %q", filename, contentType, content)
+	}
+
+	readers := map[string]io.Reader{
+		"seeker":     strings.NewReader("ID3 mp3 data"),
+		"non-seeker": io.MultiReader(strings.NewReader("ID3 mp3 data")),
+	}
+	for name, reader := range readers {
+		_, err = client.CreateTranscription(ctx, openai.AudioRequest{Reader: reader, Model: openai.Whisper1})
+		checks.NoError(t, err, name+" without a name should be identified by its content")
+		if filename != "audio.mp3" || contentType != "audio/mpeg" || content != "ID3 mp3 data" {
+			t.Errorf("unexpected upload of the %s %q, %q: %q", name, filename, contentType, content)
+		}
+	}
+}
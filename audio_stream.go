@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+// TranscriptionInclude is additional information to include in a transcription.
+type TranscriptionInclude string
+
+// TranscriptionIncludeLogprobs includes the log probabilities of the tokens of a
+// transcription, in the events of a TranscriptionStream. It only works with the
+// json response format and the gpt-4o transcription models.
+const TranscriptionIncludeLogprobs TranscriptionInclude = "logprobs"
+
+// Types of the events of a TranscriptionStream.
+const (
+	TranscriptionStreamEventDelta = "transcript.text.delta"
+	TranscriptionStreamEventDone  = "transcript.text.done"
+)
+
+// TranscriptionStreamEvent is an event of a TranscriptionStream: either a piece
+// of the transcript, or the full transcript once it is done.
+type TranscriptionStreamEvent struct {
+	Type string `json:"type"`
+	// Delta is the text added to the transcript, for delta events.
+	Delta string `json:"delta,omitempty"`
+	// Text is the full transcript, for the done event.
+	Text string `json:"text,omitempty"`
+	// Logprobs are the log probabilities of the tokens of Delta or Text, sent when
+	// the request includes TranscriptionIncludeLogprobs.
+	Logprobs []TranscriptionLogprob `json:"logprobs,omitempty"`
+	// Usage is the token usage of the request, sent with the done event.
+	Usage *TranscriptionUsage `json:"usage,omitempty"`
+}
+
+// TranscriptionLogprob is the log probability of a token of a transcription.
+type TranscriptionLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// TranscriptionUsage is the token usage of a transcription with a gpt-4o model,
+// of Type "tokens", or the duration of the transcribed audio, of Type "duration".
+type TranscriptionUsage struct {
+	Type              string                          `json:"type"`
+	InputTokens       int                             `json:"input_tokens"`
+	OutputTokens      int                             `json:"output_tokens"`
+	TotalTokens       int                             `json:"total_tokens"`
+	InputTokenDetails *TranscriptionInputTokenDetails `json:"input_token_details,omitempty"`
+	// Seconds is the billed duration of the audio, for duration usage.
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+// TranscriptionInputTokenDetails splits the input tokens of a transcription by
+// modality.
+type TranscriptionInputTokenDetails struct {
+	TextTokens  int `json:"text_tokens"`
+	AudioTokens int `json:"audio_tokens"`
+}
+
+type TranscriptionStream struct {
+	*streamReader[TranscriptionStreamEvent]
+}
+
+// CreateTranscriptionStream — API call to create a transcription streamed as it is
+// generated, with the gpt-4o transcription models. Recv returns the delta events
+// followed by the done event, then io.EOF.
+func (c *Client) CreateTranscriptionStream(
+	ctx context.Context,
+	request AudioRequest,
+) (stream *TranscriptionStream, err error) {
+	if err = c.validateAudioRequest(&request, "transcriptions"); err != nil {
+		return
+	}
+
+	url := c.fullURL("/audio/transcriptions", withModel(request.Model))
+	uploadStream := request.Reader == nil || shouldStreamUpload(request.Reader)
+	build := func(b utils.FormBuilder) error {
+		if err := b.WriteField("stream", "true"); err != nil {
+			return fmt.Errorf("writing stream: %w", err)
+		}
+		return audioMultipartForm(request, b)
+	}
+	req, body, err := c.newMultipartRequest(ctx, url, uploadStream, request.Progress, build)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[TranscriptionStreamEvent](c, req)
+	if body != nil {
+		// The form has been sent once the response is received, unless producing
+		// it failed, which is then the cause of the error.
+		if buildErr := body.buildErr(); buildErr != nil && err != nil {
+			err = buildErr
+		}
+	}
+	if err != nil {
+		return
+	}
+	stream = &TranscriptionStream{
+		streamReader: resp,
+	}
+	return
+}
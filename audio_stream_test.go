@@ -0,0 +1,91 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateTranscriptionStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		form := r.MultipartForm
+		if form.Value["stream"][0] != "true" || form.Value["include[]"][0] != "logprobs" ||
+			form.Value["model"][0] != "gpt-4o-mini-transcribe" || len(form.File["file"]) != 1 {
+			http.Error(w, "unexpected form", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"type":"transcript.text.delta","delta":"Hello",` +
+			`"logprobs":[{"token":"Hello","logprob":-0.01,"bytes":[72,101,108,108,111]}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"type":"transcript.text.delta","delta":" world"}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"type":"transcript.text.done","text":"Hello world","usage":{"type":"tokens",` +
+			`"input_tokens":14,"output_tokens":2,"total_tokens":16,"input_token_details":` +
+			`{"text_tokens":0,"audio_tokens":14}}}` + "\n\n"))
+	})
+
+	stream, err := client.CreateTranscriptionStream(context.Background(), openai.AudioRequest{
+		Reader:   bytes.NewBufferString("some mp3 data"),
+		FileName: "hello.mp3",
+		Model:    "gpt-4o-mini-transcribe",
+		Include:  []openai.TranscriptionInclude{openai.TranscriptionIncludeLogprobs},
+	})
+	checks.NoError(t, err, "CreateTranscriptionStream error")
+	defer stream.Close()
+
+	var text string
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		switch event.Type {
+		case openai.TranscriptionStreamEventDelta:
+			text += event.Delta
+			if event.Delta == "Hello" && (len(event.Logprobs) != 1 || len(event.Logprobs[0].Bytes) != 5) {
+				t.Errorf("unexpected logprobs %+v", event.Logprobs)
+			}
+		case openai.TranscriptionStreamEventDone:
+			if event.Text != text || event.Usage == nil || event.Usage.TotalTokens != 16 ||
+				event.Usage.InputTokenDetails.AudioTokens != 14 {
+				t.Errorf("unexpected done event %+v", event)
+			}
+		default:
+			t.Errorf("unexpected event type %q", event.Type)
+		}
+	}
+	if text != "Hello world" {
+		t.Errorf("unexpected transcript %q", text)
+	}
+}
+
+func TestCreateTranscriptionStreamErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"unsupported model","type":"invalid_request_error"}}`))
+	})
+
+	_, err := client.CreateTranscriptionStream(context.Background(), openai.AudioRequest{
+		Reader:   bytes.NewBufferString("some mp3 data"),
+		FileName: "hello.mp3",
+		Model:    openai.Whisper1,
+	})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Errorf("expected API error, got %v", err)
+	}
+
+	_, err = client.CreateTranscriptionStream(context.Background(), openai.AudioRequest{Model: openai.Whisper1})
+	checks.ErrorIs(t, err, openai.ErrAudioFileRequired, "request without audio should be rejected")
+}
@@ -35,13 +35,13 @@ func TestAudioWithFailingFormBuilder(t *testing.T) {
 	mockFailedErr := fmt.Errorf("mock form builder fail")
 	mockBuilder := &mockFormBuilder{}
 
-	mockBuilder.mockCreateFormFile = func(string, *os.File) error {
+	mockBuilder.mockCreateFormFileContentType = func(string, *os.File) error {
 		return mockFailedErr
 	}
 	err := audioMultipartForm(req, mockBuilder)
 	checks.ErrorIs(t, err, mockFailedErr, "audioMultipartForm should return error if form builder fails")
 
-	mockBuilder.mockCreateFormFile = func(string, *os.File) error {
+	mockBuilder.mockCreateFormFileContentType = func(string, *os.File) error {
 		return nil
 	}
 
@@ -74,7 +74,7 @@ func TestCreateFileField(t *testing.T) {
 
 		mockFailedErr := fmt.Errorf("mock form builder fail")
 		mockBuilder := &mockFormBuilder{
-			mockCreateFormFile: func(string, *os.File) error {
+			mockCreateFormFileContentType: func(string, *os.File) error {
 				return mockFailedErr
 			},
 		}
@@ -119,6 +119,10 @@ func (f *failingFormBuilder) CreateFormFile(_ string, _ *os.File) error {
 	return f.err
 }
 
+func (f *failingFormBuilder) CreateFormFileContentType(_ string, _ *os.File) error {
+	return f.err
+}
+
 func (f *failingFormBuilder) CreateFormFileReader(_ string, _ io.Reader, _ string) error {
 	return f.err
 }
@@ -127,6 +131,10 @@ func (f *failingFormBuilder) WriteField(_, _ string) error {
 	return nil
 }
 
+func (f *failingFormBuilder) WriteJSONField(_ string, _ any) error {
+	return f.err
+}
+
 func (f *failingFormBuilder) Close() error {
 	return nil
 }
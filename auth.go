@@ -0,0 +1,43 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+)
+
+// TokenProvider returns the API key to authenticate a request with. It is called
+// for every request, so it can serve credentials that rotate.
+type TokenProvider func(ctx context.Context) (string, error)
+
+// StaticToken returns a TokenProvider that always returns token.
+func StaticToken(token string) TokenProvider {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// AuthError is returned when the credentials for a request cannot be obtained.
+// The request is not sent.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("error, obtaining API token: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// authToken returns the API key for a request made with ctx.
+func (c *Client) authToken(ctx context.Context) (string, error) {
+	if c.config.TokenProvider == nil {
+		return c.config.authToken, nil
+	}
+	token, err := c.config.TokenProvider(ctx)
+	if err != nil {
+		return "", &AuthError{Err: err}
+	}
+	return token, nil
+}
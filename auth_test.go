@@ -0,0 +1,104 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestTokenProviderRotatesCredentials(t *testing.T) {
+	var seen []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+	mux.HandleFunc("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"id":"file-1"}`))
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+	// The shared test server only accepts the static test token.
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	calls := 0
+	config := openai.DefaultConfig("static-token")
+	config.BaseURL = ts.URL + "/v1"
+	config.TokenProvider = func(context.Context) (string, error) {
+		calls++
+		return fmt.Sprintf("rotated-%d", calls), nil
+	}
+	client := openai.NewClientWithConfig(config)
+	ctx := context.Background()
+
+	_, err := client.ListModels(ctx)
+	checks.NoError(t, err, "ListModels error")
+
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	checks.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644), "failed to write temp file")
+	_, err = client.CreateFile(ctx, openai.FileRequest{FilePath: path, Purpose: "fine-tune"})
+	checks.NoError(t, err, "CreateFile error")
+
+	stream, err := client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model:    openai.GPT4,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+
+	want := []string{"Bearer rotated-1", "Bearer rotated-2", "Bearer rotated-3"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("requests were authenticated with %v, want %v", seen, want)
+	}
+}
+
+type countingDoer struct{ calls int }
+
+func (d *countingDoer) Do(*http.Request) (*http.Response, error) {
+	d.calls++
+	return nil, errors.New("unexpected request")
+}
+
+func TestTokenProviderError(t *testing.T) {
+	errVault := errors.New("vault unavailable")
+	doer := &countingDoer{}
+	config := openai.DefaultConfig("")
+	config.HTTPClient = doer
+	config.TokenProvider = func(context.Context) (string, error) {
+		return "", errVault
+	}
+	client := openai.NewClientWithConfig(config)
+
+	_, err := client.ListModels(context.Background())
+	var authErr *openai.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected AuthError, got %T: %v", err, err)
+	}
+	checks.ErrorIs(t, err, errVault, "AuthError should wrap the provider error")
+	if doer.calls != 0 {
+		t.Fatalf("no request should be sent when the token cannot be obtained, sent %d", doer.calls)
+	}
+}
+
+func TestStaticToken(t *testing.T) {
+	token, err := openai.StaticToken("sk-test")(context.Background())
+	checks.NoError(t, err, "StaticToken error")
+	if token != "sk-test" {
+		t.Fatalf("unexpected token %q", token)
+	}
+}
@@ -0,0 +1,137 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// AzureADScope is the scope of Azure AD (Entra ID) tokens for Azure OpenAI.
+const AzureADScope = "https://cognitiveservices.azure.com/.default"
+
+// azureADRefreshMargin is how long before expiry a cached token is refreshed.
+const azureADRefreshMargin = 5 * time.Minute
+
+// azureADRefreshTimeout bounds a token fetch, which outlives the requests
+// waiting for it.
+const azureADRefreshTimeout = time.Minute
+
+var errEmptyAzureADToken = errors.New("azure AD token source returned an empty token")
+
+// AccessToken is an Azure AD access token and the time it expires.
+type AccessToken struct {
+	Token     string
+	ExpiresOn time.Time
+}
+
+// AzureADTokenSource fetches an Azure AD token for scopes. It matches the shape of
+// azidentity credentials, which can be adapted with:
+//
+//	func(ctx context.Context, scopes []string) (openai.AccessToken, error) {
+//		t, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: scopes})
+//		return openai.AccessToken{Token: t.Token, ExpiresOn: t.ExpiresOn}, err
+//	}
+type AzureADTokenSource func(ctx context.Context, scopes []string) (AccessToken, error)
+
+// NewAzureADTokenProvider returns a TokenProvider serving tokens from source for
+// AzureADScope. Tokens are cached and refreshed a few minutes before they expire;
+// concurrent requests share a single refresh. The refresh does not use the context
+// of the request that started it, so that its cancellation does not fail the
+// others: each request only stops waiting for it when its own context is done.
+func NewAzureADTokenProvider(source AzureADTokenSource) TokenProvider {
+	cache := &azureADTokenCache{source: source}
+	return cache.get
+}
+
+// DefaultAzureADConfig returns a config for Azure OpenAI authenticating with
+// Azure AD tokens from source, sent as Authorization: Bearer.
+func DefaultAzureADConfig(baseURL string, source AzureADTokenSource) ClientConfig {
+	return ClientConfig{
+		BaseURL:       baseURL,
+		OrgID:         "",
+		APIType:       APITypeAzureAD,
+		APIVersion:    "2023-05-15",
+		TokenProvider: NewAzureADTokenProvider(source),
+		AzureModelMapperFunc: func(model string) string {
+			return regexp.MustCompile(`[.:]`).ReplaceAllString(model, "")
+		},
+
+		HTTPClient: &http.Client{},
+
+		EmptyMessagesLimit: defaultEmptyMessagesLimit,
+	}
+}
+
+type azureADTokenCache struct {
+	source AzureADTokenSource
+
+	mu       sync.Mutex
+	token    AccessToken
+	inflight *azureADRefresh
+}
+
+// azureADRefresh is a token fetch that concurrent callers wait on together.
+type azureADRefresh struct {
+	done  chan struct{}
+	token AccessToken
+	err   error
+}
+
+func (c *azureADTokenCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	now := time.Now()
+	if c.token.Token != "" && c.token.ExpiresOn.Sub(now) > azureADRefreshMargin {
+		token := c.token.Token
+		c.mu.Unlock()
+		return token, nil
+	}
+
+	refresh := c.inflight
+	leader := refresh == nil
+	if leader {
+		refresh = &azureADRefresh{done: make(chan struct{})}
+		c.inflight = refresh
+	}
+	c.mu.Unlock()
+
+	if leader {
+		go c.refresh(refresh)
+	}
+	select {
+	case <-refresh.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if refresh.err != nil {
+		// A token that is about to expire is still better than none.
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.token.Token != "" && time.Now().Before(c.token.ExpiresOn) {
+			return c.token.Token, nil
+		}
+		return "", refresh.err
+	}
+	return refresh.token.Token, nil
+}
+
+func (c *azureADTokenCache) refresh(refresh *azureADRefresh) {
+	ctx, cancel := context.WithTimeout(context.Background(), azureADRefreshTimeout)
+	defer cancel()
+	token, err := c.source(ctx, []string{AzureADScope})
+	if err == nil && token.Token == "" {
+		err = errEmptyAzureADToken
+	}
+
+	c.mu.Lock()
+	refresh.token, refresh.err = token, err
+	if err == nil {
+		c.token = token
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(refresh.done)
+}
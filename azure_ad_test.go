@@ -0,0 +1,185 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestAzureADTokenProviderCaches(t *testing.T) {
+	lifetimes := []time.Duration{time.Hour}
+	var fetches int
+	provider := NewAzureADTokenProvider(func(_ context.Context, scopes []string) (AccessToken, error) {
+		if len(scopes) != 1 || scopes[0] != AzureADScope {
+			t.Errorf("unexpected scopes %v", scopes)
+		}
+		lifetime := lifetimes[fetches]
+		fetches++
+		return AccessToken{Token: fmt.Sprintf("token-%d", fetches), ExpiresOn: time.Now().Add(lifetime)}, nil
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		token, err := provider(ctx)
+		checks.NoError(t, err, "provider error")
+		if token != "token-1" {
+			t.Fatalf("expected cached token, got %q", token)
+		}
+	}
+}
+
+func TestAzureADTokenProviderRefreshesBeforeExpiry(t *testing.T) {
+	lifetimes := []time.Duration{time.Minute, time.Hour}
+	var fetches int
+	provider := NewAzureADTokenProvider(func(context.Context, []string) (AccessToken, error) {
+		lifetime := lifetimes[fetches]
+		fetches++
+		return AccessToken{Token: fmt.Sprintf("token-%d", fetches), ExpiresOn: time.Now().Add(lifetime)}, nil
+	})
+	ctx := context.Background()
+
+	token, err := provider(ctx)
+	checks.NoError(t, err, "provider error")
+	if token != "token-1" {
+		t.Fatalf("unexpected first token %q", token)
+	}
+	// token-1 expires within the refresh margin, so it is replaced.
+	token, err = provider(ctx)
+	checks.NoError(t, err, "provider error")
+	if token != "token-2" || fetches != 2 {
+		t.Fatalf("expected refreshed token-2 after 2 fetches, got %q after %d", token, fetches)
+	}
+}
+
+func TestAzureADTokenProviderSingleflight(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	provider := NewAzureADTokenProvider(func(context.Context, []string) (AccessToken, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return AccessToken{Token: "shared", ExpiresOn: time.Now().Add(time.Hour)}, nil
+	})
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = provider(context.Background())
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Fatalf("expected a single token fetch, got %d", fetches)
+	}
+	for _, token := range tokens {
+		if token != "shared" {
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+}
+
+func TestAzureADTokenProviderCanceledWaiter(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	provider := NewAzureADTokenProvider(func(ctx context.Context, _ []string) (AccessToken, error) {
+		close(started)
+		<-release
+		if ctx.Err() != nil {
+			return AccessToken{}, ctx.Err()
+		}
+		return AccessToken{Token: "shared", ExpiresOn: time.Now().Add(time.Hour)}, nil
+	})
+
+	// The request starting the refresh gives up, which fails neither the
+	// refresh nor the other requests waiting for it.
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := provider(ctx)
+		leaderErr <- err
+	}()
+	<-started
+	token := make(chan string, 1)
+	go func() {
+		shared, _ := provider(context.Background())
+		token <- shared
+	}()
+	cancel()
+	checks.ErrorIs(t, <-leaderErr, context.Canceled, "the canceled request should stop waiting")
+	close(release)
+	if got := <-token; got != "shared" {
+		t.Fatalf("unexpected token %q", got)
+	}
+}
+
+func TestAzureADTokenProviderFailure(t *testing.T) {
+	errIdentity := errors.New("identity endpoint unavailable")
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	config := DefaultAzureADConfig(ts.URL, func(context.Context, []string) (AccessToken, error) {
+		return AccessToken{}, errIdentity
+	})
+	client := NewClientWithConfig(config)
+
+	_, err := client.ListModels(context.Background())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || !errors.Is(err, errIdentity) {
+		t.Fatalf("expected AuthError wrapping the source error, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("no request should reach the API, got %d", requests)
+	}
+}
+
+func TestAzureADTokenProviderKeepsValidTokenOnRefreshFailure(t *testing.T) {
+	calls := 0
+	provider := NewAzureADTokenProvider(func(context.Context, []string) (AccessToken, error) {
+		calls++
+		if calls == 1 {
+			return AccessToken{Token: "expiring", ExpiresOn: time.Now().Add(time.Minute)}, nil
+		}
+		return AccessToken{}, errors.New("refresh failed")
+	})
+
+	_, _ = provider(context.Background())
+	token, err := provider(context.Background())
+	checks.NoError(t, err, "a still valid token should be used when refresh fails")
+	if token != "expiring" {
+		t.Fatalf("unexpected token %q", token)
+	}
+}
+
+func TestAzureADConfigSendsBearerToken(t *testing.T) {
+	var auth, apiKey string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, apiKey = r.Header.Get("Authorization"), r.Header.Get(AzureAPIKeyHeader)
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer ts.Close()
+
+	config := DefaultAzureADConfig(ts.URL, func(context.Context, []string) (AccessToken, error) {
+		return AccessToken{Token: "aad-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+	})
+	_, err := NewClientWithConfig(config).ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if auth != "Bearer aad-token" || apiKey != "" {
+		t.Fatalf("unexpected auth headers Authorization=%q api-key=%q", auth, apiKey)
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 const batchesSuffix = "/batches"
 
+// ErrBatchFailed is returned by WaitForBatch for batches that failed, usually
+// because their input file was invalid.
+var ErrBatchFailed = errors.New("batch failed")
+
 type BatchEndpoint string
 
 const (
@@ -74,7 +80,7 @@ type Batch struct {
 	} `json:"errors"`
 	InputFileID      string             `json:"input_file_id"`
 	CompletionWindow string             `json:"completion_window"`
-	Status           string             `json:"status"`
+	Status           BatchStatus        `json:"status"`
 	OutputFileID     *string            `json:"output_file_id"`
 	ErrorFileID      *string            `json:"error_file_id"`
 	CreatedAt        int                `json:"created_at"`
@@ -90,6 +96,30 @@ type Batch struct {
 	Metadata         map[string]any     `json:"metadata"`
 }
 
+// BatchStatus is the status of a batch. Completed, failed, expired and cancelled
+// batches are done; a cancelling batch is still finishing its running requests.
+type BatchStatus string
+
+const (
+	BatchStatusValidating BatchStatus = "validating"
+	BatchStatusFailed     BatchStatus = "failed"
+	BatchStatusInProgress BatchStatus = "in_progress"
+	BatchStatusFinalizing BatchStatus = "finalizing"
+	BatchStatusCompleted  BatchStatus = "completed"
+	BatchStatusExpired    BatchStatus = "expired"
+	BatchStatusCancelling BatchStatus = "cancelling"
+	BatchStatusCancelled  BatchStatus = "cancelled"
+)
+
+// Done reports whether s is a final status.
+func (s BatchStatus) Done() bool {
+	switch s {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
 type BatchRequestCounts struct {
 	Total     int `json:"total"`
 	Completed int `json:"completed"`
@@ -248,19 +278,36 @@ type ListBatchResponse struct {
 
 // ListBatch API call to List batch.
 func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (response ListBatchResponse, err error) {
-	urlValues := url.Values{}
+	params := ListBatchesParams{}
+	if after != nil {
+		params.After = *after
+	}
 	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
+		params.Limit = *limit
 	}
-	if after != nil {
-		urlValues.Add("after", *after)
+	return c.ListBatches(ctx, params)
+}
+
+// ListBatchesParams pages the batches listed by ListBatches. Zero values are left
+// to the API defaults.
+type ListBatchesParams struct {
+	// Limit is the number of batches per page.
+	Limit int
+	// After is the ID of the batch after which the page starts.
+	After string
+}
+
+// ListBatches lists a page of the batches of the organization, newest first.
+func (c *Client) ListBatches(ctx context.Context, params ListBatchesParams) (response ListBatchResponse, err error) {
+	urlValues := url.Values{}
+	if params.Limit > 0 {
+		urlValues.Add("limit", strconv.Itoa(params.Limit))
 	}
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
+	if params.After != "" {
+		urlValues.Add("after", params.After)
 	}
 
-	urlSuffix := fmt.Sprintf("%s%s", batchesSuffix, encodedValues)
+	urlSuffix := fmt.Sprintf("%s%s", batchesSuffix, encodeQuery(urlValues))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
 	if err != nil {
 		return
@@ -269,3 +316,66 @@ func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (resp
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// ListBatchesAll lists all the batches, following the pages of ListBatches from
+// params.After on.
+func (c *Client) ListBatchesAll(ctx context.Context, params ListBatchesParams) ([]Batch, error) {
+	return newListIterator(ctx, params.After, func(ctx context.Context, after string) ([]Batch, string, bool, error) {
+		params.After = after
+		page, err := c.ListBatches(ctx, params)
+		next := page.LastID
+		if next == "" {
+			next = lastID(page.Data, func(batch Batch) string { return batch.ID })
+		}
+		return page.Data, next, page.HasMore, err
+	}).all()
+}
+
+// WaitForBatch polls a batch until it is done, and returns it. If onProgress is
+// not nil, it is called with the request counts of the batch whenever they
+// change. A failed batch is returned with an error wrapping ErrBatchFailed;
+// expired and cancelled batches, which may have partial results, are returned
+// without error.
+func (c *Client) WaitForBatch(
+	ctx context.Context,
+	batchID string,
+	opts PollOptions,
+	onProgress func(BatchRequestCounts),
+) (batch BatchResponse, err error) {
+	var (
+		counts   BatchRequestCounts
+		reported bool
+	)
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.RetrieveBatch(ctx, batchID)
+		if err != nil {
+			return false, err
+		}
+		batch = polled
+
+		if onProgress != nil && (!reported || counts != batch.RequestCounts) {
+			counts, reported = batch.RequestCounts, true
+			onProgress(counts)
+		}
+		if batch.Status == BatchStatusFailed {
+			return true, batchFailedError(batch.Batch)
+		}
+		return batch.Status.Done(), nil
+	})
+	return
+}
+
+func batchFailedError(batch Batch) error {
+	if batch.Errors == nil || len(batch.Errors.Data) == 0 {
+		return fmt.Errorf("%w: no reason given", ErrBatchFailed)
+	}
+	first := batch.Errors.Data[0]
+	reason := fmt.Sprintf("%s (code: %s)", first.Message, first.Code)
+	if first.Line != nil {
+		reason += fmt.Sprintf(", line %d", *first.Line)
+	}
+	if n := len(batch.Errors.Data); n > 1 {
+		reason += fmt.Sprintf(", and %d more errors", n-1)
+	}
+	return fmt.Errorf("%w: %s", ErrBatchFailed, reason)
+}
@@ -0,0 +1,187 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// MaxBatchRequests is the number of requests a batch can have.
+	MaxBatchRequests = 50000
+	// MaxBatchFileBytes is the size a batch input file can have.
+	MaxBatchFileBytes = 200 << 20
+)
+
+var (
+	ErrBatchNoRequests        = errors.New("batch has no requests")
+	ErrBatchDuplicateCustomID = errors.New("batch custom IDs must be unique")
+	ErrBatchMixedEndpoints    = errors.New("batch requests must all use the same endpoint")
+	ErrBatchTooManyRequests   = fmt.Errorf("batch cannot have more than %d requests", MaxBatchRequests)
+	ErrBatchFileTooLarge      = fmt.Errorf("batch input file cannot be larger than %d bytes", MaxBatchFileBytes)
+)
+
+// BatchChatCompletionItem is a chat completion request of a batch, identified in
+// the batch output by CustomID.
+type BatchChatCompletionItem struct {
+	CustomID string
+	Body     ChatCompletionRequest
+}
+
+// BatchCompletionItem is a completion request of a batch, identified in the
+// batch output by CustomID.
+type BatchCompletionItem struct {
+	CustomID string
+	Body     CompletionRequest
+}
+
+// BatchEmbeddingItem is an embedding request of a batch, identified in the batch
+// output by CustomID.
+type BatchEmbeddingItem struct {
+	CustomID string
+	Body     EmbeddingRequest
+}
+
+// CreateBatchOptions configures the batches created from typed requests.
+type CreateBatchOptions struct {
+	// FileName is the name of the uploaded input file, "@batchinput.jsonl" by
+	// default.
+	FileName string
+	// CompletionWindow is the time frame of the batch, "24h" by default.
+	CompletionWindow string
+	Metadata         map[string]any
+}
+
+// CreateChatCompletionBatch uploads the chat completion requests as a batch
+// input file and creates a batch processing it. It returns the uploaded file
+// along with the batch, or the file alone if the batch could not be created.
+func (c *Client) CreateChatCompletionBatch(
+	ctx context.Context,
+	items []BatchChatCompletionItem,
+	options CreateBatchOptions,
+) (File, BatchResponse, error) {
+	request := UploadBatchFileRequest{FileName: options.FileName}
+	for _, item := range items {
+		request.AddChatCompletion(item.CustomID, item.Body)
+	}
+	return c.createBatchFromRequest(ctx, request, options)
+}
+
+// CreateCompletionBatch uploads the completion requests as a batch input file
+// and creates a batch processing it, like CreateChatCompletionBatch.
+func (c *Client) CreateCompletionBatch(
+	ctx context.Context,
+	items []BatchCompletionItem,
+	options CreateBatchOptions,
+) (File, BatchResponse, error) {
+	request := UploadBatchFileRequest{FileName: options.FileName}
+	for _, item := range items {
+		request.AddCompletion(item.CustomID, item.Body)
+	}
+	return c.createBatchFromRequest(ctx, request, options)
+}
+
+// CreateEmbeddingBatch uploads the embedding requests as a batch input file and
+// creates a batch processing it, like CreateChatCompletionBatch.
+func (c *Client) CreateEmbeddingBatch(
+	ctx context.Context,
+	items []BatchEmbeddingItem,
+	options CreateBatchOptions,
+) (File, BatchResponse, error) {
+	request := UploadBatchFileRequest{FileName: options.FileName}
+	for _, item := range items {
+		request.AddEmbedding(item.CustomID, item.Body)
+	}
+	return c.createBatchFromRequest(ctx, request, options)
+}
+
+func (c *Client) createBatchFromRequest(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	options CreateBatchOptions,
+) (file File, batch BatchResponse, err error) {
+	data, endpoint, err := request.encode()
+	if err != nil {
+		return
+	}
+	if request.FileName == "" {
+		request.FileName = "@batchinput.jsonl"
+	}
+	file, err = c.CreateFileBytes(ctx, FileBytesRequest{
+		Name:    request.FileName,
+		Bytes:   data,
+		Purpose: PurposeBatch,
+	})
+	if err != nil {
+		return
+	}
+	batch, err = c.CreateBatch(ctx, CreateBatchRequest{
+		InputFileID:      file.ID,
+		Endpoint:         endpoint,
+		CompletionWindow: options.CompletionWindow,
+		Metadata:         options.Metadata,
+	})
+	return
+}
+
+// Validate checks the limits of the batch API before the file is uploaded: the
+// file must have between 1 and MaxBatchRequests lines and at most
+// MaxBatchFileBytes bytes, with unique custom IDs and a single endpoint.
+func (r *UploadBatchFileRequest) Validate() error {
+	_, _, err := r.encode()
+	return err
+}
+
+// encode validates the lines and returns them as JSONL, along with their
+// endpoint.
+func (r *UploadBatchFileRequest) encode() ([]byte, BatchEndpoint, error) {
+	if len(r.Lines) == 0 {
+		return nil, "", ErrBatchNoRequests
+	}
+	if len(r.Lines) > MaxBatchRequests {
+		return nil, "", fmt.Errorf("%w, got %d", ErrBatchTooManyRequests, len(r.Lines))
+	}
+
+	var (
+		buff      bytes.Buffer
+		endpoint  BatchEndpoint
+		customIDs = make(map[string]int, len(r.Lines))
+	)
+	for i, line := range r.Lines {
+		data := line.MarshalBatchLineItem()
+		var envelope struct {
+			CustomID string        `json:"custom_id"`
+			Method   string        `json:"method"`
+			URL      BatchEndpoint `json:"url"`
+		}
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, "", fmt.Errorf("batch line %d: %w", i, err)
+		}
+		if j, ok := customIDs[envelope.CustomID]; ok {
+			return nil, "", fmt.Errorf("%w: %q is used by lines %d and %d",
+				ErrBatchDuplicateCustomID, envelope.CustomID, j, i)
+		}
+		customIDs[envelope.CustomID] = i
+		if i == 0 {
+			endpoint = envelope.URL
+		} else if envelope.URL != endpoint {
+			return nil, "", fmt.Errorf("%w: line %d uses %s instead of %s",
+				ErrBatchMixedEndpoints, i, envelope.URL, endpoint)
+		}
+		if envelope.Method != http.MethodPost {
+			return nil, "", fmt.Errorf("batch line %d: unsupported method %q", i, envelope.Method)
+		}
+
+		if i != 0 {
+			buff.WriteByte('\n')
+		}
+		buff.Write(data)
+		if buff.Len() > MaxBatchFileBytes {
+			return nil, "", fmt.Errorf("%w, exceeded at line %d", ErrBatchFileTooLarge, i)
+		}
+	}
+	return buff.Bytes(), endpoint, nil
+}
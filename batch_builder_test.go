@@ -0,0 +1,108 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateChatCompletionBatch(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var fileName, purpose, content string
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		fileName, purpose, content = header.Filename, r.FormValue("purpose"), string(data)
+		fmt.Fprint(w, `{"id":"file-batch","object":"file","purpose":"batch"}`)
+	})
+	var batchRequest openai.CreateBatchRequest
+	server.RegisterHandler("/v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&batchRequest)
+		fmt.Fprint(w, `{"id":"batch_abc123","object":"batch","endpoint":"/v1/chat/completions",`+
+			`"input_file_id":"file-batch","completion_window":"24h","status":"validating"}`)
+	})
+
+	items := make([]openai.BatchChatCompletionItem, 2)
+	for i := range items {
+		items[i] = openai.BatchChatCompletionItem{
+			CustomID: fmt.Sprintf("req-%d", i),
+			Body: openai.ChatCompletionRequest{
+				Model:    openai.GPT4oMini,
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+			},
+		}
+	}
+	file, batch, err := client.CreateChatCompletionBatch(context.Background(), items, openai.CreateBatchOptions{
+		Metadata: map[string]any{"job": "nightly"},
+	})
+	checks.NoError(t, err, "CreateChatCompletionBatch error")
+
+	if file.ID != "file-batch" || batch.ID != "batch_abc123" {
+		t.Errorf("unexpected file %+v and batch %+v", file, batch)
+	}
+	if fileName != "@batchinput.jsonl" || purpose != string(openai.PurposeBatch) {
+		t.Errorf("unexpected upload %q with purpose %q", fileName, purpose)
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], `{"custom_id":"req-1","body":{"model":"gpt-4o-mini"`) ||
+		!strings.HasSuffix(lines[1], `"method":"POST","url":"/v1/chat/completions"}`) {
+		t.Errorf("unexpected input file:\n%s", content)
+	}
+	if batchRequest.InputFileID != "file-batch" || batchRequest.Endpoint != openai.BatchEndpointChatCompletions ||
+		batchRequest.CompletionWindow != "24h" || batchRequest.Metadata["job"] != "nightly" {
+		t.Errorf("unexpected batch request %+v", batchRequest)
+	}
+}
+
+func TestCreateBatchValidation(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := 0
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, _ *http.Request) {
+		uploads++
+		fmt.Fprint(w, `{"id":"file-batch"}`)
+	})
+	ctx := context.Background()
+
+	_, _, err := client.CreateEmbeddingBatch(ctx, nil, openai.CreateBatchOptions{})
+	checks.ErrorIs(t, err, openai.ErrBatchNoRequests, "empty batches should fail")
+
+	embedding := openai.EmbeddingRequest{Input: "hello", Model: openai.SmallEmbedding3}
+	_, _, err = client.CreateEmbeddingBatch(ctx, []openai.BatchEmbeddingItem{
+		{CustomID: "a", Body: embedding}, {CustomID: "b", Body: embedding}, {CustomID: "a", Body: embedding},
+	}, openai.CreateBatchOptions{})
+	checks.ErrorIs(t, err, openai.ErrBatchDuplicateCustomID, "duplicate custom IDs should fail")
+
+	items := make([]openai.BatchEmbeddingItem, openai.MaxBatchRequests+1)
+	for i := range items {
+		items[i] = openai.BatchEmbeddingItem{CustomID: fmt.Sprint(i), Body: embedding}
+	}
+	_, _, err = client.CreateEmbeddingBatch(ctx, items, openai.CreateBatchOptions{})
+	checks.ErrorIs(t, err, openai.ErrBatchTooManyRequests, "too many requests should fail")
+
+	mixed := openai.UploadBatchFileRequest{}
+	mixed.AddEmbedding("a", embedding)
+	mixed.AddCompletion("b", openai.CompletionRequest{Model: openai.GPT3Dot5TurboInstruct, Prompt: "hello"})
+	err = mixed.Validate()
+	if !errors.Is(err, openai.ErrBatchMixedEndpoints) {
+		t.Errorf("expected ErrBatchMixedEndpoints, got %v", err)
+	}
+
+	if uploads != 0 {
+		t.Errorf("invalid batches should not be uploaded, got %d uploads", uploads)
+	}
+}
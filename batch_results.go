@@ -0,0 +1,213 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBatchResultNoResponse is returned when decoding a BatchResult without a
+// response body.
+var ErrBatchResultNoResponse = errors.New("batch result has no response")
+
+// BatchResult is the outcome of one request of a batch, read from its output or
+// error file.
+type BatchResult struct {
+	// ID is the ID of the batch request.
+	ID       string
+	CustomID string
+	// StatusCode and RequestID are those of the response, and are zero for
+	// requests that were never sent, such as those of an expired batch.
+	StatusCode int
+	RequestID  string
+	// Response is the raw body of a successful response. It is decoded with the
+	// As methods.
+	Response json.RawMessage
+	// Error is set for failed requests.
+	Error *APIError
+}
+
+type batchResultLine struct {
+	ID       string `json:"id"`
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *APIError `json:"error"`
+}
+
+func parseBatchResult(data []byte) (BatchResult, error) {
+	var line batchResultLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		return BatchResult{}, err
+	}
+	if line.CustomID == "" {
+		return BatchResult{}, errors.New("missing custom_id")
+	}
+	result := BatchResult{ID: line.ID, CustomID: line.CustomID, Error: line.Error}
+	if line.Response != nil {
+		result.StatusCode = line.Response.StatusCode
+		result.RequestID = line.Response.RequestID
+		result.Response = line.Response.Body
+	}
+	if result.StatusCode >= http.StatusBadRequest {
+		// The body of failed requests holds their error.
+		var errRes ErrorResponse
+		if err := json.Unmarshal(result.Response, &errRes); err == nil && errRes.Error != nil && result.Error == nil {
+			result.Error = errRes.Error
+		}
+		if result.Error == nil {
+			result.Error = &APIError{Message: failedRequestMessage(result.StatusCode, result.Response)}
+		}
+		result.Response = nil
+	}
+	if result.Error != nil {
+		result.Error.HTTPStatusCode = result.StatusCode
+		if result.StatusCode > 0 {
+			result.Error.HTTPStatus = fmt.Sprintf("%d %s", result.StatusCode, http.StatusText(result.StatusCode))
+		}
+	}
+	return result, nil
+}
+
+// failedRequestMessage returns the message of the error of a failed request
+// whose body holds no error: the body itself, or the status text when empty.
+func failedRequestMessage(statusCode int, body json.RawMessage) string {
+	var text string
+	if json.Unmarshal(body, &text) == nil && text != "" {
+		return text
+	}
+	if body = bytes.TrimSpace(body); len(body) > 0 && string(body) != "null" && string(body) != `""` {
+		return string(body)
+	}
+	return http.StatusText(statusCode)
+}
+
+// Err returns the error of a failed request, or nil.
+func (r BatchResult) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+	return r.Error
+}
+
+// AsChatCompletion decodes the response of a chat completion request.
+func (r BatchResult) AsChatCompletion() (response ChatCompletionResponse, err error) {
+	err = r.decode(&response)
+	return
+}
+
+// AsCompletion decodes the response of a completion request.
+func (r BatchResult) AsCompletion() (response CompletionResponse, err error) {
+	err = r.decode(&response)
+	return
+}
+
+// AsEmbedding decodes the response of an embedding request.
+func (r BatchResult) AsEmbedding() (response EmbeddingResponse, err error) {
+	err = r.decode(&response)
+	return
+}
+
+func (r BatchResult) decode(v any) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if len(r.Response) == 0 {
+		return fmt.Errorf("%w: %s", ErrBatchResultNoResponse, r.CustomID)
+	}
+	return json.Unmarshal(r.Response, v)
+}
+
+// BatchResultParseError is a line of a batch output or error file that could
+// not be parsed.
+type BatchResultParseError struct {
+	FileID string
+	// Line is the 1-based line number in the file.
+	Line int
+	Err  error
+}
+
+func (e *BatchResultParseError) Error() string {
+	return fmt.Sprintf("file %s, line %d: %v", e.FileID, e.Line, e.Err)
+}
+
+func (e *BatchResultParseError) Unwrap() error {
+	return e.Err
+}
+
+// BatchResults are the results of all the requests of a batch.
+type BatchResults struct {
+	// Results are the results of the output file followed by those of the error
+	// file, in file order.
+	Results []BatchResult
+	// ByCustomID indexes Results by their custom ID.
+	ByCustomID map[string]BatchResult
+	// ParseErrors are the lines that could not be parsed.
+	ParseErrors []*BatchResultParseError
+}
+
+// GetBatchResults downloads the output and error files of a batch and parses
+// their results. Lines that cannot be parsed are collected in ParseErrors rather
+// than failing the call. Use ForEachBatchResult to process large files without
+// holding all their results in memory.
+func (c *Client) GetBatchResults(ctx context.Context, batch Batch) (BatchResults, error) {
+	results := BatchResults{ByCustomID: make(map[string]BatchResult)}
+	for _, fileID := range []*string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == nil || *fileID == "" {
+			continue
+		}
+		parseErrors, err := c.ForEachBatchResult(ctx, *fileID, func(result BatchResult) error {
+			results.Results = append(results.Results, result)
+			results.ByCustomID[result.CustomID] = result
+			return nil
+		})
+		results.ParseErrors = append(results.ParseErrors, parseErrors...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// ForEachBatchResult streams the batch output or error file fileID and calls fn
+// with each of its results, in file order. Lines that cannot be parsed are
+// returned rather than failing the call. If fn returns an error, reading stops
+// and the error is returned.
+func (c *Client) ForEachBatchResult(
+	ctx context.Context,
+	fileID string,
+	fn func(BatchResult) error,
+) (parseErrors []*BatchResultParseError, err error) {
+	content, err := c.GetFileContent(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	reader := bufio.NewReader(content)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return parseErrors, readErr
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			result, parseErr := parseBatchResult(data)
+			if parseErr != nil {
+				parseErrors = append(parseErrors, &BatchResultParseError{FileID: fileID, Line: line, Err: parseErr})
+			} else if err = fn(result); err != nil {
+				return parseErrors, err
+			}
+		}
+		if readErr != nil {
+			return parseErrors, nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const (
+	batchOutputFile = `{"id":"batch_req_1","custom_id":"req-1","response":{"status_code":200,` +
+		`"request_id":"req_abc","body":{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini",` +
+		`"choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}]}},` +
+		`"error":null}
+not json
+{"id":"batch_req_2","custom_id":"req-2","response":{"status_code":400,"request_id":"req_def",` +
+		`"body":{"error":{"message":"Invalid model","type":"invalid_request_error","code":"model_not_found"}}},` +
+		`"error":null}
+`
+	batchErrorFile = `{"id":"batch_req_3","custom_id":"req-3","response":null,` +
+		`"error":{"code":"batch_expired","message":"This request could not be executed before the batch expired."}}
+{"id":"batch_req_4","custom_id":"req-4","response":{"status_code":502,"body":"upstream timed out"},"error":null}
+{"id":"batch_req_5","custom_id":"req-5","response":{"status_code":500,"body":null},"error":null}`
+)
+
+func TestGetBatchResults(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-out/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, batchOutputFile)
+	})
+	server.RegisterHandler("/v1/files/file-err/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, batchErrorFile)
+	})
+	outputFileID, errorFileID := "file-out", "file-err"
+
+	results, err := client.GetBatchResults(context.Background(), openai.Batch{
+		OutputFileID: &outputFileID,
+		ErrorFileID:  &errorFileID,
+	})
+	checks.NoError(t, err, "GetBatchResults error")
+
+	if len(results.Results) != 5 || len(results.ByCustomID) != 5 || results.Results[2].CustomID != "req-3" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(results.ParseErrors) != 1 || results.ParseErrors[0].Line != 2 || results.ParseErrors[0].FileID != "file-out" {
+		t.Errorf("unexpected parse errors %v", results.ParseErrors)
+	}
+
+	ok := results.ByCustomID["req-1"]
+	chat, err := ok.AsChatCompletion()
+	checks.NoError(t, err, "AsChatCompletion error")
+	if ok.StatusCode != 200 || ok.RequestID != "req_abc" || chat.Choices[0].Message.Content != "Hello!" {
+		t.Errorf("unexpected result %+v", ok)
+	}
+
+	_, err = results.ByCustomID["req-2"].AsChatCompletion()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != 400 || apiErr.Code != "model_not_found" {
+		t.Errorf("expected the APIError of the response, got %v", err)
+	}
+
+	expired := results.ByCustomID["req-3"]
+	if expired.StatusCode != 0 || expired.Err() == nil || expired.Error.Code != "batch_expired" {
+		t.Errorf("unexpected expired result %+v", expired)
+	}
+	_, err = expired.AsEmbedding()
+	checks.HasError(t, err, "decoding a failed result should fail")
+
+	// Failed requests without an error body get one from their status.
+	for customID, message := range map[string]string{"req-4": "upstream timed out", "req-5": "Internal Server Error"} {
+		failed := results.ByCustomID[customID]
+		if !errors.As(failed.Err(), &apiErr) || apiErr.Message != message || apiErr.HTTPStatusCode != failed.StatusCode {
+			t.Errorf("unexpected error of %s: %v", customID, failed.Err())
+		}
+	}
+}
+
+func TestForEachBatchResultStopsOnError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-out/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, batchOutputFile)
+	})
+
+	stop := errors.New("stop")
+	calls := 0
+	_, err := client.ForEachBatchResult(context.Background(), "file-out", func(openai.BatchResult) error {
+		calls++
+		return stop
+	})
+	checks.ErrorIs(t, err, stop, "the callback error should be returned")
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+
+	_, err = openai.BatchResult{CustomID: "req-1"}.AsEmbedding()
+	checks.ErrorIs(t, err, openai.ErrBatchResultNoResponse, "results without a response should fail")
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
@@ -92,6 +93,79 @@ func TestListBatch(t *testing.T) {
 	checks.NoError(t, err, "RetrieveBatch error")
 }
 
+func TestListBatchesAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "unexpected limit", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"batch_3"},{"id":"batch_2"}],"first_id":"batch_3",`+
+				`"last_id":"batch_2","has_more":true}`)
+		case "batch_2":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"batch_1"}],"first_id":"batch_1",`+
+				`"last_id":"batch_1","has_more":false}`)
+		}
+	})
+
+	batches, err := client.ListBatchesAll(context.Background(), openai.ListBatchesParams{Limit: 2})
+	checks.NoError(t, err, "ListBatchesAll error")
+	if len(batches) != 3 || batches[2].ID != "batch_1" {
+		t.Errorf("unexpected batches %+v", batches)
+	}
+}
+
+func TestWaitForBatch(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	// A cancelling batch is not done until it is cancelled.
+	polls := []string{
+		`"status":"in_progress","request_counts":{"total":10,"completed":2,"failed":0}`,
+		`"status":"in_progress","request_counts":{"total":10,"completed":2,"failed":0}`,
+		`"status":"cancelling","request_counts":{"total":10,"completed":5,"failed":1}`,
+		`"status":"cancelled","request_counts":{"total":10,"completed":6,"failed":1}`,
+	}
+	poll := 0
+	server.RegisterHandler("/v1/batches/batch_abc123", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":"batch_abc123",%s}`, polls[poll])
+		poll++
+	})
+
+	var progress []openai.BatchRequestCounts
+	batch, err := client.WaitForBatch(context.Background(), "batch_abc123",
+		openai.PollOptions{Interval: time.Millisecond}, func(counts openai.BatchRequestCounts) {
+			progress = append(progress, counts)
+		})
+	checks.NoError(t, err, "WaitForBatch error")
+	if batch.Status != openai.BatchStatusCancelled || poll != len(polls) {
+		t.Errorf("unexpected status %q after %d polls", batch.Status, poll)
+	}
+	if fmt.Sprint(progress) != "[{10 2 0} {10 5 1} {10 6 1}]" {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
+
+func TestWaitForBatchFailed(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/batches/batch_abc123", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"batch_abc123","status":"failed","errors":{"object":"list","data":[`+
+			`{"code":"invalid_json_line","message":"This line is not parseable as valid JSON.","line":3}]}}`)
+	})
+
+	batch, err := client.WaitForBatch(context.Background(), "batch_abc123", openai.PollOptions{}, nil)
+	checks.ErrorIs(t, err, openai.ErrBatchFailed, "failed batches should return ErrBatchFailed")
+	if err.Error() != "batch failed: This line is not parseable as valid JSON. (code: invalid_json_line), line 3" {
+		t.Errorf("unexpected error %q", err)
+	}
+	if batch.Status != openai.BatchStatusFailed {
+		t.Errorf("unexpected status %q", batch.Status)
+	}
+}
+
 func TestUploadBatchFileRequest_AddChatCompletion(t *testing.T) {
 	type args struct {
 		customerID string
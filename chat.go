@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"github.com/sashabaranov/go-openai/jsonschema"
+	"fmt"
 	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
 )
 
 // Chat message role defined by the OpenAI API.
@@ -24,6 +28,10 @@ var (
 	ErrChatCompletionInvalidModel       = errors.New("this model is not supported with this method, please use CreateCompletion client method instead") //nolint:lll
 	ErrChatCompletionStreamNotSupported = errors.New("streaming is not supported with this method, please use CreateChatCompletionStream")              //nolint:lll
 	ErrContentFieldsMisused             = errors.New("can't use both Content and MultiContent properties simultaneously")
+	ErrChatCompletionNoChoices          = errors.New("chat completion has no choices")
+	ErrChatCompletionRefusal            = errors.New("model refused to respond")
+	ErrChatCompletionToolChoiceNoTools  = errors.New("tool_choice can only be set when tools are provided")
+	ErrChatCompletionPredictionParam    = errors.New("parameter is not supported with predicted outputs")
 )
 
 type Hate struct {
@@ -53,6 +61,25 @@ type Profanity struct {
 	Detected bool `json:"detected"`
 }
 
+// ProtectedMaterialText reports text matching known content such as song lyrics.
+type ProtectedMaterialText struct {
+	Filtered bool `json:"filtered"`
+	Detected bool `json:"detected"`
+}
+
+// ProtectedMaterialCode reports code matching a public repository, along with
+// where it comes from.
+type ProtectedMaterialCode struct {
+	Filtered bool                       `json:"filtered"`
+	Detected bool                       `json:"detected"`
+	Citation *ProtectedMaterialCitation `json:"citation,omitempty"`
+}
+
+type ProtectedMaterialCitation struct {
+	URL     string `json:"URL,omitempty"`
+	License string `json:"license,omitempty"`
+}
+
 type ContentFilterResults struct {
 	Hate      Hate      `json:"hate,omitempty"`
 	SelfHarm  SelfHarm  `json:"self_harm,omitempty"`
@@ -60,6 +87,9 @@ type ContentFilterResults struct {
 	Violence  Violence  `json:"violence,omitempty"`
 	JailBreak JailBreak `json:"jailbreak,omitempty"`
 	Profanity Profanity `json:"profanity,omitempty"`
+	// Protected material is only checked in completions.
+	ProtectedMaterialText *ProtectedMaterialText `json:"protected_material_text,omitempty"`
+	ProtectedMaterialCode *ProtectedMaterialCode `json:"protected_material_code,omitempty"`
 }
 
 type PromptAnnotation struct {
@@ -83,16 +113,21 @@ type ChatMessageImageURL struct {
 type ChatMessagePartType string
 
 const (
-	ChatMessagePartTypeText     ChatMessagePartType = "text"
-	ChatMessagePartTypeImageURL ChatMessagePartType = "image_url"
+	ChatMessagePartTypeText       ChatMessagePartType = "text"
+	ChatMessagePartTypeImageURL   ChatMessagePartType = "image_url"
+	ChatMessagePartTypeInputAudio ChatMessagePartType = "input_audio"
 )
 
 type ChatMessagePart struct {
-	Type     ChatMessagePartType  `json:"type,omitempty"`
-	Text     string               `json:"text,omitempty"`
-	ImageURL *ChatMessageImageURL `json:"image_url,omitempty"`
+	Type       ChatMessagePartType    `json:"type,omitempty"`
+	Text       string                 `json:"text,omitempty"`
+	ImageURL   *ChatMessageImageURL   `json:"image_url,omitempty"`
+	InputAudio *ChatMessageInputAudio `json:"input_audio,omitempty"`
 }
 
+// ChatCompletionMessage is a message of a chat. Its Content and MultiContent
+// are its content as a text or as parts, and must not be both set;
+// MessageContent and SetContent handle them as a ChatMessageContent.
 type ChatCompletionMessage struct {
 	Role         string `json:"role"`
 	Content      string `json:"content,omitempty"`
@@ -118,6 +153,10 @@ type ChatCompletionMessage struct {
 
 	// For Role=tool prompts this should be set to the ID given in the assistant's prior request to call a tool.
 	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Audio is the audio response of the assistant when the audio modality is
+	// requested. Use NewAudioReferenceMessage to refer to it in follow-up requests.
+	Audio *ChatCompletionAudio `json:"audio,omitempty"`
 }
 
 func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
@@ -126,68 +165,55 @@ func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
 	}
 	if len(m.MultiContent) > 0 {
 		msg := struct {
-			Role             string            `json:"role"`
-			Content          string            `json:"-"`
-			Refusal          string            `json:"refusal,omitempty"`
-			MultiContent     []ChatMessagePart `json:"content,omitempty"`
-			Name             string            `json:"name,omitempty"`
-			ReasoningContent string            `json:"reasoning_content,omitempty"`
-			FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-			ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-			ToolCallID       string            `json:"tool_call_id,omitempty"`
+			Role             string               `json:"role"`
+			Content          string               `json:"-"`
+			Refusal          string               `json:"refusal,omitempty"`
+			MultiContent     []ChatMessagePart    `json:"content,omitempty"`
+			Name             string               `json:"name,omitempty"`
+			ReasoningContent string               `json:"reasoning_content,omitempty"`
+			FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+			ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+			ToolCallID       string               `json:"tool_call_id,omitempty"`
+			Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 		}(m)
 		return json.Marshal(msg)
 	}
 
 	msg := struct {
-		Role             string            `json:"role"`
-		Content          string            `json:"content,omitempty"`
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"-"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		Role             string               `json:"role"`
+		Content          string               `json:"content,omitempty"`
+		Refusal          string               `json:"refusal,omitempty"`
+		MultiContent     []ChatMessagePart    `json:"-"`
+		Name             string               `json:"name,omitempty"`
+		ReasoningContent string               `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall        `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall           `json:"tool_calls,omitempty"`
+		ToolCallID       string               `json:"tool_call_id,omitempty"`
+		Audio            *ChatCompletionAudio `json:"audio,omitempty"`
 	}(m)
 	return json.Marshal(msg)
 }
 
+// UnmarshalJSON decodes the content of the message into Content when it is a
+// string, and into MultiContent when it is an array of parts, as returned by
+// some compatible providers for assistant messages.
 func (m *ChatCompletionMessage) UnmarshalJSON(bs []byte) error {
 	msg := struct {
-		Role             string `json:"role"`
-		Content          string `json:"content"`
-		Refusal          string `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart
-		Name             string        `json:"name,omitempty"`
-		ReasoningContent string        `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall    `json:"tool_calls,omitempty"`
-		ToolCallID       string        `json:"tool_call_id,omitempty"`
-	}{}
-
-	if err := json.Unmarshal(bs, &msg); err == nil {
-		*m = ChatCompletionMessage(msg)
-		return nil
-	}
-	multiMsg := struct {
-		Role             string `json:"role"`
-		Content          string
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"content"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		chatCompletionMessageFields
+		Content ChatMessageContent `json:"content"`
 	}{}
-	if err := json.Unmarshal(bs, &multiMsg); err != nil {
+	if err := json.Unmarshal(bs, &msg); err != nil {
 		return err
 	}
-	*m = ChatCompletionMessage(multiMsg)
+	*m = ChatCompletionMessage(msg.chatCompletionMessageFields)
+	m.SetContent(msg.Content)
 	return nil
 }
 
+// chatCompletionMessageFields are the fields of a ChatCompletionMessage,
+// without its JSON methods.
+type chatCompletionMessageFields ChatCompletionMessage
+
 type ToolCall struct {
 	// Index is not nil only in chat completion chunk object
 	Index    *int         `json:"index,omitempty"`
@@ -247,7 +273,42 @@ func (r *ChatCompletionResponseFormatJSONSchema) UnmarshalJSON(data []byte) erro
 	return nil
 }
 
+// GenerateJSONSchemaResponseFormat returns a strict json_schema response format
+// describing the type of v, as generated by jsonschema.GenerateStrictSchemaForType.
+// Use its Unmarshal method to decode the reply into the same type.
+func GenerateJSONSchemaResponseFormat(name string, v any) (*ChatCompletionResponseFormatJSONSchema, error) {
+	schema, err := jsonschema.GenerateStrictSchemaForType(v)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatCompletionResponseFormatJSONSchema{
+		Name:   name,
+		Schema: schema,
+		Strict: true,
+	}, nil
+}
+
+// Unmarshal decodes the message content of the first choice of resp into v,
+// checking it against the schema first when it is a jsonschema.Definition. It
+// returns an error wrapping ErrChatCompletionRefusal if the model refused.
+func (r *ChatCompletionResponseFormatJSONSchema) Unmarshal(resp ChatCompletionResponse, v any) error {
+	if len(resp.Choices) == 0 {
+		return ErrChatCompletionNoChoices
+	}
+	message := resp.Choices[0].Message
+	if message.Refusal != "" {
+		return fmt.Errorf("%w: %s", ErrChatCompletionRefusal, message.Refusal)
+	}
+	if schema, ok := r.Schema.(*jsonschema.Definition); ok {
+		return schema.Unmarshal(message.Content, v)
+	}
+	return json.Unmarshal([]byte(message.Content), v)
+}
+
 // ChatCompletionRequest represents a request structure for chat completion API.
+// Its Temperature, TopP, PresencePenalty and FrequencyPenalty are sent when not
+// nil, including zero: set them with Float, such as Float(0) for the most
+// deterministic output.
 type ChatCompletionRequest struct {
 	Model    string                  `json:"model"`
 	Messages []ChatCompletionMessage `json:"messages"`
@@ -259,15 +320,15 @@ type ChatCompletionRequest struct {
 	// MaxCompletionTokens An upper bound for the number of tokens that can be generated for a completion,
 	// including visible output tokens and reasoning tokens https://platform.openai.com/docs/guides/reasoning
 	MaxCompletionTokens int                           `json:"max_completion_tokens,omitempty"`
-	Temperature         float32                       `json:"temperature,omitempty"`
-	TopP                float32                       `json:"top_p,omitempty"`
+	Temperature         *float32                      `json:"temperature,omitempty"`
+	TopP                *float32                      `json:"top_p,omitempty"`
 	N                   int                           `json:"n,omitempty"`
 	Stream              bool                          `json:"stream,omitempty"`
-	Stop                []string                      `json:"stop,omitempty"`
-	PresencePenalty     float32                       `json:"presence_penalty,omitempty"`
+	Stop                StopSequences                 `json:"stop,omitempty"`
+	PresencePenalty     *float32                      `json:"presence_penalty,omitempty"`
 	ResponseFormat      *ChatCompletionResponseFormat `json:"response_format,omitempty"`
 	Seed                *int                          `json:"seed,omitempty"`
-	FrequencyPenalty    float32                       `json:"frequency_penalty,omitempty"`
+	FrequencyPenalty    *float32                      `json:"frequency_penalty,omitempty"`
 	// LogitBias is must be a token id string (specified by their token ID in the tokenizer), not a word string.
 	// incorrect: `"logit_bias":{"You": 6}`, correct: `"logit_bias":{"1639": 6}`
 	// refs: https://platform.openai.com/docs/api-reference/chat/create#chat/create-logit_bias
@@ -286,12 +347,15 @@ type ChatCompletionRequest struct {
 	// Deprecated: use ToolChoice instead.
 	FunctionCall any    `json:"function_call,omitempty"`
 	Tools        []Tool `json:"tools,omitempty"`
-	// This can be either a string or an ToolChoice object.
+	// This can be either a string or an ToolChoice object, best built with
+	// ToolChoiceNone, ToolChoiceAuto, ToolChoiceRequired or ToolChoiceFunction.
+	// It can only be set along with Tools.
 	ToolChoice any `json:"tool_choice,omitempty"`
 	// Options for streaming response. Only set this when you set stream: true.
 	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
-	// Disable the default behavior of parallel tool calls by setting it: false.
-	ParallelToolCalls any `json:"parallel_tool_calls,omitempty"`
+	// Disable the default behavior of parallel tool calls by setting it to
+	// false. Nil leaves the default.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
 	// Store can be set to true to store the output of this completion request for use in distillations and evals.
 	// https://platform.openai.com/docs/api-reference/chat/create#chat-create-store
 	Store bool `json:"store,omitempty"`
@@ -308,6 +372,11 @@ type ChatCompletionRequest struct {
 	ChatTemplateKwargs map[string]any `json:"chat_template_kwargs,omitempty"`
 	// Specifies the latency tier to use for processing the request.
 	ServiceTier ServiceTier `json:"service_tier,omitempty"`
+	// Modalities are the output types the model should generate, ChatModalityText
+	// by default. Audio output also requires Audio to be set.
+	Modalities []string `json:"modalities,omitempty"`
+	// Audio configures the audio output requested with ChatModalityAudio.
+	Audio *ChatAudioConfig `json:"audio,omitempty"`
 }
 
 type StreamOptions struct {
@@ -329,9 +398,68 @@ type Tool struct {
 	Function *FunctionDefinition `json:"function,omitempty"`
 }
 
+// ToolChoiceMode is a tool choice given as a string rather than a function.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceModeNone makes the model reply with a message and call no tool.
+	ToolChoiceModeNone ToolChoiceMode = "none"
+	// ToolChoiceModeAuto lets the model decide whether to call tools.
+	ToolChoiceModeAuto ToolChoiceMode = "auto"
+	// ToolChoiceModeRequired makes the model call at least one tool.
+	ToolChoiceModeRequired ToolChoiceMode = "required"
+)
+
+// ToolChoice controls which tool the model calls. It is either a mode, encoded
+// as a string, or the function the model must call, encoded as an object.
 type ToolChoice struct {
 	Type     ToolType     `json:"type"`
 	Function ToolFunction `json:"function,omitempty"`
+	// Mode is set instead of Type and Function for the string form.
+	Mode ToolChoiceMode `json:"-"`
+}
+
+// ToolChoiceNone returns the tool choice preventing tool calls.
+func ToolChoiceNone() ToolChoice {
+	return ToolChoice{Mode: ToolChoiceModeNone}
+}
+
+// ToolChoiceAuto returns the tool choice letting the model decide.
+func ToolChoiceAuto() ToolChoice {
+	return ToolChoice{Mode: ToolChoiceModeAuto}
+}
+
+// ToolChoiceRequired returns the tool choice forcing at least one tool call.
+func ToolChoiceRequired() ToolChoice {
+	return ToolChoice{Mode: ToolChoiceModeRequired}
+}
+
+// ToolChoiceFunction returns the tool choice forcing a call to the named function.
+func ToolChoiceFunction(name string) ToolChoice {
+	return ToolChoice{Type: ToolTypeFunction, Function: ToolFunction{Name: name}}
+}
+
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Mode != "" {
+		return json.Marshal(string(c.Mode))
+	}
+	type toolChoice ToolChoice
+	return json.Marshal(toolChoice(c))
+}
+
+func (c *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*c = ToolChoice{Mode: ToolChoiceMode(mode)}
+		return nil
+	}
+	type toolChoice ToolChoice
+	var choice toolChoice
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return err
+	}
+	*c = ToolChoice(choice)
+	return nil
 }
 
 type ToolFunction struct {
@@ -373,11 +501,90 @@ type LogProb struct {
 type LogProbs struct {
 	// Content is a list of message content tokens with log probability information.
 	Content []LogProb `json:"content"`
+	// Refusal is a list of message refusal tokens with log probability information.
+	Refusal []LogProb `json:"refusal,omitempty"`
+}
+
+// Text returns the content text spelled by the tokens, rebuilt from their UTF-8
+// bytes. Tokens may split a multi-byte character, in which case their Token
+// strings hold replacement characters but the bytes of consecutive tokens
+// combine into the original character.
+func (l *LogProbs) Text() string {
+	var b []byte
+	for _, token := range l.Content {
+		if token.Bytes == nil {
+			b = append(b, token.Token...)
+		} else {
+			b = append(b, token.Bytes...)
+		}
+	}
+	return tokenBytesText(b)
+}
+
+// tokenBytesText converts the concatenated bytes of tokens to text. An incomplete
+// character at the end, whose remaining bytes are in tokens not received yet, is
+// left out.
+func tokenBytesText(b []byte) string {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				b = b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return strings.ToValidUTF8(string(b), string(utf8.RuneError))
 }
 
+// PredictionTypeContent is the only type of Prediction.
+const PredictionTypeContent = "content"
+
+// Prediction is the predicted output of a chat completion, typically the current
+// version of text the model is asked to edit, so that the matching parts of the
+// response are generated faster. Its content is either the Content string or the
+// text parts in MultiContent.
 type Prediction struct {
-	Content string `json:"content"`
-	Type    string `json:"type"`
+	Content      string `json:"content"`
+	MultiContent []ChatMessagePart
+	// Type defaults to PredictionTypeContent.
+	Type string `json:"type"`
+}
+
+func (p Prediction) MarshalJSON() ([]byte, error) {
+	if p.Content != "" && p.MultiContent != nil {
+		return nil, ErrContentFieldsMisused
+	}
+	if p.Type == "" {
+		p.Type = PredictionTypeContent
+	}
+	if p.MultiContent != nil {
+		return json.Marshal(struct {
+			Content []ChatMessagePart `json:"content"`
+			Type    string            `json:"type"`
+		}{p.MultiContent, p.Type})
+	}
+	return json.Marshal(struct {
+		Content string `json:"content"`
+		Type    string `json:"type"`
+	}{p.Content, p.Type})
+}
+
+func (p *Prediction) UnmarshalJSON(data []byte) error {
+	var prediction struct {
+		Content json.RawMessage `json:"content"`
+		Type    string          `json:"type"`
+	}
+	if err := json.Unmarshal(data, &prediction); err != nil {
+		return err
+	}
+	*p = Prediction{Type: prediction.Type}
+	if len(prediction.Content) > 0 && prediction.Content[0] == '[' {
+		return json.Unmarshal(prediction.Content, &p.MultiContent)
+	}
+	if len(prediction.Content) > 0 && string(prediction.Content) != "null" {
+		return json.Unmarshal(prediction.Content, &p.Content)
+	}
+	return nil
 }
 
 type FinishReason string
@@ -433,8 +640,45 @@ type ChatCompletionResponse struct {
 	SystemFingerprint   string                 `json:"system_fingerprint"`
 	PromptFilterResults []PromptFilterResult   `json:"prompt_filter_results,omitempty"`
 	ServiceTier         ServiceTier            `json:"service_tier,omitempty"`
+	// Metadata is the metadata of completions created with Store.
+	Metadata map[string]string `json:"metadata,omitempty"`
 
 	httpHeader
+	rawJSON
+}
+
+// validateChatCompletionRequest rejects combinations of parameters the API does
+// not accept, before the request is sent.
+func validateChatCompletionRequest(request ChatCompletionRequest) error {
+	for i, msg := range request.Messages {
+		if msg.Content != "" && msg.MultiContent != nil {
+			return fmt.Errorf("message %d: %w", i, ErrContentFieldsMisused)
+		}
+	}
+	if request.ToolChoice != nil && len(request.Tools) == 0 {
+		return ErrChatCompletionToolChoiceNoTools
+	}
+	if request.Prediction != nil {
+		var param string
+		switch {
+		case request.N > 1:
+			param = "n"
+		case request.LogProbs:
+			param = "logprobs"
+		case request.PresencePenalty != nil && *request.PresencePenalty > 0:
+			param = "presence_penalty"
+		case request.FrequencyPenalty != nil && *request.FrequencyPenalty > 0:
+			param = "frequency_penalty"
+		case request.Audio != nil:
+			param = "audio"
+		case len(request.Tools) > 0:
+			param = "tools"
+		}
+		if param != "" {
+			return fmt.Errorf("%w: %s", ErrChatCompletionPredictionParam, param)
+		}
+	}
+	return nil
 }
 
 // CreateChatCompletion — API call to Create a completion for the chat message.
@@ -453,6 +697,11 @@ func (c *Client) CreateChatCompletion(
 		return
 	}
 
+	if err = validateChatCompletionRequest(request); err != nil {
+		return
+	}
+
+	request = c.withDeveloperMessages(request)
 	reasoningValidator := NewReasoningValidator()
 	if err = reasoningValidator.Validate(request); err != nil {
 		return
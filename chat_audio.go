@@ -0,0 +1,75 @@
+package openai
+
+import "encoding/base64"
+
+// Output modalities of a chat completion, set in ChatCompletionRequest.Modalities.
+const (
+	ChatModalityText  = "text"
+	ChatModalityAudio = "audio"
+)
+
+// ChatAudioFormat is the encoding of audio sent to or generated by a chat model.
+// Input audio can only be wav or mp3.
+type ChatAudioFormat string
+
+const (
+	ChatAudioFormatWAV   ChatAudioFormat = "wav"
+	ChatAudioFormatMP3   ChatAudioFormat = "mp3"
+	ChatAudioFormatFLAC  ChatAudioFormat = "flac"
+	ChatAudioFormatOpus  ChatAudioFormat = "opus"
+	ChatAudioFormatPCM16 ChatAudioFormat = "pcm16"
+)
+
+// ChatAudioConfig sets the voice and format of the audio output requested with
+// the audio modality.
+type ChatAudioConfig struct {
+	Voice  SpeechVoice     `json:"voice"`
+	Format ChatAudioFormat `json:"format"`
+}
+
+// ChatMessageInputAudio is the content of an input_audio message part.
+type ChatMessageInputAudio struct {
+	// Data is the base64 encoded audio.
+	Data   string          `json:"data"`
+	Format ChatAudioFormat `json:"format"`
+}
+
+// NewInputAudioPart returns a content part sending the audio data, encoded in
+// format, to the model.
+func NewInputAudioPart(data []byte, format ChatAudioFormat) ChatMessagePart {
+	return ChatMessagePart{
+		Type: ChatMessagePartTypeInputAudio,
+		InputAudio: &ChatMessageInputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}
+}
+
+// ChatCompletionAudio is the audio response of a chat completion requested with
+// the audio modality. In streams each delta carries the next piece of Data and
+// Transcript.
+type ChatCompletionAudio struct {
+	ID string `json:"id"`
+	// ExpiresAt is the Unix timestamp after which the audio can no longer be
+	// referenced in follow-up requests.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+	// Data is the base64 encoded audio, in the format of the request.
+	Data       string `json:"data,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// Bytes returns the decoded audio data.
+func (a *ChatCompletionAudio) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(a.Data)
+}
+
+// NewAudioReferenceMessage returns the assistant message to send back in a
+// follow-up request in place of the audio response with the given ID, so that
+// the model hears its previous answer without it being uploaded again.
+func NewAudioReferenceMessage(id string) ChatCompletionMessage {
+	return ChatCompletionMessage{
+		Role:  ChatMessageRoleAssistant,
+		Audio: &ChatCompletionAudio{ID: id},
+	}
+}
@@ -0,0 +1,84 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestChatCompletionAudio(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"modalities":["text","audio"],"audio":{"voice":"alloy","format":"wav"}`) ||
+			!strings.Contains(string(body), `{"type":"input_audio","input_audio":{"data":"UklGRg==","format":"wav"}}`) ||
+			!strings.Contains(string(body), `{"role":"assistant","audio":{"id":"audio_0"}}`) {
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-A","object":"chat.completion","model":"gpt-4o-audio-preview",
+			"choices":[{"index":0,"message":{"role":"assistant","content":null,"refusal":null,
+			"audio":{"id":"audio_1","expires_at":1729018505,"data":"UklGRiQA","transcript":"Hi there!"}},
+			"finish_reason":"stop"}]}`))
+	})
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:      "gpt-4o-audio-preview",
+		Modalities: []string{openai.ChatModalityText, openai.ChatModalityAudio},
+		Audio:      &openai.ChatAudioConfig{Voice: openai.VoiceAlloy, Format: openai.ChatAudioFormatWAV},
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				openai.NewInputAudioPart([]byte("RIFF"), openai.ChatAudioFormatWAV),
+			}},
+			openai.NewAudioReferenceMessage("audio_0"),
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	audio := resp.Choices[0].Message.Audio
+	if audio == nil || audio.ID != "audio_1" || audio.ExpiresAt != 1729018505 || audio.Transcript != "Hi there!" {
+		t.Fatalf("unexpected audio %+v", audio)
+	}
+	data, err := audio.Bytes()
+	checks.NoError(t, err, "Bytes error")
+	if string(data) != "RIFF$\x00" {
+		t.Errorf("unexpected audio data %q", data)
+	}
+}
+
+func TestStreamAccumulatorAudio(t *testing.T) {
+	pieces := []string{"RIFF", "$\x00\x00\x00", "WAVE"}
+	var acc openai.ChatCompletionStreamAccumulator
+	for i, piece := range pieces {
+		delta := &openai.ChatCompletionAudio{Data: base64.StdEncoding.EncodeToString([]byte(piece))}
+		switch i {
+		case 0:
+			delta.ID = "audio_2"
+			delta.Transcript = "Hello"
+		case 1:
+			delta.Transcript = " world"
+		case 2:
+			delta.ExpiresAt = 1729018505
+		}
+		acc.Add(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: openai.ChatCompletionStreamChoiceDelta{Audio: delta}},
+		}})
+	}
+
+	audio := acc.Message().Audio
+	if audio == nil || audio.ID != "audio_2" || audio.ExpiresAt != 1729018505 || audio.Transcript != "Hello world" {
+		t.Fatalf("unexpected audio %+v", audio)
+	}
+	data, err := audio.Bytes()
+	checks.NoError(t, err, "Bytes error")
+	if string(data) != strings.Join(pieces, "") {
+		t.Errorf("unexpected audio data %q", data)
+	}
+}
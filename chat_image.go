@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+var (
+	ErrInvalidImageDetail = errors.New("image detail must be low, high or auto")
+	ErrAnimatedGIF        = errors.New("animated GIF images are not supported")
+)
+
+// supportedImageTypes are the MIME types of the images accepted in chat messages.
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// UnsupportedImageTypeError is returned when building an image content part from
+// data whose MIME type chat models do not accept. Supported types are PNG, JPEG,
+// WEBP and GIF.
+type UnsupportedImageTypeError struct {
+	MIMEType string
+}
+
+func (e *UnsupportedImageTypeError) Error() string {
+	return fmt.Sprintf("unsupported image type %q, must be image/png, image/jpeg, image/gif or image/webp", e.MIMEType)
+}
+
+// Valid reports whether d is a detail level accepted by the API. The empty
+// detail is valid and lets the API pick one, like ImageURLDetailAuto.
+func (d ImageURLDetail) Valid() bool {
+	switch d {
+	case "", ImageURLDetailLow, ImageURLDetailHigh, ImageURLDetailAuto:
+		return true
+	}
+	return false
+}
+
+// NewImagePartFromURL returns a content part for the image at url, which must be
+// an http(s) URL or a data URL of a supported image type.
+func NewImagePartFromURL(url string, detail ImageURLDetail) (ChatMessagePart, error) {
+	if !detail.Valid() {
+		return ChatMessagePart{}, ErrInvalidImageDetail
+	}
+	switch {
+	case strings.HasPrefix(url, "https://"), strings.HasPrefix(url, "http://"):
+	case strings.HasPrefix(url, "data:"):
+		mimeType := strings.TrimPrefix(url, "data:")
+		if i := strings.IndexAny(mimeType, ";,"); i >= 0 {
+			mimeType = mimeType[:i]
+		}
+		if !supportedImageTypes[strings.ToLower(mimeType)] {
+			return ChatMessagePart{}, &UnsupportedImageTypeError{MIMEType: mimeType}
+		}
+	default:
+		return ChatMessagePart{}, fmt.Errorf("image URL must be an http(s) or data URL, got %q", url)
+	}
+	return ChatMessagePart{
+		Type:     ChatMessagePartTypeImageURL,
+		ImageURL: &ChatMessageImageURL{URL: url, Detail: detail},
+	}, nil
+}
+
+// NewImagePartFromBytes returns a content part embedding the image data as a
+// base64 data URL. If mimeType is empty it is detected from the data. Animated
+// GIFs, which chat models do not accept, fail with ErrAnimatedGIF.
+func NewImagePartFromBytes(data []byte, mimeType string, detail ImageURLDetail) (ChatMessagePart, error) {
+	if !detail.Valid() {
+		return ChatMessagePart{}, ErrInvalidImageDetail
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil || !supportedImageTypes[mediaType] {
+		return ChatMessagePart{}, &UnsupportedImageTypeError{MIMEType: mimeType}
+	}
+	if mediaType == "image/gif" && isAnimatedGIF(data) {
+		return ChatMessagePart{}, ErrAnimatedGIF
+	}
+	return ChatMessagePart{
+		Type: ChatMessagePartTypeImageURL,
+		ImageURL: &ChatMessageImageURL{
+			URL:    "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data),
+			Detail: detail,
+		},
+	}, nil
+}
+
+// NewImagePartFromFile returns a content part embedding the whole content of
+// file as a base64 data URL. The MIME type is detected the same way as for file
+// uploads, from the file's content and extension.
+func NewImagePartFromFile(file *os.File, detail ImageURLDetail) (ChatMessagePart, error) {
+	if !detail.Valid() {
+		return ChatMessagePart{}, ErrInvalidImageDetail
+	}
+	mimeType, err := utils.FileContentType(file)
+	if err != nil {
+		return ChatMessagePart{}, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return ChatMessagePart{}, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ChatMessagePart{}, err
+	}
+	return NewImagePartFromBytes(data, mimeType, detail)
+}
+
+// isAnimatedGIF reports whether data is a GIF of more than one frame. It counts
+// the image descriptors by walking the blocks of the file, without decoding
+// them, and reports false for data it cannot parse.
+func isAnimatedGIF(data []byte) bool {
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF")) {
+		return false
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1) // global color table
+	}
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // extension introducer and label
+			i += 2
+		case 0x2C: // image descriptor
+			frames++
+			if frames > 1 {
+				return true
+			}
+			if i+10 > len(data) {
+				return false
+			}
+			packed := data[i+9]
+			i += 10
+			if packed&0x80 != 0 {
+				i += 3 << (packed&0x07 + 1) // local color table
+			}
+			i++ // LZW minimum code size
+		default: // trailer
+			return false
+		}
+		// Skip the data sub-blocks, up to the empty block ending them.
+		for i < len(data) && data[i] != 0 {
+			i += int(data[i]) + 1
+		}
+		i++
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package openai_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// pngHeader is the signature of a PNG file, enough for its type to be detected.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestNewImagePartFromURL(t *testing.T) {
+	part, err := openai.NewImagePartFromURL("https://example.com/cat.png", openai.ImageURLDetailHigh)
+	checks.NoError(t, err, "NewImagePartFromURL error")
+	if part.Type != openai.ChatMessagePartTypeImageURL || part.ImageURL.URL != "https://example.com/cat.png" ||
+		part.ImageURL.Detail != openai.ImageURLDetailHigh {
+		t.Errorf("unexpected part %+v", part)
+	}
+
+	_, err = openai.NewImagePartFromURL("data:image/jpeg;base64,/9j/", "")
+	checks.NoError(t, err, "data URL should be accepted")
+
+	_, err = openai.NewImagePartFromURL("https://example.com/cat.png", "medium")
+	checks.ErrorIs(t, err, openai.ErrInvalidImageDetail, "expected invalid detail error")
+
+	_, err = openai.NewImagePartFromURL("data:image/tiff;base64,SUkq", "")
+	var typeErr *openai.UnsupportedImageTypeError
+	if !errors.As(err, &typeErr) || typeErr.MIMEType != "image/tiff" {
+		t.Errorf("expected unsupported image type error, got %v", err)
+	}
+
+	_, err = openai.NewImagePartFromURL("ftp://example.com/cat.png", "")
+	checks.HasError(t, err, "ftp URL should be rejected")
+}
+
+func TestNewImagePartFromBytes(t *testing.T) {
+	part, err := openai.NewImagePartFromBytes(pngHeader, "", openai.ImageURLDetailLow)
+	checks.NoError(t, err, "NewImagePartFromBytes error")
+	want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngHeader)
+	if part.ImageURL.URL != want || part.ImageURL.Detail != openai.ImageURLDetailLow {
+		t.Errorf("unexpected part %+v", part.ImageURL)
+	}
+
+	part, err = openai.NewImagePartFromBytes([]byte{0xff, 0xd8, 0xff}, "image/jpeg; charset=binary", "")
+	checks.NoError(t, err, "NewImagePartFromBytes error")
+	if part.ImageURL.URL != "data:image/jpeg;base64,/9j/" {
+		t.Errorf("unexpected URL %q", part.ImageURL.URL)
+	}
+
+	_, err = openai.NewImagePartFromBytes([]byte("plain text"), "", "")
+	var typeErr *openai.UnsupportedImageTypeError
+	if !errors.As(err, &typeErr) || typeErr.MIMEType != "text/plain; charset=utf-8" {
+		t.Errorf("expected unsupported image type error, got %v", err)
+	}
+
+	_, err = openai.NewImagePartFromBytes(pngHeader, "", "ultra")
+	checks.ErrorIs(t, err, openai.ErrInvalidImageDetail, "expected invalid detail error")
+}
+
+func TestNewImagePartFromBytesGIF(t *testing.T) {
+	encode := func(frames int) []byte {
+		palette := color.Palette{color.Black, color.White}
+		anim := &gif.GIF{}
+		for i := 0; i < frames; i++ {
+			anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+			anim.Delay = append(anim.Delay, 10)
+		}
+		var buf bytes.Buffer
+		checks.NoError(t, gif.EncodeAll(&buf, anim), "EncodeAll error")
+		return buf.Bytes()
+	}
+
+	_, err := openai.NewImagePartFromBytes(encode(1), "", "")
+	checks.NoError(t, err, "a still GIF should be accepted")
+	_, err = openai.NewImagePartFromBytes(encode(3), "", "")
+	checks.ErrorIs(t, err, openai.ErrAnimatedGIF, "an animated GIF should be rejected")
+	_, err = openai.NewImagePartFromBytes([]byte("GIF89a"), "", "")
+	checks.NoError(t, err, "a truncated GIF is left for the API to reject")
+}
+
+func TestNewImagePartFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.webp")
+	checks.NoError(t, os.WriteFile(path, []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), 0o600), "WriteFile error")
+	file, err := os.Open(path)
+	checks.NoError(t, err, "Open error")
+	defer file.Close()
+
+	part, err := openai.NewImagePartFromFile(file, openai.ImageURLDetailAuto)
+	checks.NoError(t, err, "NewImagePartFromFile error")
+	want := "data:image/webp;base64," + base64.StdEncoding.EncodeToString([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "))
+	if part.ImageURL.URL != want {
+		t.Errorf("unexpected URL %q", part.ImageURL.URL)
+	}
+
+	// Image parts sit alongside text messages, which still marshal their content
+	// as a plain string.
+	part.ImageURL.URL = "URL"
+	data, err := json.Marshal([]openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "Describe images."},
+		{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{part}},
+	})
+	checks.NoError(t, err, "Marshal error")
+	wantJSON := `[{"role":"system","content":"Describe images."},` +
+		`{"role":"user","content":[{"type":"image_url","image_url":{"url":"URL","detail":"auto"}}]}]`
+	if string(data) != wantJSON {
+		t.Errorf("unexpected messages JSON %s", data)
+	}
+}
@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ChatMessageContent is the content of a chat message: either a text, sent as
+// a JSON string, or a list of parts, sent as a JSON array. Its zero value is an
+// empty text. Build it with TextContent or PartsContent:
+//
+//	msg := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser}
+//	msg.SetContent(openai.PartsContent(
+//		openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: "What is in this image?"},
+//		openai.ChatMessagePart{Type: openai.ChatMessagePartTypeImageURL, ImageURL: imageURL},
+//	))
+type ChatMessageContent struct {
+	text    string
+	parts   []ChatMessagePart
+	isParts bool
+}
+
+// TextContent returns the content made of text.
+func TextContent(text string) ChatMessageContent {
+	return ChatMessageContent{text: text}
+}
+
+// PartsContent returns the content made of parts.
+func PartsContent(parts ...ChatMessagePart) ChatMessageContent {
+	if parts == nil {
+		parts = []ChatMessagePart{}
+	}
+	return ChatMessageContent{parts: parts, isParts: true}
+}
+
+// AsText returns the text of a text content, and false for a content made of
+// parts.
+func (c ChatMessageContent) AsText() (string, bool) {
+	return c.text, !c.isParts
+}
+
+// Parts returns the parts of the content. A text content is returned as a
+// single text part, unless empty.
+func (c ChatMessageContent) Parts() []ChatMessagePart {
+	if c.isParts {
+		return c.parts
+	}
+	if c.text == "" {
+		return nil
+	}
+	return []ChatMessagePart{{Type: ChatMessagePartTypeText, Text: c.text}}
+}
+
+// Text returns the text of the content, joining the texts of its text parts.
+func (c ChatMessageContent) Text() string {
+	if !c.isParts {
+		return c.text
+	}
+	var sb strings.Builder
+	for _, part := range c.parts {
+		if part.Type == ChatMessagePartTypeText {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
+// IsEmpty reports whether the content has no text and no parts.
+func (c ChatMessageContent) IsEmpty() bool {
+	return c.text == "" && len(c.parts) == 0
+}
+
+func (c ChatMessageContent) MarshalJSON() ([]byte, error) {
+	if c.isParts {
+		return json.Marshal(c.parts)
+	}
+	return json.Marshal(c.text)
+}
+
+// UnmarshalJSON decodes a JSON string as a text content and a JSON array as
+// parts. Null is an empty text.
+func (c *ChatMessageContent) UnmarshalJSON(data []byte) error {
+	*c = ChatMessageContent{}
+	if len(data) > 0 && data[0] == '[' {
+		c.isParts = true
+		return json.Unmarshal(data, &c.parts)
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, &c.text)
+}
+
+// MessageContent returns the content of the message, from MultiContent when it
+// is set and from Content otherwise.
+func (m ChatCompletionMessage) MessageContent() ChatMessageContent {
+	if m.MultiContent != nil {
+		return PartsContent(m.MultiContent...)
+	}
+	return TextContent(m.Content)
+}
+
+// SetContent sets Content or MultiContent to content, clearing the other.
+func (m *ChatCompletionMessage) SetContent(content ChatMessageContent) {
+	m.Content, m.MultiContent = content.text, nil
+	if content.isParts {
+		m.MultiContent = content.Parts()
+	}
+}
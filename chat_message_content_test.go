@@ -0,0 +1,102 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestChatMessageContent(t *testing.T) {
+	text := openai.TextContent("Hello")
+	if s, ok := text.AsText(); !ok || s != "Hello" {
+		t.Errorf("AsText returned %q, %v", s, ok)
+	}
+	if parts := text.Parts(); len(parts) != 1 || parts[0].Text != "Hello" {
+		t.Errorf("unexpected parts of a text %+v", parts)
+	}
+
+	parts := openai.PartsContent(
+		openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: "Hello"},
+		openai.ChatMessagePart{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "a"}},
+		openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: " world"},
+	)
+	if _, ok := parts.AsText(); ok {
+		t.Error("AsText succeeded on parts")
+	}
+	if parts.Text() != "Hello world" || len(parts.Parts()) != 3 {
+		t.Errorf("unexpected parts content %q, %+v", parts.Text(), parts.Parts())
+	}
+	if !openai.TextContent("").IsEmpty() || !openai.PartsContent().IsEmpty() || parts.IsEmpty() {
+		t.Error("unexpected IsEmpty")
+	}
+
+	var msg openai.ChatCompletionMessage
+	msg.Content = "legacy"
+	msg.SetContent(parts)
+	if msg.Content != "" || len(msg.MultiContent) != 3 || !reflect.DeepEqual(msg.MessageContent(), parts) {
+		t.Errorf("SetContent set %+v", msg)
+	}
+	msg.SetContent(text)
+	if msg.Content != "Hello" || msg.MultiContent != nil {
+		t.Errorf("SetContent set %+v", msg)
+	}
+}
+
+func TestChatMessageContentJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content openai.ChatMessageContent
+		json    string
+	}{
+		{"text", openai.TextContent("Hello"), `{"role":"assistant","content":"Hello"}`},
+		{"empty", openai.TextContent(""), `{"role":"assistant"}`},
+		{"parts", openai.PartsContent(openai.ChatMessagePart{Type: openai.ChatMessagePartTypeText, Text: "Hello"}),
+			`{"role":"assistant","content":[{"type":"text","text":"Hello"}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+			msg.SetContent(tc.content)
+			data, err := json.Marshal(msg)
+			checks.NoError(t, err, "Marshal error")
+			if string(data) != tc.json {
+				t.Errorf("marshaled %s, want %s", data, tc.json)
+			}
+
+			var decoded openai.ChatCompletionMessage
+			checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+			if !reflect.DeepEqual(decoded.MessageContent(), tc.content) {
+				t.Errorf("decoded %+v, want %+v", decoded.MessageContent(), tc.content)
+			}
+		})
+	}
+
+	var content openai.ChatMessageContent
+	checks.NoError(t, json.Unmarshal([]byte(`null`), &content), "Unmarshal error")
+	if !content.IsEmpty() {
+		t.Errorf("null decoded as %+v", content)
+	}
+	checks.HasError(t, json.Unmarshal([]byte(`1`), &content), "a number should not be content")
+	var msg openai.ChatCompletionMessage
+	checks.HasError(t, json.Unmarshal([]byte(`{"role":"user","content":{}}`), &msg), "an object should not be content")
+}
+
+func TestChatCompletionMessageContentMisused(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	request := openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, Content: "Hello", MultiContent: []openai.ChatMessagePart{}},
+		},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrContentFieldsMisused, "both contents should be rejected")
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrContentFieldsMisused, "both contents should be rejected")
+}
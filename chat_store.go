@@ -0,0 +1,201 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ChatCompletionsFilter narrows the chat completions returned by
+// ListChatCompletions. Only completions created with Store are listed.
+type ChatCompletionsFilter struct {
+	// Model only lists the completions generated by this model.
+	Model string
+	// Metadata only lists the completions with all of these metadata values.
+	Metadata map[string]string
+}
+
+// ChatCompletionsList is a list of stored chat completions.
+type ChatCompletionsList struct {
+	Object  string                   `json:"object"`
+	Data    []ChatCompletionResponse `json:"data"`
+	FirstID string                   `json:"first_id"`
+	LastID  string                   `json:"last_id"`
+	HasMore bool                     `json:"has_more"`
+
+	httpHeader
+}
+
+// ChatCompletionStoredMessage is a message of the request of a stored chat
+// completion.
+type ChatCompletionStoredMessage struct {
+	ID string `json:"id"`
+	ChatCompletionMessage
+}
+
+func (m ChatCompletionStoredMessage) MarshalJSON() ([]byte, error) {
+	message, err := m.ChatCompletionMessage.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	id, err := json.Marshal(m.ID)
+	if err != nil {
+		return nil, err
+	}
+	// The message is an object with at least a role, so the ID can be inserted
+	// as its first field.
+	data := append([]byte(`{"id":`), id...)
+	data = append(data, ',')
+	return append(data, message[1:]...), nil
+}
+
+func (m *ChatCompletionStoredMessage) UnmarshalJSON(data []byte) error {
+	var id struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+	if err := m.ChatCompletionMessage.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	m.ID = id.ID
+	return nil
+}
+
+// ChatCompletionMessagesList is a list of messages of a stored chat completion.
+type ChatCompletionMessagesList struct {
+	Object  string                        `json:"object"`
+	Data    []ChatCompletionStoredMessage `json:"data"`
+	FirstID string                        `json:"first_id"`
+	LastID  string                        `json:"last_id"`
+	HasMore bool                          `json:"has_more"`
+
+	httpHeader
+}
+
+// ChatCompletionDeleteResponse represents the deletion status of a stored chat
+// completion.
+type ChatCompletionDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+
+	httpHeader
+}
+
+// GetChatCompletion retrieves a chat completion created with Store.
+func (c *Client) GetChatCompletion(
+	ctx context.Context,
+	completionID string,
+) (response ChatCompletionResponse, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s", chatCompletionsSuffix, completionID)
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// ListChatCompletions lists the chat completions created with Store.
+func (c *Client) ListChatCompletions(
+	ctx context.Context,
+	filter ChatCompletionsFilter,
+	pagination Pagination,
+) (response ChatCompletionsList, err error) {
+	urlValues := paginationValues(pagination)
+	if filter.Model != "" {
+		urlValues.Add("model", filter.Model)
+	}
+	for key, value := range filter.Metadata {
+		urlValues.Add(fmt.Sprintf("metadata[%s]", key), value)
+	}
+
+	urlSuffix := chatCompletionsSuffix + encodeQuery(urlValues)
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// ListChatCompletionMessages lists the messages of the request of a chat
+// completion created with Store.
+func (c *Client) ListChatCompletionMessages(
+	ctx context.Context,
+	completionID string,
+	pagination Pagination,
+) (response ChatCompletionMessagesList, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/messages%s", chatCompletionsSuffix, completionID,
+		encodeQuery(paginationValues(pagination)))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// UpdateChatCompletionMetadata replaces the metadata of a chat completion created
+// with Store.
+func (c *Client) UpdateChatCompletionMetadata(
+	ctx context.Context,
+	completionID string,
+	metadata map[string]string,
+) (response ChatCompletionResponse, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s", chatCompletionsSuffix, completionID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBody(map[string]any{"metadata": metadata}))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// DeleteChatCompletion deletes a chat completion created with Store.
+func (c *Client) DeleteChatCompletion(
+	ctx context.Context,
+	completionID string,
+) (response ChatCompletionDeleteResponse, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s", chatCompletionsSuffix, completionID)
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+func paginationValues(pagination Pagination) url.Values {
+	urlValues := url.Values{}
+	if pagination.Limit != nil {
+		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
+	}
+	if pagination.Order != nil {
+		urlValues.Add("order", *pagination.Order)
+	}
+	if pagination.After != nil {
+		urlValues.Add("after", *pagination.After)
+	}
+	if pagination.Before != nil {
+		urlValues.Add("before", *pagination.Before)
+	}
+	return urlValues
+}
+
+func encodeQuery(urlValues url.Values) string {
+	if len(urlValues) == 0 {
+		return ""
+	}
+	return "?" + urlValues.Encode()
+}
@@ -0,0 +1,124 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+//nolint:lll
+const storedChatCompletion = `{"id":"chatcmpl-S1","object":"chat.completion","created":1738960610,"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7},"metadata":{"topic":"greeting"}}`
+
+func TestGetChatCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-S1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte(storedChatCompletion))
+	})
+
+	resp, err := client.GetChatCompletion(context.Background(), "chatcmpl-S1")
+	checks.NoError(t, err, "GetChatCompletion error")
+	if resp.ID != "chatcmpl-S1" || resp.Metadata["topic"] != "greeting" || resp.Choices[0].Message.Content != "Hello!" {
+		t.Errorf("unexpected completion %+v", resp)
+	}
+}
+
+func TestListChatCompletions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method != http.MethodGet || query.Get("model") != "gpt-4o-mini" ||
+			query.Get("metadata[topic]") != "greeting" || query.Get("after") != "chatcmpl-S0" ||
+			query.Get("limit") != "1" || query.Get("order") != "asc" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[` + storedChatCompletion +
+			`],"first_id":"chatcmpl-S1","last_id":"chatcmpl-S1","has_more":true}`))
+	})
+
+	after, limit, order := "chatcmpl-S0", 1, "asc"
+	list, err := client.ListChatCompletions(context.Background(), openai.ChatCompletionsFilter{
+		Model:    "gpt-4o-mini",
+		Metadata: map[string]string{"topic": "greeting"},
+	}, openai.Pagination{After: &after, Limit: &limit, Order: &order})
+	checks.NoError(t, err, "ListChatCompletions error")
+	if len(list.Data) != 1 || list.Data[0].ID != "chatcmpl-S1" || list.LastID != "chatcmpl-S1" || !list.HasMore {
+		t.Errorf("unexpected list %+v", list)
+	}
+}
+
+func TestListChatCompletionMessages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-S1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "10" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"chatcmpl-S1-0","role":"user","content":"Hi"}],` +
+			`"first_id":"chatcmpl-S1-0","last_id":"chatcmpl-S1-0","has_more":false}`))
+	})
+
+	limit := 10
+	list, err := client.ListChatCompletionMessages(context.Background(), "chatcmpl-S1", openai.Pagination{Limit: &limit})
+	checks.NoError(t, err, "ListChatCompletionMessages error")
+	if len(list.Data) != 1 || list.Data[0].ID != "chatcmpl-S1-0" || list.Data[0].Role != openai.ChatMessageRoleUser ||
+		list.Data[0].Content != "Hi" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	data, err := json.Marshal(list.Data[0])
+	checks.NoError(t, err, "Marshal error")
+	if string(data) != `{"id":"chatcmpl-S1-0","role":"user","content":"Hi"}` {
+		t.Errorf("unexpected message JSON %s", data)
+	}
+}
+
+func TestUpdateChatCompletionMetadata(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-S1", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != `{"metadata":{"topic":"greeting"}}` {
+			http.Error(w, "unexpected request "+string(body), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(storedChatCompletion))
+	})
+
+	resp, err := client.UpdateChatCompletionMetadata(context.Background(), "chatcmpl-S1",
+		map[string]string{"topic": "greeting"})
+	checks.NoError(t, err, "UpdateChatCompletionMetadata error")
+	if resp.Metadata["topic"] != "greeting" {
+		t.Errorf("unexpected completion %+v", resp)
+	}
+}
+
+func TestDeleteChatCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions/chatcmpl-S1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"chat.completion.deleted","id":"chatcmpl-S1","deleted":true}`))
+	})
+
+	resp, err := client.DeleteChatCompletion(context.Background(), "chatcmpl-S1")
+	checks.NoError(t, err, "DeleteChatCompletion error")
+	if !resp.Deleted || resp.ID != "chatcmpl-S1" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
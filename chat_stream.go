@@ -2,6 +2,8 @@ package openai
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 )
 
@@ -11,6 +13,9 @@ type ChatCompletionStreamChoiceDelta struct {
 	FunctionCall *FunctionCall `json:"function_call,omitempty"`
 	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
 	Refusal      string        `json:"refusal,omitempty"`
+	// Audio carries the next piece of the audio response when the audio modality
+	// is requested. Its Data is encoded separately from the other deltas.
+	Audio *ChatCompletionAudio `json:"audio,omitempty"`
 
 	// This property is used for the "reasoning" feature supported by deepseek-reasoner
 	// which is not in the official documentation.
@@ -24,6 +29,18 @@ type ChatCompletionStreamChoiceLogprobs struct {
 	Refusal []ChatCompletionTokenLogprob `json:"refusal,omitempty"`
 }
 
+// Text returns the content text spelled by the tokens of the chunk, as
+// LogProbs.Text does. To get the text of a whole stream, accumulate the chunks
+// with a ChatCompletionStreamAccumulator, as tokens may split a character across
+// chunks.
+func (l *ChatCompletionStreamChoiceLogprobs) Text() string {
+	var b []byte
+	for _, token := range l.Content {
+		b = append(b, token.bytes()...)
+	}
+	return tokenBytesText(b)
+}
+
 type ChatCompletionTokenLogprob struct {
 	Token       string                                 `json:"token"`
 	Bytes       []int64                                `json:"bytes,omitempty"`
@@ -31,6 +48,36 @@ type ChatCompletionTokenLogprob struct {
 	TopLogprobs []ChatCompletionTokenLogprobTopLogprob `json:"top_logprobs"`
 }
 
+func (t ChatCompletionTokenLogprob) bytes() []byte {
+	if t.Bytes == nil {
+		return []byte(t.Token)
+	}
+	return int64sToBytes(t.Bytes)
+}
+
+// logProb converts the streamed form of a token to the one of a full response.
+func (t ChatCompletionTokenLogprob) logProb() LogProb {
+	p := LogProb{Token: t.Token, LogProb: t.Logprob, Bytes: int64sToBytes(t.Bytes)}
+	if t.TopLogprobs != nil {
+		p.TopLogProbs = make([]TopLogProbs, len(t.TopLogprobs))
+		for i, top := range t.TopLogprobs {
+			p.TopLogProbs[i] = TopLogProbs{Token: top.Token, LogProb: top.Logprob, Bytes: int64sToBytes(top.Bytes)}
+		}
+	}
+	return p
+}
+
+func int64sToBytes(values []int64) []byte {
+	if values == nil {
+		return nil
+	}
+	b := make([]byte, len(values))
+	for i, v := range values {
+		b[i] = byte(v)
+	}
+	return b
+}
+
 type ChatCompletionTokenLogprobTopLogprob struct {
 	Token   string  `json:"token"`
 	Bytes   []int64 `json:"bytes"`
@@ -63,6 +110,8 @@ type ChatCompletionStreamResponse struct {
 	// When present, it contains a null value except for the last chunk which contains the token usage statistics
 	// for the entire request.
 	Usage *Usage `json:"usage,omitempty"`
+
+	rawJSON
 }
 
 // ChatCompletionStream
@@ -71,6 +120,56 @@ type ChatCompletionStream struct {
 	*streamReader[ChatCompletionStreamResponse]
 }
 
+// Choice returns the choice with the given index as received so far by Recv,
+// with its finish reason once it finished, and whether any chunk for it was
+// received. With N > 1 the deltas of the choices are interleaved, and the
+// choices finish independently; the stream only ends once all of them did.
+func (stream *ChatCompletionStream) Choice(index int) (ChatCompletionChoice, bool) {
+	return stream.accumulator.Choice(index)
+}
+
+// TextReader returns a reader of the text of the stream: the content of the
+// deltas of its first choice, as received. Other deltas, such as those of tool
+// calls or announcing the role, are skipped. Read returns io.EOF at the end of
+// the stream, and the error that ended it otherwise. Closing the reader closes
+// the stream.
+//
+//	_, err := io.Copy(w, stream.TextReader())
+func (stream *ChatCompletionStream) TextReader() io.ReadCloser {
+	return &chatCompletionTextReader{stream: stream}
+}
+
+type chatCompletionTextReader struct {
+	stream *ChatCompletionStream
+	// text is the text received but not read yet.
+	text []byte
+	err  error
+}
+
+func (r *chatCompletionTextReader) Read(p []byte) (int, error) {
+	for len(r.text) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		var chunk ChatCompletionStreamResponse
+		chunk, r.err = r.stream.Recv()
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				// Multi-byte characters split across deltas are joined
+				// again by the reader of the bytes.
+				r.text = append(r.text, choice.Delta.Content...)
+			}
+		}
+	}
+	n := copy(p, r.text)
+	r.text = r.text[n:]
+	return n, nil
+}
+
+func (r *chatCompletionTextReader) Close() error {
+	return r.stream.Close()
+}
+
 // CreateChatCompletionStream — API call to create a chat completion w/ streaming
 // support. It sets whether to stream back partial progress. If set, tokens will be
 // sent as data-only server-sent events as they become available, with the
@@ -85,7 +184,12 @@ func (c *Client) CreateChatCompletionStream(
 		return
 	}
 
+	if err = validateChatCompletionRequest(request); err != nil {
+		return
+	}
+
 	request.Stream = true
+	request = c.withDeveloperMessages(request)
 	reasoningValidator := NewReasoningValidator()
 	if err = reasoningValidator.Validate(request); err != nil {
 		return
@@ -105,8 +209,51 @@ func (c *Client) CreateChatCompletionStream(
 	if err != nil {
 		return
 	}
+	resp.choices = request.N
+	resp.accumulator = &ChatCompletionStreamAccumulator{}
 	stream = &ChatCompletionStream{
 		streamReader: resp,
 	}
 	return
 }
+
+// StreamChatCompletion streams a chat completion, calling onChunk with every chunk
+// as it is received, and returns the complete response once the stream ends, as
+// assembled by a ChatCompletionStreamAccumulator. The usage is only included for
+// requests with StreamOptions.IncludeUsage.
+//
+// If onChunk returns an error the stream is closed and the error returned, along
+// with the response received until then; the same goes for errors of the stream.
+func (c *Client) StreamChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	onChunk func(chunk ChatCompletionStreamResponse) error,
+) (response ChatCompletionResponse, err error) {
+	stream, err := c.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+
+	var acc ChatCompletionStreamAccumulator
+	defer func() {
+		response = acc.Response()
+		response.httpHeader = stream.httpHeader
+	}()
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return
+		}
+		if recvErr != nil {
+			err = recvErr
+			return
+		}
+		acc.Add(chunk)
+		if onChunk != nil {
+			if err = onChunk(chunk); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package openai
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// ChatCompletionStreamAccumulator merges the chunks of a chat completion stream
+// into complete messages. Feed it every chunk received with Add; at any point it
+// holds the messages generated so far, and once the stream ends Message returns
+// the reply ready to be appended to the conversation. The zero value is ready to
+// use.
+//
+//	var acc openai.ChatCompletionStreamAccumulator
+//	for {
+//		chunk, err := stream.Recv()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//		acc.Add(chunk)
+//	}
+//	messages = append(messages, acc.Message())
+type ChatCompletionStreamAccumulator struct {
+	id                string
+	model             string
+	created           int64
+	systemFingerprint string
+	usage             *Usage
+	choices           map[int]*accumulatedChoice
+}
+
+type accumulatedChoice struct {
+	message      ChatCompletionMessage
+	finishReason FinishReason
+	logProbs     *LogProbs
+	// audio holds the decoded audio data, as each delta encodes its piece
+	// separately. Pieces that are not valid base64 are left out.
+	audio []byte
+	// toolCalls are keyed by the index the stream gives them, which is also the
+	// order they are returned in.
+	toolCalls map[int]*ToolCall
+	// lastToolCall is the index of the tool call that fragments without an
+	// index belong to.
+	lastToolCall int
+}
+
+// Add merges chunk into the accumulated response.
+func (a *ChatCompletionStreamAccumulator) Add(chunk ChatCompletionStreamResponse) {
+	if a.id == "" {
+		a.id = chunk.ID
+	}
+	if a.model == "" {
+		a.model = chunk.Model
+	}
+	if a.created == 0 {
+		a.created = chunk.Created
+	}
+	if chunk.SystemFingerprint != "" {
+		a.systemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+	for _, choice := range chunk.Choices {
+		a.choice(choice.Index).add(choice)
+	}
+}
+
+func (a *ChatCompletionStreamAccumulator) choice(index int) *accumulatedChoice {
+	if a.choices == nil {
+		a.choices = make(map[int]*accumulatedChoice)
+	}
+	c, ok := a.choices[index]
+	if !ok {
+		c = &accumulatedChoice{lastToolCall: -1}
+		a.choices[index] = c
+	}
+	return c
+}
+
+func (c *accumulatedChoice) add(choice ChatCompletionStreamChoice) {
+	delta := choice.Delta
+	if delta.Role != "" {
+		c.message.Role = delta.Role
+	}
+	c.message.Content += delta.Content
+	c.message.Refusal += delta.Refusal
+	c.message.ReasoningContent += delta.ReasoningContent
+	if delta.FunctionCall != nil {
+		if c.message.FunctionCall == nil {
+			c.message.FunctionCall = &FunctionCall{}
+		}
+		c.message.FunctionCall.Name += delta.FunctionCall.Name
+		c.message.FunctionCall.Arguments += delta.FunctionCall.Arguments
+	}
+	for _, fragment := range delta.ToolCalls {
+		c.addToolCall(fragment)
+	}
+	if delta.Audio != nil {
+		c.addAudio(*delta.Audio)
+	}
+	if choice.FinishReason != "" {
+		c.finishReason = choice.FinishReason
+	}
+	if choice.Logprobs != nil {
+		if c.logProbs == nil {
+			c.logProbs = &LogProbs{}
+		}
+		for _, token := range choice.Logprobs.Content {
+			c.logProbs.Content = append(c.logProbs.Content, token.logProb())
+		}
+		for _, token := range choice.Logprobs.Refusal {
+			c.logProbs.Refusal = append(c.logProbs.Refusal, token.logProb())
+		}
+	}
+}
+
+// addToolCall merges a tool call fragment. Fragments are matched by index; a
+// fragment without one starts a new call if it carries an ID, and continues the
+// last call otherwise.
+func (c *accumulatedChoice) addToolCall(fragment ToolCall) {
+	if c.toolCalls == nil {
+		c.toolCalls = make(map[int]*ToolCall)
+	}
+	var index int
+	switch {
+	case fragment.Index != nil:
+		index = *fragment.Index
+	case fragment.ID != "" || c.lastToolCall < 0:
+		index = len(c.toolCalls)
+		for c.toolCalls[index] != nil {
+			index++
+		}
+	default:
+		index = c.lastToolCall
+	}
+	c.lastToolCall = index
+
+	call, ok := c.toolCalls[index]
+	if !ok {
+		call = &ToolCall{}
+		c.toolCalls[index] = call
+	}
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Type != "" {
+		call.Type = fragment.Type
+	}
+	call.Function.Name += fragment.Function.Name
+	call.Function.Arguments += fragment.Function.Arguments
+}
+
+func (c *accumulatedChoice) addAudio(audio ChatCompletionAudio) {
+	if c.message.Audio == nil {
+		c.message.Audio = &ChatCompletionAudio{}
+	}
+	if audio.ID != "" {
+		c.message.Audio.ID = audio.ID
+	}
+	if audio.ExpiresAt != 0 {
+		c.message.Audio.ExpiresAt = audio.ExpiresAt
+	}
+	c.message.Audio.Transcript += audio.Transcript
+	if data, err := base64.StdEncoding.DecodeString(audio.Data); err == nil {
+		c.audio = append(c.audio, data...)
+	}
+}
+
+func (c *accumulatedChoice) build() ChatCompletionMessage {
+	message := c.message
+	if message.Role == "" {
+		message.Role = ChatMessageRoleAssistant
+	}
+	if message.FunctionCall != nil {
+		call := *message.FunctionCall
+		message.FunctionCall = &call
+	}
+	if message.Audio != nil {
+		audio := *message.Audio
+		audio.Data = base64.StdEncoding.EncodeToString(c.audio)
+		message.Audio = &audio
+	}
+	if len(c.toolCalls) == 0 {
+		return message
+	}
+
+	indices := make([]int, 0, len(c.toolCalls))
+	for index := range c.toolCalls {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	message.ToolCalls = make([]ToolCall, 0, len(indices))
+	for _, index := range indices {
+		call := *c.toolCalls[index]
+		if call.Type == "" {
+			call.Type = ToolTypeFunction
+		}
+		message.ToolCalls = append(message.ToolCalls, call)
+	}
+	return message
+}
+
+func (c *accumulatedChoice) choice(index int) ChatCompletionChoice {
+	return ChatCompletionChoice{
+		Index:        index,
+		Message:      c.build(),
+		FinishReason: c.finishReason,
+		LogProbs:     c.logProbs,
+	}
+}
+
+// Message returns the message of the first choice generated so far. Its tool
+// calls are in index order and carry no Index, so the message can be sent back
+// as is in the follow-up request.
+func (a *ChatCompletionStreamAccumulator) Message() ChatCompletionMessage {
+	return a.MessageAt(0)
+}
+
+// MessageAt returns the message of the choice with the given index generated so
+// far, for requests with N > 1.
+func (a *ChatCompletionStreamAccumulator) MessageAt(index int) ChatCompletionMessage {
+	c, ok := a.choices[index]
+	if !ok {
+		return ChatCompletionMessage{Role: ChatMessageRoleAssistant}
+	}
+	return c.build()
+}
+
+// FinishReason returns why the first choice finished, or an empty reason while
+// it is still being generated.
+func (a *ChatCompletionStreamAccumulator) FinishReason() FinishReason {
+	return a.FinishReasonAt(0)
+}
+
+// FinishReasonAt returns why the choice with the given index finished, or an
+// empty reason while it is still being generated. With N > 1 the choices finish
+// independently, in any order.
+func (a *ChatCompletionStreamAccumulator) FinishReasonAt(index int) FinishReason {
+	if c, ok := a.choices[index]; ok {
+		return c.finishReason
+	}
+	return ""
+}
+
+// Choice returns the choice with the given index generated so far, and whether
+// any chunk for it was received.
+func (a *ChatCompletionStreamAccumulator) Choice(index int) (ChatCompletionChoice, bool) {
+	c, ok := a.choices[index]
+	if !ok {
+		return ChatCompletionChoice{Index: index, Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant}}, false
+	}
+	return c.choice(index), true
+}
+
+// Usage returns the token usage of the request. It is only reported in the last
+// chunk of streams created with StreamOptions.IncludeUsage, and nil until then.
+func (a *ChatCompletionStreamAccumulator) Usage() *Usage {
+	return a.usage
+}
+
+// Choices returns every choice generated so far, ordered by index.
+func (a *ChatCompletionStreamAccumulator) Choices() []ChatCompletionChoice {
+	indices := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	choices := make([]ChatCompletionChoice, 0, len(indices))
+	for _, index := range indices {
+		choices = append(choices, a.choices[index].choice(index))
+	}
+	return choices
+}
+
+// Response returns the accumulated stream as the response the same request would
+// have received without streaming.
+func (a *ChatCompletionStreamAccumulator) Response() ChatCompletionResponse {
+	resp := ChatCompletionResponse{
+		ID:                a.id,
+		Object:            "chat.completion",
+		Created:           a.created,
+		Model:             a.model,
+		Choices:           a.Choices(),
+		SystemFingerprint: a.systemFingerprint,
+	}
+	if a.usage != nil {
+		resp.Usage = *a.usage
+	}
+	return resp
+}
@@ -0,0 +1,373 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// Streams captured from the API, trimmed of logprobs and fingerprints.
+//
+//nolint:lll
+const (
+	parallelToolCallsStream = `data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"role":"assistant","content":null,"tool_calls":[{"index":0,"id":"call_Paris","type":"function","function":{"name":"get_weather","arguments":""}}],"refusal":null},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"lo"}}]},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"cation\": \"Paris\"}"}}]},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_Tokyo","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"location\": "}}]},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"Tokyo\"}"}}]},"finish_reason":null}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":null}
+
+data: {"id":"chatcmpl-A1","object":"chat.completion.chunk","created":1726000000,"model":"gpt-4o-2024-08-06","choices":[],"usage":{"prompt_tokens":80,"completion_tokens":42,"total_tokens":122}}
+
+data: [DONE]
+
+`
+
+	multipleChoicesStream = `data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null},{"index":1,"delta":{"role":"assistant","content":""},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":1,"delta":{"content":"Bonjour"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":" there"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-B2","object":"chat.completion.chunk","created":1726000001,"model":"gpt-4o-mini","choices":[{"index":1,"delta":{},"finish_reason":"length"}]}
+
+data: [DONE]
+
+`
+
+	refusalStream = `data: {"id":"chatcmpl-C3","object":"chat.completion.chunk","created":1726000002,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"role":"assistant","content":null,"refusal":""},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-C3","object":"chat.completion.chunk","created":1726000002,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"refusal":"I'm sorry, "},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-C3","object":"chat.completion.chunk","created":1726000002,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"refusal":"I can't help with that."},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-C3","object":"chat.completion.chunk","created":1726000002,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`
+
+	// Choices start out of order and keep streaming after others finished.
+	outOfOrderChoicesStream = `data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[{"index":2,"delta":{"role":"assistant","content":"Três"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[{"index":1,"delta":{"role":"assistant","content":"Dos"},"finish_reason":null},{"index":0,"delta":{"role":"assistant","content":"One"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[{"index":2,"delta":{"content":" e quatro"},"finish_reason":null},{"index":1,"delta":{"content":" y tres"},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[{"index":2,"delta":{},"finish_reason":"length"}]}
+
+data: {"id":"chatcmpl-E5","object":"chat.completion.chunk","created":1726000004,"model":"gpt-4o-mini","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":12,"total_tokens":21}}
+
+data: [DONE]
+
+`
+
+	// Some OpenAI compatible servers leave out the index of tool calls.
+	unindexedToolCallsStream = `data: {"id":"D4","object":"chat.completion.chunk","created":1726000003,"model":"local","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":"}}]},"finish_reason":null}]}
+
+data: {"id":"D4","object":"chat.completion.chunk","created":1726000003,"model":"local","choices":[{"index":0,"delta":{"tool_calls":[{"function":{"arguments":"\"go\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"D4","object":"chat.completion.chunk","created":1726000003,"model":"local","choices":[{"index":0,"delta":{"tool_calls":[{"id":"call_2","function":{"name":"lookup","arguments":"{\"q\":\"rust\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"D4","object":"chat.completion.chunk","created":1726000003,"model":"local","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+)
+
+// replayStream accumulates a captured stream received through the client.
+func replayStream(t *testing.T, captured string) *openai.ChatCompletionStreamAccumulator {
+	t.Helper()
+	client, server, teardown := setupOpenAITestServer()
+	t.Cleanup(teardown)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(captured))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	acc := &openai.ChatCompletionStreamAccumulator{}
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return acc
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		acc.Add(chunk)
+	}
+}
+
+func TestStreamAccumulatorParallelToolCalls(t *testing.T) {
+	acc := replayStream(t, parallelToolCallsStream)
+
+	want := openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleAssistant,
+		ToolCalls: []openai.ToolCall{
+			{ID: "call_Paris", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{
+				Name: "get_weather", Arguments: `{"location": "Paris"}`,
+			}},
+			{ID: "call_Tokyo", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{
+				Name: "get_weather", Arguments: `{"location": "Tokyo"}`,
+			}},
+		},
+	}
+	if got := acc.Message(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected message:\n got %+v\nwant %+v", got, want)
+	}
+	if acc.FinishReason() != openai.FinishReasonToolCalls {
+		t.Errorf("unexpected finish reason %q", acc.FinishReason())
+	}
+	if usage := acc.Usage(); usage == nil || usage.TotalTokens != 122 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	// The message can be sent back as is, without the chunk indices.
+	data, err := json.Marshal(acc.Message())
+	checks.NoError(t, err, "marshal error")
+	if strings.Contains(string(data), `"index"`) {
+		t.Errorf("unexpected follow-up message %s", data)
+	}
+}
+
+func TestStreamAccumulatorMultipleChoices(t *testing.T) {
+	acc := replayStream(t, multipleChoicesStream)
+
+	choices := acc.Choices()
+	if len(choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(choices))
+	}
+	if choices[0].Message.Content != "Hello there" || choices[0].FinishReason != openai.FinishReasonStop {
+		t.Errorf("unexpected first choice %+v", choices[0])
+	}
+	if choices[1].Message.Content != "Bonjour" || choices[1].FinishReason != openai.FinishReasonLength {
+		t.Errorf("unexpected second choice %+v", choices[1])
+	}
+	if acc.MessageAt(1).Content != "Bonjour" {
+		t.Errorf("unexpected message at 1: %+v", acc.MessageAt(1))
+	}
+
+	resp := acc.Response()
+	if resp.ID != "chatcmpl-B2" || resp.Model != "gpt-4o-mini" || len(resp.Choices) != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestStreamAccumulatorRefusal(t *testing.T) {
+	acc := replayStream(t, refusalStream)
+
+	msg := acc.Message()
+	if msg.Refusal != "I'm sorry, I can't help with that." || msg.Content != "" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
+
+func TestStreamAccumulatorUnindexedToolCalls(t *testing.T) {
+	acc := replayStream(t, unindexedToolCallsStream)
+
+	calls := acc.Message().ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %+v", calls)
+	}
+	if calls[0].ID != "call_1" || calls[0].Function.Arguments != `{"q":"go"}` {
+		t.Errorf("unexpected first call %+v", calls[0])
+	}
+	if calls[1].ID != "call_2" || calls[1].Type != openai.ToolTypeFunction || calls[1].Function.Arguments != `{"q":"rust"}` {
+		t.Errorf("unexpected second call %+v", calls[1])
+	}
+}
+
+func TestStreamAccumulatorInProgress(t *testing.T) {
+	var acc openai.ChatCompletionStreamAccumulator
+	if msg := acc.Message(); msg.Role != openai.ChatMessageRoleAssistant || msg.Content != "" {
+		t.Fatalf("unexpected empty message %+v", msg)
+	}
+
+	acc.Add(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+		{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "Hel"}},
+	}})
+	if acc.Message().Content != "Hel" || acc.FinishReason() != "" {
+		t.Fatalf("unexpected partial message %+v", acc.Message())
+	}
+	acc.Add(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+		{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "lo"}},
+	}})
+	if acc.Message().Content != "Hello" {
+		t.Fatalf("unexpected message %+v", acc.Message())
+	}
+}
+
+func TestStreamAccumulatorLogProbs(t *testing.T) {
+	var acc openai.ChatCompletionStreamAccumulator
+	for _, token := range []openai.ChatCompletionTokenLogprob{
+		{Token: "Hi", Logprob: -0.1, Bytes: []int64{72, 105}},
+		{Token: ` \xe2\x98`, Logprob: -0.2, Bytes: []int64{32, 226, 152}},
+		{Token: `\x95`, Logprob: -0.3, Bytes: []int64{149}, TopLogprobs: []openai.ChatCompletionTokenLogprobTopLogprob{
+			{Token: "!", Logprob: -2, Bytes: []int64{33}},
+		}},
+	} {
+		chunk := &openai.ChatCompletionStreamChoiceLogprobs{
+			Content: []openai.ChatCompletionTokenLogprob{token},
+		}
+		if token.Token == ` \xe2\x98` && chunk.Text() != " " {
+			t.Errorf("unexpected text of an incomplete character %q", chunk.Text())
+		}
+		acc.Add(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+			{Logprobs: chunk},
+		}})
+	}
+
+	logProbs := acc.Choices()[0].LogProbs
+	if logProbs == nil || len(logProbs.Content) != 3 {
+		t.Fatalf("unexpected logprobs %+v", logProbs)
+	}
+	if text := logProbs.Text(); text != "Hi ☕" {
+		t.Errorf("unexpected text %q", text)
+	}
+	if top := logProbs.Content[2].TopLogProbs; len(top) != 1 || top[0].LogProb != -2 || string(top[0].Bytes) != "!" {
+		t.Errorf("unexpected top logprobs %+v", top)
+	}
+}
+
+func TestStreamChatCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("x-request-id", "req_stream")
+		_, _ = w.Write([]byte(parallelToolCallsStream))
+	})
+
+	var chunks int
+	resp, err := client.StreamChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+	}, func(openai.ChatCompletionStreamResponse) error {
+		chunks++
+		return nil
+	})
+	checks.NoError(t, err, "StreamChatCompletion error")
+	if chunks != 8 {
+		t.Errorf("expected 8 chunks, got %d", chunks)
+	}
+	if resp.ID != "chatcmpl-A1" || resp.Usage.TotalTokens != 122 || resp.Header().Get("x-request-id") != "req_stream" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if len(resp.Choices) != 1 || len(resp.Choices[0].Message.ToolCalls) != 2 ||
+		resp.Choices[0].FinishReason != openai.FinishReasonToolCalls {
+		t.Errorf("unexpected choices %+v", resp.Choices)
+	}
+}
+
+func TestStreamChatCompletionCallbackError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(multipleChoicesStream))
+	})
+
+	errStop := errors.New("stop")
+	var chunks int
+	resp, err := client.StreamChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+	}, func(openai.ChatCompletionStreamResponse) error {
+		chunks++
+		if chunks == 3 {
+			return errStop
+		}
+		return nil
+	})
+	checks.ErrorIs(t, err, errStop, "StreamChatCompletion should return the callback error")
+	if chunks != 3 {
+		t.Errorf("expected the stream to stop after 3 chunks, got %d", chunks)
+	}
+	// The chunks received until then are still returned.
+	if len(resp.Choices) != 2 || resp.Choices[0].Message.Content != "Hello" || resp.Choices[1].Message.Content != "Bonjour" {
+		t.Errorf("unexpected partial response %+v", resp.Choices)
+	}
+}
+
+func TestStreamAccumulatorOutOfOrderChoices(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(outOfOrderChoicesStream))
+	})
+
+	var (
+		acc    openai.ChatCompletionStreamAccumulator
+		chunks int
+	)
+	resp, err := client.StreamChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:         openai.GPT4oMini,
+		N:             3,
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	}, func(chunk openai.ChatCompletionStreamResponse) error {
+		acc.Add(chunk)
+		chunks++
+		// The first choice finishing does not end the others.
+		if chunks == 3 {
+			choice, _ := acc.Choice(2)
+			if acc.FinishReasonAt(0) != openai.FinishReasonStop || choice.FinishReason != "" ||
+				choice.Message.Content != "Três" {
+				t.Errorf("unexpected choices after the first finished: %+v", acc.Choices())
+			}
+		}
+		return nil
+	})
+	checks.NoError(t, err, "StreamChatCompletion error")
+
+	want := []struct {
+		content string
+		reason  openai.FinishReason
+	}{
+		{"One", openai.FinishReasonStop},
+		{"Dos y tres", openai.FinishReasonStop},
+		{"Três e quatro", openai.FinishReasonLength},
+	}
+	if len(resp.Choices) != len(want) {
+		t.Fatalf("expected %d choices, got %+v", len(want), resp.Choices)
+	}
+	for i, w := range want {
+		choice, ok := acc.Choice(i)
+		if !ok || choice.Index != i || choice.Message.Content != w.content || choice.FinishReason != w.reason {
+			t.Errorf("unexpected choice %d: %+v", i, choice)
+		}
+		if !reflect.DeepEqual(resp.Choices[i], choice) {
+			t.Errorf("unexpected response choice %d: %+v", i, resp.Choices[i])
+		}
+	}
+	if _, ok := acc.Choice(3); ok {
+		t.Error("unexpected choice 3")
+	}
+	if resp.Usage.TotalTokens != 21 {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
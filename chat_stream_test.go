@@ -8,9 +8,12 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 )
 
@@ -642,8 +645,7 @@ func TestAzureCreateChatCompletionStreamRateLimitError(t *testing.T) {
 		t.Errorf("Did not return HTTPStatusCode got = %d, want = %d\n", apiErr.HTTPStatusCode, http.StatusTooManyRequests)
 		return
 	}
-	code, ok := apiErr.Code.(string)
-	if !ok || code != wantCode {
+	if string(apiErr.Code) != wantCode {
 		t.Errorf("Did not return Code. got = %v, want = %s\n", apiErr.Code, wantCode)
 		return
 	}
@@ -747,6 +749,9 @@ func TestCreateChatCompletionStreamStreamOptions(t *testing.T) {
 		b, _ := json.Marshal(expectedResponse)
 		t.Logf("%d: %s", ix, string(b))
 
+		if stream.GetUsage() != nil {
+			t.Errorf("Stream usage %v before the usage chunk", stream.GetUsage())
+		}
 		receivedResponse, streamErr := stream.Recv()
 		checks.NoError(t, streamErr, "stream.Recv() failed")
 		if !compareChatResponses(expectedResponse, receivedResponse) {
@@ -758,6 +763,12 @@ func TestCreateChatCompletionStreamStreamOptions(t *testing.T) {
 	if !errors.Is(streamErr, io.EOF) {
 		t.Errorf("stream.Recv() did not return EOF in the end: %v", streamErr)
 	}
+	if usage := stream.GetUsage(); usage == nil || usage.TotalTokens != 2 {
+		t.Errorf("Stream usage is %v, expected 2 total tokens", usage)
+	}
+	if fingerprint := stream.GetSystemFingerprint(); fingerprint != "fp_d9767fc5b9" {
+		t.Errorf("Stream system fingerprint is %q, expected fp_d9767fc5b9", fingerprint)
+	}
 
 	_, streamErr = stream.Recv()
 
@@ -1021,3 +1032,438 @@ func compareChatStreamResponseChoices(c1, c2 openai.ChatCompletionStreamChoice)
 	}
 	return true
 }
+
+func TestCreateChatCompletionStreamRawJSON(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	chunks := []string{
+		`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi"}}],"obfuscation":"a"}`,
+		`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.WithOptions(openai.WithRawJSON()).CreateChatCompletionStream(context.Background(),
+		openai.ChatCompletionRequest{Model: openai.GPT4o, Stream: true})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	for i, chunk := range chunks {
+		resp, recvErr := stream.Recv()
+		checks.NoError(t, recvErr, "Recv error")
+		if string(resp.RawJSON()) != chunk {
+			t.Errorf("chunk %d: unexpected raw JSON %s", i, resp.RawJSON())
+		}
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "stream should end")
+}
+
+func TestCreateChatCompletionStreamMidStreamFailures(t *testing.T) {
+	const (
+		chunk    = `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi"}}]}`
+		finished = `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},` +
+			`"finish_reason":"stop"}]}`
+		rateLimit = `data: {"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`
+	)
+	testCases := []struct {
+		name    string
+		body    string
+		chunks  int
+		wantErr func(t *testing.T, err error)
+	}{
+		{
+			name: "error as the first event",
+			body: rateLimit + "\n\n",
+			wantErr: func(t *testing.T, err error) {
+				if !openai.IsRateLimit(err) {
+					t.Errorf("expected a rate limit error, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "error in the middle",
+			body:   chunk + "\n\n" + `data: { "error": {"message": "Rate limit reached", "code": 429} }` + "\n\n",
+			chunks: 1,
+			wantErr: func(t *testing.T, err error) {
+				var apiErr *openai.APIError
+				if !errors.As(err, &apiErr) || apiErr.Message != "Rate limit reached" {
+					t.Errorf("expected the APIError of the event, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "unterminated error before the connection closes",
+			body:   chunk + "\n\n" + rateLimit,
+			chunks: 1,
+			wantErr: func(t *testing.T, err error) {
+				if !openai.IsRateLimit(err) {
+					t.Errorf("expected a rate limit error, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "connection closed mid-event",
+			body:   chunk + "\n\n" + chunk[:40],
+			chunks: 1,
+			wantErr: func(t *testing.T, err error) {
+				checks.ErrorIs(t, err, openai.ErrStreamInterrupted)
+				checks.ErrorIs(t, err, io.ErrUnexpectedEOF)
+				if errors.Is(err, io.EOF) {
+					t.Error("interrupted stream reported as ended")
+				}
+			},
+		},
+		{
+			name:   "connection closed before the finish reason",
+			body:   chunk + "\n\n" + chunk + "\n\n",
+			chunks: 2,
+			wantErr: func(t *testing.T, err error) {
+				checks.ErrorIs(t, err, openai.ErrStreamInterrupted)
+			},
+		},
+		{
+			name:   "connection closed after the finish reason",
+			body:   chunk + "\n\n" + finished + "\n\n",
+			chunks: 2,
+			wantErr: func(t *testing.T, err error) {
+				checks.ErrorIs(t, err, io.EOF)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(tc.body))
+			})
+
+			stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+				Model:    openai.GPT4oMini,
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+			})
+			checks.NoError(t, err, "CreateChatCompletionStream error")
+			defer stream.Close()
+
+			for i := 0; i < tc.chunks; i++ {
+				_, err = stream.Recv()
+				checks.NoError(t, err, "Recv error")
+			}
+			_, err = stream.Recv()
+			tc.wantErr(t, err)
+			if _, err = stream.Recv(); !errors.Is(err, io.EOF) {
+				t.Errorf("expected io.EOF after the failure, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionStreamUsageDetails(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":"stop"}]}` +
+			"\n\n" + `data: {"id":"1","choices":[],"usage":{"prompt_tokens":2000,"completion_tokens":300,` +
+			`"total_tokens":2300,"prompt_tokens_details":{"cached_tokens":1920,"audio_tokens":0},` +
+			`"completion_tokens_details":{"reasoning_tokens":256,"audio_tokens":0,` +
+			`"accepted_prediction_tokens":0,"rejected_prediction_tokens":0}}}` + "\n\ndata: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:         openai.O3Mini,
+		Messages:      []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "Recv error")
+	}
+
+	usage := stream.GetUsage()
+	if usage == nil || usage.PromptTokensDetails == nil || usage.PromptTokensDetails.CachedTokens != 1920 ||
+		usage.CompletionTokensDetails == nil || usage.CompletionTokensDetails.ReasoningTokens != 256 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+}
+
+func TestCreateChatCompletionStreamMaxEventSize(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.StreamMaxEventSize = 1 << 10
+	client := openai.NewClientWithConfig(config)
+
+	content := strings.Repeat("a", 2<<10)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` +
+			"\n\ndata: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, openai.ErrStreamEventTooLarge)
+}
+
+func TestCreateChatCompletionStreamRawEvents(t *testing.T) {
+	const (
+		chunk    = `{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi"}}]}`
+		finished = `{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`
+	)
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+			"data: " + chunk + "\n\n" +
+			"data: processing\n\n" +
+			"event: message\ndata: " + finished + "\n\n" +
+			"data: [done]\n\n"))
+	})
+
+	stream, err := client.WithOptions(openai.WithRawStreamEvents()).CreateChatCompletionStream(
+		context.Background(),
+		openai.ChatCompletionRequest{
+			Model:    openai.GPT4oMini,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+		},
+	)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var raw *openai.RawStreamEvent
+	_, err = stream.Recv()
+	if !errors.As(err, &raw) || raw.Event != "ping" || string(raw.Data) != `{"type":"ping"}` || raw.Err != nil {
+		t.Fatalf("expected the raw ping event, got %v", err)
+	}
+	response, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if response.Choices[0].Delta.Content != "Hi" {
+		t.Errorf("unexpected chunk %+v", response)
+	}
+	_, err = stream.Recv()
+	if !errors.As(err, &raw) || string(raw.Data) != "processing" || raw.Err == nil {
+		t.Fatalf("expected the raw undecodable event, got %v", err)
+	}
+	response, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if response.Choices[0].FinishReason != openai.FinishReasonStop {
+		t.Errorf("unexpected chunk %+v", response)
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "stream should end at [done]")
+}
+
+func TestCreateChatCompletionStreamClosedAfterFinalChunk(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// The final chunk is not even followed by a line ending.
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n" +
+			`data: {"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for i := 0; i < 2; i++ {
+		_, err = stream.Recv()
+		checks.NoError(t, err, "Recv error")
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "stream should end after the final chunk")
+}
+
+func TestCreateChatCompletionStreamRecvContext(t *testing.T) {
+	const chunk = `data: {"id":"1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	release := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chunk))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(chunk + "data: [DONE]\n\n"))
+	})
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	recv := func() (openai.ChatCompletionStreamResponse, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		return stream.RecvContext(ctx)
+	}
+	_, err = recv()
+	checks.NoError(t, err, "RecvContext error")
+	_, err = recv()
+	checks.ErrorIs(t, err, context.DeadlineExceeded, "RecvContext should time out")
+	_, err = recv()
+	checks.ErrorIs(t, err, context.DeadlineExceeded, "RecvContext should time out again")
+
+	close(release)
+	response, err := stream.Recv()
+	checks.NoError(t, err, "Recv error after the timeouts")
+	if response.Choices[0].Delta.Content != "Hi" {
+		t.Errorf("unexpected chunk %+v", response)
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "stream should end")
+
+	// Canceling the context of the stream aborts it.
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chunk))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err = client.CreateChatCompletionStream(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, context.Canceled, "Recv should fail with the error of the canceled context")
+	if errors.Is(err, io.EOF) {
+		t.Errorf("aborted stream reported as ended: %v", err)
+	}
+}
+
+func TestChatCompletionStreamTextReader(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			`data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"content":"Caf"}}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":1,"delta":{"content":"other choice"}}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"content":"é 日本"}}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1",` +
+				`"type":"function","function":{"name":"f","arguments":"{}"}}]}}]}` + "\n\n" +
+				`data: {"id":"1","choices":[]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"content":"語 🍵"},"finish_reason":"stop"}]}` + "\n\n" +
+				"data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	reader := stream.TextReader()
+	defer reader.Close()
+
+	// Reading a byte at a time splits the multi-byte characters.
+	var text []byte
+	buf := make([]byte, 1)
+	for {
+		n, readErr := reader.Read(buf)
+		text = append(text, buf[:n]...)
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, readErr, "Read error")
+	}
+	if string(text) != "Café 日本語 🍵" {
+		t.Errorf("read %q, want %q", text, "Café 日本語 🍵")
+	}
+}
+
+func TestChatCompletionStreamTextReaderError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chatStreamChunk("Hello") +
+			`data: {"error":{"message":"Rate limit reached","code":"rate_limit_exceeded"}}` + "\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	reader := stream.TextReader()
+	text, err := io.ReadAll(reader)
+	if string(text) != "Hello" || !openai.IsRateLimit(err) {
+		t.Errorf("read %q with error %v, want the text before the rate limit error", text, err)
+	}
+	checks.NoError(t, reader.Close(), "Close error")
+}
+
+func TestCreateChatCompletionStreamChoices(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	// The connection closes without [DONE], which only ends the stream once
+	// all the choices finished.
+	body := strings.TrimSuffix(outOfOrderChoicesStream, "data: [DONE]\n\n")
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(body))
+	})
+	recvAll := func(n int) (*openai.ChatCompletionStream, error) {
+		stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+			Model: openai.GPT4oMini,
+			N:     n,
+		})
+		checks.NoError(t, err, "CreateChatCompletionStream error")
+		for {
+			if _, err = stream.Recv(); err != nil {
+				return stream, err
+			}
+		}
+	}
+
+	stream, err := recvAll(3)
+	defer stream.Close()
+	checks.ErrorIs(t, err, io.EOF, "the stream should end once all the choices finished")
+	choice, ok := stream.Choice(1)
+	if !ok || choice.Message.Content != "Dos y tres" || choice.FinishReason != openai.FinishReasonStop {
+		t.Errorf("unexpected choice 1: %+v", choice)
+	}
+
+	// Only the first of the three choices finishes before the connection closes.
+	body = strings.Join(strings.SplitAfterN(body, "\n\n", 4)[:3], "")
+	stream, err = recvAll(3)
+	defer stream.Close()
+	checks.ErrorIs(t, err, openai.ErrStreamInterrupted, "the stream should not end before all the choices finished")
+	if choice, ok = stream.Choice(0); !ok || choice.FinishReason != openai.FinishReasonStop {
+		t.Errorf("unexpected choice 0: %+v", choice)
+	}
+	if choice, ok = stream.Choice(2); !ok || choice.Message.Content != "Três" || choice.FinishReason != "" {
+		t.Errorf("unexpected choice 2: %+v", choice)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 	"github.com/sashabaranov/go-openai/jsonschema"
 )
@@ -119,7 +121,7 @@ func TestO1ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(2),
+				Temperature: openai.Float(2),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -136,8 +138,8 @@ func TestO1ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(1),
-				TopP:        float32(0.1),
+				Temperature: openai.Float(1),
+				TopP:        openai.Float(0.1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -154,8 +156,8 @@ func TestO1ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(1),
-				TopP:        float32(1),
+				Temperature: openai.Float(1),
+				TopP:        openai.Float(1),
 				N:           2,
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
@@ -173,7 +175,7 @@ func TestO1ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				PresencePenalty: float32(1),
+				PresencePenalty: openai.Float(1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -190,7 +192,7 @@ func TestO1ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				FrequencyPenalty: float32(0.1),
+				FrequencyPenalty: openai.Float(0.1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -239,7 +241,7 @@ func TestO3ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(2),
+				Temperature: openai.Float(2),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -256,8 +258,8 @@ func TestO3ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(1),
-				TopP:        float32(0.1),
+				Temperature: openai.Float(1),
+				TopP:        openai.Float(0.1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -274,8 +276,8 @@ func TestO3ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				Temperature: float32(1),
-				TopP:        float32(1),
+				Temperature: openai.Float(1),
+				TopP:        openai.Float(1),
 				N:           2,
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
@@ -293,7 +295,7 @@ func TestO3ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				PresencePenalty: float32(1),
+				PresencePenalty: openai.Float(1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -310,7 +312,7 @@ func TestO3ModelsChatCompletionsBetaLimitations(t *testing.T) {
 						Role: openai.ChatMessageRoleAssistant,
 					},
 				},
-				FrequencyPenalty: float32(0.1),
+				FrequencyPenalty: openai.Float(0.1),
 			},
 			expectedError: openai.ErrReasoningModelLimitationsOther,
 		},
@@ -1085,3 +1087,383 @@ func TestChatCompletionRequest_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+type structuredRecipe struct {
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty" enum:"easy,medium,hard"`
+	Servings   *int   `json:"servings"`
+	Source     *struct {
+		Author string `json:"author"`
+		URL    string `json:"url,omitempty"`
+	} `json:"source,omitempty"`
+	Steps []string `json:"steps"`
+}
+
+func TestStructuredOutputsRoundTrip(t *testing.T) {
+	format, err := openai.GenerateJSONSchemaResponseFormat("recipe", structuredRecipe{})
+	checks.NoError(t, err, "GenerateJSONSchemaResponseFormat error")
+	if !format.Strict || format.Name != "recipe" {
+		t.Fatalf("unexpected response format %+v", format)
+	}
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	content := `{"title":"Crepes","difficulty":"easy","servings":null,` +
+		`"source":{"author":"Ann","url":null},"steps":["mix","cook"]}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResponseFormat struct {
+				JSONSchema struct {
+					Strict bool `json:"strict"`
+					Schema struct {
+						AdditionalProperties bool     `json:"additionalProperties"`
+						Required             []string `json:"required"`
+					} `json:"schema"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		schema := req.ResponseFormat.JSONSchema
+		if !schema.Strict || schema.Schema.AdditionalProperties || len(schema.Schema.Required) != 5 {
+			http.Error(w, "not a strict schema", http.StatusBadRequest)
+			return
+		}
+		resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+		}}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Crepes?"}},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type:       openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: format,
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	var recipe structuredRecipe
+	err = format.Unmarshal(resp, &recipe)
+	checks.NoError(t, err, "Unmarshal error")
+	if recipe.Title != "Crepes" || recipe.Servings != nil || recipe.Source == nil ||
+		recipe.Source.Author != "Ann" || len(recipe.Steps) != 2 {
+		t.Fatalf("unexpected recipe %+v", recipe)
+	}
+
+	resp.Choices[0].Message.Content = strings.Replace(content, "easy", "trivial", 1)
+	checks.HasError(t, format.Unmarshal(resp, &recipe), "enum violation should fail")
+}
+
+func TestStructuredOutputsRefusal(t *testing.T) {
+	format, err := openai.GenerateJSONSchemaResponseFormat("recipe", structuredRecipe{})
+	checks.NoError(t, err, "GenerateJSONSchemaResponseFormat error")
+
+	var recipe structuredRecipe
+	err = format.Unmarshal(openai.ChatCompletionResponse{}, &recipe)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionNoChoices, "expected no choices error")
+
+	err = format.Unmarshal(openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+		Message: openai.ChatCompletionMessage{Refusal: "I can't help with that."},
+	}}}, &recipe)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionRefusal, "expected refusal error")
+}
+
+func TestChatCompletionLogProbs(t *testing.T) {
+	// "café ☕" with the cup split across two tokens, as the API reports it.
+	const response = `{"id":"chatcmpl-L1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,
+		"message":{"role":"assistant","content":"café ☕"},"finish_reason":"stop","logprobs":{"content":[
+		{"token":"caf","logprob":-0.1,"bytes":[99,97,102],"top_logprobs":[]},
+		{"token":"é","logprob":-0.2,"bytes":[195,169],"top_logprobs":[{"token":"e","logprob":-1.5,"bytes":[101]}]},
+		{"token":" \\xe2\\x98","logprob":-0.3,"bytes":[32,226,152],"top_logprobs":[]},
+		{"token":"\\x95","logprob":-0.4,"bytes":[149],"top_logprobs":[]}],"refusal":null}}]}`
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(response))
+	})
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:       openai.GPT4o,
+		Messages:    []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Order"}},
+		LogProbs:    true,
+		TopLogProbs: 1,
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	logProbs := resp.Choices[0].LogProbs
+	if logProbs == nil || len(logProbs.Content) != 4 {
+		t.Fatalf("unexpected logprobs %+v", logProbs)
+	}
+	if top := logProbs.Content[1].TopLogProbs; len(top) != 1 || string(top[0].Bytes) != "e" {
+		t.Errorf("unexpected top logprobs %+v", top)
+	}
+	if text := logProbs.Text(); text != "café ☕" {
+		t.Errorf("unexpected text %q", text)
+	}
+
+	// Until the last token arrives, the split character is left out.
+	partial := openai.LogProbs{Content: logProbs.Content[:3]}
+	if text := partial.Text(); text != "café " {
+		t.Errorf("unexpected partial text %q", text)
+	}
+}
+
+func TestChatCompletionSeedRoundTrip(t *testing.T) {
+	for _, seed := range []*int{nil, new(int), func() *int { v := 42; return &v }()} {
+		data, err := json.Marshal(openai.ChatCompletionRequest{Model: openai.GPT4o, Seed: seed})
+		checks.NoError(t, err, "Marshal error")
+		if hasSeed := strings.Contains(string(data), `"seed"`); hasSeed != (seed != nil) {
+			t.Fatalf("unexpected request JSON %s", data)
+		}
+
+		var req openai.ChatCompletionRequest
+		checks.NoError(t, json.Unmarshal(data, &req), "Unmarshal error")
+		if (req.Seed == nil) != (seed == nil) || (seed != nil && *req.Seed != *seed) {
+			t.Errorf("seed %v did not round-trip: %s", seed, data)
+		}
+	}
+
+	data, err := json.Marshal(openai.ChatCompletionResponse{SystemFingerprint: "fp_44709d6fcb"})
+	checks.NoError(t, err, "Marshal error")
+	var resp openai.ChatCompletionResponse
+	checks.NoError(t, json.Unmarshal(data, &resp), "Unmarshal error")
+	if resp.SystemFingerprint != "fp_44709d6fcb" {
+		t.Errorf("system fingerprint did not round-trip: %s", data)
+	}
+}
+
+func TestToolChoiceJSON(t *testing.T) {
+	cases := []struct {
+		choice openai.ToolChoice
+		json   string
+	}{
+		{openai.ToolChoiceNone(), `"none"`},
+		{openai.ToolChoiceAuto(), `"auto"`},
+		{openai.ToolChoiceRequired(), `"required"`},
+		{openai.ToolChoiceFunction("get_weather"), `{"type":"function","function":{"name":"get_weather"}}`},
+	}
+	for _, tc := range cases {
+		data, err := json.Marshal(tc.choice)
+		checks.NoError(t, err, "Marshal error")
+		if string(data) != tc.json {
+			t.Errorf("unexpected JSON %s, want %s", data, tc.json)
+		}
+
+		var choice openai.ToolChoice
+		checks.NoError(t, json.Unmarshal(data, &choice), "Unmarshal error")
+		if choice != tc.choice {
+			t.Errorf("unexpected choice %+v decoded from %s", choice, data)
+		}
+	}
+
+	parallelToolCalls := false
+	request := openai.ChatCompletionRequest{
+		Model:             openai.GPT4o,
+		Tools:             []openai.Tool{{Type: openai.ToolTypeFunction}},
+		ToolChoice:        openai.ToolChoiceRequired(),
+		ParallelToolCalls: &parallelToolCalls,
+	}
+	data, err := json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(data), `"tool_choice":"required","parallel_tool_calls":false`) {
+		t.Errorf("unexpected request JSON %s", data)
+	}
+
+	request.ParallelToolCalls = nil
+	data, err = json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(data), "parallel_tool_calls") {
+		t.Errorf("unset parallel_tool_calls should be omitted, got %s", data)
+	}
+}
+
+func TestChatCompletionToolChoiceWithoutTools(t *testing.T) {
+	client := openai.NewClient("")
+	request := openai.ChatCompletionRequest{
+		Model:      openai.GPT4o,
+		Messages:   []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		ToolChoice: openai.ToolChoiceAuto(),
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionToolChoiceNoTools, "CreateChatCompletion error")
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionToolChoiceNoTools, "CreateChatCompletionStream error")
+}
+
+func TestPredictionJSON(t *testing.T) {
+	cases := []struct {
+		prediction openai.Prediction
+		json       string
+	}{
+		{openai.Prediction{Content: "func main() {}"}, `{"content":"func main() {}","type":"content"}`},
+		{openai.Prediction{MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "func main() {}"},
+		}}, `{"content":[{"type":"text","text":"func main() {}"}],"type":"content"}`},
+	}
+	for _, tc := range cases {
+		data, err := json.Marshal(tc.prediction)
+		checks.NoError(t, err, "Marshal error")
+		if string(data) != tc.json {
+			t.Errorf("unexpected JSON %s, want %s", data, tc.json)
+		}
+
+		var prediction openai.Prediction
+		checks.NoError(t, json.Unmarshal(data, &prediction), "Unmarshal error")
+		tc.prediction.Type = openai.PredictionTypeContent
+		if !reflect.DeepEqual(prediction, tc.prediction) {
+			t.Errorf("unexpected prediction %+v decoded from %s", prediction, data)
+		}
+	}
+
+	_, err := json.Marshal(openai.Prediction{Content: "a", MultiContent: []openai.ChatMessagePart{}})
+	checks.ErrorIs(t, err, openai.ErrContentFieldsMisused, "both contents should be rejected")
+}
+
+func TestChatCompletionPrediction(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-P","object":"chat.completion","model":"gpt-4o",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"func main() { run() }"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":20,"completion_tokens":9,"total_tokens":29,
+			"completion_tokens_details":{"accepted_prediction_tokens":5,"rejected_prediction_tokens":1}}}`))
+	})
+
+	request := openai.ChatCompletionRequest{
+		Model:      openai.GPT4o,
+		Messages:   []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Call run in main"}},
+		Prediction: &openai.Prediction{Content: "func main() {}"},
+	}
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	details := resp.Usage.CompletionTokensDetails
+	if details == nil || details.AcceptedPredictionTokens != 5 || details.RejectedPredictionTokens != 1 {
+		t.Errorf("unexpected completion tokens details %+v", details)
+	}
+
+	request.N = 2
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionPredictionParam, "n should be rejected")
+	request.N = 1
+	request.LogProbs = true
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrChatCompletionPredictionParam, "logprobs should be rejected")
+	if err != nil && !strings.Contains(err.Error(), "logprobs") {
+		t.Errorf("error %q does not name the parameter", err)
+	}
+}
+
+func TestReasoningModelSystemMessages(t *testing.T) {
+	request := openai.ChatCompletionRequest{
+		Model:               openai.O3Mini,
+		MaxCompletionTokens: 1000,
+		ReasoningEffort:     openai.ReasoningEffortHigh,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Answer in French."},
+			{Role: openai.ChatMessageRoleUser, Content: "Hello!"},
+		},
+	}
+
+	client := openai.NewClient("")
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrReasoningModelSystemMessage, "system message should be rejected")
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrReasoningModelSystemMessage, "system message should be rejected")
+
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+			req.Messages[0].Role != openai.ChatMessageRoleDeveloper || req.ReasoningEffort != openai.ReasoningEffortHigh {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-R","object":"chat.completion","model":"o3-mini",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"Bonjour !"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":12,"completion_tokens":80,"total_tokens":92,
+			"completion_tokens_details":{"reasoning_tokens":64}}}`))
+	})
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.ReasoningDeveloperMessages = true
+	client = openai.NewClientWithConfig(config)
+
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if details := resp.Usage.CompletionTokensDetails; details == nil || details.ReasoningTokens != 64 {
+		t.Errorf("unexpected completion tokens details %+v", details)
+	}
+	if request.Messages[0].Role != openai.ChatMessageRoleSystem {
+		t.Error("the messages of the request were modified")
+	}
+}
+
+func TestAzureChatCompletionContentFilterResults(t *testing.T) {
+	//nolint:lll
+	const response = `{"id":"chatcmpl-F","object":"chat.completion","model":"gpt-4o",
+		"prompt_filter_results":[{"prompt_index":0,"content_filter_results":{"hate":{"filtered":false,"severity":"safe"},"jailbreak":{"filtered":false,"detected":false}}}],
+		"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"for i := range n {}"},
+		"content_filter_results":{"hate":{"filtered":false,"severity":"low"},"protected_material_text":{"filtered":false,"detected":false},
+		"protected_material_code":{"filtered":false,"detected":true,"citation":{"URL":"https://github.com/golang/go","license":"BSD-3-Clause"}}}}]}`
+
+	var resp openai.ChatCompletionResponse
+	checks.NoError(t, json.Unmarshal([]byte(response), &resp), "Unmarshal error")
+	if len(resp.PromptFilterResults) != 1 || resp.PromptFilterResults[0].ContentFilterResults.Hate.Severity != "safe" {
+		t.Errorf("unexpected prompt filter results %+v", resp.PromptFilterResults)
+	}
+	results := resp.Choices[0].ContentFilterResults
+	code := results.ProtectedMaterialCode
+	if results.Hate.Severity != "low" || results.ProtectedMaterialText == nil || results.ProtectedMaterialText.Detected ||
+		code == nil || !code.Detected || code.Citation == nil || code.Citation.License != "BSD-3-Clause" {
+		t.Errorf("unexpected content filter results %+v", results)
+	}
+
+	var chunk openai.ChatCompletionStreamResponse
+	checks.NoError(t, json.Unmarshal([]byte(response), &chunk), "Unmarshal error")
+	if code := chunk.Choices[0].ContentFilterResults.ProtectedMaterialCode; code == nil || code.Citation == nil {
+		t.Errorf("unexpected chunk content filter results %+v", chunk.Choices[0].ContentFilterResults)
+	}
+}
+
+func TestChatCompletionRawJSON(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	body := `{"id":"chatcmpl-R1","object":"chat.completion","model":"gpt-4o",` +
+		`"choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],` +
+		`"service_tier":"scale","new_field":{"nested":true}}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body + "\n"))
+	})
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if resp.RawJSON() != nil {
+		t.Errorf("raw JSON should only be kept with WithRawJSON, got %s", resp.RawJSON())
+	}
+
+	resp, err = client.WithOptions(openai.WithRawJSON()).CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if string(resp.RawJSON()) != body || resp.Choices[0].Message.Content != "Hi" {
+		t.Fatalf("unexpected raw JSON %s", resp.RawJSON())
+	}
+	var extra struct {
+		NewField struct {
+			Nested bool `json:"nested"`
+		} `json:"new_field"`
+	}
+	checks.NoError(t, json.Unmarshal(resp.RawJSON(), &extra), "Unmarshal error")
+	if !extra.NewField.Nested {
+		t.Errorf("unknown field not readable from raw JSON")
+	}
+}
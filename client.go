@@ -2,13 +2,16 @@ package openai
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
@@ -19,12 +22,38 @@ type Client struct {
 
 	requestBuilder    utils.RequestBuilder
 	createFormBuilder func(io.Writer) utils.FormBuilder
+
+	// models caches the model list for SupportsModel. The clients returned by
+	// WithOptions share it, unless they call another backend or account.
+	models *modelCache
 }
 
 type Response interface {
 	SetHeader(http.Header)
 }
 
+// rawJSONSetter is implemented by the responses that can keep the JSON they were
+// decoded from, for calls made with WithRawJSON.
+type rawJSONSetter interface {
+	setRawJSON(data []byte)
+}
+
+// rawJSON holds the JSON body a response was decoded from.
+type rawJSON struct {
+	raw []byte
+}
+
+// RawJSON returns the JSON the response was decoded from, including fields the
+// library does not know about, for calls made with WithRawJSON. It returns nil
+// otherwise.
+func (r *rawJSON) RawJSON() []byte {
+	return r.raw
+}
+
+func (r *rawJSON) setRawJSON(data []byte) {
+	r.raw = data
+}
+
 type httpHeader http.Header
 
 func (h *httpHeader) SetHeader(header http.Header) {
@@ -39,12 +68,33 @@ func (h *httpHeader) GetRateLimitHeaders() RateLimitHeaders {
 	return newRateLimitHeaders(h.Header())
 }
 
+// RequestID returns the ID OpenAI assigned to the request, to quote in support
+// tickets.
+func (h *httpHeader) RequestID() string {
+	return h.Header().Get("x-request-id")
+}
+
+// ProcessingTime returns how long the API spent processing the request, or zero
+// if the response does not report it.
+func (h *httpHeader) ProcessingTime() time.Duration {
+	ms, err := strconv.ParseFloat(h.Header().Get("openai-processing-ms"), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
 type RawResponse struct {
 	io.ReadCloser
 
 	httpHeader
 }
 
+// ContentType returns the media type of the response body, such as audio/mpeg.
+func (r RawResponse) ContentType() string {
+	return r.Header().Get("Content-Type")
+}
+
 // NewClient creates new OpenAI API client.
 func NewClient(authToken string) *Client {
 	config := DefaultConfig(authToken)
@@ -59,6 +109,7 @@ func NewClientWithConfig(config ClientConfig) *Client {
 		createFormBuilder: func(body io.Writer) utils.FormBuilder {
 			return utils.NewFormBuilder(body)
 		},
+		models: &modelCache{},
 	}
 }
 
@@ -71,6 +122,93 @@ func NewOrgClient(authToken, org string) *Client {
 	return NewClientWithConfig(config)
 }
 
+// CallOption overrides part of the client configuration for the calls made
+// through a client returned by WithOptions.
+type CallOption func(*ClientConfig)
+
+// WithBaseURL sends calls to baseURL instead of ClientConfig.BaseURL. URLs are
+// built from it exactly as from the configured one, including Azure deployments.
+func WithBaseURL(baseURL string) CallOption {
+	return func(config *ClientConfig) {
+		config.BaseURL = baseURL
+	}
+}
+
+// WithHTTPClient sends calls through client instead of ClientConfig.HTTPClient.
+func WithHTTPClient(client HTTPDoer) CallOption {
+	return func(config *ClientConfig) {
+		config.HTTPClient = client
+	}
+}
+
+// WithOrganization sends calls on behalf of the organization orgID instead of
+// ClientConfig.OrgID.
+func WithOrganization(orgID string) CallOption {
+	return func(config *ClientConfig) {
+		config.OrgID = orgID
+	}
+}
+
+// WithProject bills calls to project instead of ClientConfig.Project.
+func WithProject(project string) CallOption {
+	return func(config *ClientConfig) {
+		config.Project = project
+	}
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header of the call, so that
+// the API creates at most one object however many times the call is sent. It is
+// honored by POST endpoints that create objects, such as batches, files, uploads
+// and fine-tuning jobs; it is not sent on GET, HEAD or DELETE requests.
+//
+// A client configured with MaxRetries generates a key for every retryable POST
+// call that has none, and reports it in RetryError.IdempotencyKey, so that a
+// failed call can be re-issued with the same key.
+func WithIdempotencyKey(key string) CallOption {
+	return func(config *ClientConfig) {
+		config.idempotencyKey = key
+	}
+}
+
+// WithRawJSON keeps the JSON body of the responses to the call, returned by their
+// RawJSON method, so that fields the library does not know about can be read.
+// For streams, each chunk keeps its event data. It is off by default to avoid
+// holding a second copy of large responses such as embeddings.
+func WithRawJSON() CallOption {
+	return func(config *ClientConfig) {
+		config.keepRawJSON = true
+	}
+}
+
+// WithRawStreamEvents makes Recv return the events of the streams of the call it
+// cannot decode as a *RawStreamEvent error instead of failing, for streams
+// from gateways and compatible servers that send events of their own. The
+// stream goes on after them.
+func WithRawStreamEvents() CallOption {
+	return func(config *ClientConfig) {
+		config.rawEvents = true
+	}
+}
+
+// WithOptions returns a client sharing c's configuration with opts applied, for
+// use on a per-call basis:
+//
+//	client.WithOptions(openai.WithBaseURL(proxyURL)).CreateChatCompletion(ctx, req)
+//
+// Creating it is cheap, and it shares c's rate limiter and middlewares.
+func (c *Client) WithOptions(opts ...CallOption) *Client {
+	clone := *c
+	for _, opt := range opts {
+		opt(&clone.config)
+	}
+	if clone.config.BaseURL != c.config.BaseURL || clone.config.OrgID != c.config.OrgID ||
+		clone.config.Project != c.config.Project {
+		// Other models may be available there.
+		clone.models = &modelCache{}
+	}
+	return &clone
+}
+
 type requestOptions struct {
 	body   any
 	header http.Header
@@ -104,9 +242,13 @@ func withContentType(contentType string) requestOption {
 	}
 }
 
+// withBetaAssistantVersion sets the OpenAI-Beta header required by the
+// assistants, threads, runs and vector stores endpoints, unless version is empty.
 func withBetaAssistantVersion(version string) requestOption {
 	return func(args *requestOptions) {
-		args.header.Set("OpenAI-Beta", fmt.Sprintf("assistants=%s", version))
+		if version != "" {
+			args.header.Set("OpenAI-Beta", fmt.Sprintf("assistants=%s", version))
+		}
 	}
 }
 
@@ -123,7 +265,9 @@ func (c *Client) newRequest(ctx context.Context, method, url string, setters ...
 	if err != nil {
 		return nil, err
 	}
-	c.setCommonHeaders(req)
+	if err = c.setCommonHeaders(req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
@@ -137,8 +281,11 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	res, err := c.config.HTTPClient.Do(req)
-	if err != nil {
+	req, tracker := c.trackCall(req)
+	res, err := c.doRequest(req, false)
+	tracker.response(res)
+	if res == nil {
+		tracker.finish(nil, err)
 		return err
 	}
 
@@ -148,21 +295,46 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		v.SetHeader(res.Header)
 	}
 
-	if isFailureStatusCode(res) {
-		return c.handleErrorResp(res)
+	if err != nil {
+		tracker.finish(nil, err)
+		return err
 	}
 
-	return decodeResponse(res.Body, v)
+	raw, keepRaw := v.(rawJSONSetter)
+	keepRaw = keepRaw && c.config.keepRawJSON
+	if tracker == nil && !keepRaw {
+		return decodeResponse(res.Body, v)
+	}
+	body := &countingReader{r: res.Body}
+	var buf bytes.Buffer
+	var reader io.Reader = body
+	if keepRaw {
+		reader = io.TeeReader(body, &buf)
+	}
+	err = decodeResponse(reader, v)
+	if keepRaw && err == nil {
+		raw.setRawJSON(bytes.TrimSpace(buf.Bytes()))
+	}
+	if tracker != nil {
+		var decoded *callResponse
+		if err == nil {
+			decoded = decodedCallResponse(v)
+		}
+		tracker.bodySize = body.n
+		tracker.finish(decoded, err)
+	}
+	return err
 }
 
 func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err error) {
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body should be closed by outer function
+	req, tracker := c.trackCall(req)
+	resp, err := c.doRequest(req, true) //nolint:bodyclose // body should be closed by outer function
+	tracker.response(resp)
+	tracker.finish(nil, err)
 	if err != nil {
-		return
-	}
-
-	if isFailureStatusCode(resp) {
-		err = c.handleErrorResp(resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
 		return
 	}
 
@@ -172,48 +344,81 @@ func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err er
 }
 
 func sendRequestStream[T streamable](client *Client, req *http.Request) (*streamReader[T], error) {
-	req.Header.Set("Content-Type", "application/json")
+	// Streams are requested with a JSON body, except for transcriptions, which
+	// send a multipart form.
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	resp, err := client.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	req, tracker := client.trackCall(req)
+	req, stopConnectTimeout, cancel := client.withStreamConnectTimeout(req)
+	resp, err := client.doRequest(req, true) //nolint:bodyclose // body is closed in stream.Close()
+	if stopConnectTimeout() {
+		err = ErrStreamConnectTimeout
+	}
+	tracker.response(resp)
 	if err != nil {
+		tracker.finish(nil, err)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
 		return new(streamReader[T]), err
 	}
-	if isFailureStatusCode(resp) {
-		return new(streamReader[T]), client.handleErrorResp(resp)
+	if timeout := client.config.StreamIdleTimeout; timeout > 0 {
+		resp.Body = newIdleTimeoutBody(resp.Body, timeout)
 	}
 	return &streamReader[T]{
+		tracker:            tracker,
+		cancel:             cancel,
 		emptyMessagesLimit: client.config.EmptyMessagesLimit,
+		maxEventSize:       client.config.StreamMaxEventSize,
 		reader:             bufio.NewReader(resp.Body),
 		response:           resp,
 		errAccumulator:     utils.NewErrorAccumulator(),
 		unmarshaler:        &utils.JSONUnmarshaler{},
+		keepRawJSON:        client.config.keepRawJSON,
+		rawEvents:          client.config.rawEvents,
+		eventsBuffer:       client.config.StreamEventsBuffer,
 		httpHeader:         httpHeader(resp.Header),
 	}, nil
 }
 
-func (c *Client) setCommonHeaders(req *http.Request) {
+func (c *Client) setCommonHeaders(req *http.Request) error {
+	authToken, err := c.authToken(req.Context())
+	if err != nil {
+		return err
+	}
+
 	// https://learn.microsoft.com/en-us/azure/cognitive-services/openai/reference#authentication
 	switch c.config.APIType {
 	case APITypeAzure, APITypeCloudflareAzure:
 		// Azure API Key authentication
-		req.Header.Set(AzureAPIKeyHeader, c.config.authToken)
+		req.Header.Set(AzureAPIKeyHeader, authToken)
 	case APITypeAnthropic:
 		// https://docs.anthropic.com/en/api/versioning
 		req.Header.Set("anthropic-version", c.config.APIVersion)
 	case APITypeOpenAI, APITypeAzureAD:
 		fallthrough
 	default:
-		if c.config.authToken != "" {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.authToken))
+		if authToken != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
 		}
 	}
 
-	if c.config.OrgID != "" {
-		req.Header.Set("OpenAI-Organization", c.config.OrgID)
+	// Azure rejects unknown headers next to its own authentication in some API versions.
+	if !c.config.isAzure() {
+		if c.config.OrgID != "" {
+			req.Header.Set("OpenAI-Organization", c.config.OrgID)
+		}
+		if c.config.Project != "" {
+			req.Header.Set("OpenAI-Project", c.config.Project)
+		}
 	}
+	return nil
 }
 
 func isFailureStatusCode(resp *http.Response) bool {
@@ -282,6 +487,9 @@ func (c *Client) fullURL(suffix string, setters ...fullURLOption) string {
 	if c.config.APIVersion != "" {
 		suffix = c.suffixWithAPIVersion(suffix)
 	}
+	if !strings.HasPrefix(suffix, "/") {
+		suffix = "/" + suffix
+	}
 	return fmt.Sprintf("%s%s", baseURL, suffix)
 }
 
@@ -320,6 +528,7 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 			HTTPStatusCode: resp.StatusCode,
 			Err:            err,
 			Body:           body,
+			httpHeader:     httpHeader(resp.Header),
 		}
 		if errRes.Error != nil {
 			reqErr.Err = errRes.Error
@@ -329,7 +538,8 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 
 	errRes.Error.HTTPStatus = resp.Status
 	errRes.Error.HTTPStatusCode = resp.StatusCode
-	return errRes.Error
+	errRes.Error.SetHeader(resp.Header)
+	return contentFilterError(errRes.Error)
 }
 
 func containsSubstr(s []string, e string) bool {
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
@@ -16,6 +17,24 @@ import (
 
 var errTestRequestBuilderFailed = errors.New("test request builder failed")
 
+// setupOpenAITestServerWithConfig starts a test server and returns a client of
+// it whose config is changed by configure, if not nil.
+func setupOpenAITestServerWithConfig(
+	configure func(*ClientConfig),
+) (client *Client, server *test.ServerTest, teardown func()) {
+	server = test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	teardown = ts.Close
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	if configure != nil {
+		configure(&config)
+	}
+	client = NewClientWithConfig(config)
+	return
+}
+
 type failingRequestBuilder struct{}
 
 func (*failingRequestBuilder) Build(_ context.Context, _, _ string, _ any, _ http.Header) (*http.Request, error) {
@@ -54,6 +73,42 @@ func TestSetCommonHeadersAnthropic(t *testing.T) {
 	}
 }
 
+func TestSetCommonHeadersOrganizationAndProject(t *testing.T) {
+	config := DefaultConfig("mock-token")
+	config.OrgID = "org-1"
+	config.Project = "proj-1"
+	client := NewClientWithConfig(config)
+
+	newRequest := func(c *Client) *http.Request {
+		req, err := c.newRequest(context.Background(), http.MethodGet, "http://example.com")
+		checks.NoError(t, err, "newRequest error")
+		return req
+	}
+
+	req := newRequest(client)
+	if req.Header.Get("OpenAI-Organization") != "org-1" || req.Header.Get("OpenAI-Project") != "proj-1" {
+		t.Errorf("unexpected headers %v", req.Header)
+	}
+
+	req = newRequest(client.WithOptions(WithOrganization("org-2"), WithProject("proj-2")))
+	if req.Header.Get("OpenAI-Organization") != "org-2" || req.Header.Get("OpenAI-Project") != "proj-2" {
+		t.Errorf("per-call overrides were not applied: %v", req.Header)
+	}
+
+	req = newRequest(client.WithOptions(WithProject("")))
+	if _, ok := req.Header["Openai-Project"]; ok {
+		t.Errorf("empty project should not be sent: %v", req.Header)
+	}
+
+	azureConfig := DefaultAzureConfig("mock-token", "https://test.openai.azure.com/")
+	azureConfig.OrgID = "org-1"
+	azureConfig.Project = "proj-1"
+	req = newRequest(NewClientWithConfig(azureConfig))
+	if req.Header.Get("OpenAI-Organization") != "" || req.Header.Get("OpenAI-Project") != "" {
+		t.Errorf("Azure requests must not carry organization or project headers: %v", req.Header)
+	}
+}
+
 func TestDecodeResponse(t *testing.T) {
 	stringInput := ""
 
@@ -317,6 +372,15 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"ListFineTuningJobEvents", func() (any, error) {
 			return client.ListFineTuningJobEvents(ctx, "")
 		}},
+		{"ListFineTuningJobEventsAll", func() (any, error) {
+			return client.ListFineTuningJobEventsAll(ctx, "")
+		}},
+		{"StreamFineTuningJobEvents", func() (any, error) {
+			return client.StreamFineTuningJobEvents(ctx, "")
+		}},
+		{"ListFineTuningJobCheckpoints", func() (any, error) {
+			return client.ListFineTuningJobCheckpoints(ctx, "", ListFineTuningJobCheckpointsParams{})
+		}},
 		{"Moderations", func() (any, error) {
 			return client.Moderations(ctx, ModerationRequest{})
 		}},
@@ -329,6 +393,9 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"CreateImage", func() (any, error) {
 			return client.CreateImage(ctx, ImageRequest{})
 		}},
+		{"CreateImageStream", func() (any, error) {
+			return client.CreateImageStream(ctx, ImageRequest{Model: CreateImageModelGptImage1})
+		}},
 		{"CreateFileBytes", func() (any, error) {
 			return client.CreateFileBytes(ctx, FileBytesRequest{})
 		}},
@@ -341,9 +408,27 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"GetFileContent", func() (any, error) {
 			return client.GetFileContent(ctx, "")
 		}},
+		{"DownloadFile", func() (any, error) {
+			return client.DownloadFile(ctx, "", io.Discard)
+		}},
+		{"CreateUpload", func() (any, error) {
+			return client.CreateUpload(ctx, UploadRequest{})
+		}},
+		{"AddUploadPart", func() (any, error) {
+			return client.AddUploadPart(ctx, "", bytes.NewReader([]byte("part")))
+		}},
+		{"CompleteUpload", func() (any, error) {
+			return client.CompleteUpload(ctx, "", CompleteUploadRequest{})
+		}},
+		{"CancelUpload", func() (any, error) {
+			return client.CancelUpload(ctx, "")
+		}},
 		{"ListFiles", func() (any, error) {
 			return client.ListFiles(ctx)
 		}},
+		{"ListFilesAll", func() (any, error) {
+			return client.ListFilesAll(ctx, ListFilesParams{})
+		}},
 		{"ListEngines", func() (any, error) {
 			return client.ListEngines(ctx)
 		}},
@@ -420,7 +505,7 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.DeleteThread(ctx, "")
 		}},
 		{"CreateRun", func() (any, error) {
-			return client.CreateRun(ctx, "", RunRequest{})
+			return client.CreateRun(ctx, "", RunRequest{AssistantID: "asst"})
 		}},
 		{"RetrieveRun", func() (any, error) {
 			return client.RetrieveRun(ctx, "", "")
@@ -438,7 +523,50 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.CancelRun(ctx, "", "")
 		}},
 		{"CreateThreadAndRun", func() (any, error) {
-			return client.CreateThreadAndRun(ctx, CreateThreadAndRunRequest{})
+			return client.CreateThreadAndRun(ctx, CreateThreadAndRunRequest{RunRequest: RunRequest{AssistantID: "asst"}})
+		}},
+		{"SearchVectorStore", func() (any, error) {
+			return client.SearchVectorStore(ctx, "", VectorStoreSearchRequest{})
+		}},
+		{"SearchVectorStoreAll", func() (any, error) {
+			return client.SearchVectorStoreAll(ctx, "", VectorStoreSearchRequest{})
+		}},
+		{"ListAssistantsWithPagination", func() (any, error) {
+			return client.ListAssistantsWithPagination(ctx, Pagination{})
+		}},
+		{"ListMessagesWithPagination", func() (any, error) {
+			return client.ListMessagesWithPagination(ctx, "", Pagination{}, "")
+		}},
+		{"CreateResponse", func() (any, error) {
+			return client.CreateResponse(ctx, ResponseRequest{Input: ""})
+		}},
+		{"CreateResponseStream", func() (any, error) {
+			return client.CreateResponseStream(ctx, ResponseRequest{Input: ""})
+		}},
+		{"CancelResponse", func() (any, error) { return client.CancelResponse(ctx, "") }},
+		{"WaitForResponse", func() (any, error) { return client.WaitForResponse(ctx, "", PollOptions{}) }},
+		{"ResumeResponseStream", func() (any, error) { return client.ResumeResponseStream(ctx, "", 0) }},
+		{"ConnectRealtime", func() (any, error) { return client.ConnectRealtime(ctx, RealtimeOptions{}) }},
+		{"CreateRealtimeSession", func() (any, error) {
+			return client.CreateRealtimeSession(ctx, RealtimeSessionRequest{})
+		}},
+		{"CreateRealtimeTranscriptionSession", func() (any, error) {
+			return client.CreateRealtimeTranscriptionSession(ctx, RealtimeTranscriptionSessionRequest{})
+		}},
+		{"GetResponse", func() (any, error) { return client.GetResponse(ctx, "") }},
+		{"DeleteResponse", func() (any, error) { return client.DeleteResponse(ctx, "") }},
+		{"ListResponseInputItems", func() (any, error) {
+			return client.ListResponseInputItems(ctx, "", Pagination{})
+		}},
+		{"WaitForRun", func() (any, error) { return client.WaitForRun(ctx, "", "", PollOptions{}) }},
+		{"CreateRunStream", func() (any, error) {
+			return client.CreateRunStream(ctx, "", RunRequest{AssistantID: "asst"})
+		}},
+		{"CreateThreadAndRunStream", func() (any, error) {
+			return client.CreateThreadAndRunStream(ctx, CreateThreadAndRunRequest{RunRequest: RunRequest{AssistantID: "asst"}})
+		}},
+		{"SubmitToolOutputsStream", func() (any, error) {
+			return client.SubmitToolOutputsStream(ctx, "", "", SubmitToolOutputsRequest{})
 		}},
 		{"RetrieveRunStep", func() (any, error) {
 			return client.RetrieveRunStep(ctx, "", "", "")
@@ -446,20 +574,53 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"ListRunSteps", func() (any, error) {
 			return client.ListRunSteps(ctx, "", "", Pagination{})
 		}},
+		{"RetrieveRunStepWithInclude", func() (any, error) {
+			return client.RetrieveRunStepWithInclude(ctx, "", "", "", RunStepIncludeFileSearchResultContent)
+		}},
+		{"ListRunStepsWithParams", func() (any, error) {
+			return client.ListRunStepsWithParams(ctx, "", "", ListRunStepsParams{})
+		}},
 		{"CreateSpeech", func() (any, error) {
 			return client.CreateSpeech(ctx, CreateSpeechRequest{Model: TTSModel1, Voice: VoiceAlloy})
 		}},
+		{"CreateTranscriptionStream", func() (any, error) {
+			return client.CreateTranscriptionStream(ctx, AudioRequest{
+				Reader:   bytes.NewBufferString("audio"),
+				FileName: "audio.mp3",
+			})
+		}},
 		{"CreateBatch", func() (any, error) {
 			return client.CreateBatch(ctx, CreateBatchRequest{})
 		}},
 		{"CreateBatchWithUploadFile", func() (any, error) {
 			return client.CreateBatchWithUploadFile(ctx, CreateBatchWithUploadFileRequest{})
 		}},
+		{"CreateChatCompletionBatch", func() (any, error) {
+			file, _, err := client.CreateChatCompletionBatch(ctx, []BatchChatCompletionItem{{CustomID: "a"}},
+				CreateBatchOptions{})
+			return file, err
+		}},
+		{"ForEachBatchResult", func() (any, error) {
+			return client.ForEachBatchResult(ctx, "", func(BatchResult) error { return nil })
+		}},
 		{"RetrieveBatch", func() (any, error) {
 			return client.RetrieveBatch(ctx, "")
 		}},
 		{"CancelBatch", func() (any, error) { return client.CancelBatch(ctx, "") }},
 		{"ListBatch", func() (any, error) { return client.ListBatch(ctx, nil, nil) }},
+		{"ListBatches", func() (any, error) { return client.ListBatches(ctx, ListBatchesParams{}) }},
+		{"WaitForBatch", func() (any, error) { return client.WaitForBatch(ctx, "", PollOptions{}, nil) }},
+		{"GetChatCompletion", func() (any, error) { return client.GetChatCompletion(ctx, "") }},
+		{"ListChatCompletions", func() (any, error) {
+			return client.ListChatCompletions(ctx, ChatCompletionsFilter{}, Pagination{})
+		}},
+		{"ListChatCompletionMessages", func() (any, error) {
+			return client.ListChatCompletionMessages(ctx, "", Pagination{})
+		}},
+		{"UpdateChatCompletionMetadata", func() (any, error) {
+			return client.UpdateChatCompletionMetadata(ctx, "", nil)
+		}},
+		{"DeleteChatCompletion", func() (any, error) { return client.DeleteChatCompletion(ctx, "") }},
 	}
 
 	for _, testCase := range testCases {
@@ -586,3 +747,91 @@ func TestClient_baseURLWithAzureDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestClientWithOptionsFullURL(t *testing.T) {
+	client := NewClient("")
+	proxied := client.WithOptions(WithBaseURL("https://proxy.example.com/v1/"))
+	if got := proxied.fullURL("/models"); got != "https://proxy.example.com/v1/models" {
+		t.Errorf("unexpected URL %s", got)
+	}
+	if got := proxied.fullURL("models"); got != "https://proxy.example.com/v1/models" {
+		t.Errorf("suffix without a leading slash produced %s", got)
+	}
+	if got := client.fullURL("/models"); got != "https://api.openai.com/v1/models" {
+		t.Errorf("WithOptions must not modify the original client, got %s", got)
+	}
+
+	azure := NewClientWithConfig(DefaultAzureConfig("", "https://test.openai.azure.com/"))
+	got := azure.WithOptions(WithBaseURL("https://gateway.example.com//")).
+		fullURL(chatCompletionsSuffix, withModel("gpt-4"))
+	want := "https://gateway.example.com/openai/deployments/gpt-4/chat/completions?api-version=2023-05-15"
+	if got != want {
+		t.Errorf("unexpected Azure URL %s, want %s", got, want)
+	}
+}
+
+type recordingHTTPDoer struct {
+	calls int
+	next  HTTPDoer
+}
+
+func (d *recordingHTTPDoer) Do(req *http.Request) (*http.Response, error) {
+	d.calls++
+	return d.next.Do(req)
+}
+
+func TestClientWithOptionsRequests(t *testing.T) {
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"hi"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = "http://unreachable.invalid/v1"
+	client := NewClientWithConfig(config)
+
+	doer := &recordingHTTPDoer{next: &http.Client{}}
+	stream, err := client.WithOptions(WithBaseURL(ts.URL+"/v1"), WithHTTPClient(doer)).
+		CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+			Model:    GPT4,
+			Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	resp, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if resp.Choices[0].Delta.Content != "hi" {
+		t.Fatalf("unexpected content %q", resp.Choices[0].Delta.Content)
+	}
+	if doer.calls != 1 {
+		t.Fatalf("expected the overriding HTTP client to be used once, got %d", doer.calls)
+	}
+	if client.config.HTTPClient == HTTPDoer(doer) {
+		t.Fatal("WithOptions must not modify the original client")
+	}
+}
+
+func TestHTTPHeaderAccessors(t *testing.T) {
+	var h httpHeader
+	h.SetHeader(http.Header{
+		"X-Request-Id":         {"req_abc"},
+		"Openai-Processing-Ms": {"250.5"},
+	})
+	if h.RequestID() != "req_abc" {
+		t.Errorf("unexpected request ID %q", h.RequestID())
+	}
+	if h.ProcessingTime() != 250500*time.Microsecond {
+		t.Errorf("unexpected processing time %v", h.ProcessingTime())
+	}
+
+	var empty httpHeader
+	if empty.RequestID() != "" || empty.ProcessingTime() != 0 {
+		t.Error("missing headers should read as zero values")
+	}
+}
@@ -1,7 +1,22 @@
 package openai
 
+import "encoding/json"
+
 // common.go defines common types used throughout the OpenAI API.
 
+// Float returns a pointer to v, to set optional parameters such as
+// ChatCompletionRequest.Temperature, which are sent whenever they are not nil,
+// even as zero.
+func Float(v float32) *float32 {
+	return &v
+}
+
+// Int returns a pointer to v, to set optional parameters such as
+// ChatCompletionRequest.Seed.
+func Int(v int) *int {
+	return &v
+}
+
 // Usage Represents the total token usage per request to OpenAI.
 type Usage struct {
 	PromptTokens            int                      `json:"prompt_tokens"`
@@ -11,6 +26,31 @@ type Usage struct {
 	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details"`
 }
 
+// Add adds the tokens of other to u, including their details, to total the
+// usage of several requests. The details of u are allocated if other has some.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	if other.PromptTokensDetails != nil {
+		if u.PromptTokensDetails == nil {
+			u.PromptTokensDetails = &PromptTokensDetails{}
+		}
+		u.PromptTokensDetails.AudioTokens += other.PromptTokensDetails.AudioTokens
+		u.PromptTokensDetails.CachedTokens += other.PromptTokensDetails.CachedTokens
+	}
+	if other.CompletionTokensDetails != nil {
+		if u.CompletionTokensDetails == nil {
+			u.CompletionTokensDetails = &CompletionTokensDetails{}
+		}
+		details := u.CompletionTokensDetails
+		details.AudioTokens += other.CompletionTokensDetails.AudioTokens
+		details.ReasoningTokens += other.CompletionTokensDetails.ReasoningTokens
+		details.AcceptedPredictionTokens += other.CompletionTokensDetails.AcceptedPredictionTokens
+		details.RejectedPredictionTokens += other.CompletionTokensDetails.RejectedPredictionTokens
+	}
+}
+
 // CompletionTokensDetails Breakdown of tokens used in a completion.
 type CompletionTokensDetails struct {
 	AudioTokens              int `json:"audio_tokens"`
@@ -24,3 +64,43 @@ type PromptTokensDetails struct {
 	AudioTokens  int `json:"audio_tokens"`
 	CachedTokens int `json:"cached_tokens"`
 }
+
+// StopSequences are the sequences where the API stops generating further tokens.
+// A single sequence is encoded as a bare string, which every OpenAI compatible
+// server accepts, and several as an array. Both forms are decoded.
+type StopSequences []string
+
+// StopString returns the stop sequences made of the single sequence s.
+func StopString(s string) StopSequences {
+	return StopSequences{s}
+}
+
+// StopSlice returns the stop sequences made of s.
+func StopSlice(s []string) StopSequences {
+	return StopSequences(s)
+}
+
+func (s StopSequences) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = nil
+		return nil
+	}
+	var sequence string
+	if err := json.Unmarshal(data, &sequence); err == nil {
+		*s = StopSequences{sequence}
+		return nil
+	}
+	var sequences []string
+	if err := json.Unmarshal(data, &sequences); err != nil {
+		return err
+	}
+	*s = sequences
+	return nil
+}
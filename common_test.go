@@ -0,0 +1,180 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestStopSequencesJSON(t *testing.T) {
+	cases := []struct {
+		stop openai.StopSequences
+		json string
+	}{
+		{openai.StopString("\n"), `"\n"`},
+		{openai.StopSlice([]string{"END", "STOP"}), `["END","STOP"]`},
+		{openai.StopSequences{}, `[]`},
+	}
+	for _, tc := range cases {
+		data, err := json.Marshal(tc.stop)
+		checks.NoError(t, err, "Marshal error")
+		if string(data) != tc.json {
+			t.Errorf("unexpected JSON %s, want %s", data, tc.json)
+		}
+		var stop openai.StopSequences
+		checks.NoError(t, json.Unmarshal(data, &stop), "Unmarshal error")
+		if !reflect.DeepEqual(stop, tc.stop) {
+			t.Errorf("unexpected stop sequences %q decoded from %s", stop, data)
+		}
+	}
+
+	var stop openai.StopSequences
+	checks.NoError(t, json.Unmarshal([]byte(`["END"]`), &stop), "Unmarshal error")
+	if !reflect.DeepEqual(stop, openai.StopString("END")) {
+		t.Errorf("unexpected stop sequences %q", stop)
+	}
+	checks.NoError(t, json.Unmarshal([]byte(`null`), &stop), "Unmarshal error")
+	if stop != nil {
+		t.Errorf("unexpected stop sequences %q decoded from null", stop)
+	}
+	checks.HasError(t, json.Unmarshal([]byte(`4`), &stop), "numbers should be rejected")
+
+	// Requests keep accepting plain string slices and leave out empty ones.
+	data, err := json.Marshal(openai.ChatCompletionRequest{Stop: []string{"END"}})
+	checks.NoError(t, err, "Marshal error")
+	var req map[string]any
+	checks.NoError(t, json.Unmarshal(data, &req), "Unmarshal error")
+	if req["stop"] != "END" {
+		t.Errorf("unexpected request JSON %s", data)
+	}
+	data, err = json.Marshal(openai.CompletionRequest{})
+	checks.NoError(t, err, "Marshal error")
+	var emptyReq map[string]any
+	checks.NoError(t, json.Unmarshal(data, &emptyReq), "Unmarshal error")
+	if _, ok := emptyReq["stop"]; ok {
+		t.Errorf("unexpected request JSON %s", data)
+	}
+}
+
+func FuzzStopSequencesRoundTrip(f *testing.F) {
+	f.Add("\n", "", false)
+	f.Add("END", "STOP", true)
+	f.Add("", "é\"", true)
+	f.Fuzz(func(t *testing.T, first, second string, two bool) {
+		stop := openai.StopString(first)
+		if two {
+			stop = openai.StopSlice([]string{first, second})
+		}
+		data, err := json.Marshal(stop)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var decoded openai.StopSequences
+		if err = json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		// Invalid UTF-8 is replaced when encoding, as for any string.
+		var want []string
+		if err = json.Unmarshal(mustMarshal(t, []string(stop)), &want); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if !reflect.DeepEqual([]string(decoded), want) {
+			t.Errorf("stop sequences %q decoded as %q from %s", stop, decoded, data)
+		}
+	})
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return data
+}
+
+func TestUsageAdd(t *testing.T) {
+	var total openai.Usage
+	total.Add(openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+	if total.PromptTokensDetails != nil || total.CompletionTokensDetails != nil {
+		t.Fatalf("details allocated without any to add: %+v", total)
+	}
+	total.Add(openai.Usage{
+		PromptTokens:            100,
+		CompletionTokens:        50,
+		TotalTokens:             150,
+		PromptTokensDetails:     &openai.PromptTokensDetails{CachedTokens: 64, AudioTokens: 2},
+		CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: 30, AcceptedPredictionTokens: 4},
+	})
+	total.Add(openai.Usage{
+		PromptTokens:            1,
+		CompletionTokens:        1,
+		TotalTokens:             2,
+		PromptTokensDetails:     &openai.PromptTokensDetails{CachedTokens: 1},
+		CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: 1, RejectedPredictionTokens: 3},
+	})
+
+	want := openai.Usage{
+		PromptTokens:        111,
+		CompletionTokens:    56,
+		TotalTokens:         167,
+		PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 65, AudioTokens: 2},
+		CompletionTokensDetails: &openai.CompletionTokensDetails{
+			ReasoningTokens:          31,
+			AcceptedPredictionTokens: 4,
+			RejectedPredictionTokens: 3,
+		},
+	}
+	if !reflect.DeepEqual(total, want) {
+		t.Errorf("unexpected total %+v, want %+v", total, want)
+	}
+}
+
+func TestOptionalSamplingParametersJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		request any
+		want    map[string]any
+	}{
+		{"chat unset", openai.ChatCompletionRequest{}, map[string]any{}},
+		{"chat zero", openai.ChatCompletionRequest{
+			Temperature:      openai.Float(0),
+			TopP:             openai.Float(0),
+			PresencePenalty:  openai.Float(0),
+			FrequencyPenalty: openai.Float(0),
+			Seed:             openai.Int(0),
+		}, map[string]any{"temperature": 0.0, "top_p": 0.0, "presence_penalty": 0.0, "frequency_penalty": 0.0, "seed": 0.0}},
+		{"completion unset", openai.CompletionRequest{}, map[string]any{}},
+		{"completion zero", openai.CompletionRequest{
+			Temperature:      openai.Float(0),
+			TopP:             openai.Float(0),
+			PresencePenalty:  openai.Float(0),
+			FrequencyPenalty: openai.Float(0),
+		}, map[string]any{"temperature": 0.0, "top_p": 0.0, "presence_penalty": 0.0, "frequency_penalty": 0.0}},
+		{"run unset", openai.RunRequest{}, map[string]any{}},
+		{"run zero", openai.RunRequest{Temperature: openai.Float(0), TopP: openai.Float(0)},
+			map[string]any{"temperature": 0.0, "top_p": 0.0}},
+		{"assistant unset", openai.AssistantRequest{}, map[string]any{}},
+		{"assistant zero", openai.AssistantRequest{Temperature: openai.Float(0), TopP: openai.Float(0.5)},
+			map[string]any{"temperature": 0.0, "top_p": 0.5}},
+	}
+	params := []string{"temperature", "top_p", "presence_penalty", "frequency_penalty", "seed"}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.request)
+			checks.NoError(t, err, "Marshal error")
+			var fields map[string]any
+			checks.NoError(t, json.Unmarshal(data, &fields), "Unmarshal error")
+			for _, param := range params {
+				got, ok := fields[param]
+				want, wantOK := tc.want[param]
+				if ok != wantOK || got != want {
+					t.Errorf("%s is %v in %s, want %v", param, got, data, want)
+				}
+			}
+		})
+	}
+}
@@ -189,12 +189,14 @@ func checkPromptType(prompt any) bool {
 }
 
 // CompletionRequest represents a request structure for completion API.
+// Its FrequencyPenalty, PresencePenalty, Temperature and TopP are sent when not
+// nil, including zero: set them with Float.
 type CompletionRequest struct {
-	Model            string  `json:"model"`
-	Prompt           any     `json:"prompt,omitempty"`
-	BestOf           int     `json:"best_of,omitempty"`
-	Echo             bool    `json:"echo,omitempty"`
-	FrequencyPenalty float32 `json:"frequency_penalty,omitempty"`
+	Model            string   `json:"model"`
+	Prompt           any      `json:"prompt,omitempty"`
+	BestOf           int      `json:"best_of,omitempty"`
+	Echo             bool     `json:"echo,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
 	// LogitBias is must be a token id string (specified by their token ID in the tokenizer), not a word string.
 	// incorrect: `"logit_bias":{"You": 6}`, correct: `"logit_bias":{"1639": 6}`
 	// refs: https://platform.openai.com/docs/api-reference/completions/create#completions/create-logit_bias
@@ -207,13 +209,13 @@ type CompletionRequest struct {
 	LogProbs        int               `json:"logprobs,omitempty"`
 	MaxTokens       int               `json:"max_tokens,omitempty"`
 	N               int               `json:"n,omitempty"`
-	PresencePenalty float32           `json:"presence_penalty,omitempty"`
+	PresencePenalty *float32          `json:"presence_penalty,omitempty"`
 	Seed            *int              `json:"seed,omitempty"`
-	Stop            []string          `json:"stop,omitempty"`
+	Stop            StopSequences     `json:"stop,omitempty"`
 	Stream          bool              `json:"stream,omitempty"`
 	Suffix          string            `json:"suffix,omitempty"`
-	Temperature     float32           `json:"temperature,omitempty"`
-	TopP            float32           `json:"top_p,omitempty"`
+	Temperature     *float32          `json:"temperature,omitempty"`
+	TopP            *float32          `json:"top_p,omitempty"`
 	User            string            `json:"user,omitempty"`
 	// Options for streaming response. Only set this when you set stream: true.
 	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
@@ -243,8 +245,12 @@ type CompletionResponse struct {
 	Model   string             `json:"model"`
 	Choices []CompletionChoice `json:"choices"`
 	Usage   *Usage             `json:"usage,omitempty"`
+	// SystemFingerprint identifies the backend configuration the model ran with.
+	// Together with Seed, it tells whether results can be expected to reproduce.
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
 
 	httpHeader
+	rawJSON
 }
 
 // CreateCompletion — API call to create a completion. This is the main endpoint of the API. Returns new text as well
@@ -3,6 +3,7 @@ package openai
 import (
 	"net/http"
 	"regexp"
+	"time"
 )
 
 const (
@@ -27,6 +28,7 @@ const (
 
 const AzureAPIKeyHeader = "api-key"
 
+// defaultAssistantVersion is the default ClientConfig.AssistantVersion.
 const defaultAssistantVersion = "v2" // upgrade to v2 to support vector store
 
 type HTTPDoer interface {
@@ -35,17 +37,109 @@ type HTTPDoer interface {
 
 // ClientConfig is a configuration of a client.
 type ClientConfig struct {
-	authToken string
-
-	BaseURL              string
-	OrgID                string
-	APIType              APIType
-	APIVersion           string // required when APIType is APITypeAzure or APITypeAzureAD or APITypeAnthropic
+	authToken      string
+	idempotencyKey string // set per call with WithIdempotencyKey
+	keepRawJSON    bool   // set per call with WithRawJSON
+	rawEvents      bool   // set per call with WithRawStreamEvents
+
+	BaseURL    string
+	OrgID      string
+	Project    string // sent as OpenAI-Project, not used with Azure
+	APIType    APIType
+	APIVersion string // required when APIType is APITypeAzure or APITypeAzureAD or APITypeAnthropic
+	// AssistantVersion is sent in the OpenAI-Beta header of the assistants,
+	// threads, runs and vector stores requests, as "assistants=" followed by
+	// it. Setting it to "" omits the header, for servers that reject it.
 	AssistantVersion     string
 	AzureModelMapperFunc func(model string) string // replace model to azure deployment name func
 	HTTPClient           HTTPDoer
+	// TokenProvider, if set, is called for every request to obtain the API key and
+	// takes precedence over the token the config was created with.
+	TokenProvider TokenProvider
 
 	EmptyMessagesLimit uint
+
+	// StreamConnectTimeout bounds the time until the response headers of a
+	// streaming request are received, retries included. The stream itself has no
+	// overall deadline. Zero, the default, disables it.
+	StreamConnectTimeout time.Duration
+	// StreamIdleTimeout aborts a stream with ErrStreamIdleTimeout when Recv waits
+	// that long for the next event, detecting connections that stalled without
+	// being closed. Set it well above the longest pause expected while
+	// the model generates. Zero, the default, disables it.
+	StreamIdleTimeout time.Duration
+	// StreamMaxEventSize is the size above which a line or event of a stream is
+	// rejected with ErrStreamEventTooLarge, bounding the memory used to buffer
+	// them. Zero, the default, uses DefaultStreamMaxEventSize.
+	StreamMaxEventSize int
+	// StreamEventsBuffer is the capacity of the channels returned by the Events
+	// method of streams. Zero, the default, makes them unbuffered, so that
+	// streams are read no faster than their events are consumed.
+	StreamEventsBuffer int
+
+	// MaxAudioUploadSize is the size above which audio files are rejected with
+	// ErrAudioFileTooLarge before being uploaded for transcription or translation.
+	// Zero, the default, uses DefaultMaxAudioUploadSize. Readers whose size cannot
+	// be determined are not checked.
+	MaxAudioUploadSize int64
+	// SkipAudioValidation disables the size and format checks of audio uploads,
+	// for OpenAI-compatible servers with different limits.
+	SkipAudioValidation bool
+
+	// ReasoningDeveloperMessages rewrites the system messages of chat completion
+	// requests to o-series models as developer messages, which these models take
+	// instead. Otherwise such requests fail with ErrReasoningModelSystemMessage.
+	ReasoningDeveloperMessages bool
+
+	// MaxRetries is how many times a failed request is retried. Zero, the default,
+	// disables retries.
+	MaxRetries int
+	// RetryBackoff returns the delay before the given retry attempt, starting at 1.
+	// Retry-After and x-ratelimit-reset-* response headers take precedence over it.
+	// Defaults to DefaultRetryBackoff.
+	RetryBackoff func(attempt int) time.Duration
+	// RetryMaxDelay is the longest delay before a retry that the response headers
+	// may ask for. A request asked to wait longer is not retried. Defaults to 30s.
+	RetryMaxDelay time.Duration
+	// ShouldRetry reports whether a request that ended with resp or err should be
+	// retried. Defaults to DefaultShouldRetry.
+	ShouldRetry func(resp *http.Response, err error) bool
+
+	// Middlewares wrap every request sent by the client, the first being the
+	// outermost. They run once per call, around all of its retries.
+	Middlewares []Middleware
+
+	// Debug logs every request and response to Logger, with credentials redacted.
+	Debug bool
+	// Logger receives debug output. Defaults to the standard logger.
+	Logger Logger
+	// DebugRedactContent hides prompts and generated content in debug output.
+	DebugRedactContent bool
+
+	// MetricsHook, if set, is notified of the start and end of every API call.
+	MetricsHook MetricsHook
+	// PriceTable prices the calls reported to a MetricsHook implementing
+	// CostMetricsHook. Defaults to DefaultPriceTable.
+	PriceTable PriceTable
+
+	// Tracer, if set, traces every API call in a span. See Tracer for using it
+	// with OpenTelemetry.
+	Tracer Tracer
+
+	// RateLimiter, if set, is waited on before every request. See NewRateLimiter.
+	RateLimiter RateLimiter
+	// PromptTokenEstimator estimates the prompt tokens of a request for the
+	// RateLimiter. Defaults to DefaultTokenEstimator.
+	PromptTokenEstimator TokenEstimator
+
+	// ModelListCacheTTL is how long SupportsModel reuses the model list it
+	// fetched. Defaults to 5 minutes.
+	ModelListCacheTTL time.Duration
+
+	// Tokenizer counts tokens client-side, such as those of conversations to
+	// fit in context windows. Defaults to an EstimateTokenizer. See the
+	// tokenizer package for counting them exactly with tiktoken.
+	Tokenizer Tokenizer
 }
 
 func DefaultConfig(authToken string) ClientConfig {
@@ -64,11 +158,12 @@ func DefaultConfig(authToken string) ClientConfig {
 
 func DefaultAzureConfig(apiKey, baseURL string) ClientConfig {
 	return ClientConfig{
-		authToken:  apiKey,
-		BaseURL:    baseURL,
-		OrgID:      "",
-		APIType:    APITypeAzure,
-		APIVersion: "2023-05-15",
+		authToken:        apiKey,
+		BaseURL:          baseURL,
+		OrgID:            "",
+		APIType:          APITypeAzure,
+		APIVersion:       "2023-05-15",
+		AssistantVersion: defaultAssistantVersion,
 		AzureModelMapperFunc: func(model string) string {
 			return regexp.MustCompile(`[.:]`).ReplaceAllString(model, "")
 		},
@@ -84,11 +179,12 @@ func DefaultAnthropicConfig(apiKey, baseURL string) ClientConfig {
 		baseURL = "https://api.anthropic.com/v1"
 	}
 	return ClientConfig{
-		authToken:  apiKey,
-		BaseURL:    baseURL,
-		OrgID:      "",
-		APIType:    APITypeAnthropic,
-		APIVersion: AnthropicAPIVersion,
+		authToken:        apiKey,
+		BaseURL:          baseURL,
+		OrgID:            "",
+		APIType:          APITypeAnthropic,
+		APIVersion:       AnthropicAPIVersion,
+		AssistantVersion: defaultAssistantVersion,
 
 		HTTPClient: &http.Client{},
 
@@ -100,6 +196,10 @@ func (ClientConfig) String() string {
 	return "<OpenAI API ClientConfig>"
 }
 
+func (c ClientConfig) isAzure() bool {
+	return c.APIType == APITypeAzure || c.APIType == APITypeAzureAD || c.APIType == APITypeCloudflareAzure
+}
+
 func (c ClientConfig) GetAzureDeploymentByModel(model string) string {
 	if c.AzureModelMapperFunc != nil {
 		return c.AzureModelMapperFunc(model)
@@ -0,0 +1,262 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrConversationSummarizerMissing is returned when a conversation truncated
+// with TruncateSummarize has no Summarize function.
+var ErrConversationSummarizerMissing = errors.New("conversation has no summarizer")
+
+// ConversationTruncation is how a Conversation shortens its history when it
+// does not fit its token budget. The history is shortened by whole exchanges:
+// a user message with the replies that follow it, or a step of a tool loop, so
+// that tool calls are never separated from their results.
+type ConversationTruncation string
+
+const (
+	// TruncateDropOldest drops the oldest exchanges. It is the default.
+	TruncateDropOldest ConversationTruncation = "drop_oldest"
+	// TruncateKeepSystem drops the oldest exchanges but keeps their system
+	// and developer messages.
+	TruncateKeepSystem ConversationTruncation = "keep_system"
+	// TruncateSummarize replaces the oldest exchanges with the message
+	// Conversation.Summarize returns for them, and drops the oldest exchanges
+	// if the history still does not fit.
+	TruncateSummarize ConversationTruncation = "summarize"
+)
+
+// ConversationSummarizer returns a message summing up msgs, such as a system
+// message with a summary written by a model.
+type ConversationSummarizer func(ctx context.Context, msgs []ChatCompletionMessage) (ChatCompletionMessage, error)
+
+// Conversation holds the instructions and the message history of a chat, to
+// build the requests of its turns:
+//
+//	conv := openai.NewConversation(openai.GPT4o, "You are a helpful assistant.")
+//	conv.TokenBudget = 100000
+//	conv.AppendUser("Hello!")
+//	request, err := conv.Request(ctx)
+//	...
+//	response, err := client.CreateChatCompletion(ctx, request)
+//	...
+//	conv.AppendAssistant(response.Choices[0].Message)
+//
+// When the prompt exceeds TokenBudget, Request shortens the history with the
+// Truncation strategy first. Conversations are persisted as JSON, without
+// their Tokenizer and Summarize function. A Conversation is not safe for
+// concurrent use.
+type Conversation struct {
+	Model string `json:"model"`
+	// Instructions are the system or developer messages sent before the
+	// history. They are never truncated.
+	Instructions []ChatCompletionMessage `json:"instructions,omitempty"`
+	Messages     []ChatCompletionMessage `json:"messages,omitempty"`
+	Tools        []Tool                  `json:"tools,omitempty"`
+
+	// TokenBudget is the number of prompt tokens the instructions, history
+	// and tools may use, as counted by Tokenizer: the context window of the
+	// model minus the tokens kept for the completion. Zero disables truncation.
+	TokenBudget int                    `json:"token_budget,omitempty"`
+	Truncation  ConversationTruncation `json:"truncation,omitempty"`
+
+	// Tokenizer counts the tokens of the conversation. Nil uses an
+	// EstimateTokenizer; set it to the Tokenizer of the client to count as
+	// configured there.
+	Tokenizer Tokenizer `json:"-"`
+	// Summarize is called by the TruncateSummarize strategy.
+	Summarize ConversationSummarizer `json:"-"`
+}
+
+// NewConversation returns a conversation with model, with instructions as its
+// system message unless empty.
+func NewConversation(model, instructions string) *Conversation {
+	conv := &Conversation{Model: model}
+	if instructions != "" {
+		conv.Instructions = []ChatCompletionMessage{{Role: ChatMessageRoleSystem, Content: instructions}}
+	}
+	return conv
+}
+
+// Append appends msgs to the history.
+func (c *Conversation) Append(msgs ...ChatCompletionMessage) {
+	c.Messages = append(c.Messages, msgs...)
+}
+
+// AppendUser appends a user message with content to the history.
+func (c *Conversation) AppendUser(content string) {
+	c.Append(ChatCompletionMessage{Role: ChatMessageRoleUser, Content: content})
+}
+
+// AppendAssistant appends a message of the model, such as the message of a
+// choice of a response, to the history.
+func (c *Conversation) AppendAssistant(msg ChatCompletionMessage) {
+	if msg.Role == "" {
+		msg.Role = ChatMessageRoleAssistant
+	}
+	c.Append(msg)
+}
+
+// AppendToolResult appends the result of the tool call with toolCallID to the
+// history.
+func (c *Conversation) AppendToolResult(toolCallID, content string) {
+	c.Append(ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: toolCallID, Content: content})
+}
+
+// Tokens returns the number of prompt tokens of the conversation.
+func (c *Conversation) Tokens() (int, error) {
+	counts, err := c.count()
+	if err != nil {
+		return 0, err
+	}
+	return counts.total(0), nil
+}
+
+// Request truncates the history to fit the token budget, and returns the
+// request of the next turn of the conversation. The truncation is kept in the
+// history, so that summaries are made once. It returns an error wrapping
+// ErrContextLengthExceeded when even the last exchange does not fit.
+func (c *Conversation) Request(ctx context.Context) (ChatCompletionRequest, error) {
+	if err := c.truncate(ctx); err != nil {
+		return ChatCompletionRequest{}, err
+	}
+	msgs := make([]ChatCompletionMessage, 0, len(c.Instructions)+len(c.Messages))
+	msgs = append(append(msgs, c.Instructions...), c.Messages...)
+	return ChatCompletionRequest{Model: c.Model, Messages: msgs, Tools: c.Tools}, nil
+}
+
+// conversationCounts are the tokens of the parts of a conversation. Messages
+// are counted one by one, the tokens of a list of messages being those priming
+// the reply plus those of each message.
+type conversationCounts struct {
+	// fixed are the tokens of the instructions and tools, and those priming
+	// the reply.
+	fixed    int
+	messages []int
+}
+
+// total returns the tokens of the conversation without its first dropped
+// messages.
+func (c conversationCounts) total(dropped int) int {
+	total := c.fixed
+	for _, n := range c.messages[dropped:] {
+		total += n
+	}
+	return total
+}
+
+func (c *Conversation) count() (conversationCounts, error) {
+	tokenizer := c.Tokenizer
+	if tokenizer == nil {
+		tokenizer = EstimateTokenizer{}
+	}
+	reply, err := tokenizer.CountMessageTokens(c.Model, nil)
+	if err != nil {
+		return conversationCounts{}, err
+	}
+	fixed, err := CountRequestTokens(tokenizer, ChatCompletionRequest{
+		Model:    c.Model,
+		Messages: c.Instructions,
+		Tools:    c.Tools,
+	})
+	if err != nil {
+		return conversationCounts{}, err
+	}
+	counts := conversationCounts{fixed: fixed, messages: make([]int, len(c.Messages))}
+	for i := range c.Messages {
+		n, err := tokenizer.CountMessageTokens(c.Model, c.Messages[i:i+1])
+		if err != nil {
+			return conversationCounts{}, err
+		}
+		counts.messages[i] = n - reply
+	}
+	return counts, nil
+}
+
+// exchangeStarts returns the indexes of the messages starting the exchanges of
+// msgs: user messages, and assistant messages following tool results.
+func exchangeStarts(msgs []ChatCompletionMessage) []int {
+	var starts []int
+	for i, msg := range msgs {
+		if i == 0 || msg.Role == ChatMessageRoleUser ||
+			(msg.Role == ChatMessageRoleAssistant && msgs[i-1].Role == ChatMessageRoleTool) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func (c *Conversation) truncate(ctx context.Context) error {
+	switch c.Truncation {
+	case "", TruncateDropOldest, TruncateKeepSystem:
+	case TruncateSummarize:
+		if c.Summarize == nil {
+			return ErrConversationSummarizerMissing
+		}
+	default:
+		return fmt.Errorf("unknown truncation strategy %q", c.Truncation)
+	}
+	if c.TokenBudget <= 0 {
+		return nil
+	}
+	counts, err := c.count()
+	if err != nil {
+		return err
+	}
+	if counts.total(0) <= c.TokenBudget {
+		return nil
+	}
+
+	// drop is the start of the first exchange kept.
+	drop := -1
+	for _, start := range exchangeStarts(c.Messages)[1:] {
+		total := counts.total(start)
+		if c.Truncation == TruncateKeepSystem {
+			for i, msg := range c.Messages[:start] {
+				if isSystemMessage(msg) {
+					total += counts.messages[i]
+				}
+			}
+		}
+		if total <= c.TokenBudget {
+			drop = start
+			break
+		}
+	}
+	if drop < 0 {
+		return fmt.Errorf("%w: the conversation does not fit in %d tokens without its last exchange",
+			ErrContextLengthExceeded, c.TokenBudget)
+	}
+
+	switch c.Truncation {
+	case TruncateKeepSystem:
+		var msgs []ChatCompletionMessage
+		for _, msg := range c.Messages[:drop] {
+			if isSystemMessage(msg) {
+				msgs = append(msgs, msg)
+			}
+		}
+		c.Messages = append(msgs, c.Messages[drop:]...)
+	case TruncateSummarize:
+		summary, err := c.Summarize(ctx, c.Messages[:drop])
+		if err != nil {
+			return fmt.Errorf("summarizing the conversation: %w", err)
+		}
+		c.Messages = append([]ChatCompletionMessage{summary}, c.Messages[drop:]...)
+		// The summary may not fit with the rest.
+		truncated := *c
+		truncated.Truncation = TruncateDropOldest
+		err = truncated.truncate(ctx)
+		c.Messages = truncated.Messages
+		return err
+	default:
+		c.Messages = c.Messages[drop:]
+	}
+	return nil
+}
+
+func isSystemMessage(msg ChatCompletionMessage) bool {
+	return msg.Role == ChatMessageRoleSystem || msg.Role == ChatMessageRoleDeveloper
+}
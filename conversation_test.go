@@ -0,0 +1,169 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// wordTokenizer counts a token per word.
+var wordTokenizer = openai.EstimateTokenizer{Estimator: func(text string) int {
+	return len(strings.Fields(text))
+}}
+
+// newTestConversation returns a conversation of 4 exchanges, the second one
+// calling a tool.
+func newTestConversation() *openai.Conversation {
+	conv := openai.NewConversation(openai.GPT4o, "Be brief.")
+	conv.Tokenizer = wordTokenizer
+	conv.AppendUser("What is the weather in Paris today?")
+	conv.AppendAssistant(openai.ChatCompletionMessage{Content: "Let me check."})
+	conv.AppendUser("And in Rome?")
+	conv.AppendAssistant(openai.ChatCompletionMessage{ToolCalls: []openai.ToolCall{{
+		ID:       "call_1",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`},
+	}}})
+	conv.AppendToolResult("call_1", "sunny")
+	conv.AppendAssistant(openai.ChatCompletionMessage{Content: "It is sunny in Rome."})
+	conv.AppendUser("Thanks!")
+	return conv
+}
+
+func TestConversationRequest(t *testing.T) {
+	conv := newTestConversation()
+	conv.Tools = []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{
+		Name: "get_weather",
+	}}}
+
+	request, err := conv.Request(context.Background())
+	checks.NoError(t, err, "Request error")
+	if request.Model != openai.GPT4o || len(request.Messages) != 8 || len(request.Tools) != 1 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if request.Messages[0].Role != openai.ChatMessageRoleSystem ||
+		request.Messages[4].Role != openai.ChatMessageRoleAssistant {
+		t.Errorf("unexpected messages %+v", request.Messages)
+	}
+
+	tokens, err := conv.Tokens()
+	checks.NoError(t, err, "Tokens error")
+	want, err := openai.CountRequestTokens(wordTokenizer, request)
+	checks.NoError(t, err, "CountRequestTokens error")
+	if tokens != want {
+		t.Errorf("conversation has %d tokens, its request %d", tokens, want)
+	}
+}
+
+func TestConversationTruncation(t *testing.T) {
+	full, err := newTestConversation().Tokens()
+	checks.NoError(t, err, "Tokens error")
+
+	// Whatever the budget, tool calls stay with their results.
+	for budget := full; budget > 0; budget-- {
+		conv := newTestConversation()
+		conv.TokenBudget = budget
+		request, err := conv.Request(context.Background())
+		if openai.IsContextLengthExceeded(err) {
+			if len(conv.Messages) != 7 {
+				t.Errorf("budget %d: history truncated on error", budget)
+			}
+			continue
+		}
+		checks.NoError(t, err, "Request error")
+		tokens, _ := conv.Tokens()
+		if tokens > budget {
+			t.Errorf("budget %d: %d tokens after truncation", budget, tokens)
+		}
+		if request.Messages[0].Content != "Be brief." {
+			t.Errorf("budget %d: instructions dropped", budget)
+		}
+		if first := conv.Messages[0]; first.Role == openai.ChatMessageRoleTool {
+			t.Errorf("budget %d: tool result separated from its call", budget)
+		}
+	}
+
+	conv := newTestConversation()
+	conv.TokenBudget = full - 1
+	_, err = conv.Request(context.Background())
+	checks.NoError(t, err, "Request error")
+	if len(conv.Messages) != 5 || conv.Messages[0].Content != "And in Rome?" {
+		t.Errorf("expected the first exchange dropped, got %+v", conv.Messages)
+	}
+
+	conv = newTestConversation()
+	conv.TokenBudget = 1
+	_, err = conv.Request(context.Background())
+	if !openai.IsContextLengthExceeded(err) {
+		t.Errorf("expected a context length error, got %v", err)
+	}
+}
+
+func TestConversationTruncateKeepSystem(t *testing.T) {
+	conv := newTestConversation()
+	conv.Messages = append([]openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleDeveloper, Content: "Answer in English."},
+	}, conv.Messages...)
+	full, err := conv.Tokens()
+	checks.NoError(t, err, "Tokens error")
+	conv.TokenBudget = full - 1
+	conv.Truncation = openai.TruncateKeepSystem
+
+	_, err = conv.Request(context.Background())
+	checks.NoError(t, err, "Request error")
+	if len(conv.Messages) != 6 || conv.Messages[0].Content != "Answer in English." ||
+		conv.Messages[1].Content != "And in Rome?" {
+		t.Errorf("expected the first exchange dropped but its developer message, got %+v", conv.Messages)
+	}
+}
+
+func TestConversationTruncateSummarize(t *testing.T) {
+	conv := newTestConversation()
+	full, err := conv.Tokens()
+	checks.NoError(t, err, "Tokens error")
+	conv.TokenBudget = full - 1
+	conv.Truncation = openai.TruncateSummarize
+	_, err = conv.Request(context.Background())
+	checks.ErrorIs(t, err, openai.ErrConversationSummarizerMissing)
+
+	var summarized [][]openai.ChatCompletionMessage
+	conv.Summarize = func(_ context.Context, msgs []openai.ChatCompletionMessage) (openai.ChatCompletionMessage, error) {
+		summarized = append(summarized, msgs)
+		return openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem, Content: "Paris."}, nil
+	}
+	_, err = conv.Request(context.Background())
+	checks.NoError(t, err, "Request error")
+	if len(summarized) != 1 || len(summarized[0]) != 2 {
+		t.Fatalf("expected the first exchange summarized, got %+v", summarized)
+	}
+	if len(conv.Messages) != 6 || conv.Messages[0].Content != "Paris." {
+		t.Errorf("expected the summary to replace the first exchange, got %+v", conv.Messages)
+	}
+
+	// The summary is kept in the history.
+	_, err = conv.Request(context.Background())
+	checks.NoError(t, err, "Request error")
+	if len(summarized) != 1 {
+		t.Errorf("summarized %d times, want once", len(summarized))
+	}
+}
+
+func TestConversationJSON(t *testing.T) {
+	conv := newTestConversation()
+	conv.TokenBudget = 1000
+	conv.Truncation = openai.TruncateKeepSystem
+	data, err := json.Marshal(conv)
+	checks.NoError(t, err, "Marshal error")
+
+	var restored openai.Conversation
+	checks.NoError(t, json.Unmarshal(data, &restored), "Unmarshal error")
+	conv.Tokenizer = nil
+	if !reflect.DeepEqual(&restored, conv) {
+		t.Errorf("restored %+v, want %+v", restored, *conv)
+	}
+}
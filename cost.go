@@ -0,0 +1,134 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrUnknownModelPricing is returned when estimating the cost of a model that
+// is not in the price table.
+var ErrUnknownModelPricing = errors.New("unknown model pricing")
+
+// ModelPrice is the price of a model, in USD per million tokens.
+type ModelPrice struct {
+	Input float64
+	// CachedInput is the price of the input tokens read from the prompt cache.
+	// Zero means cached tokens cost as much as the others.
+	CachedInput float64
+	Output      float64
+}
+
+// PriceTable maps models to their prices. Snapshots of a model, such as
+// gpt-4o-2024-08-06, are priced as the model unless they are in the table.
+type PriceTable map[string]ModelPrice
+
+// snapshotSuffix matches the suffixes of dated model snapshots.
+var snapshotSuffix = regexp.MustCompile(`^-\d{4}(-\d{2}-\d{2})?$`)
+
+// DefaultPriceTable returns the standard prices of the text and embedding
+// models as of its release, for modification. Prices change over time: set the
+// current ones of the models used with ClientConfig.PriceTable.
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		"gpt-5":                 {Input: 1.25, CachedInput: 0.125, Output: 10},
+		"gpt-5-mini":            {Input: 0.25, CachedInput: 0.025, Output: 2},
+		"gpt-5-nano":            {Input: 0.05, CachedInput: 0.005, Output: 0.4},
+		GPT4Dot1:                {Input: 2, CachedInput: 0.5, Output: 8},
+		GPT4Dot1Mini:            {Input: 0.4, CachedInput: 0.1, Output: 1.6},
+		GPT4Dot1Nano:            {Input: 0.1, CachedInput: 0.025, Output: 0.4},
+		GPT4o:                   {Input: 2.5, CachedInput: 1.25, Output: 10},
+		GPT4o20240513:           {Input: 5, Output: 15},
+		GPT4oMini:               {Input: 0.15, CachedInput: 0.075, Output: 0.6},
+		GPT4Dot5Preview:         {Input: 75, CachedInput: 37.5, Output: 150},
+		O1:                      {Input: 15, CachedInput: 7.5, Output: 60},
+		O1Mini:                  {Input: 1.1, CachedInput: 0.55, Output: 4.4},
+		O3:                      {Input: 2, CachedInput: 0.5, Output: 8},
+		O3Mini:                  {Input: 1.1, CachedInput: 0.55, Output: 4.4},
+		O4Mini:                  {Input: 1.1, CachedInput: 0.275, Output: 4.4},
+		GPT4Turbo:               {Input: 10, Output: 30},
+		GPT4Turbo0125:           {Input: 10, Output: 30},
+		GPT4Turbo1106:           {Input: 10, Output: 30},
+		GPT4TurboPreview:        {Input: 10, Output: 30},
+		GPT4:                    {Input: 30, Output: 60},
+		GPT432K:                 {Input: 60, Output: 120},
+		GPT3Dot5Turbo:           {Input: 0.5, Output: 1.5},
+		GPT3Dot5Turbo16K:        {Input: 3, Output: 4},
+		string(LargeEmbedding3): {Input: 0.13},
+		string(SmallEmbedding3): {Input: 0.02},
+		string(AdaEmbeddingV2):  {Input: 0.1},
+	}
+}
+
+// Price returns the price of model, or of the model it is a snapshot of, and
+// whether it is known.
+func (t PriceTable) Price(model string) (ModelPrice, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	var (
+		price ModelPrice
+		found string
+	)
+	for name, p := range t {
+		if len(name) > len(found) && len(name) < len(model) && model[:len(name)] == name &&
+			snapshotSuffix.MatchString(model[len(name):]) {
+			price, found = p, name
+		}
+	}
+	return price, found != ""
+}
+
+// Cost is the cost of a request, in USD.
+type Cost struct {
+	Input  float64
+	Output float64
+	Total  float64
+}
+
+// EstimateCost returns the cost of a request to model that used usage, with the
+// cached prompt tokens at their discounted price. It returns an error wrapping
+// ErrUnknownModelPricing for models not in the table.
+func (t PriceTable) EstimateCost(model string, usage Usage) (Cost, error) {
+	price, ok := t.Price(model)
+	if !ok {
+		return Cost{}, fmt.Errorf("%w: %s", ErrUnknownModelPricing, model)
+	}
+	cached := 0
+	if usage.PromptTokensDetails != nil && price.CachedInput > 0 {
+		cached = usage.PromptTokensDetails.CachedTokens
+	}
+	const perToken = 1e-6
+	cost := Cost{
+		Input:  (float64(usage.PromptTokens-cached)*price.Input + float64(cached)*price.CachedInput) * perToken,
+		Output: float64(usage.CompletionTokens) * price.Output * perToken,
+	}
+	cost.Total = cost.Input + cost.Output
+	return cost, nil
+}
+
+// EstimateCost returns the cost of a request to model that used usage, with
+// the prices of DefaultPriceTable.
+func EstimateCost(model string, usage Usage) (Cost, error) {
+	return DefaultPriceTable().EstimateCost(model, usage)
+}
+
+// CostMetricsHook is a MetricsHook also told the estimated cost of calls. When
+// the configured MetricsHook implements it, OnRequestEndWithCost is called
+// instead of OnRequestEnd.
+type CostMetricsHook interface {
+	MetricsHook
+	// OnRequestEndWithCost is OnRequestEnd with the cost of the call, estimated
+	// with ClientConfig.PriceTable from its usage. cost is nil when the usage is
+	// unknown, and costErr is set when the model has no price.
+	OnRequestEndWithCost(
+		endpoint, model string,
+		status int,
+		duration time.Duration,
+		usage *Usage,
+		cost *Cost,
+		costErr error,
+		err error,
+	)
+}
@@ -0,0 +1,64 @@
+package openai_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func assertCost(t *testing.T, cost openai.Cost, input, output float64) {
+	t.Helper()
+	if math.Abs(cost.Input-input) > 1e-12 || math.Abs(cost.Output-output) > 1e-12 ||
+		math.Abs(cost.Total-input-output) > 1e-12 {
+		t.Errorf("unexpected cost %+v, want input %g and output %g", cost, input, output)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	usage := openai.Usage{
+		PromptTokens:        1_000_000,
+		CompletionTokens:    100_000,
+		TotalTokens:         1_100_000,
+		PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 400_000},
+	}
+
+	// 600k input tokens at $2.50 and 400k cached at $1.25, 100k output at $10.
+	cost, err := openai.EstimateCost(openai.GPT4o, usage)
+	checks.NoError(t, err, "EstimateCost error")
+	assertCost(t, cost, 1.5+0.5, 1)
+
+	// Snapshots are priced as their model.
+	cost, err = openai.EstimateCost(openai.GPT4o20240806, usage)
+	checks.NoError(t, err, "EstimateCost error")
+	assertCost(t, cost, 2, 1)
+
+	// Models without cached price bill cached tokens in full.
+	cost, err = openai.EstimateCost(openai.GPT4, usage)
+	checks.NoError(t, err, "EstimateCost error")
+	assertCost(t, cost, 30, 6)
+
+	_, err = openai.EstimateCost("gpt-4o-realtime", usage)
+	checks.ErrorIs(t, err, openai.ErrUnknownModelPricing)
+	_, err = openai.EstimateCost("o3-pro", usage)
+	checks.ErrorIs(t, err, openai.ErrUnknownModelPricing, "o3-pro is not a snapshot of o3")
+}
+
+func TestPriceTableOverrides(t *testing.T) {
+	prices := openai.DefaultPriceTable()
+	prices[openai.GPT4o] = openai.ModelPrice{Input: 1, Output: 2}
+	prices["my-fine-tune"] = openai.ModelPrice{Input: 3, Output: 6}
+
+	usage := openai.Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+	cost, err := prices.EstimateCost(openai.GPT4o, usage)
+	checks.NoError(t, err, "EstimateCost error")
+	assertCost(t, cost, 1, 2)
+	cost, err = prices.EstimateCost("my-fine-tune", usage)
+	checks.NoError(t, err, "EstimateCost error")
+	assertCost(t, cost, 3, 6)
+
+	if cost, _ = openai.EstimateCost(openai.GPT4o, usage); cost.Input != 2.5 {
+		t.Errorf("overriding a copy changed the default prices: %+v", cost)
+	}
+}
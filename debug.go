@@ -0,0 +1,274 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// debugBodyLimit is the number of bytes of a request or response body logged in
+// debug mode.
+const debugBodyLimit = 4 << 10
+
+// debugResponseReadLimit is the number of bytes of a response read to be logged.
+// The rest is passed on to the caller without being held in memory.
+const debugResponseReadLimit = 1 << 20
+
+const redacted = "[REDACTED]"
+
+// Logger receives the debug output of the client. *log.Logger satisfies it, and
+// adapters for other logging libraries only need a Printf method.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// sensitiveHeaders are never logged verbatim.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Api-Key":       true,
+	"X-Api-Key":     true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// contentFields are the JSON fields hidden when ClientConfig.DebugRedactContent
+// is set.
+var contentFields = map[string]bool{
+	"content":      true,
+	"text":         true,
+	"input":        true,
+	"prompt":       true,
+	"instructions": true,
+	"arguments":    true,
+}
+
+// credentialFields are the JSON fields always hidden, such as the headers of MCP
+// tools, which carry the credentials of their servers.
+var credentialFields = map[string]bool{
+	"headers": true,
+}
+
+func (c *Client) debugLogger() Logger {
+	if c.config.Logger != nil {
+		return c.config.Logger
+	}
+	return log.Default()
+}
+
+// sendHTTP sends a single attempt of req, logging it and its response when
+// ClientConfig.Debug is set.
+func (c *Client) sendHTTP(req *http.Request) (*http.Response, error) {
+	if !c.config.Debug {
+		return c.config.HTTPClient.Do(req)
+	}
+
+	logger := c.debugLogger()
+	logger.Printf("openai: --> %s %s\n%s%s", req.Method, req.URL.Redacted(),
+		formatDebugHeaders(req.Header), c.debugRequestBody(req))
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		logger.Printf("openai: <-- %s %s error: %v", req.Method, req.URL.Redacted(), err)
+		return resp, err
+	}
+
+	logger.Printf("openai: <-- %s %s\n%s%s", resp.Status, req.URL.Redacted(),
+		formatDebugHeaders(resp.Header), c.debugResponseBody(resp))
+	return resp, nil
+}
+
+func formatDebugHeaders(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		value := strings.Join(h[k], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			value = redacted
+		}
+		b.WriteString(k + ": " + value + "\n")
+	}
+	return b.String()
+}
+
+func (c *Client) debugRequestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return "<streamed body not logged>"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "<body unavailable: " + err.Error() + ">"
+	}
+	defer body.Close()
+	// The body is replayable, so it is in memory already. It is redacted whole,
+	// as a truncated JSON document could not be.
+	data, _ := io.ReadAll(body)
+	return c.formatDebugBody(data, req.Header.Get("Content-Type"), false)
+}
+
+// debugResponseBody returns the loggable form of a response body. Streams are
+// logged line by line as they are read instead.
+func (c *Client) debugResponseBody(resp *http.Response) string {
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		resp.Body = &debugStreamBody{ReadCloser: resp.Body, client: c}
+		return "<stream events follow>"
+	}
+	if !isTextContentType(contentType) {
+		return "<" + contentType + " body not logged>"
+	}
+
+	body := resp.Body
+	data, err := io.ReadAll(io.LimitReader(body, debugResponseReadLimit+1))
+	cut := len(data) > debugResponseReadLimit
+	switch {
+	case err != nil:
+		body.Close()
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), erroredReader{err}))
+	case cut:
+		// The caller reads the rest from the body.
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+		data = data[:debugResponseReadLimit]
+	default:
+		body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	return c.formatDebugBody(data, contentType, cut)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func isTextContentType(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "application/json") ||
+		strings.HasPrefix(contentType, "text/")
+}
+
+// formatDebugBody redacts data and truncates it to debugBodyLimit. cut tells
+// that data is already only the start of the body.
+func (c *Client) formatDebugBody(data []byte, contentType string, cut bool) string {
+	if strings.HasPrefix(contentType, "multipart/") {
+		return "<multipart body not logged>"
+	}
+	if c.config.DebugRedactContent {
+		data = redactJSONContent(data)
+	} else {
+		data = redactJSONCredentials(data)
+	}
+	truncated := cut || len(data) > debugBodyLimit
+	if len(data) > debugBodyLimit {
+		data = data[:debugBodyLimit]
+	}
+	if truncated {
+		return string(data) + "... (truncated)"
+	}
+	return string(data)
+}
+
+// redactJSONContent replaces the values of content and credential fields in a
+// JSON document. Data that is not JSON is fully redacted, as it cannot be
+// inspected.
+func redactJSONContent(data []byte) []byte {
+	return redactJSONFields(data, func(k string) bool { return contentFields[k] || credentialFields[k] })
+}
+
+// redactJSONCredentials replaces the values of credential fields in a JSON
+// document, leaving documents without them untouched. Data that is not JSON,
+// such as a truncated body, is fully redacted if it mentions one.
+func redactJSONCredentials(data []byte) []byte {
+	for field := range credentialFields {
+		if bytes.Contains(data, []byte(`"`+field+`"`)) {
+			return redactJSONFields(data, func(k string) bool { return credentialFields[k] })
+		}
+	}
+	return data
+}
+
+// redactJSONFields replaces the values of the fields of a JSON document for
+// which hide returns true. Data that is not JSON is fully redacted.
+func redactJSONFields(data []byte, hide func(field string) bool) []byte {
+	var v any
+	if json.Unmarshal(data, &v) != nil {
+		return []byte(redacted)
+	}
+	var redact func(v any) any
+	redact = func(v any) any {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, item := range v {
+				if hide(k) {
+					v[k] = redacted
+				} else {
+					v[k] = redact(item)
+				}
+			}
+		case []any:
+			for i, item := range v {
+				v[i] = redact(item)
+			}
+		}
+		return v
+	}
+	out, err := json.Marshal(redact(v))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return out
+}
+
+// debugStreamBody logs every line of a server-sent events stream as the caller
+// reads it.
+type debugStreamBody struct {
+	io.ReadCloser
+	client  *Client
+	pending []byte
+}
+
+func (b *debugStreamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.pending = append(b.pending, p[:n]...)
+	for {
+		i := bytes.IndexByte(b.pending, '\n')
+		if i < 0 {
+			break
+		}
+		b.logLine(b.pending[:i])
+		b.pending = b.pending[i+1:]
+	}
+	if err != nil && len(b.pending) > 0 {
+		b.logLine(b.pending)
+		b.pending = nil
+	}
+	return n, err
+}
+
+func (b *debugStreamBody) logLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	if bytes.HasPrefix(line, []byte("data:")) {
+		data := bytes.TrimSpace(line[len("data:"):])
+		switch {
+		case bytes.Equal(data, []byte("[DONE]")):
+		case b.client.config.DebugRedactContent:
+			line = append([]byte("data: "), redactJSONContent(data)...)
+		default:
+			line = append([]byte("data: "), redactJSONCredentials(data)...)
+		}
+	}
+	b.client.debugLogger().Printf("openai: <-- event %s", line)
+}
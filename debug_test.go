@@ -0,0 +1,221 @@
+package openai_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type bufferLogger struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (l *bufferLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(&l.buf, format+"\n", v...)
+}
+
+func (l *bufferLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+// handleDebugChatCompletion replies with "secret reply", streamed if requested,
+// or with a long reply if the request asks for one.
+func handleDebugChatCompletion(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if strings.Contains(string(body), `"stream":true`) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"secret reply"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	reply := "secret reply"
+	if strings.Contains(string(body), "long reply") {
+		reply = strings.Repeat("long reply ", 200000)
+	}
+	_, _ = w.Write([]byte(`{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"` +
+		reply + `"}}]}`))
+}
+
+func handleDebugResponse(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"id":"resp_1","status":"completed","output":[]}`))
+}
+
+var debugChatRequest = openai.ChatCompletionRequest{
+	Model:    openai.GPT4,
+	Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "secret prompt"}},
+}
+
+func TestDebugLogging(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Debug = true
+		c.Logger = logger
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleDebugChatCompletion)
+
+	_, err := client.CreateChatCompletion(context.Background(), debugChatRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	out := logger.String()
+	for _, want := range []string{
+		"--> POST", "/v1/chat/completions", "Authorization: [REDACTED]",
+		"secret prompt", "<-- 200 OK", "secret reply",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, test.GetTestToken()) {
+		t.Errorf("debug output leaks the API key:\n%s", out)
+	}
+}
+
+func TestDebugLoggingRedactsContent(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Debug = true
+		c.Logger = logger
+		c.DebugRedactContent = true
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleDebugChatCompletion)
+
+	_, err := client.CreateChatCompletion(context.Background(), debugChatRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), debugChatRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+
+	out := logger.String()
+	if strings.Contains(out, "secret") {
+		t.Errorf("debug output leaks content:\n%s", out)
+	}
+	for _, want := range []string{`"content":"[REDACTED]"`, "<-- event data: {", "<-- event data: [DONE]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDebugLoggingLargeBodies(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Debug = true
+		c.Logger = logger
+		c.DebugRedactContent = true
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleDebugChatCompletion)
+
+	// The request is redacted before being truncated, so the fields around its
+	// long content are still logged.
+	request := openai.ChatCompletionRequest{
+		Model: openai.GPT4,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: strings.Repeat("secret prompt ", 1000)},
+			{Role: openai.ChatMessageRoleUser, Content: "long reply"},
+		},
+	}
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(resp.Choices[0].Message.Content) != len("long reply ")*200000 {
+		t.Fatalf("response altered by debug logging: %d bytes of content", len(resp.Choices[0].Message.Content))
+	}
+
+	out := logger.String()
+	for _, want := range []string{`"model":"gpt-4"`, `"content":"[REDACTED]"`, `"role":"user"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret") || len(out) > 16<<10 {
+		t.Errorf("debug output of %d bytes leaks content or is not truncated", len(out))
+	}
+}
+
+func TestDebugLoggingRedactsMCPHeaders(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Debug = true
+		c.Logger = logger
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/responses", handleDebugResponse)
+
+	_, err := client.CreateResponse(context.Background(), openai.ResponseRequest{
+		Model: openai.GPT4Dot1,
+		Input: "Roll a die.",
+		Tools: []openai.ResponseTool{openai.NewResponseMCPTool("dice", "https://dice.example.com/mcp",
+			map[string]string{"Authorization": "Bearer mcp-secret"}, "never")},
+	})
+	checks.NoError(t, err, "CreateResponse error")
+
+	out := logger.String()
+	if strings.Contains(out, "mcp-secret") {
+		t.Errorf("debug output leaks the MCP headers:\n%s", out)
+	}
+	for _, want := range []string{`"headers":"[REDACTED]"`, `"server_url":"https://dice.example.com/mcp"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDebugLoggingStreamEvents(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Debug = true
+		c.Logger = logger
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleDebugChatCompletion)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), debugChatRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	resp, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if resp.Choices[0].Delta.Content != "secret reply" {
+		t.Fatalf("stream content altered by debug logging: %q", resp.Choices[0].Delta.Content)
+	}
+	if !strings.Contains(logger.String(), `<-- event data: {"id":"1"`) {
+		t.Errorf("stream event was not logged:\n%s", logger.String())
+	}
+}
+
+func TestDebugLoggingDisabledByDefault(t *testing.T) {
+	logger := &bufferLogger{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Logger = logger
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleDebugChatCompletion)
+
+	_, err := client.CreateChatCompletion(context.Background(), debugChatRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if out := logger.String(); out != "" {
+		t.Errorf("expected no output without Debug, got:\n%s", out)
+	}
+}
@@ -1,16 +1,24 @@
 package openai
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"net/http"
+	"strings"
 )
 
-var ErrVectorLengthMismatch = errors.New("vector length mismatch")
+var (
+	ErrVectorLengthMismatch = errors.New("vector length mismatch")
+	// ErrEmbeddingDimensions is returned for embedding requests with negative
+	// dimensions, or dimensions for a model that always returns full vectors.
+	ErrEmbeddingDimensions = errors.New("dimensions must be positive and are only supported by text-embedding-3 models")
+)
 
 // EmbeddingModel enumerates the models which can be used
 // to generate Embedding vectors.
@@ -52,6 +60,39 @@ type Embedding struct {
 	Index     int       `json:"index"`
 }
 
+// UnmarshalJSON decodes the embedding vector from either an array of numbers or
+// a base64 string of little-endian float32s, as returned for the base64 encoding
+// format, so that servers ignoring the requested format are still understood.
+func (e *Embedding) UnmarshalJSON(data []byte) error {
+	var embedding struct {
+		Object    string          `json:"object"`
+		Embedding json.RawMessage `json:"embedding"`
+		Index     int             `json:"index"`
+	}
+	if err := json.Unmarshal(data, &embedding); err != nil {
+		return err
+	}
+	e.Object, e.Index, e.Embedding = embedding.Object, embedding.Index, nil
+
+	vector := bytes.TrimSpace(embedding.Embedding)
+	if len(vector) == 0 {
+		return nil
+	}
+	if vector[0] != '"' {
+		return json.Unmarshal(vector, &e.Embedding)
+	}
+	var encoded base64String
+	if err := json.Unmarshal(vector, &encoded); err != nil {
+		return err
+	}
+	floats, err := encoded.Decode()
+	if err != nil {
+		return err
+	}
+	e.Embedding = floats
+	return nil
+}
+
 // DotProduct calculates the dot product of the embedding vector with another
 // embedding vector. Both vectors must have the same length; otherwise, an
 // ErrVectorLengthMismatch is returned. The method returns the calculated dot
@@ -77,6 +118,7 @@ type EmbeddingResponse struct {
 	Usage  Usage          `json:"usage"`
 
 	httpHeader
+	rawJSON
 }
 
 type base64String string
@@ -111,6 +153,7 @@ type EmbeddingResponseBase64 struct {
 	Usage  Usage             `json:"usage"`
 
 	httpHeader
+	rawJSON
 }
 
 // ToEmbeddingResponse converts an embeddingResponseBase64 to an EmbeddingResponse.
@@ -131,10 +174,12 @@ func (r *EmbeddingResponseBase64) ToEmbeddingResponse() (EmbeddingResponse, erro
 	}
 
 	return EmbeddingResponse{
-		Object: r.Object,
-		Model:  r.Model,
-		Data:   data,
-		Usage:  r.Usage,
+		Object:     r.Object,
+		Model:      r.Model,
+		Data:       data,
+		Usage:      r.Usage,
+		httpHeader: r.httpHeader,
+		rawJSON:    r.rawJSON,
 	}, nil
 }
 
@@ -144,8 +189,8 @@ type EmbeddingRequestConverter interface {
 }
 
 // EmbeddingEncodingFormat is the format of the embeddings data.
-// Currently, only "float" and "base64" are supported, however, "base64" is not officially documented.
-// If not specified OpenAI will use "float".
+// Currently, only "float" and "base64" are supported. CreateEmbeddings requests
+// "base64" unless another format is set, and returns float32 vectors either way.
 type EmbeddingEncodingFormat string
 
 const (
@@ -154,6 +199,8 @@ const (
 )
 
 type EmbeddingRequest struct {
+	// Input is a string, []string, []int or [][]int, or an EmbeddingInput, which
+	// wraps them with a type checked at compile time.
 	Input          any                     `json:"input"`
 	Model          EmbeddingModel          `json:"model"`
 	User           string                  `json:"user,omitempty"`
@@ -243,6 +290,16 @@ func (r EmbeddingRequestTokens) Convert() EmbeddingRequest {
 	}
 }
 
+// embeddingDimensionsSupported reports whether dimensions can be requested from
+// model. text-embedding-ada-002 and the first generation -001 models do not take
+// them; other models are left to the API to check.
+func embeddingDimensionsSupported(model EmbeddingModel, dimensions int) bool {
+	if dimensions == 0 {
+		return true
+	}
+	return dimensions > 0 && model != AdaEmbeddingV2 && !strings.HasSuffix(string(model), "-001")
+}
+
 // CreateEmbeddings returns an EmbeddingResponse which will contain an Embedding for every item in |body.Input|.
 // https://beta.openai.com/docs/api-reference/embeddings/create
 //
@@ -253,6 +310,19 @@ func (c *Client) CreateEmbeddings(
 	conv EmbeddingRequestConverter,
 ) (res EmbeddingResponse, err error) {
 	baseReq := conv.Convert()
+	// Base64 vectors are several times smaller and faster to decode than arrays
+	// of numbers. Azure deployments are sent the format they were asked for, as
+	// older API versions reject it.
+	if baseReq.EncodingFormat == "" && !c.config.isAzure() {
+		baseReq.EncodingFormat = EmbeddingEncodingFormatBase64
+	}
+	if err = checkEmbeddingInput(baseReq.Input); err != nil {
+		return
+	}
+	if !embeddingDimensionsSupported(baseReq.Model, baseReq.Dimensions) {
+		err = fmt.Errorf("%w: %d with %s", ErrEmbeddingDimensions, baseReq.Dimensions, baseReq.Model)
+		return
+	}
 
 	// The body map is used to dynamically construct the request payload for the embedding API.
 	// Instead of relying on a fixed struct, the body map allows for flexible inclusion of fields
@@ -281,17 +351,7 @@ func (c *Client) CreateEmbeddings(
 		return
 	}
 
-	if baseReq.EncodingFormat != EmbeddingEncodingFormatBase64 {
-		err = c.sendRequest(req, &res)
-		return
-	}
-
-	base64Response := &EmbeddingResponseBase64{}
-	err = c.sendRequest(req, base64Response)
-	if err != nil {
-		return
-	}
-
-	res, err = base64Response.ToEmbeddingResponse()
+	// Embedding decodes both formats.
+	err = c.sendRequest(req, &res)
 	return
 }
@@ -0,0 +1,207 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultEmbeddingBatchMaxItems is the maximum number of inputs of an
+	// embeddings request.
+	DefaultEmbeddingBatchMaxItems = 2048
+	// DefaultEmbeddingBatchMaxTokens keeps batches below the limit of about 300k
+	// tokens per embeddings request, with a margin for the token estimate of
+	// string inputs.
+	DefaultEmbeddingBatchMaxTokens = 250000
+	// DefaultEmbeddingBatchConcurrency is the number of batches sent at once.
+	DefaultEmbeddingBatchConcurrency = 4
+)
+
+// ErrEmbeddingBatchInput is returned by CreateEmbeddingsBatched for inputs that
+// are not a list of strings or a list of token lists.
+var ErrEmbeddingBatchInput = errors.New("batched embeddings input must be []string or [][]int")
+
+// BatchOptions configures how CreateEmbeddingsBatched splits a request.
+type BatchOptions struct {
+	// MaxItems is the maximum number of inputs per request, at most
+	// DefaultEmbeddingBatchMaxItems, which is used when it is zero.
+	MaxItems int
+	// MaxTokens is the maximum number of tokens per request, or
+	// DefaultEmbeddingBatchMaxTokens when zero. Tokens of string inputs are
+	// estimated as one per four bytes. An input above the limit is sent alone.
+	MaxTokens int
+	// MaxConcurrency is the maximum number of requests in flight, or
+	// DefaultEmbeddingBatchConcurrency when zero.
+	MaxConcurrency int
+	// ReturnPartial makes CreateEmbeddingsBatched return the embeddings of the
+	// batches that succeeded along with the error of a failed one.
+	ReturnPartial bool
+}
+
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.MaxItems <= 0 || o.MaxItems > DefaultEmbeddingBatchMaxItems {
+		o.MaxItems = DefaultEmbeddingBatchMaxItems
+	}
+	if o.MaxTokens <= 0 {
+		o.MaxTokens = DefaultEmbeddingBatchMaxTokens
+	}
+	if o.MaxConcurrency <= 0 {
+		o.MaxConcurrency = DefaultEmbeddingBatchConcurrency
+	}
+	return o
+}
+
+// embeddingBatch is a range of the inputs of a batched request.
+type embeddingBatch struct {
+	start, end int
+}
+
+// splitEmbeddingInput splits n inputs into batches of at most maxItems inputs
+// and maxTokens tokens, as counted by tokens.
+func splitEmbeddingInput(n int, tokens func(i int) int, maxItems, maxTokens int) []embeddingBatch {
+	var batches []embeddingBatch
+	start, batchTokens := 0, 0
+	for i := 0; i < n; i++ {
+		count := tokens(i)
+		if i > start && (i-start == maxItems || batchTokens+count > maxTokens) {
+			batches = append(batches, embeddingBatch{start, i})
+			start, batchTokens = i, 0
+		}
+		batchTokens += count
+	}
+	if start < n {
+		batches = append(batches, embeddingBatch{start, n})
+	}
+	return batches
+}
+
+// CreateEmbeddingsBatched creates embeddings for inputs of any length, by
+// splitting the input of the request into batches sent concurrently with
+// CreateEmbeddings. The embeddings of the response are in the order of the
+// inputs, with Index set to the position of their input, and Usage is the sum of
+// the usage of all batches.
+//
+// The input must be a []string or a [][]int. The first failed batch cancels the
+// others and its error is returned, along with the embeddings of the batches that
+// succeeded if opts.ReturnPartial is set.
+func (c *Client) CreateEmbeddingsBatched(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	opts BatchOptions,
+) (res EmbeddingResponse, err error) {
+	baseReq := conv.Convert()
+	opts = opts.withDefaults()
+
+	var (
+		n      int
+		tokens func(i int) int
+		slice  func(b embeddingBatch) any
+	)
+	input := baseReq.Input
+	if embeddingInput, ok := input.(EmbeddingInput); ok {
+		input = embeddingInput.Value()
+	}
+	switch input := input.(type) {
+	case []string:
+		n = len(input)
+		tokens = func(i int) int { return (len(input[i]) + 3) / 4 }
+		slice = func(b embeddingBatch) any { return input[b.start:b.end] }
+	case [][]int:
+		n = len(input)
+		tokens = func(i int) int { return len(input[i]) }
+		slice = func(b embeddingBatch) any { return input[b.start:b.end] }
+	default:
+		err = fmt.Errorf("%w, got %T", ErrEmbeddingBatchInput, baseReq.Input)
+		return
+	}
+	batches := splitEmbeddingInput(n, tokens, opts.MaxItems, opts.MaxTokens)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		responses = make([]*EmbeddingResponse, len(batches))
+		next      = make(chan int)
+	)
+	workers := opts.MaxConcurrency
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				req := baseReq
+				req.Input = slice(batches[i])
+				resp, batchErr := c.CreateEmbeddings(ctx, req)
+				if batchErr != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("embedding inputs %d to %d: %w",
+							batches[i].start, batches[i].end-1, batchErr)
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				responses[i] = &resp
+			}
+		}()
+	}
+
+dispatch:
+	for i := range batches {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for _, resp := range responses {
+		if firstErr == nil && resp == nil {
+			// The parent context was done before every batch was sent.
+			firstErr = ctx.Err()
+		}
+	}
+	if firstErr != nil && !opts.ReturnPartial {
+		err = firstErr
+		return
+	}
+	res = mergeEmbeddingResponses(batches, responses)
+	err = firstErr
+	return
+}
+
+// mergeEmbeddingResponses joins the responses of batches, skipping failed ones,
+// and makes the indexes of the embeddings refer to the whole input.
+func mergeEmbeddingResponses(batches []embeddingBatch, responses []*EmbeddingResponse) EmbeddingResponse {
+	var res EmbeddingResponse
+	first := true
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if first {
+			first = false
+			res.Object, res.Model, res.httpHeader = resp.Object, resp.Model, resp.httpHeader
+		}
+		for _, embedding := range resp.Data {
+			embedding.Index += batches[i].start
+			res.Data = append(res.Data, embedding)
+		}
+		res.Usage.Add(resp.Usage)
+	}
+	// The embeddings of a response are not guaranteed to be in input order.
+	sort.SliceStable(res.Data, func(i, j int) bool { return res.Data[i].Index < res.Data[j].Index })
+	return res
+}
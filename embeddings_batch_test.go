@@ -0,0 +1,199 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// batchEmbeddingsHandler embeds each input "n" as the vector [n], returning the
+// embeddings of a request in reverse order, and fails requests with a "fail"
+// input.
+type batchEmbeddingsHandler struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	requests int
+}
+
+func (h *batchEmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.inFlight++
+	h.requests++
+	if h.inFlight > h.peak {
+		h.peak = h.inFlight
+	}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.inFlight--
+		h.mu.Unlock()
+	}()
+
+	var req struct {
+		Input []string `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := openai.EmbeddingResponse{Object: "list", Model: openai.SmallEmbedding3}
+	for i := len(req.Input) - 1; i >= 0; i-- {
+		n, err := strconv.Atoi(req.Input[i])
+		if err != nil {
+			http.Error(w, `{"error":{"message":"invalid input"}}`, http.StatusBadRequest)
+			return
+		}
+		resp.Data = append(resp.Data, openai.Embedding{Object: "embedding", Embedding: []float32{float32(n)}, Index: i})
+	}
+	resp.Usage = openai.Usage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *batchEmbeddingsHandler) stats() (requests, peak int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests, h.peak
+}
+
+func batchEmbeddingsInput(n int) []string {
+	input := make([]string, n)
+	for i := range input {
+		input[i] = strconv.Itoa(i)
+	}
+	return input
+}
+
+func TestCreateEmbeddingsBatched(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &batchEmbeddingsHandler{}
+	server.RegisterHandler("/v1/embeddings", handler.ServeHTTP)
+
+	res, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequestStrings{
+		Input: batchEmbeddingsInput(10),
+		Model: openai.SmallEmbedding3,
+	}, openai.BatchOptions{MaxItems: 3, MaxConcurrency: 2})
+	checks.NoError(t, err, "CreateEmbeddingsBatched error")
+
+	if len(res.Data) != 10 {
+		t.Fatalf("got %d embeddings, want 10", len(res.Data))
+	}
+	for i, embedding := range res.Data {
+		if embedding.Index != i || embedding.Embedding[0] != float32(i) {
+			t.Errorf("embedding %d has index %d and vector %v", i, embedding.Index, embedding.Embedding)
+		}
+	}
+	if res.Usage.PromptTokens != 10 || res.Usage.TotalTokens != 10 {
+		t.Errorf("usage was not merged: %+v", res.Usage)
+	}
+	if res.Model != openai.SmallEmbedding3 {
+		t.Errorf("unexpected model %q", res.Model)
+	}
+	requests, peak := handler.stats()
+	if requests != 4 {
+		t.Errorf("sent %d requests, want 4", requests)
+	}
+	if peak > 2 {
+		t.Errorf("%d requests were in flight, want at most 2", peak)
+	}
+}
+
+func TestCreateEmbeddingsBatchedMaxTokens(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &batchEmbeddingsHandler{}
+	server.RegisterHandler("/v1/embeddings", handler.ServeHTTP)
+
+	// Each input is estimated at one token.
+	res, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequest{
+		Input: batchEmbeddingsInput(5),
+		Model: openai.SmallEmbedding3,
+	}, openai.BatchOptions{MaxTokens: 2})
+	checks.NoError(t, err, "CreateEmbeddingsBatched error")
+	if requests, _ := handler.stats(); len(res.Data) != 5 || requests != 3 {
+		t.Errorf("got %d embeddings in %d requests, want 5 in 3", len(res.Data), requests)
+	}
+}
+
+func TestCreateEmbeddingsBatchedError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &batchEmbeddingsHandler{}
+	server.RegisterHandler("/v1/embeddings", handler.ServeHTTP)
+
+	input := batchEmbeddingsInput(8)
+	input[3] = "fail"
+	req := openai.EmbeddingRequest{Input: input, Model: openai.SmallEmbedding3}
+
+	res, err := client.CreateEmbeddingsBatched(context.Background(), req,
+		openai.BatchOptions{MaxItems: 2, MaxConcurrency: 1})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if len(res.Data) != 0 {
+		t.Errorf("got %d embeddings without ReturnPartial", len(res.Data))
+	}
+
+	res, err = client.CreateEmbeddingsBatched(context.Background(), req,
+		openai.BatchOptions{MaxItems: 2, MaxConcurrency: 1, ReturnPartial: true})
+	checks.HasError(t, err, "CreateEmbeddingsBatched should fail")
+	// Only the batch before the failed one was sent.
+	if len(res.Data) != 2 || res.Data[0].Index != 0 || res.Data[1].Index != 1 {
+		t.Errorf("unexpected partial embeddings %+v", res.Data)
+	}
+}
+
+func TestCreateEmbeddingsBatchedCanceled(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &batchEmbeddingsHandler{}
+	server.RegisterHandler("/v1/embeddings", handler.ServeHTTP)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.CreateEmbeddingsBatched(ctx, openai.EmbeddingRequest{
+		Input: batchEmbeddingsInput(4),
+		Model: openai.SmallEmbedding3,
+	}, openai.BatchOptions{MaxItems: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCreateEmbeddingsBatchedInput(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	_, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequest{
+		Input: "a single string",
+		Model: openai.SmallEmbedding3,
+	}, openai.BatchOptions{})
+	if !errors.Is(err, openai.ErrEmbeddingBatchInput) {
+		t.Fatalf("expected ErrEmbeddingBatchInput, got %v", err)
+	}
+}
+
+func TestCreateEmbeddingsBatchedEmbeddingInput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	handler := &batchEmbeddingsHandler{}
+	server.RegisterHandler("/v1/embeddings", handler.ServeHTTP)
+
+	res, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequest{
+		Input: openai.EmbeddingInputFromStrings(batchEmbeddingsInput(5)),
+		Model: openai.SmallEmbedding3,
+	}, openai.BatchOptions{MaxItems: 2})
+	checks.NoError(t, err, "CreateEmbeddingsBatched error")
+	if len(res.Data) != 5 {
+		t.Errorf("got %d embeddings, want 5", len(res.Data))
+	}
+}
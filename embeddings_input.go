@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrEmbeddingInputInvalid is returned for embedding requests whose input is not
+// one of the types accepted by the API, or is an empty EmbeddingInput.
+var ErrEmbeddingInputInvalid = errors.New("embedding input must be a string, []string, []int or [][]int")
+
+// EmbeddingInput is the input of an embeddings request: a string, a list of
+// strings, a list of tokens or a list of token lists. It is created with the
+// EmbeddingInputFrom functions, so that its type is checked at compile time, and
+// can be used as EmbeddingRequest.Input.
+type EmbeddingInput struct {
+	value any
+}
+
+// EmbeddingInputFromString returns the input to embed a single text.
+func EmbeddingInputFromString(text string) EmbeddingInput {
+	return EmbeddingInput{value: text}
+}
+
+// EmbeddingInputFromStrings returns the input to embed each of texts.
+func EmbeddingInputFromStrings(texts []string) EmbeddingInput {
+	if texts == nil {
+		texts = []string{}
+	}
+	return EmbeddingInput{value: texts}
+}
+
+// EmbeddingInputFromTokens returns the input to embed a single text, given as
+// the tokens of the model's encoding.
+func EmbeddingInputFromTokens(tokens []int) EmbeddingInput {
+	if tokens == nil {
+		tokens = []int{}
+	}
+	return EmbeddingInput{value: tokens}
+}
+
+// EmbeddingInputFromTokenLists returns the input to embed several texts, each
+// given as the tokens of the model's encoding. The lists may have different
+// lengths.
+func EmbeddingInputFromTokenLists(tokenLists [][]int) EmbeddingInput {
+	lists := make([][]int, len(tokenLists))
+	for i, tokens := range tokenLists {
+		if tokens == nil {
+			tokens = []int{}
+		}
+		lists[i] = tokens
+	}
+	return EmbeddingInput{value: lists}
+}
+
+// Value returns the string, []string, []int or [][]int of the input, or nil for
+// the zero EmbeddingInput.
+func (i EmbeddingInput) Value() any {
+	return i.value
+}
+
+// Len returns the number of embeddings the input asks for.
+func (i EmbeddingInput) Len() int {
+	switch v := i.value.(type) {
+	case string, []int:
+		return 1
+	case []string:
+		return len(v)
+	case [][]int:
+		return len(v)
+	}
+	return 0
+}
+
+// Validate checks that the input is not empty, which the API rejects: it must
+// contain at least one text, and no text can be empty.
+func (i EmbeddingInput) Validate() error {
+	switch v := i.value.(type) {
+	case nil:
+		return fmt.Errorf("%w: input is not set", ErrEmbeddingInputInvalid)
+	case string:
+		if v == "" {
+			return fmt.Errorf("%w: input is empty", ErrEmbeddingInputInvalid)
+		}
+	case []int:
+		if len(v) == 0 {
+			return fmt.Errorf("%w: input is empty", ErrEmbeddingInputInvalid)
+		}
+	case []string:
+		if len(v) == 0 {
+			return fmt.Errorf("%w: input is empty", ErrEmbeddingInputInvalid)
+		}
+		for n, text := range v {
+			if text == "" {
+				return fmt.Errorf("%w: input %d is empty", ErrEmbeddingInputInvalid, n)
+			}
+		}
+	case [][]int:
+		if len(v) == 0 {
+			return fmt.Errorf("%w: input is empty", ErrEmbeddingInputInvalid)
+		}
+		for n, tokens := range v {
+			if len(tokens) == 0 {
+				return fmt.Errorf("%w: input %d is empty", ErrEmbeddingInputInvalid, n)
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the input as the API expects it.
+func (i EmbeddingInput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.value)
+}
+
+// checkEmbeddingInput checks the type of EmbeddingRequest.Input, and validates
+// an EmbeddingInput. An unset input is left to the API to reject, as are empty
+// inputs of other types.
+func checkEmbeddingInput(input any) error {
+	switch v := input.(type) {
+	case nil, string, []string, []int, [][]int:
+		return nil
+	case EmbeddingInput:
+		return v.Validate()
+	case *EmbeddingInput:
+		if v == nil {
+			return nil
+		}
+		return v.Validate()
+	case []any:
+		// Strings decoded from JSON.
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("%w, got []any with %T", ErrEmbeddingInputInvalid, item)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w, got %T", ErrEmbeddingInputInvalid, input)
+}
@@ -0,0 +1,93 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestEmbeddingInput(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input openai.EmbeddingInput
+		json  string
+		len   int
+		valid bool
+	}{
+		{"string", openai.EmbeddingInputFromString("hello"), `"hello"`, 1, true},
+		{"empty string", openai.EmbeddingInputFromString(""), `""`, 1, false},
+		{"strings", openai.EmbeddingInputFromStrings([]string{"a", "b"}), `["a","b"]`, 2, true},
+		{"empty strings", openai.EmbeddingInputFromStrings([]string{}), `[]`, 0, false},
+		{"nil strings", openai.EmbeddingInputFromStrings(nil), `[]`, 0, false},
+		{"strings with an empty one", openai.EmbeddingInputFromStrings([]string{"a", ""}), `["a",""]`, 2, false},
+		{"tokens", openai.EmbeddingInputFromTokens([]int{1, 2, 3}), `[1,2,3]`, 1, true},
+		{"empty tokens", openai.EmbeddingInputFromTokens(nil), `[]`, 1, false},
+		{
+			"token lists of mixed lengths",
+			openai.EmbeddingInputFromTokenLists([][]int{{1}, {2, 3, 4}, {5, 6}}),
+			`[[1],[2,3,4],[5,6]]`, 3, true,
+		},
+		{"empty token lists", openai.EmbeddingInputFromTokenLists([][]int{}), `[]`, 0, false},
+		{"token lists with an empty one", openai.EmbeddingInputFromTokenLists([][]int{{1}, nil}), `[[1],[]]`, 2, false},
+		{"zero value", openai.EmbeddingInput{}, `null`, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.input)
+			checks.NoError(t, err, "Marshal error")
+			if string(data) != tc.json {
+				t.Errorf("got %s, want %s", data, tc.json)
+			}
+			if tc.input.Len() != tc.len {
+				t.Errorf("got length %d, want %d", tc.input.Len(), tc.len)
+			}
+			err = tc.input.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tc.valid && !errors.Is(err, openai.ErrEmbeddingInputInvalid) {
+				t.Errorf("expected ErrEmbeddingInputInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddingInputRequest(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input [][]int `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Input) != 2 || len(req.Input[1]) != 3 {
+			http.Error(w, `{"error":{"message":"unexpected input"}}`, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[` +
+			`{"object":"embedding","embedding":[1],"index":0},{"object":"embedding","embedding":[2],"index":1}]}`))
+	})
+
+	res, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+		Input: openai.EmbeddingInputFromTokenLists([][]int{{1}, {2, 3, 4}}),
+		Model: openai.SmallEmbedding3,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(res.Data) != 2 {
+		t.Fatalf("got %d embeddings, want 2", len(res.Data))
+	}
+
+	for _, input := range []any{openai.EmbeddingInputFromStrings(nil), 42, []float64{1}} {
+		_, err = client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+			Input: input,
+			Model: openai.SmallEmbedding3,
+		})
+		if !errors.Is(err, openai.ErrEmbeddingInputInvalid) {
+			t.Errorf("expected ErrEmbeddingInputInvalid for %#v, got %v", input, err)
+		}
+	}
+}
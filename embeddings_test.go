@@ -3,6 +3,8 @@ package openai_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -325,3 +327,169 @@ func TestDotProduct(t *testing.T) {
 		t.Errorf("Expected Vector Length Mismatch Error, but got: %v", err)
 	}
 }
+
+func TestEmbeddingRawJSON(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	body := `{"object":"list","data":[{"object":"embedding","embedding":"pHCdP4XrkUDhevxA","index":0}],` +
+		`"model":"text-embedding-3-small","usage":{"prompt_tokens":1,"total_tokens":1}}`
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		_, _ = w.Write([]byte(body))
+	})
+
+	// Base64 responses are converted after decoding, and keep the raw JSON and
+	// headers of the original response.
+	resp, err := client.WithOptions(openai.WithRawJSON()).CreateEmbeddings(context.Background(),
+		openai.EmbeddingRequest{
+			Input:          []string{"hello"},
+			Model:          openai.SmallEmbedding3,
+			EncodingFormat: openai.EmbeddingEncodingFormatBase64,
+		})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if string(resp.RawJSON()) != body || resp.Header().Get("X-Request-Id") != "req-1" {
+		t.Errorf("unexpected raw JSON %s", resp.RawJSON())
+	}
+}
+
+func TestEmbeddingDimensions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var bodies []map[string]any
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		bodies = append(bodies, body)
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","embedding":[0.6,0.8],"index":0}]}`))
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: "hello", Model: openai.LargeEmbedding3, Dimensions: 2,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	_, err = client.CreateEmbeddings(ctx, openai.EmbeddingRequest{Input: "hello", Model: openai.AdaEmbeddingV2})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(bodies) != 2 || bodies[0]["dimensions"] != 2.0 {
+		t.Fatalf("unexpected requests %v", bodies)
+	}
+	if _, ok := bodies[1]["dimensions"]; ok {
+		t.Errorf("zero dimensions should be omitted, got %v", bodies[1])
+	}
+
+	for _, request := range []openai.EmbeddingRequest{
+		{Input: "hello", Model: openai.AdaEmbeddingV2, Dimensions: 256},
+		{Input: "hello", Model: openai.AdaSearchQuery, Dimensions: 256},
+		{Input: "hello", Model: openai.SmallEmbedding3, Dimensions: -1},
+	} {
+		_, err = client.CreateEmbeddings(ctx, request)
+		checks.ErrorIs(t, err, openai.ErrEmbeddingDimensions, "unsupported dimensions should be rejected")
+	}
+	if len(bodies) != 2 {
+		t.Errorf("rejected requests should not be sent, got %d requests", len(bodies))
+	}
+}
+
+func TestEmbeddingDefaultBase64(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var formats []any
+	responses := []string{
+		`{"object":"list","data":[{"object":"embedding","embedding":"pHCdP4XrkUDhevxA","index":0}]}`,
+		// A server ignoring the requested format returns numbers.
+		`{"object":"list","data":[{"object":"embedding","embedding":[1.23,4.56,7.89],"index":0}]}`,
+	}
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		formats = append(formats, body["encoding_format"])
+		_, _ = w.Write([]byte(responses[(len(formats)-1)%len(responses)]))
+	})
+
+	want := []float32{1.23, 4.56, 7.89}
+	for range responses {
+		resp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+			Input: "hello",
+			Model: openai.SmallEmbedding3,
+		})
+		checks.NoError(t, err, "CreateEmbeddings error")
+		if !reflect.DeepEqual(resp.Data[0].Embedding, want) {
+			t.Errorf("unexpected embedding %v", resp.Data[0].Embedding)
+		}
+	}
+	_, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+		Input:          "hello",
+		Model:          openai.SmallEmbedding3,
+		EncodingFormat: openai.EmbeddingEncodingFormatFloat,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if !reflect.DeepEqual(formats, []any{"base64", "base64", "float"}) {
+		t.Errorf("unexpected requested formats %v", formats)
+	}
+
+	var embedding openai.Embedding
+	checks.HasError(t, json.Unmarshal([]byte(`{"embedding":"not base64!"}`), &embedding), "invalid base64")
+	checks.NoError(t, json.Unmarshal([]byte(`{"object":"embedding","index":1}`), &embedding), "no embedding")
+	if embedding.Index != 1 || embedding.Embedding != nil {
+		t.Errorf("unexpected embedding without a vector %+v", embedding)
+	}
+}
+
+func benchmarkEmbeddingResponse(b *testing.B, encode func([]float32) any) {
+	vector := make([]float32, 3072)
+	for i := range vector {
+		vector[i] = float32(math.Sin(float64(i))) / 3
+	}
+	data := make([]map[string]any, 64)
+	for i := range data {
+		data[i] = map[string]any{"object": "embedding", "embedding": encode(vector), "index": i}
+	}
+	body, err := json.Marshal(map[string]any{"object": "list", "data": data})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var resp openai.EmbeddingResponse
+		if err = json.Unmarshal(body, &resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEmbeddingDecodeFloat(b *testing.B) {
+	benchmarkEmbeddingResponse(b, func(vector []float32) any { return vector })
+}
+
+func BenchmarkEmbeddingDecodeBase64(b *testing.B) {
+	benchmarkEmbeddingResponse(b, func(vector []float32) any {
+		buf := make([]byte, 4*len(vector))
+		for i, v := range vector {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+		}
+		return base64.StdEncoding.EncodeToString(buf)
+	})
+}
+
+func TestAzureEmbeddingFormatUnset(t *testing.T) {
+	client, server, teardown := setupAzureTestServer()
+	defer teardown()
+	server.RegisterHandler("/openai/deployments/text-embedding-ada-002/embeddings", func(w http.ResponseWriter,
+		r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "Decode error")
+		if _, ok := body["encoding_format"]; ok {
+			http.Error(w, "unexpected encoding_format", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","embedding":[0.5],"index":0}]}`))
+	})
+
+	_, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+		Input: "hello",
+		Model: openai.AdaEmbeddingV2,
+	})
+	checks.NoError(t, err, "Azure requests should not set a default encoding format")
+}
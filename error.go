@@ -2,26 +2,110 @@ package openai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 )
 
+// Errors an APIError or RequestError can be compared to with errors.Is, to tell
+// why the API rejected a request, for OpenAI as well as Azure OpenAI Service.
+var (
+	// ErrContextLengthExceeded is matched by errors reporting that the prompt and
+	// the requested completion do not fit in the context window of the model.
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	// ErrRateLimited is matched by errors reporting that a rate limit was hit, in
+	// which case retrying later can succeed. Exhausted quotas do not match it.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrInvalidAPIKey is matched by errors reporting that the API key, or the
+	// Azure subscription key, was missing or rejected.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrQuotaExceeded is matched by errors reporting that the account ran out of
+	// credits or quota, which retrying does not fix.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrDeploymentNotFound is matched by errors reporting that the Azure
+	// deployment, or the OpenAI model, of the request does not exist.
+	ErrDeploymentNotFound = errors.New("deployment not found")
+)
+
+// requestErrorBodyLimit is the number of bytes of the body of a RequestError
+// included in its message.
+const requestErrorBodyLimit = 512
+
 // APIError provides error information returned by the OpenAI API.
 // InnerError struct is only valid for Azure OpenAI Service.
 type APIError struct {
-	Code           any         `json:"code,omitempty"`
-	Message        string      `json:"message"`
-	Param          *string     `json:"param,omitempty"`
-	Type           string      `json:"type"`
-	HTTPStatus     string      `json:"-"`
-	HTTPStatusCode int         `json:"-"`
-	InnerError     *InnerError `json:"innererror,omitempty"`
+	Code           APIErrorCode `json:"code,omitempty"`
+	Message        string       `json:"message"`
+	Param          *string      `json:"param,omitempty"`
+	Type           string       `json:"type"`
+	HTTPStatus     string       `json:"-"`
+	HTTPStatusCode int          `json:"-"`
+	InnerError     *InnerError  `json:"innererror,omitempty"`
+
+	// The headers of the failed response, giving access to its request ID and,
+	// for a 429, when the rate limit resets.
+	httpHeader
 }
 
 // InnerError Azure Content filtering. Only valid for Azure OpenAI Service.
 type InnerError struct {
 	Code                 string               `json:"code,omitempty"`
 	ContentFilterResults ContentFilterResults `json:"content_filter_result,omitempty"`
+	// RevisedPrompt is the prompt an image request was rewritten to before it
+	// was filtered.
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// UnmarshalJSON also reads the content filter results of image requests, which
+// Azure names content_filter_results.
+func (e *InnerError) UnmarshalJSON(data []byte) error {
+	type alias InnerError
+	var decoded struct {
+		alias
+		Results *ContentFilterResults `json:"content_filter_results,omitempty"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = InnerError(decoded.alias)
+	if decoded.Results != nil {
+		e.ContentFilterResults = *decoded.Results
+	}
+	return nil
+}
+
+// ContentFilterError is returned when Azure OpenAI Service rejects a request
+// because its prompt was blocked by content filtering, as opposed to requests
+// that are malformed. It wraps the APIError of the response.
+type ContentFilterError struct {
+	// Results tells which categories the prompt was filtered for.
+	Results ContentFilterResults
+	Err     *APIError
+}
+
+func (e *ContentFilterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContentFilterError) Unwrap() error {
+	return e.Err
+}
+
+// contentFilterError wraps err in a ContentFilterError if it reports a prompt
+// blocked by content filtering.
+func contentFilterError(err *APIError) error {
+	code := err.CodeString()
+	if code != "content_filter" && code != "contentFilter" &&
+		(err.InnerError == nil || err.InnerError.Code != "ResponsibleAIPolicyViolation") {
+		return err
+	}
+	filterErr := &ContentFilterError{Err: err}
+	if err.InnerError != nil {
+		filterErr.Results = err.InnerError.ContentFilterResults
+	}
+	return filterErr
 }
 
 // RequestError provides information about generic request errors.
@@ -30,12 +114,35 @@ type RequestError struct {
 	HTTPStatusCode int
 	Err            error
 	Body           []byte
+
+	httpHeader
 }
 
 type ErrorResponse struct {
 	Error *APIError `json:"error,omitempty"`
 }
 
+// UnmarshalJSON also reads the errors the API management gateway in front of
+// Azure OpenAI Service returns, such as 401s for invalid subscription keys,
+// which have a statusCode and a message instead of an error.
+func (r *ErrorResponse) UnmarshalJSON(data []byte) error {
+	type alias ErrorResponse
+	var decoded struct {
+		alias
+		StatusCode int    `json:"statusCode"`
+		Message    string `json:"message"`
+	}
+	err := json.Unmarshal(data, &decoded)
+	*r = ErrorResponse(decoded.alias)
+	if err != nil {
+		return err
+	}
+	if r.Error == nil && decoded.StatusCode != 0 && decoded.Message != "" {
+		r.Error = &APIError{Code: APIErrorCode(strconv.Itoa(decoded.StatusCode)), Message: decoded.Message}
+	}
+	return nil
+}
+
 func (e *APIError) Error() string {
 	if e.HTTPStatusCode > 0 {
 		return fmt.Sprintf("error, status code: %d, status: %s, message: %s", e.HTTPStatusCode, e.HTTPStatus, e.Message)
@@ -78,6 +185,13 @@ func (e *APIError) UnmarshalJSON(data []byte) (err error) {
 			return
 		}
 	}
+	// image requests name it inner_error
+	if _, ok := rawMap["inner_error"]; ok && e.InnerError == nil {
+		err = json.Unmarshal(rawMap["inner_error"], &e.InnerError)
+		if err != nil {
+			return
+		}
+	}
 
 	// optional fields
 	if _, ok := rawMap["param"]; ok {
@@ -90,26 +204,134 @@ func (e *APIError) UnmarshalJSON(data []byte) (err error) {
 	if _, ok := rawMap["code"]; !ok {
 		return nil
 	}
+	return json.Unmarshal(rawMap["code"], &e.Code)
+}
 
-	// if the api returned a number, we need to force an integer
-	// since the json package defaults to float64
-	var intCode int
-	err = json.Unmarshal(rawMap["code"], &intCode)
-	if err == nil {
-		e.Code = intCode
+// APIErrorCode is the code of an APIError. OpenAI sends it as a string, and
+// some backends as a number, which is kept in its decimal form. It is empty
+// when the API sent none.
+type APIErrorCode string
+
+// UnmarshalJSON decodes a JSON string or number. Null is an empty code.
+func (c *APIErrorCode) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var code string
+		if err := json.Unmarshal(data, &code); err != nil {
+			return err
+		}
+		*c = APIErrorCode(code)
 		return nil
 	}
+	if string(data) == "null" {
+		*c = ""
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("error code is neither a string nor a number: %w", err)
+	}
+	*c = APIErrorCode(number)
+	return nil
+}
 
-	return json.Unmarshal(rawMap["code"], &e.Code)
+// Number returns the code as a number, and false if it is not one.
+func (c APIErrorCode) Number() (int, bool) {
+	n, err := strconv.Atoi(string(c))
+	return n, err == nil
+}
+
+// CodeString returns the code of the error as a string, whether the API sent it
+// as a string or as a number, or "" if it sent none.
+func (e *APIError) CodeString() string {
+	return string(e.Code)
+}
+
+// Is reports whether the error is one of ErrContextLengthExceeded,
+// ErrRateLimited, ErrInvalidAPIKey, ErrQuotaExceeded and ErrDeploymentNotFound,
+// from its code, type and HTTP status code.
+func (e *APIError) Is(target error) bool {
+	code := e.CodeString()
+	switch target {
+	case ErrContextLengthExceeded:
+		return code == "context_length_exceeded" ||
+			strings.Contains(strings.ToLower(e.Message), "maximum context length")
+	case ErrQuotaExceeded:
+		return e.quotaExceeded()
+	case ErrRateLimited:
+		if e.quotaExceeded() {
+			return false
+		}
+		return code == "rate_limit_exceeded" || e.Type == "rate_limit_exceeded" ||
+			code == "429" || e.HTTPStatusCode == http.StatusTooManyRequests
+	case ErrInvalidAPIKey:
+		return code == "invalid_api_key" || code == "401" || e.HTTPStatusCode == http.StatusUnauthorized
+	case ErrDeploymentNotFound:
+		return code == "DeploymentNotFound" || code == "model_not_found"
+	}
+	return false
+}
+
+// quotaExceeded reports whether the error is about an exhausted quota, which
+// OpenAI reports with a 429 like rate limits.
+func (e *APIError) quotaExceeded() bool {
+	switch e.CodeString() {
+	case "insufficient_quota", "InsufficientQuota", "quota_exceeded":
+		return true
+	}
+	return e.Type == "insufficient_quota"
 }
 
 func (e *RequestError) Error() string {
-	return fmt.Sprintf(
+	body := e.Body
+	if len(body) > requestErrorBodyLimit {
+		body = append(body[:requestErrorBodyLimit:requestErrorBodyLimit], "..."...)
+	}
+	msg := fmt.Sprintf(
 		"error, status code: %d, status: %s, message: %s, body: %s",
-		e.HTTPStatusCode, e.HTTPStatus, e.Err, e.Body,
+		e.HTTPStatusCode, e.HTTPStatus, e.Err, body,
 	)
+	if id := e.RequestID(); id != "" {
+		msg += ", request id: " + id
+	}
+	return msg
 }
 
 func (e *RequestError) Unwrap() error {
 	return e.Err
 }
+
+// Is reports whether the HTTP status code of the error is that of
+// ErrRateLimited or ErrInvalidAPIKey, for errors whose body could not be
+// decoded.
+func (e *RequestError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.HTTPStatusCode == http.StatusTooManyRequests
+	case ErrInvalidAPIKey:
+		return e.HTTPStatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+// IsRateLimit reports whether err, or an error it wraps, is ErrRateLimited.
+func IsRateLimit(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsInvalidAPIKey reports whether err, or an error it wraps, is
+// ErrInvalidAPIKey.
+func IsInvalidAPIKey(err error) bool {
+	return errors.Is(err, ErrInvalidAPIKey)
+}
+
+// IsQuotaExceeded reports whether err, or an error it wraps, is
+// ErrQuotaExceeded.
+func IsQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+// IsContextLengthExceeded reports whether err, or an error it wraps, is
+// ErrContextLengthExceeded.
+func IsContextLengthExceeded(err error) bool {
+	return errors.Is(err, ErrContextLengthExceeded)
+}
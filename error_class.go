@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ErrorClass is the kind of failure an error reports, as told by Classify.
+type ErrorClass string
+
+const (
+	// ErrorClassUnknown is that of nil errors and of errors that do not come
+	// from the API or the network, such as invalid requests rejected by the
+	// client.
+	ErrorClassUnknown ErrorClass = "unknown"
+	// ErrorClassCanceled is that of requests whose context was canceled.
+	ErrorClassCanceled ErrorClass = "canceled"
+
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassOverloaded is that of 503s, returned when the API cannot take
+	// more requests for the moment.
+	ErrorClassOverloaded  ErrorClass = "overloaded"
+	ErrorClassServerError ErrorClass = "server_error"
+	// ErrorClassTimeout is that of requests, and streams, that timed out,
+	// including those whose context deadline passed.
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassConnection is that of requests whose connection failed, and of
+	// streams interrupted before they ended.
+	ErrorClassConnection ErrorClass = "connection"
+	// ErrorClassConflict is that of 409s, returned for requests that conflicted
+	// with concurrent ones.
+	ErrorClassConflict ErrorClass = "conflict"
+
+	ErrorClassBadRequest      ErrorClass = "bad_request"
+	ErrorClassAuthError       ErrorClass = "auth_error"
+	ErrorClassQuotaExceeded   ErrorClass = "quota_exceeded"
+	ErrorClassContentFiltered ErrorClass = "content_filtered"
+	ErrorClassContextLength   ErrorClass = "context_length"
+)
+
+// Retryable reports whether requests failing with errors of the class can
+// succeed when retried: rate limits, overloads, server errors, timeouts,
+// connection failures and conflicts.
+func (c ErrorClass) Retryable() bool {
+	switch c {
+	case ErrorClassRateLimited, ErrorClassOverloaded, ErrorClassServerError,
+		ErrorClassTimeout, ErrorClassConnection, ErrorClassConflict:
+		return true
+	}
+	return false
+}
+
+// Classify returns the class of err, from the code, type and HTTP status code
+// of the APIError or RequestError it wraps, or else from the network or
+// context error it wraps.
+func Classify(err error) ErrorClass {
+	var (
+		filterErr *ContentFilterError
+		apiErr    *APIError
+		reqErr    *RequestError
+		netErr    net.Error
+	)
+	switch {
+	case err == nil:
+		return ErrorClassUnknown
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrStreamIdleTimeout),
+		errors.Is(err, ErrStreamConnectTimeout):
+		return ErrorClassTimeout
+	case errors.As(err, &filterErr):
+		return ErrorClassContentFiltered
+	case errors.As(err, &apiErr):
+		return classifyAPIError(apiErr)
+	case errors.As(err, &reqErr):
+		return classifyStatus(reqErr.HTTPStatusCode)
+	case errors.Is(err, ErrStreamInterrupted), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassConnection
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+	return ErrorClassUnknown
+}
+
+// IsRetryable reports whether a request failing with err can succeed when
+// retried, as the client does when configured with MaxRetries. Requests whose
+// context is done are never retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return Classify(err).Retryable()
+}
+
+func classifyAPIError(err *APIError) ErrorClass {
+	switch {
+	case err.CodeString() == "content_filter", err.CodeString() == "contentFilter":
+		return ErrorClassContentFiltered
+	case err.Is(ErrContextLengthExceeded):
+		return ErrorClassContextLength
+	case err.quotaExceeded():
+		return ErrorClassQuotaExceeded
+	case err.Is(ErrRateLimited):
+		return ErrorClassRateLimited
+	case err.Is(ErrInvalidAPIKey):
+		return ErrorClassAuthError
+	case err.HTTPStatusCode > 0:
+		return classifyStatus(err.HTTPStatusCode)
+	}
+	// Errors sent in the middle of a stream have no status code.
+	if err.Type == "server_error" {
+		return ErrorClassServerError
+	}
+	return ErrorClassUnknown
+}
+
+// classifyStatus returns the class of the failed responses with the given HTTP
+// status code.
+func classifyStatus(statusCode int) ErrorClass {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case http.StatusRequestTimeout:
+		return ErrorClassTimeout
+	case http.StatusConflict:
+		return ErrorClassConflict
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorClassAuthError
+	case http.StatusServiceUnavailable:
+		return ErrorClassOverloaded
+	}
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return ErrorClassServerError
+	case statusCode >= http.StatusBadRequest:
+		return ErrorClassBadRequest
+	}
+	return ErrorClassUnknown
+}
@@ -0,0 +1,111 @@
+package openai //nolint:testpackage // testing private errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		class     ErrorClass
+		retryable bool
+	}{
+		{"nil", nil, ErrorClassUnknown, false},
+		{"client validation", ErrCompletionUnsupportedModel, ErrorClassUnknown, false},
+		{"canceled", fmt.Errorf("sending: %w", context.Canceled), ErrorClassCanceled, false},
+		{"deadline", &url.Error{Op: "Post", Err: context.DeadlineExceeded}, ErrorClassTimeout, false},
+		{"idle timeout", ErrStreamIdleTimeout, ErrorClassTimeout, true},
+		{"network timeout", &url.Error{Op: "Post", Err: timeoutError{}}, ErrorClassTimeout, true},
+		{
+			"connection refused",
+			&url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			ErrorClassConnection,
+			true,
+		},
+		{"stream interrupted", &streamInterruptedError{cause: io.ErrUnexpectedEOF}, ErrorClassConnection, true},
+		{
+			"rate limit",
+			&APIError{Code: "rate_limit_exceeded", HTTPStatusCode: http.StatusTooManyRequests},
+			ErrorClassRateLimited,
+			true,
+		},
+		{
+			"quota",
+			&APIError{Code: "insufficient_quota", HTTPStatusCode: http.StatusTooManyRequests},
+			ErrorClassQuotaExceeded,
+			false,
+		},
+		{"overloaded", &APIError{HTTPStatusCode: http.StatusServiceUnavailable}, ErrorClassOverloaded, true},
+		{"server error", &APIError{HTTPStatusCode: http.StatusBadGateway}, ErrorClassServerError, true},
+		{"mid-stream server error", &APIError{Type: "server_error"}, ErrorClassServerError, true},
+		{"conflict", &RequestError{HTTPStatusCode: http.StatusConflict}, ErrorClassConflict, true},
+		{"request timeout", &RequestError{HTTPStatusCode: http.StatusRequestTimeout}, ErrorClassTimeout, true},
+		{"bad request", &APIError{HTTPStatusCode: http.StatusBadRequest}, ErrorClassBadRequest, false},
+		{"not found", &RequestError{HTTPStatusCode: http.StatusNotFound}, ErrorClassBadRequest, false},
+		{"auth", &APIError{Code: "invalid_api_key", HTTPStatusCode: http.StatusUnauthorized}, ErrorClassAuthError, false},
+		{"forbidden", &APIError{HTTPStatusCode: http.StatusForbidden}, ErrorClassAuthError, false},
+		{
+			"content filter",
+			&ContentFilterError{Err: &APIError{Code: "content_filter", HTTPStatusCode: http.StatusBadRequest}},
+			ErrorClassContentFiltered,
+			false,
+		},
+		{
+			"context length",
+			&APIError{Code: "context_length_exceeded", HTTPStatusCode: http.StatusBadRequest},
+			ErrorClassContextLength,
+			false,
+		},
+		{
+			"exhausted retries",
+			&RetryError{Attempts: 3, Err: &APIError{HTTPStatusCode: http.StatusInternalServerError}},
+			ErrorClassServerError,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if class := Classify(tc.err); class != tc.class {
+				t.Errorf("Classify = %s, want %s", class, tc.class)
+			}
+			if retryable := IsRetryable(tc.err); retryable != tc.retryable {
+				t.Errorf("IsRetryable = %v, want %v", retryable, tc.retryable)
+			}
+		})
+	}
+}
+
+func TestDefaultShouldRetryAgreesWithIsRetryable(t *testing.T) {
+	for status := http.StatusBadRequest; status < 600; status++ {
+		resp := &http.Response{StatusCode: status, Body: http.NoBody}
+		if DefaultShouldRetry(resp, nil) != IsRetryable(&RequestError{HTTPStatusCode: status}) {
+			t.Errorf("DefaultShouldRetry and IsRetryable disagree on %d", status)
+		}
+	}
+	err := &url.Error{Op: "Post", Err: context.Canceled}
+	if DefaultShouldRetry(nil, err) {
+		t.Error("canceled request retried")
+	}
+	// Errors of custom transports are not classified, but still retried.
+	if !DefaultShouldRetry(nil, errors.New("transport failed")) {
+		t.Error("transport error not retried")
+	}
+	if DefaultShouldRetry(nil, &APIError{HTTPStatusCode: http.StatusBadRequest}) {
+		t.Error("bad request retried")
+	}
+}
@@ -3,15 +3,46 @@ package openai
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+var (
+	// ErrFileReaderAndPath is returned by CreateFile for a request with both a
+	// Reader and a FilePath.
+	ErrFileReaderAndPath = errors.New("file request cannot have both a Reader and a FilePath")
+	// ErrFileNameRequired is returned by CreateFile for a request with a Reader
+	// but no FileName.
+	ErrFileNameRequired = errors.New("file request with a Reader needs a FileName")
+	// ErrFilePurposeRequired is returned by CreateFile for a request with a
+	// Reader but no Purpose.
+	ErrFilePurposeRequired = errors.New("file request needs a Purpose")
 )
 
+// FileRequest uploads a file, read either from the local file at FilePath or
+// from Reader.
 type FileRequest struct {
+	// FileName is the name of the uploaded file. It is required with Reader, and
+	// ignored with FilePath, whose base name is used.
 	FileName string `json:"file"`
 	FilePath string `json:"-"`
 	Purpose  string `json:"purpose"`
+	// Reader, if set, is uploaded instead of a local file, such as an object
+	// read from a bucket.
+	Reader io.Reader `json:"-"`
+	// ContentType is the Content-Type of the content of Reader. When it is empty,
+	// it is guessed from the extension of FileName.
+	ContentType string `json:"-"`
+
+	// Progress, if set, is called as the file is uploaded.
+	Progress UploadProgressFunc `json:"-"`
 }
 
 // PurposeType represents the purpose of the file when uploading.
@@ -23,6 +54,9 @@ const (
 	PurposeAssistants       PurposeType = "assistants"
 	PurposeAssistantsOutput PurposeType = "assistants_output"
 	PurposeBatch            PurposeType = "batch"
+	PurposeBatchOutput      PurposeType = "batch_output"
+	PurposeVision           PurposeType = "vision"
+	PurposeUserData         PurposeType = "user_data"
 )
 
 // FileBytesRequest represents a file upload request.
@@ -49,13 +83,55 @@ type File struct {
 	httpHeader
 }
 
-// FilesList is a list of files that belong to the user or organization.
+// Statuses of a File. Uploaded files are processed before they can be used, for
+// example by a fine-tuning job.
+const (
+	FileStatusUploaded  = "uploaded"
+	FileStatusProcessed = "processed"
+	FileStatusError     = "error"
+)
+
+// FileProcessingError is returned by WaitForFileProcessed for a file whose
+// processing failed.
+type FileProcessingError struct {
+	FileID        string
+	Status        string
+	StatusDetails string
+}
+
+func (e *FileProcessingError) Error() string {
+	if e.StatusDetails == "" {
+		return fmt.Sprintf("processing file %s failed with status %s", e.FileID, e.Status)
+	}
+	return fmt.Sprintf("processing file %s failed with status %s: %s", e.FileID, e.Status, e.StatusDetails)
+}
+
+// FilesList is a list of files that belong to the user or organization. When
+// HasMore is set, the next page is listed by passing LastID as
+// ListFilesParams.After.
 type FilesList struct {
-	Files []File `json:"data"`
+	Object  string `json:"object"`
+	Files   []File `json:"data"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
 
 	httpHeader
 }
 
+// ListFilesParams narrows and pages the files listed by ListFilesWithParams.
+// Zero values are left to the API defaults.
+type ListFilesParams struct {
+	// Purpose only lists the files with this purpose.
+	Purpose PurposeType
+	// Limit is the number of files per page, between 1 and 10000.
+	Limit int
+	// Order sorts the files by creation time, "asc" or "desc".
+	Order string
+	// After is the ID of the file after which the page starts.
+	After string
+}
+
 // CreateFileBytes uploads bytes directly to OpenAI without requiring a local file.
 func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest) (file File, err error) {
 	var b bytes.Buffer
@@ -88,14 +164,12 @@ func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest)
 }
 
 // CreateFile uploads a jsonl file to GPT3
-// FilePath must be a local file path.
+// FilePath must be a local file path, unless the content is given by Reader. Files
+// and large readers are streamed to the API while the request is sent, so they
+// are never held in memory.
 func (c *Client) CreateFile(ctx context.Context, request FileRequest) (file File, err error) {
-	var b bytes.Buffer
-	builder := c.createFormBuilder(&b)
-
-	err = builder.WriteField("purpose", request.Purpose)
-	if err != nil {
-		return
+	if request.Reader != nil {
+		return c.createFileFromReader(ctx, request)
 	}
 
 	fileData, err := os.Open(request.FilePath)
@@ -104,23 +178,43 @@ func (c *Client) CreateFile(ctx context.Context, request FileRequest) (file File
 	}
 	defer fileData.Close()
 
-	err = builder.CreateFormFile("file", fileData)
-	if err != nil {
-		return
-	}
+	err = c.sendMultipartRequest(ctx, c.fullURL("/files"), true, request.Progress, func(builder utils.FormBuilder) error {
+		if err := builder.WriteField("purpose", request.Purpose); err != nil {
+			return err
+		}
+		if err := builder.CreateFormFile("file", fileData); err != nil {
+			return err
+		}
+		return builder.Close()
+	}, &file)
+	return
+}
 
-	err = builder.Close()
-	if err != nil {
+// createFileFromReader uploads the content of request.Reader.
+func (c *Client) createFileFromReader(ctx context.Context, request FileRequest) (response File, err error) {
+	switch {
+	case request.FilePath != "":
+		err = ErrFileReaderAndPath
 		return
-	}
-
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"),
-		withBody(&b), withContentType(builder.FormDataContentType()))
-	if err != nil {
+	case request.FileName == "":
+		err = ErrFileNameRequired
+		return
+	case request.Purpose == "":
+		err = ErrFilePurposeRequired
 		return
 	}
 
-	err = c.sendRequest(req, &file)
+	content := file{Reader: request.Reader, name: request.FileName, contentType: request.ContentType}
+	err = c.sendMultipartRequest(ctx, c.fullURL("/files"), shouldStreamUpload(content), request.Progress,
+		func(builder utils.FormBuilder) error {
+			if err := builder.WriteField("purpose", request.Purpose); err != nil {
+				return err
+			}
+			if err := builder.CreateFormFileReader("file", content, request.FileName); err != nil {
+				return err
+			}
+			return builder.Close()
+		}, &response)
 	return
 }
 
@@ -138,7 +232,26 @@ func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
 // ListFiles Lists the currently available files,
 // and provides basic information about each file such as the file name and purpose.
 func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/files"))
+	return c.ListFilesWithParams(ctx, ListFilesParams{})
+}
+
+// ListFilesWithParams lists a page of the files matching params.
+func (c *Client) ListFilesWithParams(ctx context.Context, params ListFilesParams) (files FilesList, err error) {
+	urlValues := url.Values{}
+	if params.Purpose != "" {
+		urlValues.Add("purpose", string(params.Purpose))
+	}
+	if params.Limit > 0 {
+		urlValues.Add("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Order != "" {
+		urlValues.Add("order", params.Order)
+	}
+	if params.After != "" {
+		urlValues.Add("after", params.After)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/files"+encodeQuery(urlValues)))
 	if err != nil {
 		return
 	}
@@ -147,6 +260,20 @@ func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
 	return
 }
 
+// ListFilesAll lists all the files matching params, following the pages of
+// ListFilesWithParams from params.After on.
+func (c *Client) ListFilesAll(ctx context.Context, params ListFilesParams) ([]File, error) {
+	return newListIterator(ctx, params.After, func(ctx context.Context, after string) ([]File, string, bool, error) {
+		params.After = after
+		page, err := c.ListFilesWithParams(ctx, params)
+		next := page.LastID
+		if next == "" {
+			next = lastID(page.Files, func(file File) string { return file.ID })
+		}
+		return page.Files, next, page.HasMore, err
+	}).all()
+}
+
 // GetFile Retrieves a file instance, providing basic information about the file
 // such as the file name and purpose.
 func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err error) {
@@ -160,6 +287,31 @@ func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err err
 	return
 }
 
+// WaitForFileProcessed polls a file with GetFile until it is processed, and
+// returns it. A file whose processing fails is returned with a
+// *FileProcessingError. On other errors, the last polled file is returned.
+func (c *Client) WaitForFileProcessed(ctx context.Context, fileID string, opts PollOptions) (file File, err error) {
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.GetFile(ctx, fileID)
+		if err != nil {
+			return false, err
+		}
+		file = polled
+		switch file.Status {
+		case FileStatusProcessed:
+			return true, nil
+		case FileStatusError:
+			return true, &FileProcessingError{FileID: fileID, Status: file.Status, StatusDetails: file.StatusDetails}
+		}
+		return false, nil
+	})
+	return
+}
+
+// GetFileContent returns the content of a file, such as the output of a batch
+// or the results of a fine-tuning job. The content is read from the response as
+// it is received, and has to be closed. Errors, such as for files that cannot be
+// downloaded, are returned as an APIError.
 func (c *Client) GetFileContent(ctx context.Context, fileID string) (content RawResponse, err error) {
 	urlSuffix := fmt.Sprintf("/files/%s/content", fileID)
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
@@ -169,3 +321,15 @@ func (c *Client) GetFileContent(ctx context.Context, fileID string) (content Raw
 
 	return c.sendRequestRaw(req)
 }
+
+// DownloadFile copies the content of a file to w as it is received, and returns
+// the number of bytes written.
+func (c *Client) DownloadFile(ctx context.Context, fileID string, w io.Writer) (int64, error) {
+	content, err := c.GetFileContent(ctx, fileID)
+	if err != nil {
+		return 0, err
+	}
+	defer content.Close()
+
+	return io.Copy(w, content)
+}
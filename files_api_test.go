@@ -8,7 +8,9 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -102,6 +104,73 @@ func TestListFile(t *testing.T) {
 	checks.NoError(t, err, "ListFiles error")
 }
 
+func TestListFilesWithParams(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("purpose") != "batch" || query.Get("limit") != "2" ||
+			query.Get("order") != "asc" || query.Get("after") != "file-1" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"file-2"},{"id":"file-3"}],`+
+			`"first_id":"file-2","last_id":"file-3","has_more":true}`)
+	})
+	files, err := client.ListFilesWithParams(context.Background(), openai.ListFilesParams{
+		Purpose: openai.PurposeBatch,
+		Limit:   2,
+		Order:   "asc",
+		After:   "file-1",
+	})
+	checks.NoError(t, err, "ListFilesWithParams error")
+	if len(files.Files) != 2 || files.LastID != "file-3" || !files.HasMore {
+		t.Errorf("unexpected files list %+v", files)
+	}
+}
+
+func TestListFilesAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	pages := map[string]string{
+		"":       `{"data":[{"id":"file-1"},{"id":"file-2"}],"last_id":"file-2","has_more":true}`,
+		"file-2": `{"data":[{"id":"file-3"},{"id":"file-4"}],"last_id":"file-4","has_more":true}`,
+		"file-4": `{"data":[{"id":"file-5"}],"last_id":"file-5","has_more":false}`,
+	}
+	requests := 0
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, ok := pages[r.URL.Query().Get("after")]
+		if !ok || r.URL.Query().Get("purpose") != "vision" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	})
+	files, err := client.ListFilesAll(context.Background(), openai.ListFilesParams{Purpose: openai.PurposeVision})
+	checks.NoError(t, err, "ListFilesAll error")
+	if len(files) != 5 || files[4].ID != "file-5" || requests != 3 {
+		t.Errorf("got %d files in %d requests, want 5 in 3", len(files), requests)
+	}
+}
+
+func TestListFilesAllError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprint(w, `{"data":[{"id":"file-1"}],"last_id":"file-1","has_more":true}`)
+			return
+		}
+		http.Error(w, `{"error":{"message":"server error"}}`, http.StatusBadRequest)
+	})
+	files, err := client.ListFilesAll(context.Background(), openai.ListFilesParams{})
+	checks.HasError(t, err, "ListFilesAll should fail")
+	if len(files) != 1 {
+		t.Errorf("got %d files, want the first page", len(files))
+	}
+}
+
 func TestGetFile(t *testing.T) {
 	client, server, teardown := setupOpenAITestServer()
 	defer teardown()
@@ -194,3 +263,240 @@ func TestGetFileContentReturnTimeoutError(t *testing.T) {
 		t.Fatal("Did not return timeout error")
 	}
 }
+
+// maxWriteRecorder counts the bytes written to it and the size of the largest
+// write, without keeping them.
+type maxWriteRecorder struct {
+	written  int64
+	maxWrite int
+}
+
+func (w *maxWriteRecorder) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if len(p) > w.maxWrite {
+		w.maxWrite = len(p)
+	}
+	return len(p), nil
+}
+
+func TestGetFileContentStreams(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	release := make(chan struct{})
+	server.RegisterHandler("/v1/files/deadbeef/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "first,")
+		w.(http.Flusher).Flush()
+		<-release
+		fmt.Fprint(w, "second")
+	})
+
+	// The content is returned before the server has sent all of it.
+	content, err := client.GetFileContent(context.Background(), "deadbeef")
+	checks.NoError(t, err, "GetFileContent error")
+	defer content.Close()
+	first := make([]byte, len("first,"))
+	_, err = io.ReadFull(content, first)
+	checks.NoError(t, err, "ReadFull error")
+	close(release)
+
+	rest, err := io.ReadAll(content)
+	checks.NoError(t, err, "ReadAll error")
+	if string(first)+string(rest) != "first,second" {
+		t.Errorf("unexpected content %q", string(first)+string(rest))
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	const size = 8 << 20
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-batch/content", func(w http.ResponseWriter, _ *http.Request) {
+		chunk := make([]byte, 64<<10)
+		for sent := 0; sent < size; sent += len(chunk) {
+			_, _ = w.Write(chunk)
+		}
+	})
+
+	w := &maxWriteRecorder{}
+	n, err := client.DownloadFile(context.Background(), "file-batch", w)
+	checks.NoError(t, err, "DownloadFile error")
+	if n != size || w.written != size {
+		t.Errorf("wrote %d bytes, reported %d, want %d", w.written, n, size)
+	}
+	// The content is copied in small pieces rather than read into memory first.
+	if w.maxWrite > 1<<20 {
+		t.Errorf("content was buffered: largest write was %d bytes", w.maxWrite)
+	}
+}
+
+func TestDownloadFileNotFound(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-missing/content", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"No such File object: file-missing","type":"invalid_request_error"}}`)
+	})
+
+	w := &maxWriteRecorder{}
+	n, err := client.DownloadFile(context.Background(), "file-missing", w)
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 APIError, got %v", err)
+	}
+	if n != 0 || w.written != 0 {
+		t.Errorf("the error was written as content: %d bytes", w.written)
+	}
+}
+
+func TestFileUploadFromReader(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	type part struct {
+		disposition, contentType, content string
+	}
+	var parts []part
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			p, err := reader.NextRawPart()
+			if err != nil {
+				break
+			}
+			content, _ := io.ReadAll(p)
+			parts = append(parts, part{p.Header.Get("Content-Disposition"), p.Header.Get("Content-Type"), string(content)})
+		}
+		fmt.Fprint(w, `{"id":"file-1","object":"file","filename":"batch.jsonl","purpose":"batch"}`)
+	})
+
+	testCases := []struct {
+		name        string
+		contentType string
+		fileName    string
+		want        string
+	}{
+		{"explicit content type", "application/x-ndjson", "batch.jsonl", "application/x-ndjson"},
+		{"content type from the extension", "", "batch.jsonl", "application/jsonl"},
+		{"unknown content type", "", "batch", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts = nil
+			file, err := client.CreateFile(context.Background(), openai.FileRequest{
+				Reader:      strings.NewReader(`{"custom_id":"1"}`),
+				FileName:    tc.fileName,
+				ContentType: tc.contentType,
+				Purpose:     string(openai.PurposeBatch),
+			})
+			checks.NoError(t, err, "CreateFile error")
+			if file.ID != "file-1" {
+				t.Errorf("unexpected file %+v", file)
+			}
+
+			want := []part{
+				{`form-data; name="purpose"`, "", "batch"},
+				{`form-data; name="file"; filename="` + tc.fileName + `"`, tc.want, `{"custom_id":"1"}`},
+			}
+			if !reflect.DeepEqual(parts, want) {
+				t.Errorf("got parts %q, want %q", parts, want)
+			}
+		})
+	}
+}
+
+func TestFileUploadFromReaderValidation(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	testCases := []struct {
+		name    string
+		request openai.FileRequest
+		want    error
+	}{
+		{"reader and path", openai.FileRequest{
+			Reader: strings.NewReader("x"), FilePath: "client.go", FileName: "a.jsonl", Purpose: "batch",
+		}, openai.ErrFileReaderAndPath},
+		{"no file name", openai.FileRequest{
+			Reader: strings.NewReader("x"), Purpose: "batch",
+		}, openai.ErrFileNameRequired},
+		{"no purpose", openai.FileRequest{
+			Reader: strings.NewReader("x"), FileName: "a.jsonl",
+		}, openai.ErrFilePurposeRequired},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateFile(context.Background(), tc.request)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestWaitForFileProcessed(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	statuses := []string{openai.FileStatusUploaded, openai.FileStatusUploaded, openai.FileStatusProcessed}
+	polls := 0
+	server.RegisterHandler("/v1/files/file-1", func(w http.ResponseWriter, _ *http.Request) {
+		status := statuses[polls]
+		polls++
+		fmt.Fprintf(w, `{"id":"file-1","object":"file","status":%q}`, status)
+	})
+
+	file, err := client.WaitForFileProcessed(context.Background(), "file-1",
+		openai.PollOptions{Interval: time.Millisecond})
+	checks.NoError(t, err, "WaitForFileProcessed error")
+	if file.Status != openai.FileStatusProcessed || polls != 3 {
+		t.Errorf("got status %q after %d polls, want processed after 3", file.Status, polls)
+	}
+}
+
+func TestWaitForFileProcessedError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-1", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"file-1","object":"file","status":"error",`+
+			`"status_details":"Invalid file format for Fine-Tuning API."}`)
+	})
+
+	file, err := client.WaitForFileProcessed(context.Background(), "file-1", openai.PollOptions{})
+	var processingErr *openai.FileProcessingError
+	if !errors.As(err, &processingErr) {
+		t.Fatalf("expected a FileProcessingError, got %v", err)
+	}
+	if processingErr.StatusDetails != "Invalid file format for Fine-Tuning API." || processingErr.FileID != "file-1" {
+		t.Errorf("unexpected error %+v", processingErr)
+	}
+	if file.Status != openai.FileStatusError {
+		t.Errorf("the failed file was not returned: %+v", file)
+	}
+}
+
+func TestWaitForFileProcessedTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-1", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"file-1","object":"file","status":"uploaded"}`)
+	})
+
+	file, err := client.WaitForFileProcessed(context.Background(), "file-1",
+		openai.PollOptions{Interval: time.Millisecond, MaxWait: 20 * time.Millisecond})
+	if !errors.Is(err, openai.ErrPollTimeout) {
+		t.Fatalf("expected ErrPollTimeout, got %v", err)
+	}
+	if file.Status != openai.FileStatusUploaded {
+		t.Errorf("the last polled file was not returned: %+v", file)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = client.WaitForFileProcessed(ctx, "file-1", openai.PollOptions{Interval: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
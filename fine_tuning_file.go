@@ -0,0 +1,288 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	// MinFineTuningExamples is the number of examples a training file needs.
+	// ValidateFineTuningFile warns about files with fewer.
+	MinFineTuningExamples = 10
+	// DefaultFineTuningMaxExampleTokens is the default number of tokens above
+	// which ValidateFineTuningFile warns that an example will be truncated.
+	DefaultFineTuningMaxExampleTokens = 65536
+)
+
+// Tokens added by the chat format to every message and to every example.
+const (
+	fineTuningTokensPerMessage = 3
+	fineTuningTokensPerExample = 3
+)
+
+var (
+	fineTuningExampleKeys = map[string]bool{
+		"messages": true, "tools": true, "functions": true, "parallel_tool_calls": true,
+	}
+	fineTuningMessageKeys = map[string]bool{
+		"role": true, "content": true, "name": true, "weight": true,
+		"tool_calls": true, "tool_call_id": true, "function_call": true,
+	}
+	fineTuningRoles = map[string]bool{
+		ChatMessageRoleSystem: true, ChatMessageRoleDeveloper: true, ChatMessageRoleUser: true,
+		ChatMessageRoleAssistant: true, ChatMessageRoleTool: true, ChatMessageRoleFunction: true,
+	}
+)
+
+// FineTuningChatFormat configures how ValidateFineTuningFile checks a training
+// file in the chat format, with one example of messages per line.
+type FineTuningChatFormat struct {
+	// TokenEstimator estimates the tokens of the examples. DefaultTokenEstimator
+	// is used when it is nil.
+	TokenEstimator TokenEstimator
+	// MaxExampleTokens is the number of tokens above which an example is
+	// reported as truncated. DefaultFineTuningMaxExampleTokens is used when it is
+	// zero.
+	MaxExampleTokens int
+}
+
+// FineTuningFileIssue is a problem found in a training file. Line is the
+// 1-based line of the example, or 0 for problems with the whole file.
+type FineTuningFileIssue struct {
+	Line    int
+	Message string
+}
+
+func (i FineTuningFileIssue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// FineTuningFileReport is the result of ValidateFineTuningFile. Files with
+// errors are rejected by the API, while warnings point at files that are
+// accepted but likely to train poorly.
+type FineTuningFileReport struct {
+	// Examples is the number of examples in the file, including invalid ones.
+	Examples int
+	// ExampleTokens are the estimated tokens of each example, in file order.
+	// Invalid examples count as 0.
+	ExampleTokens []int
+	// Tokens is the estimated number of tokens of the whole file per epoch.
+	Tokens   int
+	Errors   []FineTuningFileIssue
+	Warnings []FineTuningFileIssue
+}
+
+// Valid reports whether the file has no errors.
+func (r FineTuningFileReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidateFineTuningFile checks a JSONL training file before it is uploaded,
+// streaming it line by line. Every problem is reported with its line in the
+// returned report, and the error is only set when r cannot be read.
+func ValidateFineTuningFile(r io.Reader, format FineTuningChatFormat) (FineTuningFileReport, error) {
+	if format.TokenEstimator == nil {
+		format.TokenEstimator = DefaultTokenEstimator
+	}
+	if format.MaxExampleTokens <= 0 {
+		format.MaxExampleTokens = DefaultFineTuningMaxExampleTokens
+	}
+
+	var report FineTuningFileReport
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return report, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			report.addExample(line, data, format)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if report.Examples < MinFineTuningExamples {
+		report.Warnings = append(report.Warnings, FineTuningFileIssue{
+			Message: fmt.Sprintf("the file has %d examples, at least %d are required",
+				report.Examples, MinFineTuningExamples),
+		})
+	}
+	return report, nil
+}
+
+func (r *FineTuningFileReport) addExample(line int, data []byte, format FineTuningChatFormat) {
+	r.Examples++
+	problems, tokens := checkFineTuningExample(data, format.TokenEstimator)
+	for _, problem := range problems {
+		r.Errors = append(r.Errors, FineTuningFileIssue{Line: line, Message: problem})
+	}
+	if len(problems) > 0 {
+		tokens = 0
+	}
+	r.ExampleTokens = append(r.ExampleTokens, tokens)
+	r.Tokens += tokens
+	if tokens > format.MaxExampleTokens {
+		r.Warnings = append(r.Warnings, FineTuningFileIssue{
+			Line: line,
+			Message: fmt.Sprintf("the example has about %d tokens and will be truncated to %d",
+				tokens, format.MaxExampleTokens),
+		})
+	}
+}
+
+// checkFineTuningExample returns the problems of an example and its estimated
+// tokens.
+func checkFineTuningExample(data []byte, estimate TokenEstimator) (problems []string, tokens int) {
+	var example map[string]json.RawMessage
+	if err := json.Unmarshal(data, &example); err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %v", err)}, 0
+	}
+	problems = unrecognizedFineTuningKeys(example, fineTuningExampleKeys)
+
+	if tools, ok := example["tools"]; ok {
+		problems = append(problems, checkFineTuningTools(tools)...)
+		tokens += estimate(string(tools))
+	}
+	if functions, ok := example["functions"]; ok {
+		tokens += estimate(string(functions))
+	}
+
+	var messages []map[string]json.RawMessage
+	if raw, ok := example["messages"]; !ok {
+		return append(problems, "missing messages"), 0
+	} else if err := json.Unmarshal(raw, &messages); err != nil || len(messages) == 0 {
+		return append(problems, "messages must be a non-empty array of objects"), 0
+	}
+
+	hasAssistant := false
+	tokens += fineTuningTokensPerExample
+	for i, message := range messages {
+		role, messageProblems, messageTokens := checkFineTuningMessage(message, estimate)
+		for _, problem := range messageProblems {
+			problems = append(problems, fmt.Sprintf("message %d: %s", i, problem))
+		}
+		hasAssistant = hasAssistant || role == ChatMessageRoleAssistant
+		tokens += messageTokens
+	}
+	if !hasAssistant {
+		problems = append(problems, "missing assistant message")
+	}
+	return problems, tokens
+}
+
+// unrecognizedFineTuningKeys reports the keys of object missing from known, in
+// sorted order.
+func unrecognizedFineTuningKeys(object map[string]json.RawMessage, known map[string]bool) (problems []string) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		problems = append(problems, fmt.Sprintf("unrecognized key %q", key))
+	}
+	return problems
+}
+
+func checkFineTuningTools(data json.RawMessage) (problems []string) {
+	var tools []Tool
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return []string{fmt.Sprintf("invalid tools: %v", err)}
+	}
+	for i, tool := range tools {
+		if tool.Type != ToolTypeFunction || tool.Function == nil || tool.Function.Name == "" {
+			problems = append(problems, fmt.Sprintf("tool %d must be a function with a name", i))
+		}
+	}
+	return problems
+}
+
+// checkFineTuningMessage returns the role of a message, its problems and its
+// estimated tokens.
+func checkFineTuningMessage(
+	message map[string]json.RawMessage,
+	estimate TokenEstimator,
+) (role string, problems []string, tokens int) {
+	problems = unrecognizedFineTuningKeys(message, fineTuningMessageKeys)
+
+	if raw, ok := message["role"]; !ok {
+		problems = append(problems, "missing role")
+	} else if err := json.Unmarshal(raw, &role); err != nil || !fineTuningRoles[role] {
+		problems = append(problems, fmt.Sprintf("unrecognized role %s", raw))
+	}
+	tokens = fineTuningTokensPerMessage + estimate(role)
+	if name, ok := message["name"]; ok {
+		tokens += estimate(string(name))
+	}
+
+	content, hasContent, ok := fineTuningMessageContent(message["content"])
+	if !ok {
+		problems = append(problems, "content must be a string or an array of content parts")
+	}
+	tokens += estimate(content)
+
+	_, hasToolCalls := message["tool_calls"]
+	_, hasFunctionCall := message["function_call"]
+	isAssistant := role == ChatMessageRoleAssistant
+	switch {
+	case !hasContent && !(isAssistant && (hasToolCalls || hasFunctionCall)):
+		problems = append(problems, "missing content")
+	case (hasToolCalls || hasFunctionCall) && !isAssistant:
+		problems = append(problems, "only assistant messages can call tools")
+	}
+	if hasToolCalls {
+		var calls []ToolCall
+		if err := json.Unmarshal(message["tool_calls"], &calls); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid tool calls: %v", err))
+		}
+		tokens += estimate(string(message["tool_calls"]))
+	}
+	if hasFunctionCall {
+		tokens += estimate(string(message["function_call"]))
+	}
+	if _, ok := message["tool_call_id"]; ok != (role == ChatMessageRoleTool) {
+		problems = append(problems, "tool_call_id must be set on tool messages only")
+	}
+
+	if raw, ok := message["weight"]; ok {
+		var weight int
+		switch {
+		case !isAssistant:
+			problems = append(problems, "weight can only be set on assistant messages")
+		case json.Unmarshal(raw, &weight) != nil || (weight != 0 && weight != 1):
+			problems = append(problems, fmt.Sprintf("weight must be 0 or 1, got %s", raw))
+		}
+	}
+	return role, problems, tokens
+}
+
+// fineTuningMessageContent returns the text of the content of a message, which
+// is a string, an array of content parts or null, and whether it has any.
+func fineTuningMessageContent(data json.RawMessage) (text string, hasContent, ok bool) {
+	if len(data) == 0 || string(data) == "null" {
+		return "", false, true
+	}
+	if err := json.Unmarshal(data, &text); err == nil {
+		return text, text != "", true
+	}
+	var parts []ChatMessagePart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return "", false, false
+	}
+	for _, part := range parts {
+		text += part.Text
+	}
+	return text, len(parts) > 0, true
+}
@@ -0,0 +1,92 @@
+package openai_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const validFineTuningExample = `{"messages":[{"role":"system","content":"Marv is a chatbot."},` +
+	`{"role":"user","content":"What's the capital of France?"},` +
+	`{"role":"assistant","content":"Paris, as if everyone doesn't know that already.","weight":1}]}`
+
+func TestValidateFineTuningFile(t *testing.T) {
+	lines := []string{
+		validFineTuningExample,
+		`{"messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello","weight":2}]}`,
+		`not json`,
+		``,
+		`{"messages":[{"role":"robot","content":"Hi"},{"role":"user","content":"Hi","weight":0}]}`,
+		`{"prompt":"Hi","completion":"Hello"}`,
+		`{"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}}],` +
+			`"messages":[{"role":"user","content":[{"type":"text","text":"Weather?"}]},` +
+			`{"role":"assistant","tool_calls":[{"id":"call_1","type":"function",` +
+			`"function":{"name":"get_weather","arguments":"{}"}}]},` +
+			`{"role":"tool","tool_call_id":"call_1","content":"Sunny"},` +
+			`{"role":"assistant","content":"It is sunny."}]}`,
+		`{"messages":[{"role":"user","content":"Hi","tool_call_id":"call_1"},{"role":"assistant","content":null}]}`,
+	}
+	report, err := openai.ValidateFineTuningFile(strings.NewReader(strings.Join(lines, "\n")),
+		openai.FineTuningChatFormat{})
+	checks.NoError(t, err, "ValidateFineTuningFile error")
+
+	var got []string
+	for _, issue := range report.Errors {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"line 2: message 1: weight must be 0 or 1, got 2",
+		"line 3: invalid JSON: invalid character 'o' in literal null (expecting 'u')",
+		`line 5: message 0: unrecognized role "robot"`,
+		"line 5: message 1: weight can only be set on assistant messages",
+		"line 5: missing assistant message",
+		`line 6: unrecognized key "completion"`,
+		`line 6: unrecognized key "prompt"`,
+		"line 6: missing messages",
+		"line 8: message 0: tool_call_id must be set on tool messages only",
+		"line 8: message 1: missing content",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.Valid() || report.Examples != 7 || len(report.ExampleTokens) != 7 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.ExampleTokens[0] == 0 || report.ExampleTokens[5] == 0 || report.ExampleTokens[1] != 0 {
+		t.Errorf("unexpected example tokens %v", report.ExampleTokens)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Line != 0 {
+		t.Errorf("expected a warning about the number of examples, got %v", report.Warnings)
+	}
+}
+
+func TestValidateFineTuningFileTokens(t *testing.T) {
+	file := strings.Repeat(validFineTuningExample+"\n", openai.MinFineTuningExamples)
+	words := func(text string) int { return len(strings.Fields(text)) }
+	report, err := openai.ValidateFineTuningFile(strings.NewReader(file), openai.FineTuningChatFormat{
+		TokenEstimator:   words,
+		MaxExampleTokens: 20,
+	})
+	checks.NoError(t, err, "ValidateFineTuningFile error")
+	if !report.Valid() || report.Examples != openai.MinFineTuningExamples {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	// 3 tokens per example, then 3 per message with its role and content words.
+	const exampleTokens = 3 + (3 + 1 + 4) + (3 + 1 + 5) + (3 + 1 + 8)
+	if report.ExampleTokens[0] != exampleTokens || report.Tokens != exampleTokens*openai.MinFineTuningExamples {
+		t.Errorf("got %d tokens per example and %d in total", report.ExampleTokens[0], report.Tokens)
+	}
+	if len(report.Warnings) != openai.MinFineTuningExamples || report.Warnings[9].Line != 10 {
+		t.Errorf("expected every example to be truncated, got %v", report.Warnings)
+	}
+}
+
+func TestValidateFineTuningFileReadError(t *testing.T) {
+	readErr := errors.New("read failed")
+	_, err := openai.ValidateFineTuningFile(iotest.ErrReader(readErr), openai.FineTuningChatFormat{})
+	checks.ErrorIs(t, err, readErr, "the read error should be returned")
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -21,10 +22,43 @@ type FineTuningJob struct {
 	ValidationFile  string          `json:"validation_file,omitempty"`
 	ResultFiles     []string        `json:"result_files"`
 	TrainedTokens   int             `json:"trained_tokens"`
+	// EstimatedFinish is the Unix time at which the job is estimated to finish,
+	// while it is running.
+	EstimatedFinish int64                   `json:"estimated_finish,omitempty"`
+	Seed            int                     `json:"seed"`
+	Integrations    []FineTuningIntegration `json:"integrations,omitempty"`
+	// Method is the fine-tuning method of the job, with its hyperparameters.
+	Method *FineTuningMethod `json:"method,omitempty"`
+	// Error describes why the job failed, for failed jobs.
+	Error *FineTuningJobError `json:"error,omitempty"`
 
 	httpHeader
 }
 
+// Statuses of a FineTuningJob. Succeeded, failed and cancelled jobs are done.
+const (
+	FineTuningJobStatusValidatingFiles = "validating_files"
+	FineTuningJobStatusQueued          = "queued"
+	FineTuningJobStatusRunning         = "running"
+	FineTuningJobStatusSucceeded       = "succeeded"
+	FineTuningJobStatusFailed          = "failed"
+	FineTuningJobStatusCancelled       = "cancelled"
+)
+
+// FineTuningJobError is the reason a fine-tuning job failed. It is returned by
+// WaitForFineTuningJob for failed jobs.
+type FineTuningJobError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param,omitempty"`
+}
+
+func (e *FineTuningJobError) Error() string {
+	return fmt.Sprintf("fine-tuning job failed: %s (code: %s)", e.Message, e.Code)
+}
+
+// Hyperparameters are the legacy hyperparameters of a fine-tuning job, which are
+// set with Method instead for new jobs. Each value is "auto" or a number.
 type Hyperparameters struct {
 	Epochs                 any `json:"n_epochs,omitempty"`
 	LearningRateMultiplier any `json:"learning_rate_multiplier,omitempty"`
@@ -32,17 +66,66 @@ type Hyperparameters struct {
 }
 
 type FineTuningJobRequest struct {
-	TrainingFile    string           `json:"training_file"`
-	ValidationFile  string           `json:"validation_file,omitempty"`
-	Model           string           `json:"model,omitempty"`
+	TrainingFile   string `json:"training_file"`
+	ValidationFile string `json:"validation_file,omitempty"`
+	Model          string `json:"model,omitempty"`
+	// Hyperparameters are the legacy way of setting the hyperparameters of a
+	// supervised job. They cannot be combined with Method.
 	Hyperparameters *Hyperparameters `json:"hyperparameters,omitempty"`
 	Suffix          string           `json:"suffix,omitempty"`
+	// Method is the fine-tuning method and its hyperparameters. Jobs without one
+	// are supervised.
+	Method *FineTuningMethod `json:"method,omitempty"`
+	// Seed makes the job reproducible. The API picks one when it is not set.
+	Seed *int `json:"seed,omitempty"`
+	// Integrations report the progress of the job to other services.
+	Integrations []FineTuningIntegration `json:"integrations,omitempty"`
+}
+
+// FineTuningIntegrationTypeWandb is the type of Weights & Biases integrations.
+const FineTuningIntegrationTypeWandb = "wandb"
+
+// ErrFineTuningIntegrationInvalid is returned for fine-tuning integrations
+// without the configuration their type requires.
+var ErrFineTuningIntegrationInvalid = errors.New("invalid fine-tuning integration")
+
+// FineTuningIntegration is an integration of a fine-tuning job with another
+// service. Only Weights & Biases is supported, with type "wandb".
+type FineTuningIntegration struct {
+	Type  string            `json:"type"`
+	Wandb *WandbIntegration `json:"wandb,omitempty"`
+}
+
+// WandbIntegration reports the metrics of a fine-tuning job to a Weights &
+// Biases project.
+type WandbIntegration struct {
+	// Project is the project the run is created in. It is required.
+	Project string `json:"project"`
+	// Name is the display name of the run. It defaults to the job ID.
+	Name string `json:"name,omitempty"`
+	// Entity is the team or user the run is created for, or the default entity
+	// of the API key when empty.
+	Entity string `json:"entity,omitempty"`
+	// Tags are added to the run, along with the tags set by OpenAI.
+	Tags []string `json:"tags,omitempty"`
 }
 
+func (r FineTuningJobRequest) validate() error {
+	for i, integration := range r.Integrations {
+		if integration.Type == FineTuningIntegrationTypeWandb &&
+			(integration.Wandb == nil || integration.Wandb.Project == "") {
+			return fmt.Errorf("%w: integration %d needs a wandb project", ErrFineTuningIntegrationInvalid, i)
+		}
+	}
+	return r.validateMethod()
+}
+
+// FineTuningJobEventList is a page of the events of a fine-tuning job, newest
+// first. When HasMore is set, the older events follow the ID of the last one.
 type FineTuningJobEventList struct {
-	Object  string          `json:"object"`
-	Data    []FineTuneEvent `json:"data"`
-	HasMore bool            `json:"has_more"`
+	Object  string               `json:"object"`
+	Data    []FineTuningJobEvent `json:"data"`
+	HasMore bool                 `json:"has_more"`
 
 	httpHeader
 }
@@ -50,7 +133,7 @@ type FineTuningJobEventList struct {
 type FineTuningJobEvent struct {
 	Object    string `json:"object"`
 	ID        string `json:"id"`
-	CreatedAt int    `json:"created_at"`
+	CreatedAt int64  `json:"created_at"`
 	Level     string `json:"level"`
 	Message   string `json:"message"`
 	Data      any    `json:"data"`
@@ -62,6 +145,10 @@ func (c *Client) CreateFineTuningJob(
 	ctx context.Context,
 	request FineTuningJobRequest,
 ) (response FineTuningJob, err error) {
+	if err = request.validate(); err != nil {
+		return
+	}
+
 	urlSuffix := "/fine_tuning/jobs"
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request))
 	if err != nil {
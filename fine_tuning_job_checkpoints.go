@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// FineTuningJobCheckpoint is a model checkpoint saved at a step of a fine-tuning
+// job. Its FineTunedModelCheckpoint can be used as the model of chat completions.
+type FineTuningJobCheckpoint struct {
+	ID                       string               `json:"id"`
+	Object                   string               `json:"object"`
+	CreatedAt                int64                `json:"created_at"`
+	FineTunedModelCheckpoint string               `json:"fine_tuned_model_checkpoint"`
+	FineTuningJobID          string               `json:"fine_tuning_job_id"`
+	StepNumber               int                  `json:"step_number"`
+	Metrics                  FineTuningJobMetrics `json:"metrics"`
+}
+
+// validLoss returns the validation loss of the checkpoint, computed on the full
+// validation file when available.
+func (c FineTuningJobCheckpoint) validLoss() (float64, bool) {
+	switch {
+	case c.Metrics.FullValidLoss != nil:
+		return *c.Metrics.FullValidLoss, true
+	case c.Metrics.ValidLoss != nil:
+		return *c.Metrics.ValidLoss, true
+	}
+	return 0, false
+}
+
+// FineTuningJobCheckpointList is a page of the checkpoints of a fine-tuning job.
+// When HasMore is set, the next page is listed by passing LastID as
+// ListFineTuningJobCheckpointsParams.After.
+type FineTuningJobCheckpointList struct {
+	Object  string                    `json:"object"`
+	Data    []FineTuningJobCheckpoint `json:"data"`
+	FirstID string                    `json:"first_id"`
+	LastID  string                    `json:"last_id"`
+	HasMore bool                      `json:"has_more"`
+
+	httpHeader
+}
+
+// ListFineTuningJobCheckpointsParams pages the checkpoints listed by
+// ListFineTuningJobCheckpoints. Zero values are left to the API defaults.
+type ListFineTuningJobCheckpointsParams struct {
+	// Limit is the number of checkpoints per page.
+	Limit int
+	// After is the ID of the checkpoint after which the page starts.
+	After string
+}
+
+// ListFineTuningJobCheckpoints lists a page of the checkpoints of a fine-tuning
+// job, newest first.
+func (c *Client) ListFineTuningJobCheckpoints(
+	ctx context.Context,
+	fineTuningJobID string,
+	params ListFineTuningJobCheckpointsParams,
+) (response FineTuningJobCheckpointList, err error) {
+	urlValues := url.Values{}
+	if params.Limit > 0 {
+		urlValues.Add("limit", strconv.Itoa(params.Limit))
+	}
+	if params.After != "" {
+		urlValues.Add("after", params.After)
+	}
+
+	urlSuffix := fmt.Sprintf("/fine_tuning/jobs/%s/checkpoints%s", fineTuningJobID, encodeQuery(urlValues))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// ListFineTuningJobCheckpointsAll lists all the checkpoints of a fine-tuning
+// job, following the pages of ListFineTuningJobCheckpoints from params.After on.
+func (c *Client) ListFineTuningJobCheckpointsAll(
+	ctx context.Context,
+	fineTuningJobID string,
+	params ListFineTuningJobCheckpointsParams,
+) ([]FineTuningJobCheckpoint, error) {
+	return newListIterator(ctx, params.After,
+		func(ctx context.Context, after string) ([]FineTuningJobCheckpoint, string, bool, error) {
+			params.After = after
+			page, err := c.ListFineTuningJobCheckpoints(ctx, fineTuningJobID, params)
+			next := page.LastID
+			if next == "" {
+				next = lastID(page.Data, func(checkpoint FineTuningJobCheckpoint) string { return checkpoint.ID })
+			}
+			return page.Data, next, page.HasMore, err
+		}).all()
+}
+
+// BestFineTuningJobCheckpoint returns the checkpoint with the lowest validation
+// loss, on the full validation file when it was computed. It returns false when
+// no checkpoint has a validation loss, as for jobs without a validation file.
+func BestFineTuningJobCheckpoint(checkpoints []FineTuningJobCheckpoint) (FineTuningJobCheckpoint, bool) {
+	var (
+		best     FineTuningJobCheckpoint
+		bestLoss float64
+		found    bool
+	)
+	for _, checkpoint := range checkpoints {
+		loss, ok := checkpoint.validLoss()
+		if ok && (!found || loss < bestLoss) {
+			best, bestLoss, found = checkpoint, loss, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,100 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func fineTuningJobCheckpoint(step int, validLoss float64) string {
+	return fmt.Sprintf(`{"object":"fine_tuning.job.checkpoint","id":"ftckpt_%d","created_at":1721764867,`+
+		`"fine_tuned_model_checkpoint":"ft:gpt-4o-mini-2024-07-18:my-org:custom-suffix:96olL566:ckpt-step-%d",`+
+		`"fine_tuning_job_id":%q,"step_number":%d,"metrics":{"step":%d,"train_loss":0.3,`+
+		`"train_mean_token_accuracy":0.9,"valid_loss":%g,"valid_mean_token_accuracy":0.8,`+
+		`"full_valid_loss":%g,"full_valid_mean_token_accuracy":0.85}}`,
+		step, step, testFineTuninigJobID, step, step, validLoss+0.1, validLoss)
+}
+
+func TestFineTuningJobCheckpoints(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID+"/checkpoints", func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "unexpected limit", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprintf(w, `{"object":"list","data":[%s,%s],"first_id":"ftckpt_300","last_id":"ftckpt_200",`+
+				`"has_more":true}`, fineTuningJobCheckpoint(300, 0.52), fineTuningJobCheckpoint(200, 0.41))
+		case "ftckpt_200":
+			fmt.Fprintf(w, `{"object":"list","data":[%s],"first_id":"ftckpt_100","last_id":"ftckpt_100",`+
+				`"has_more":false}`, fineTuningJobCheckpoint(100, 0.6))
+		}
+	})
+	var chatModel string
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		chatModel = req.Model
+		fmt.Fprintf(w, `{"id":"chatcmpl-1","object":"chat.completion","model":%q,"choices":[`+
+			`{"index":0,"message":{"role":"assistant","content":"Hi!"},"finish_reason":"stop"}]}`, req.Model)
+	})
+	ctx := context.Background()
+
+	page, err := client.ListFineTuningJobCheckpoints(ctx, testFineTuninigJobID,
+		openai.ListFineTuningJobCheckpointsParams{Limit: 2})
+	checks.NoError(t, err, "ListFineTuningJobCheckpoints error")
+	if len(page.Data) != 2 || !page.HasMore || page.LastID != "ftckpt_200" || page.Data[0].StepNumber != 300 {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	checkpoints, err := client.ListFineTuningJobCheckpointsAll(ctx, testFineTuninigJobID,
+		openai.ListFineTuningJobCheckpointsParams{Limit: 2})
+	checks.NoError(t, err, "ListFineTuningJobCheckpointsAll error")
+	if len(checkpoints) != 3 {
+		t.Fatalf("got %d checkpoints, want 3", len(checkpoints))
+	}
+
+	// The checkpoint with the lowest validation loss is used as a chat model.
+	best, ok := openai.BestFineTuningJobCheckpoint(checkpoints)
+	if !ok || best.StepNumber != 200 || *best.Metrics.FullValidLoss != 0.41 {
+		t.Fatalf("unexpected best checkpoint %+v", best)
+	}
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    best.FineTunedModelCheckpoint,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if chatModel != best.FineTunedModelCheckpoint || resp.Choices[0].Message.Content != "Hi!" {
+		t.Errorf("the checkpoint was not used as the model: %q", chatModel)
+	}
+}
+
+func TestBestFineTuningJobCheckpoint(t *testing.T) {
+	loss := func(v float64) *float64 { return &v }
+	checkpoints := []openai.FineTuningJobCheckpoint{
+		{ID: "no-validation"},
+		{ID: "valid-only", Metrics: openai.FineTuningJobMetrics{ValidLoss: loss(0.5)}},
+		{ID: "full-valid", Metrics: openai.FineTuningJobMetrics{ValidLoss: loss(0.1), FullValidLoss: loss(0.4)}},
+	}
+	best, ok := openai.BestFineTuningJobCheckpoint(checkpoints)
+	if !ok || best.ID != "full-valid" {
+		t.Errorf("unexpected best checkpoint %+v", best)
+	}
+
+	_, ok = openai.BestFineTuningJobCheckpoint(checkpoints[:1])
+	if ok {
+		t.Error("checkpoints without validation loss should have no best")
+	}
+	_, ok = openai.BestFineTuningJobCheckpoint(nil)
+	if ok {
+		t.Error("no checkpoints should have no best")
+	}
+}
@@ -0,0 +1,150 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Types of a FineTuningJobEvent.
+const (
+	FineTuningJobEventTypeMessage = "message"
+	FineTuningJobEventTypeMetrics = "metrics"
+)
+
+// FineTuningJobMetrics are the training metrics of a step of a fine-tuning job,
+// sent as the data of metrics events. The validation metrics are only set for
+// jobs with a validation file, at the steps they are computed.
+type FineTuningJobMetrics struct {
+	Step                       int      `json:"step"`
+	TotalSteps                 int      `json:"total_steps,omitempty"`
+	TrainLoss                  float64  `json:"train_loss"`
+	TrainMeanTokenAccuracy     float64  `json:"train_mean_token_accuracy"`
+	ValidLoss                  *float64 `json:"valid_loss,omitempty"`
+	ValidMeanTokenAccuracy     *float64 `json:"valid_mean_token_accuracy,omitempty"`
+	FullValidLoss              *float64 `json:"full_valid_loss,omitempty"`
+	FullValidMeanTokenAccuracy *float64 `json:"full_valid_mean_token_accuracy,omitempty"`
+}
+
+// Metrics returns the training metrics of a metrics event. It returns false for
+// other events.
+func (e FineTuningJobEvent) Metrics() (FineTuningJobMetrics, bool) {
+	var metrics FineTuningJobMetrics
+	if e.Type != FineTuningJobEventTypeMetrics || e.Data == nil {
+		return metrics, false
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return metrics, false
+	}
+	return metrics, json.Unmarshal(data, &metrics) == nil
+}
+
+// ListFineTuningJobEventsAll lists all the events of a fine-tuning job, newest
+// first, following the pages of ListFineTuningJobEvents. A limit set with
+// ListFineTuningJobEventsWithLimit is the size of the pages.
+func (c *Client) ListFineTuningJobEventsAll(
+	ctx context.Context,
+	fineTuningJobID string,
+	setters ...ListFineTuningJobEventsParameter,
+) ([]FineTuningJobEvent, error) {
+	return c.fineTuningJobEventsIterator(ctx, fineTuningJobID, setters).all()
+}
+
+// fineTuningJobEventsIterator iterates over the events of a fine-tuning job,
+// newest first.
+func (c *Client) fineTuningJobEventsIterator(
+	ctx context.Context,
+	fineTuningJobID string,
+	setters []ListFineTuningJobEventsParameter,
+) *ListIterator[FineTuningJobEvent] {
+	return newListIterator(ctx, "",
+		func(ctx context.Context, after string) ([]FineTuningJobEvent, string, bool, error) {
+			pageSetters := setters
+			if after != "" {
+				// The cursor follows, and so overrides, the caller's setters.
+				pageSetters = append(setters[:len(setters):len(setters)], ListFineTuningJobEventsWithAfter(after))
+			}
+			page, err := c.ListFineTuningJobEvents(ctx, fineTuningJobID, pageSetters...)
+			next := lastID(page.Data, func(event FineTuningJobEvent) string { return event.ID })
+			return page.Data, next, page.HasMore, err
+		})
+}
+
+type FineTuningJobEventStream struct {
+	*streamReader[FineTuningJobEvent]
+}
+
+// StreamFineTuningJobEvents streams the events of a fine-tuning job as they are
+// emitted. Recv returns io.EOF once the job is done.
+func (c *Client) StreamFineTuningJobEvents(
+	ctx context.Context,
+	fineTuningJobID string,
+) (stream *FineTuningJobEventStream, err error) {
+	urlSuffix := fmt.Sprintf("/fine_tuning/jobs/%s/events?stream=true", fineTuningJobID)
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[FineTuningJobEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &FineTuningJobEventStream{
+		streamReader: resp,
+	}
+	return
+}
+
+// WaitForFineTuningJob polls a fine-tuning job until it is done, and returns it.
+// If onEvent is not nil, it is called with the events of the job in the order
+// they were emitted, starting with those emitted before the call, as they are
+// found at every poll. A failed job is returned with its *FineTuningJobError;
+// cancelled jobs are returned without error.
+func (c *Client) WaitForFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts PollOptions,
+	onEvent func(FineTuningJobEvent),
+) (job FineTuningJob, err error) {
+	var lastEventID string
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.RetrieveFineTuningJob(ctx, fineTuningJobID)
+		if err != nil {
+			return false, err
+		}
+		job = polled
+
+		if onEvent != nil {
+			// The events are listed newest first, down to the last one delivered.
+			var events []FineTuningJobEvent
+			it := c.fineTuningJobEventsIterator(ctx, fineTuningJobID, nil)
+			for it.Next() && it.Current().ID != lastEventID {
+				events = append(events, it.Current())
+			}
+			if err = it.Err(); err != nil {
+				return false, err
+			}
+			for i := len(events) - 1; i >= 0; i-- {
+				onEvent(events[i])
+			}
+			if len(events) > 0 {
+				lastEventID = events[0].ID
+			}
+		}
+
+		switch job.Status {
+		case FineTuningJobStatusSucceeded, FineTuningJobStatusCancelled:
+			return true, nil
+		case FineTuningJobStatusFailed:
+			if job.Error == nil {
+				return true, &FineTuningJobError{Message: "no reason given"}
+			}
+			return true, job.Error
+		}
+		return false, nil
+	})
+	return
+}
@@ -3,9 +3,12 @@ package openai_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
@@ -104,3 +107,188 @@ func TestFineTuningJob(t *testing.T) {
 	)
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 }
+
+// fineTuningJobEventsPage renders events, given newest first, as a page of the
+// events list.
+func fineTuningJobEventsPage(events []openai.FineTuningJobEvent, hasMore bool) []byte {
+	data, _ := json.Marshal(openai.FineTuningJobEventList{Object: "list", Data: events, HasMore: hasMore})
+	return data
+}
+
+func TestListFineTuningJobEventsAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID+"/events", func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "unexpected limit", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("after") {
+		case "":
+			_, _ = w.Write(fineTuningJobEventsPage([]openai.FineTuningJobEvent{{ID: "ev-5"}, {ID: "ev-4"}}, true))
+		case "ev-4":
+			_, _ = w.Write(fineTuningJobEventsPage([]openai.FineTuningJobEvent{{ID: "ev-3"}, {ID: "ev-2"}}, true))
+		case "ev-2":
+			_, _ = w.Write(fineTuningJobEventsPage([]openai.FineTuningJobEvent{{ID: "ev-1"}}, false))
+		}
+	})
+
+	events, err := client.ListFineTuningJobEventsAll(context.Background(), testFineTuninigJobID,
+		openai.ListFineTuningJobEventsWithLimit(2))
+	checks.NoError(t, err, "ListFineTuningJobEventsAll error")
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if fmt.Sprint(ids) != "[ev-5 ev-4 ev-3 ev-2 ev-1]" {
+		t.Errorf("unexpected events %v", ids)
+	}
+}
+
+func TestStreamFineTuningJobEvents(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID+"/events", func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.URL.Query().Get("stream") != "true" {
+			http.Error(w, "not streamed", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"object":"fine_tuning.job.event","id":"ev-1","level":"info",` +
+			`"message":"Step 10/100: training loss=0.50","type":"metrics",` +
+			`"data":{"step":10,"total_steps":100,"train_loss":0.5,"train_mean_token_accuracy":0.8}}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"object":"fine_tuning.job.event","id":"ev-2","level":"info",` +
+			`"message":"The job has successfully completed","type":"message"}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.StreamFineTuningJobEvents(context.Background(), testFineTuninigJobID)
+	checks.NoError(t, err, "StreamFineTuningJobEvents error")
+	defer stream.Close()
+
+	event, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	metrics, ok := event.Metrics()
+	if !ok || metrics.Step != 10 || metrics.TotalSteps != 100 || metrics.TrainLoss != 0.5 || metrics.ValidLoss != nil {
+		t.Errorf("unexpected metrics %+v of %+v", metrics, event)
+	}
+
+	event, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if _, ok = event.Metrics(); ok || event.Message != "The job has successfully completed" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	_, err = stream.Recv()
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestWaitForFineTuningJob(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	// Each poll of the job reveals one more event.
+	statuses := []string{
+		openai.FineTuningJobStatusRunning, openai.FineTuningJobStatusRunning, openai.FineTuningJobStatusSucceeded,
+	}
+	allEvents := []openai.FineTuningJobEvent{{ID: "ev-3"}, {ID: "ev-2"}, {ID: "ev-1"}, {ID: "ev-0"}}
+	polls := 0
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID, func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":%q,"status":%q}`, testFineTuninigJobID, statuses[polls])
+		polls++
+	})
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID+"/events", func(w http.ResponseWriter,
+		r *http.Request) {
+		// Pages of a single event, of the events emitted so far.
+		events := allEvents[len(allEvents)-polls-1:]
+		start := 0
+		for i, event := range events {
+			if event.ID == r.URL.Query().Get("after") {
+				start = i + 1
+			}
+		}
+		_, _ = w.Write(fineTuningJobEventsPage(events[start:start+1], start+1 < len(events)))
+	})
+
+	var seen []string
+	job, err := client.WaitForFineTuningJob(context.Background(), testFineTuninigJobID,
+		openai.PollOptions{Interval: time.Millisecond}, func(event openai.FineTuningJobEvent) {
+			seen = append(seen, event.ID)
+		})
+	checks.NoError(t, err, "WaitForFineTuningJob error")
+	if job.Status != openai.FineTuningJobStatusSucceeded {
+		t.Errorf("unexpected status %q", job.Status)
+	}
+	if fmt.Sprint(seen) != "[ev-0 ev-1 ev-2 ev-3]" {
+		t.Errorf("unexpected events %v", seen)
+	}
+}
+
+func TestWaitForFineTuningJobFailed(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs/"+testFineTuninigJobID, func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":%q,"status":"failed","error":{"code":"invalid_training_file",`+
+			`"message":"The training file is invalid.","param":"training_file"}}`, testFineTuninigJobID)
+	})
+
+	job, err := client.WaitForFineTuningJob(context.Background(), testFineTuninigJobID, openai.PollOptions{}, nil)
+	var jobErr *openai.FineTuningJobError
+	if !errors.As(err, &jobErr) || jobErr.Code != "invalid_training_file" {
+		t.Fatalf("expected a FineTuningJobError, got %v", err)
+	}
+	if job.Status != openai.FineTuningJobStatusFailed {
+		t.Errorf("unexpected status %q", job.Status)
+	}
+}
+
+func TestFineTuningJobIntegrations(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var body string
+	server.RegisterHandler("/v1/fine_tuning/jobs", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		fmt.Fprint(w, `{"id":"ftjob-1","status":"running","seed":42,"estimated_finish":1721764900,`+
+			`"trained_tokens":null,"integrations":[{"type":"wandb","wandb":{"project":"my-project",`+
+			`"tags":["ft"]}}]}`)
+	})
+	ctx := context.Background()
+
+	seed := 42
+	job, err := client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+		TrainingFile: "file-abc",
+		Seed:         &seed,
+		Integrations: []openai.FineTuningIntegration{{
+			Type:  openai.FineTuningIntegrationTypeWandb,
+			Wandb: &openai.WandbIntegration{Project: "my-project", Tags: []string{"ft"}},
+		}},
+	})
+	checks.NoError(t, err, "CreateFineTuningJob error")
+
+	want := `{"training_file":"file-abc","seed":42,"integrations":[{"type":"wandb",` +
+		`"wandb":{"project":"my-project","tags":["ft"]}}]}`
+	if body != want {
+		t.Errorf("got request %s, want %s", body, want)
+	}
+	if job.Seed != 42 || job.EstimatedFinish != 1721764900 || len(job.Integrations) != 1 ||
+		job.Integrations[0].Wandb.Project != "my-project" {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	for _, integration := range []openai.FineTuningIntegration{
+		{Type: openai.FineTuningIntegrationTypeWandb},
+		{Type: openai.FineTuningIntegrationTypeWandb, Wandb: &openai.WandbIntegration{Name: "run"}},
+	} {
+		_, err = client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+			TrainingFile: "file-abc",
+			Integrations: []openai.FineTuningIntegration{integration},
+		})
+		if !errors.Is(err, openai.ErrFineTuningIntegrationInvalid) {
+			t.Errorf("expected ErrFineTuningIntegrationInvalid for %+v, got %v", integration, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// hyperparameterAuto is the value of hyperparameters left to the API.
+const hyperparameterAuto = "auto"
+
+var (
+	// ErrFineTuningHyperparametersWithMethod is returned for fine-tuning jobs
+	// with both the legacy Hyperparameters and a Method.
+	ErrFineTuningHyperparametersWithMethod = errors.New(
+		"fine-tuning hyperparameters must be set in the method rather than at the top level")
+	// ErrFineTuningMethodMismatch is returned for a fine-tuning method whose
+	// configuration does not match its type.
+	ErrFineTuningMethodMismatch = errors.New("fine-tuning method configuration does not match its type")
+)
+
+// IntOrAuto is an integer hyperparameter, or "auto" to let the API choose it.
+type IntOrAuto struct {
+	Auto  bool
+	Value int
+}
+
+// IntValue returns an IntOrAuto set to v.
+func IntValue(v int) *IntOrAuto {
+	return &IntOrAuto{Value: v}
+}
+
+// AutoInt returns an IntOrAuto set to "auto".
+func AutoInt() *IntOrAuto {
+	return &IntOrAuto{Auto: true}
+}
+
+func (v IntOrAuto) MarshalJSON() ([]byte, error) {
+	if v.Auto {
+		return json.Marshal(hyperparameterAuto)
+	}
+	return json.Marshal(v.Value)
+}
+
+func (v *IntOrAuto) UnmarshalJSON(data []byte) error {
+	auto, err := unmarshalAuto(data)
+	if err != nil || auto {
+		*v = IntOrAuto{Auto: auto}
+		return err
+	}
+	*v = IntOrAuto{}
+	return json.Unmarshal(data, &v.Value)
+}
+
+// FloatOrAuto is a number hyperparameter, or "auto" to let the API choose it.
+type FloatOrAuto struct {
+	Auto  bool
+	Value float64
+}
+
+// FloatValue returns a FloatOrAuto set to v.
+func FloatValue(v float64) *FloatOrAuto {
+	return &FloatOrAuto{Value: v}
+}
+
+// AutoFloat returns a FloatOrAuto set to "auto".
+func AutoFloat() *FloatOrAuto {
+	return &FloatOrAuto{Auto: true}
+}
+
+func (v FloatOrAuto) MarshalJSON() ([]byte, error) {
+	if v.Auto {
+		return json.Marshal(hyperparameterAuto)
+	}
+	return json.Marshal(v.Value)
+}
+
+func (v *FloatOrAuto) UnmarshalJSON(data []byte) error {
+	auto, err := unmarshalAuto(data)
+	if err != nil || auto {
+		*v = FloatOrAuto{Auto: auto}
+		return err
+	}
+	*v = FloatOrAuto{}
+	return json.Unmarshal(data, &v.Value)
+}
+
+// unmarshalAuto reports whether data is the string "auto". Other strings are an
+// error, while numbers are left to the caller.
+func unmarshalAuto(data []byte) (bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '"' {
+		return false, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return false, err
+	}
+	if s != hyperparameterAuto {
+		return false, fmt.Errorf("invalid hyperparameter %q: must be a number or %q", s, hyperparameterAuto)
+	}
+	return true, nil
+}
+
+// FineTuningMethodType is the method of a fine-tuning job.
+type FineTuningMethodType string
+
+const (
+	FineTuningMethodTypeSupervised FineTuningMethodType = "supervised"
+	FineTuningMethodTypeDPO        FineTuningMethodType = "dpo"
+)
+
+// FineTuningMethod is the method of a fine-tuning job, with the configuration
+// matching its Type.
+type FineTuningMethod struct {
+	Type       FineTuningMethodType        `json:"type"`
+	Supervised *SupervisedFineTuningMethod `json:"supervised,omitempty"`
+	DPO        *DPOFineTuningMethod        `json:"dpo,omitempty"`
+}
+
+// SupervisedFineTuningMethod configures supervised fine-tuning.
+type SupervisedFineTuningMethod struct {
+	Hyperparameters *SupervisedHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+// SupervisedHyperparameters are the hyperparameters of supervised fine-tuning.
+// Unset values are chosen by the API.
+type SupervisedHyperparameters struct {
+	BatchSize              *IntOrAuto   `json:"batch_size,omitempty"`
+	LearningRateMultiplier *FloatOrAuto `json:"learning_rate_multiplier,omitempty"`
+	NEpochs                *IntOrAuto   `json:"n_epochs,omitempty"`
+}
+
+// DPOFineTuningMethod configures direct preference optimization.
+type DPOFineTuningMethod struct {
+	Hyperparameters *DPOHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+// DPOHyperparameters are the hyperparameters of direct preference optimization.
+// Unset values are chosen by the API.
+type DPOHyperparameters struct {
+	// Beta weighs the penalty between the policy and reference models.
+	Beta                   *FloatOrAuto `json:"beta,omitempty"`
+	BatchSize              *IntOrAuto   `json:"batch_size,omitempty"`
+	LearningRateMultiplier *FloatOrAuto `json:"learning_rate_multiplier,omitempty"`
+	NEpochs                *IntOrAuto   `json:"n_epochs,omitempty"`
+}
+
+func (r FineTuningJobRequest) validateMethod() error {
+	if r.Method == nil {
+		return nil
+	}
+	if r.Hyperparameters != nil {
+		return ErrFineTuningHyperparametersWithMethod
+	}
+	switch {
+	case r.Method.Type == FineTuningMethodTypeSupervised && r.Method.DPO != nil,
+		r.Method.Type == FineTuningMethodTypeDPO && r.Method.Supervised != nil:
+		return fmt.Errorf("%w: %s", ErrFineTuningMethodMismatch, r.Method.Type)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestIntOrAuto(t *testing.T) {
+	for _, tc := range []struct {
+		value *openai.IntOrAuto
+		json  string
+	}{
+		{openai.AutoInt(), `"auto"`},
+		{openai.IntValue(3), `3`},
+		{openai.IntValue(0), `0`},
+	} {
+		data, err := json.Marshal(tc.value)
+		checks.NoError(t, err, "Marshal error")
+		if string(data) != tc.json {
+			t.Errorf("got %s, want %s", data, tc.json)
+		}
+		var decoded openai.IntOrAuto
+		checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+		if decoded != *tc.value {
+			t.Errorf("%s decoded as %+v", data, decoded)
+		}
+	}
+
+	var v openai.IntOrAuto
+	checks.HasError(t, json.Unmarshal([]byte(`"many"`), &v), "strings other than auto should fail")
+	checks.HasError(t, json.Unmarshal([]byte(`1.5`), &v), "fractions should fail")
+}
+
+func TestFloatOrAuto(t *testing.T) {
+	for _, tc := range []struct {
+		value *openai.FloatOrAuto
+		json  string
+	}{
+		{openai.AutoFloat(), `"auto"`},
+		{openai.FloatValue(0.1), `0.1`},
+		{openai.FloatValue(2), `2`},
+	} {
+		data, err := json.Marshal(tc.value)
+		checks.NoError(t, err, "Marshal error")
+		if string(data) != tc.json {
+			t.Errorf("got %s, want %s", data, tc.json)
+		}
+		var decoded openai.FloatOrAuto
+		checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+		if decoded != *tc.value {
+			t.Errorf("%s decoded as %+v", data, decoded)
+		}
+	}
+}
+
+func TestFineTuningJobMethod(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var body string
+	server.RegisterHandler("/v1/fine_tuning/jobs", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		// The API echoes the method, with "auto" for the values it chose.
+		fmt.Fprint(w, `{"id":"ftjob-1","status":"queued","hyperparameters":{"n_epochs":"auto",`+
+			`"batch_size":"auto","learning_rate_multiplier":"auto"},"method":{"type":"dpo","dpo":`+
+			`{"hyperparameters":{"beta":0.1,"batch_size":"auto","learning_rate_multiplier":"auto","n_epochs":2}}}}`)
+	})
+
+	job, err := client.CreateFineTuningJob(context.Background(), openai.FineTuningJobRequest{
+		TrainingFile: "file-abc",
+		Model:        "gpt-4o-mini-2024-07-18",
+		Method: &openai.FineTuningMethod{
+			Type: openai.FineTuningMethodTypeDPO,
+			DPO: &openai.DPOFineTuningMethod{Hyperparameters: &openai.DPOHyperparameters{
+				Beta:    openai.FloatValue(0.1),
+				NEpochs: openai.IntValue(2),
+			}},
+		},
+	})
+	checks.NoError(t, err, "CreateFineTuningJob error")
+
+	want := `{"training_file":"file-abc","model":"gpt-4o-mini-2024-07-18","method":{"type":"dpo",` +
+		`"dpo":{"hyperparameters":{"beta":0.1,"n_epochs":2}}}}`
+	if body != want {
+		t.Errorf("got request %s, want %s", body, want)
+	}
+	if job.Hyperparameters.Epochs != "auto" {
+		t.Errorf("unexpected legacy hyperparameters %+v", job.Hyperparameters)
+	}
+	hyperparameters := job.Method.DPO.Hyperparameters
+	if job.Method.Type != openai.FineTuningMethodTypeDPO || hyperparameters.Beta.Value != 0.1 ||
+		!hyperparameters.BatchSize.Auto || hyperparameters.NEpochs.Value != 2 {
+		t.Errorf("unexpected method %+v", hyperparameters)
+	}
+}
+
+func TestFineTuningJobMethodValidation(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"ftjob-1"}`)
+	})
+	ctx := context.Background()
+
+	// The legacy hyperparameters still work on their own.
+	_, err := client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+		TrainingFile:    "file-abc",
+		Hyperparameters: &openai.Hyperparameters{Epochs: 3},
+	})
+	checks.NoError(t, err, "CreateFineTuningJob error")
+
+	_, err = client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+		TrainingFile:    "file-abc",
+		Hyperparameters: &openai.Hyperparameters{Epochs: 3},
+		Method:          &openai.FineTuningMethod{Type: openai.FineTuningMethodTypeSupervised},
+	})
+	if !errors.Is(err, openai.ErrFineTuningHyperparametersWithMethod) {
+		t.Errorf("expected ErrFineTuningHyperparametersWithMethod, got %v", err)
+	}
+
+	_, err = client.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{
+		TrainingFile: "file-abc",
+		Method: &openai.FineTuningMethod{
+			Type: openai.FineTuningMethodTypeSupervised,
+			DPO:  &openai.DPOFineTuningMethod{},
+		},
+	})
+	if !errors.Is(err, openai.ErrFineTuningMethodMismatch) {
+		t.Errorf("expected ErrFineTuningMethodMismatch, got %v", err)
+	}
+}
@@ -3,9 +3,16 @@ package openai
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+
+	utils "github.com/sashabaranov/go-openai/internal"
 )
 
 // Image sizes defined by the OpenAI API.
@@ -21,6 +28,7 @@ const (
 	// gpt-image-1 supported only.
 	CreateImageSize1536x1024 = "1536x1024" // Landscape
 	CreateImageSize1024x1536 = "1024x1536" // Portrait
+	CreateImageSizeAuto      = "auto"
 )
 
 const (
@@ -43,6 +51,7 @@ const (
 	CreateImageQualityHigh   = "high"
 	CreateImageQualityMedium = "medium"
 	CreateImageQualityLow    = "low"
+	CreateImageQualityAuto   = "auto"
 )
 
 const (
@@ -55,11 +64,13 @@ const (
 	// gpt-image-1 only.
 	CreateImageBackgroundTransparent = "transparent"
 	CreateImageBackgroundOpaque      = "opaque"
+	CreateImageBackgroundAuto        = "auto"
 )
 
 const (
 	// gpt-image-1 only.
-	CreateImageModerationLow = "low"
+	CreateImageModerationLow  = "low"
+	CreateImageModerationAuto = "auto"
 )
 
 const (
@@ -69,7 +80,26 @@ const (
 	CreateImageOutputFormatWEBP = "webp"
 )
 
+var (
+	// ErrImageModelUnsupportedParam is returned for image requests setting a
+	// parameter that their model does not take.
+	ErrImageModelUnsupportedParam = errors.New("parameter is not supported by the image model")
+	// ErrImageEditMaskNotPNG is returned for image edits whose mask is not a PNG
+	// image, the only format with the alpha channel marking the area to edit.
+	ErrImageEditMaskNotPNG = errors.New("image edit mask must be a PNG image")
+	// ErrImageEditImageCount is returned for image edits without an image, or with
+	// more images than their model accepts: one for dall-e-2, up to 16 for gpt-image-1.
+	ErrImageEditImageCount = errors.New("unsupported number of images for the image edit model")
+	// ErrImageOutputCompression is returned for an output compression outside 0-100,
+	// or set for the png output format, which is lossless.
+	ErrImageOutputCompression = errors.New("output compression must be between 0 and 100, with jpeg or webp output")
+)
+
 // ImageRequest represents the request structure for the image API.
+// gpt-image-1 always returns base64 encoded images, takes the Background,
+// Moderation, OutputFormat and OutputCompression parameters, and reports the
+// token usage of the request; the dall-e models take ResponseFormat instead, and
+// dall-e-3 takes Style. OutputCompression defaults to 100 and cannot be set to 0.
 type ImageRequest struct {
 	Prompt            string `json:"prompt,omitempty"`
 	Model             string `json:"model,omitempty"`
@@ -83,6 +113,10 @@ type ImageRequest struct {
 	Moderation        string `json:"moderation,omitempty"`
 	OutputCompression int    `json:"output_compression,omitempty"`
 	OutputFormat      string `json:"output_format,omitempty"`
+	// Stream is set by CreateImageStream, which sends PartialImages, from 0 to 3,
+	// partial images before the final one.
+	Stream        bool `json:"stream,omitempty"`
+	PartialImages int  `json:"partial_images,omitempty"`
 }
 
 // ImageResponse represents a response structure for image API.
@@ -117,6 +151,14 @@ type ImageResponseDataInner struct {
 
 // CreateImage - API call to create an image. This is the main endpoint of the DALL-E API.
 func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (response ImageResponse, err error) {
+	if request.Stream {
+		err = ErrImageStreamNotSupported
+		return
+	}
+	if err = validateImageRequest(request); err != nil {
+		return
+	}
+
 	urlSuffix := "/images/generations"
 	req, err := c.newRequest(
 		ctx,
@@ -132,6 +174,48 @@ func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (respons
 	return
 }
 
+// validateImageRequest rejects the parameters that the model of request does
+// not take. Models other than dall-e and gpt-image ones are not checked.
+func validateImageRequest(request ImageRequest) error {
+	model := request.Model
+	if model == "" {
+		model = CreateImageModelDallE2
+	}
+	unsupported := func(param string) error {
+		return fmt.Errorf("%w: %s with %s", ErrImageModelUnsupportedParam, param, model)
+	}
+
+	switch {
+	case strings.HasPrefix(model, "gpt-image-"):
+		if request.ResponseFormat != "" {
+			return unsupported("response_format")
+		}
+		if request.Style != "" {
+			return unsupported("style")
+		}
+		if request.OutputCompression < 0 || request.OutputCompression > 100 ||
+			(request.OutputCompression != 0 && (request.OutputFormat == "" ||
+				request.OutputFormat == CreateImageOutputFormatPNG)) {
+			return ErrImageOutputCompression
+		}
+	case model == CreateImageModelDallE2, model == CreateImageModelDallE3:
+		if request.Style != "" && model == CreateImageModelDallE2 {
+			return unsupported("style")
+		}
+		switch {
+		case request.Background != "":
+			return unsupported("background")
+		case request.Moderation != "":
+			return unsupported("moderation")
+		case request.OutputFormat != "":
+			return unsupported("output_format")
+		case request.OutputCompression != 0:
+			return unsupported("output_compression")
+		}
+	}
+	return nil
+}
+
 // WrapReader wraps an io.Reader with filename and Content-type.
 func WrapReader(rdr io.Reader, filename string, contentType string) io.Reader {
 	return file{rdr, filename, contentType}
@@ -156,82 +240,174 @@ func (f file) ContentType() string {
 	return f.contentType
 }
 
+// Len returns the size of the content, or -1 when it is unknown, so that upload
+// progress reports the total of the wrapped reader.
+func (f file) Len() int {
+	return int(utils.ReaderSize(f.Reader))
+}
+
+// maxImageEditImages is the number of source images accepted by gpt-image-1 edits.
+const maxImageEditImages = 16
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ImageInput is an image uploaded with a request, read either from Reader or from
+// the file at FilePath.
+type ImageInput struct {
+	Reader io.Reader
+	// Filename is sent with the contents of Reader; its extension tells the
+	// format of the image. It defaults to the base name of FilePath.
+	Filename string
+	FilePath string
+}
+
 // ImageEditRequest represents the request structure for the image API.
 // Use WrapReader to wrap an io.Reader with filename and Content-type.
+// The images to edit are Image followed by Images; gpt-image-1 accepts several
+// of them, the dall-e-2 model only one.
 type ImageEditRequest struct {
-	Image          io.Reader `json:"image,omitempty"`
-	ImageFilename  string    `json:"image_filename,omitempty"` // 新增字段，用于指定文件名
-	Mask           io.Reader `json:"mask,omitempty"`
-	MaskFilename   string    `json:"mask_filename,omitempty"` // 新增字段，用于指定掩码文件名
-	Prompt         string    `json:"prompt,omitempty"`
-	Model          string    `json:"model,omitempty"`
-	N              int       `json:"n,omitempty"`
-	Size           string    `json:"size,omitempty"`
-	ResponseFormat string    `json:"response_format,omitempty"`
-	Quality        string    `json:"quality,omitempty"`
-	User           string    `json:"user,omitempty"`
+	Image          io.Reader    `json:"image,omitempty"`
+	ImageFilename  string       `json:"image_filename,omitempty"` // 新增字段，用于指定文件名
+	Images         []ImageInput `json:"-"`
+	Mask           io.Reader    `json:"mask,omitempty"`
+	MaskFilename   string       `json:"mask_filename,omitempty"` // 新增字段，用于指定掩码文件名
+	Prompt         string       `json:"prompt,omitempty"`
+	Model          string       `json:"model,omitempty"`
+	N              int          `json:"n,omitempty"`
+	Size           string       `json:"size,omitempty"`
+	ResponseFormat string       `json:"response_format,omitempty"`
+	Quality        string       `json:"quality,omitempty"`
+	User           string       `json:"user,omitempty"`
+
+	// Progress, if set, is called as the image and mask are uploaded.
+	Progress UploadProgressFunc `json:"-"`
 }
 
 // CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
+// Images read from an *os.File, or from readers holding a large payload, are streamed
+// to the API rather than buffered in memory.
 func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
-
-	// image, filename verification can be postponed
-	err = builder.CreateFormFileReader("image", request.Image, request.ImageFilename)
-	if err != nil {
+	images := request.images()
+	maxImages := 1
+	if strings.HasPrefix(request.Model, "gpt-image-") {
+		maxImages = maxImageEditImages
+	}
+	if len(images) == 0 || len(images) > maxImages {
+		err = fmt.Errorf("%w: %d", ErrImageEditImageCount, len(images))
 		return
 	}
 
+	stream := request.Mask != nil && shouldStreamUpload(request.Mask)
+	for _, image := range images {
+		stream = stream || image.Reader == nil || shouldStreamUpload(image.Reader)
+	}
+	err = c.sendMultipartRequest(ctx, c.fullURL("/images/edits", withModel(request.Model)), stream, request.Progress,
+		func(builder utils.FormBuilder) error {
+			return imageEditMultipartForm(request, builder)
+		}, &response)
+	return
+}
+
+// images returns the images to edit: Image, if set, followed by Images.
+func (r ImageEditRequest) images() []ImageInput {
+	if r.Image == nil {
+		return r.Images
+	}
+	return append([]ImageInput{{Reader: r.Image, Filename: r.ImageFilename}}, r.Images...)
+}
+
+// imageEditMultipartForm writes the fields of an image edit request into b.
+func imageEditMultipartForm(request ImageEditRequest, builder utils.FormBuilder) error {
+	// Several images are sent as repeated image[] parts.
+	images := request.images()
+	fieldname := "image"
+	if len(images) > 1 {
+		fieldname = "image[]"
+	}
+	for _, image := range images {
+		// filename verification can be postponed
+		if err := writeImageInput(builder, fieldname, image); err != nil {
+			return err
+		}
+	}
+
 	// mask, it is optional
 	if request.Mask != nil {
+		mask, err := checkPNG(request.Mask)
+		if err != nil {
+			return err
+		}
 		// filename verification can be postponed
-		err = builder.CreateFormFileReader("mask", request.Mask, request.MaskFilename)
+		err = builder.CreateFormFileReader("mask", mask, request.MaskFilename)
 		if err != nil {
-			return
+			return err
 		}
 	}
 
-	err = builder.WriteField("prompt", request.Prompt)
+	err := builder.WriteField("prompt", request.Prompt)
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("n", strconv.Itoa(request.N))
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("size", request.Size)
 	if err != nil {
-		return
+		return err
 	}
 
 	if request.Model != "gpt-image-1" {
 		err = builder.WriteField("response_format", request.ResponseFormat)
 		if err != nil {
-			return
+			return err
 		}
 	}
 
-	err = builder.Close()
-	if err != nil {
-		return
+	return builder.Close()
+}
+
+// writeImageInput writes image as a file part named fieldname.
+func writeImageInput(builder utils.FormBuilder, fieldname string, image ImageInput) error {
+	filename := image.Filename
+	if image.Reader != nil {
+		return builder.CreateFormFileReader(fieldname, image.Reader, filename)
 	}
 
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/edits", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	f, err := os.Open(image.FilePath)
 	if err != nil {
-		return
+		return fmt.Errorf("opening image file: %w", err)
+	}
+	defer f.Close()
+	if filename == "" {
+		filename = filepath.Base(image.FilePath)
 	}
+	return builder.CreateFormFileReader(fieldname, f, filename)
+}
 
-	err = c.sendRequest(req, &response)
-	return
+// checkPNG returns ErrImageEditMaskNotPNG unless r starts with the PNG signature,
+// and otherwise a reader returning the whole content of r, with its name and
+// content type.
+func checkPNG(r io.Reader) (io.Reader, error) {
+	header := make([]byte, len(pngSignature))
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Equal(header[:n], pngSignature) {
+		return nil, ErrImageEditMaskNotPNG
+	}
+	checked := file{Reader: io.MultiReader(bytes.NewReader(header), r)}
+	if named, ok := r.(interface{ Name() string }); ok {
+		checked.name = named.Name()
+	}
+	if typed, ok := r.(interface{ ContentType() string }); ok {
+		checked.contentType = typed.ContentType()
+	}
+	return checked, nil
 }
 
 // ImageVariRequest represents the request structure for the image API.
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -93,7 +94,9 @@ func TestImageEdit(t *testing.T) {
 	}
 	defer origin.Close()
 
-	mask, err := os.Create(filepath.Join(t.TempDir(), "mask.png"))
+	maskPath := filepath.Join(t.TempDir(), "mask.png")
+	checks.NoError(t, os.WriteFile(maskPath, []byte("\x89PNG\r\n\x1a\n"), 0o600), "WriteFile error")
+	mask, err := os.Open(maskPath)
 	if err != nil {
 		t.Fatalf("open mask file error: %v", err)
 	}
@@ -212,3 +215,129 @@ func handleVariateImageEndpoint(w http.ResponseWriter, r *http.Request) {
 	resBytes, _ = json.Marshal(responses)
 	fmt.Fprintln(w, string(resBytes))
 }
+
+func TestImagesGPTImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request["background"] != "transparent" || request["output_format"] != "webp" ||
+			request["output_compression"] != 80.0 || request["moderation"] != "low" || request["size"] != "auto" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"created":1713833628,"data":[{"b64_json":"UklGRg=="}],"usage":{"total_tokens":100,`+
+			`"input_tokens":50,"output_tokens":50,"input_tokens_details":{"text_tokens":10,"image_tokens":40}}}`)
+	})
+
+	resp, err := client.CreateImage(context.Background(), openai.ImageRequest{
+		Prompt:            "A cute baby sea otter",
+		Model:             openai.CreateImageModelGptImage1,
+		Size:              openai.CreateImageSizeAuto,
+		Quality:           openai.CreateImageQualityAuto,
+		Background:        openai.CreateImageBackgroundTransparent,
+		Moderation:        openai.CreateImageModerationLow,
+		OutputFormat:      openai.CreateImageOutputFormatWEBP,
+		OutputCompression: 80,
+	})
+	checks.NoError(t, err, "CreateImage error")
+	if resp.Data[0].B64JSON != "UklGRg==" || resp.Usage.TotalTokens != 100 ||
+		resp.Usage.InputTokensDetails.ImageTokens != 40 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestImagesValidation(t *testing.T) {
+	client := openai.NewClient("token")
+	cases := []struct {
+		name    string
+		request openai.ImageRequest
+		wantErr error
+	}{
+		{"gpt-image response format", openai.ImageRequest{
+			Model: openai.CreateImageModelGptImage1, ResponseFormat: openai.CreateImageResponseFormatURL,
+		}, openai.ErrImageModelUnsupportedParam},
+		{"gpt-image style", openai.ImageRequest{
+			Model: openai.CreateImageModelGptImage1, Style: openai.CreateImageStyleVivid,
+		}, openai.ErrImageModelUnsupportedParam},
+		{"gpt-image compression out of range", openai.ImageRequest{
+			Model: openai.CreateImageModelGptImage1, OutputFormat: openai.CreateImageOutputFormatJPEG,
+			OutputCompression: 101,
+		}, openai.ErrImageOutputCompression},
+		{"gpt-image compression with png", openai.ImageRequest{
+			Model: openai.CreateImageModelGptImage1, OutputFormat: openai.CreateImageOutputFormatPNG,
+			OutputCompression: 50,
+		}, openai.ErrImageOutputCompression},
+		{"dall-e-2 style", openai.ImageRequest{
+			Style: openai.CreateImageStyleNatural,
+		}, openai.ErrImageModelUnsupportedParam},
+		{"dall-e-3 background", openai.ImageRequest{
+			Model: openai.CreateImageModelDallE3, Background: openai.CreateImageBackgroundOpaque,
+		}, openai.ErrImageModelUnsupportedParam},
+		{"dall-e-3 output format", openai.ImageRequest{
+			Model: openai.CreateImageModelDallE3, OutputFormat: openai.CreateImageOutputFormatPNG,
+		}, openai.ErrImageModelUnsupportedParam},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateImage(context.Background(), tc.request)
+			checks.ErrorIs(t, err, tc.wantErr, "unexpected error")
+		})
+	}
+}
+
+func TestImageEditMultipleImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		images := r.MultipartForm.File["image[]"]
+		masks := r.MultipartForm.File["mask"]
+		if len(images) != 2 || images[0].Filename != "lotion.png" || images[1].Filename != "soap.webp" ||
+			len(masks) != 1 || masks[0].Filename != "mask.png" || r.MultipartForm.Value["response_format"] != nil {
+			http.Error(w, "unexpected form", http.StatusBadRequest)
+			return
+		}
+		mask, err := masks[0].Open()
+		checks.NoError(t, err, "Open mask error")
+		defer mask.Close()
+		content, _ := io.ReadAll(mask)
+		if string(content) != "\x89PNG\r\n\x1a\nmask data" {
+			http.Error(w, "unexpected mask", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"created":1713833628,"data":[{"b64_json":"iVBORw=="}]}`)
+	})
+
+	path := filepath.Join(t.TempDir(), "soap.webp")
+	checks.NoError(t, os.WriteFile(path, []byte("RIFF\x00\x00\x00\x00WEBP"), 0o600), "WriteFile error")
+	request := openai.ImageEditRequest{
+		Images: []openai.ImageInput{
+			{Reader: strings.NewReader("\x89PNG\r\n\x1a\nlotion"), Filename: "lotion.png"},
+			{FilePath: path},
+		},
+		Mask:         strings.NewReader("\x89PNG\r\n\x1a\nmask data"),
+		MaskFilename: "mask.png",
+		Prompt:       "A gift basket",
+		Model:        openai.CreateImageModelGptImage1,
+	}
+	resp, err := client.CreateEditImage(context.Background(), request)
+	checks.NoError(t, err, "CreateEditImage error")
+	if resp.Data[0].B64JSON != "iVBORw==" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	request.Mask = strings.NewReader("GIF89a")
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrImageEditMaskNotPNG, "non-PNG mask should be rejected")
+
+	request.Model = openai.CreateImageModelDallE2
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrImageEditImageCount, "dall-e-2 should take a single image")
+
+	request.Model = openai.CreateImageModelGptImage1
+	request.Images = make([]openai.ImageInput, 17)
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrImageEditImageCount, "more than 16 images should be rejected")
+}
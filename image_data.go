@@ -0,0 +1,150 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// imageDownloadAttempts is how many times Download tries to fetch an image.
+const imageDownloadAttempts = 3
+
+var (
+	// ErrImageNoData is returned for an image response without images, or an
+	// image with neither base64 data nor a URL.
+	ErrImageNoData = errors.New("image response has no image data")
+	// ErrImageURLExpired is returned when downloading an image whose URL has
+	// expired. Image URLs returned by the API are valid for an hour.
+	ErrImageURLExpired = errors.New("image URL has expired")
+)
+
+// First returns the first image of the response, or ErrImageNoData when it has
+// none, for example because the prompt was rejected.
+func (r *ImageResponse) First() (ImageResponseDataInner, error) {
+	if len(r.Data) == 0 {
+		return ImageResponseDataInner{}, ErrImageNoData
+	}
+	return r.Data[0], nil
+}
+
+// Bytes returns the decoded image of a response requested in the b64_json
+// format. Images returned as a URL have to be downloaded with Download.
+func (d *ImageResponseDataInner) Bytes() ([]byte, error) {
+	if d.B64JSON == "" {
+		if d.URL != "" {
+			return nil, fmt.Errorf("%w: the image was returned as a URL, use Download", ErrImageNoData)
+		}
+		return nil, ErrImageNoData
+	}
+	return base64.StdEncoding.DecodeString(d.B64JSON)
+}
+
+// Download returns the image, decoded from B64JSON or fetched from URL with
+// httpClient, or http.DefaultClient if it is nil. Failed downloads are retried
+// like API requests are by DefaultShouldRetry, up to three times in total.
+// Expired URLs fail with ErrImageURLExpired.
+func (d *ImageResponseDataInner) Download(ctx context.Context, httpClient HTTPDoer) ([]byte, error) {
+	if d.B64JSON != "" || d.URL == "" {
+		return d.Bytes()
+	}
+	if expiry, ok := imageURLExpiry(d.URL); ok && time.Now().After(expiry) {
+		return nil, fmt.Errorf("%w at %s", ErrImageURLExpired, expiry.Format(time.RFC3339))
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	for attempt := 1; ; attempt++ {
+		data, resp, err := downloadImage(ctx, httpClient, d.URL)
+		if err == nil {
+			return data, nil
+		}
+		retry := DefaultShouldRetry(nil, err)
+		if resp != nil {
+			// err only describes the status of resp.
+			retry = DefaultShouldRetry(resp, nil)
+		}
+		if !retry || attempt == imageDownloadAttempts {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(DefaultRetryBackoff(attempt)):
+		}
+	}
+}
+
+// downloadImage fetches imageURL once. The response is returned, without its
+// body, to decide whether to retry.
+func downloadImage(ctx context.Context, httpClient HTTPDoer, imageURL string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		// Expired signed URLs are refused by the storage service.
+		return nil, resp, fmt.Errorf("%w or is no longer available: status %d", ErrImageURLExpired, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, resp, fmt.Errorf("downloading image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, resp, nil
+}
+
+// imageURLExpiry returns the expiry time of a signed image URL, given by its se
+// (signed expiry) parameter.
+func imageURLExpiry(imageURL string) (time.Time, bool) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, u.Query().Get("se"))
+	return expiry, err == nil
+}
+
+// SaveTo writes the image to the file at path, downloading it with Download if it
+// was returned as a URL. When path has no extension, the one of the detected
+// image format is appended. It returns the path of the written file.
+func (d *ImageResponseDataInner) SaveTo(ctx context.Context, httpClient HTTPDoer, path string) (string, error) {
+	data, err := d.Download(ctx, httpClient)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Ext(path) == "" {
+		path += imageExtension(data)
+	}
+	return path, os.WriteFile(path, data, 0o644) //nolint:gosec // images are not sensitive
+}
+
+// imageExtension returns the file extension of the format of the image data.
+func imageExtension(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	return ".bin"
+}
@@ -0,0 +1,84 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestImageDataBytes(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	data := openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString(png)}
+	got, err := data.Bytes()
+	checks.NoError(t, err, "Bytes error")
+	if string(got) != string(png) {
+		t.Errorf("unexpected bytes %q", got)
+	}
+
+	path, err := data.SaveTo(context.Background(), nil, filepath.Join(t.TempDir(), "otter"))
+	checks.NoError(t, err, "SaveTo error")
+	if filepath.Ext(path) != ".png" {
+		t.Errorf("unexpected path %s", path)
+	}
+	saved, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	if string(saved) != string(png) {
+		t.Errorf("unexpected saved image %q", saved)
+	}
+
+	_, err = (&openai.ImageResponseDataInner{URL: "https://example.com/a.png"}).Bytes()
+	checks.ErrorIs(t, err, openai.ErrImageNoData, "URL images have no bytes")
+
+	var resp openai.ImageResponse
+	_, err = resp.First()
+	checks.ErrorIs(t, err, openai.ErrImageNoData, "empty response has no image")
+}
+
+func TestImageDataDownload(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch {
+		case r.URL.Path == "/gone.png":
+			w.WriteHeader(http.StatusForbidden)
+		case attempts == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte("GIF89a"))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	data := openai.ImageResponseDataInner{URL: server.URL + "/cat.gif?se=" +
+		time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}
+	got, err := data.Download(ctx, server.Client())
+	checks.NoError(t, err, "Download error")
+	if string(got) != "GIF89a" || attempts != 2 {
+		t.Errorf("unexpected image %q after %d attempts", got, attempts)
+	}
+
+	attempts = 0
+	data.URL = server.URL + "/gone.png"
+	_, err = data.Download(ctx, server.Client())
+	checks.ErrorIs(t, err, openai.ErrImageURLExpired, "forbidden URL should be reported as expired")
+	if attempts != 1 {
+		t.Errorf("forbidden URL should not be retried, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	data.URL = server.URL + "/cat.gif?se=2020-01-01T00%3A00%3A00Z&sig=abc"
+	_, err = data.Download(ctx, server.Client())
+	if !errors.Is(err, openai.ErrImageURLExpired) || attempts != 0 {
+		t.Errorf("expired URL should fail without a request, got %v after %d attempts", err, attempts)
+	}
+}
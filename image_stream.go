@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxPartialImages is the largest number of partial images of an image stream.
+const maxPartialImages = 3
+
+var (
+	// ErrImageStreamPartialImages is returned for image streams requesting fewer
+	// than 0 or more than 3 partial images.
+	ErrImageStreamPartialImages = errors.New("partial images must be between 0 and 3")
+	ErrImageStreamNotSupported  = errors.New("streaming is not supported with this method, please use CreateImageStream")
+)
+
+// Types of the events of an ImageStream.
+const (
+	ImageStreamEventPartialImage = "image_generation.partial_image"
+	ImageStreamEventCompleted    = "image_generation.completed"
+)
+
+// ImageStreamEvent is an event of an ImageStream: a partial image, rendered while
+// the image is generated, or the final image.
+type ImageStreamEvent struct {
+	Type string `json:"type"`
+	// B64JSON is the base64 encoded image.
+	B64JSON string `json:"b64_json"`
+	// PartialImageIndex is the index of a partial image, from 0.
+	PartialImageIndex int    `json:"partial_image_index,omitempty"`
+	CreatedAt         int64  `json:"created_at,omitempty"`
+	Size              string `json:"size,omitempty"`
+	Quality           string `json:"quality,omitempty"`
+	Background        string `json:"background,omitempty"`
+	OutputFormat      string `json:"output_format,omitempty"`
+	// Usage is the token usage of the request, sent with the completed event.
+	Usage *ImageResponseUsage `json:"usage,omitempty"`
+}
+
+// Bytes returns the decoded image of the event.
+func (e *ImageStreamEvent) Bytes() ([]byte, error) {
+	data := ImageResponseDataInner{B64JSON: e.B64JSON}
+	return data.Bytes()
+}
+
+type ImageStream struct {
+	*streamReader[ImageStreamEvent]
+}
+
+// CreateImageStream — API call to create an image with a gpt-image model, streaming
+// request.PartialImages partial images as it is generated. Recv returns the
+// partial image events followed by the completed event, then io.EOF.
+func (c *Client) CreateImageStream(ctx context.Context, request ImageRequest) (stream *ImageStream, err error) {
+	if !strings.HasPrefix(request.Model, "gpt-image-") {
+		err = fmt.Errorf("%w: stream with %s", ErrImageModelUnsupportedParam, request.Model)
+		return
+	}
+	if request.PartialImages < 0 || request.PartialImages > maxPartialImages {
+		err = ErrImageStreamPartialImages
+		return
+	}
+	if err = validateImageRequest(request); err != nil {
+		return
+	}
+
+	request.Stream = true
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/generations", withModel(request.Model)),
+		withBody(request),
+	)
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[ImageStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &ImageStream{
+		streamReader: resp,
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateImageStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request["stream"] != true || request["partial_images"] != 2.0 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: image_generation.partial_image\n" +
+			`data: {"type":"image_generation.partial_image","b64_json":"cGFydDA=","partial_image_index":0}` + "\n\n"))
+		_, _ = w.Write([]byte("event: image_generation.partial_image\n" +
+			`data: {"type":"image_generation.partial_image","b64_json":"cGFydDE=","partial_image_index":1}` + "\n\n"))
+		_, _ = w.Write([]byte("event: image_generation.completed\n" +
+			`data: {"type":"image_generation.completed","b64_json":"ZmluYWw=","size":"1024x1024",` +
+			`"usage":{"total_tokens":300,"input_tokens":20,"output_tokens":280}}` + "\n\n"))
+	})
+
+	stream, err := client.CreateImageStream(context.Background(), openai.ImageRequest{
+		Prompt:        "A lighthouse",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 2,
+	})
+	checks.NoError(t, err, "CreateImageStream error")
+	defer stream.Close()
+
+	var images []string
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		image, decodeErr := event.Bytes()
+		checks.NoError(t, decodeErr, "Bytes error")
+		images = append(images, string(image))
+
+		switch {
+		case event.Type == openai.ImageStreamEventPartialImage && event.PartialImageIndex == len(images)-1:
+		case event.Type == openai.ImageStreamEventCompleted && len(images) == 3:
+			if event.Usage == nil || event.Usage.TotalTokens != 300 || event.Size != "1024x1024" {
+				t.Errorf("unexpected completed event %+v", event)
+			}
+		default:
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+	if len(images) != 3 || images[0] != "part0" || images[1] != "part1" || images[2] != "final" {
+		t.Errorf("unexpected images %q", images)
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "stream should stay at EOF")
+}
+
+func TestCreateImageStreamValidation(t *testing.T) {
+	client := openai.NewClient("token")
+	ctx := context.Background()
+
+	_, err := client.CreateImageStream(ctx, openai.ImageRequest{Model: openai.CreateImageModelDallE3})
+	checks.ErrorIs(t, err, openai.ErrImageModelUnsupportedParam, "dall-e models cannot stream")
+
+	for _, partialImages := range []int{-1, 4} {
+		_, err = client.CreateImageStream(ctx, openai.ImageRequest{
+			Model:         openai.CreateImageModelGptImage1,
+			PartialImages: partialImages,
+		})
+		checks.ErrorIs(t, err, openai.ErrImageStreamPartialImages, "partial images out of range")
+	}
+
+	_, err = client.CreateImage(ctx, openai.ImageRequest{Model: openai.CreateImageModelGptImage1, Stream: true})
+	checks.ErrorIs(t, err, openai.ErrImageStreamNotSupported, "CreateImage cannot stream")
+}
@@ -13,16 +13,22 @@ import (
 )
 
 type mockFormBuilder struct {
-	mockCreateFormFile       func(string, *os.File) error
-	mockCreateFormFileReader func(string, io.Reader, string) error
-	mockWriteField           func(string, string) error
-	mockClose                func() error
+	mockCreateFormFile            func(string, *os.File) error
+	mockCreateFormFileContentType func(string, *os.File) error
+	mockCreateFormFileReader      func(string, io.Reader, string) error
+	mockWriteField                func(string, string) error
+	mockWriteJSONField            func(string, any) error
+	mockClose                     func() error
 }
 
 func (fb *mockFormBuilder) CreateFormFile(fieldname string, file *os.File) error {
 	return fb.mockCreateFormFile(fieldname, file)
 }
 
+func (fb *mockFormBuilder) CreateFormFileContentType(fieldname string, file *os.File) error {
+	return fb.mockCreateFormFileContentType(fieldname, file)
+}
+
 func (fb *mockFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
 	return fb.mockCreateFormFileReader(fieldname, r, filename)
 }
@@ -31,6 +37,10 @@ func (fb *mockFormBuilder) WriteField(fieldname, value string) error {
 	return fb.mockWriteField(fieldname, value)
 }
 
+func (fb *mockFormBuilder) WriteJSONField(fieldname string, v any) error {
+	return fb.mockWriteJSONField(fieldname, v)
+}
+
 func (fb *mockFormBuilder) Close() error {
 	return fb.mockClose()
 }
@@ -63,7 +73,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				fb.mockWriteField = func(string, string) error { return nil }
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "mask",
@@ -77,7 +87,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				fb.mockWriteField = func(string, string) error { return nil }
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "prompt",
@@ -91,7 +101,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				}
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "n",
@@ -105,7 +115,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				}
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "size",
@@ -119,7 +129,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				}
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "response_format",
@@ -133,7 +143,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				}
 				fb.mockClose = func() error { return nil }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 		{
 			name: "close",
@@ -142,7 +152,7 @@ func TestImageFormBuilderFailures(t *testing.T) {
 				fb.mockWriteField = func(string, string) error { return nil }
 				fb.mockClose = func() error { return mockFailedErr }
 			},
-			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)},
+			req: ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(pngSignature)},
 		},
 	}
 
@@ -165,7 +175,10 @@ func TestImageFormBuilderFailures(t *testing.T) {
 		client := newClient(fb)
 		client.requestBuilder = &failingRequestBuilder{}
 
-		_, err := client.CreateEditImage(ctx, ImageEditRequest{Image: bytes.NewBuffer(nil), Mask: bytes.NewBuffer(nil)})
+		_, err := client.CreateEditImage(ctx, ImageEditRequest{
+			Image: bytes.NewBuffer(nil),
+			Mask:  bytes.NewBuffer(pngSignature),
+		})
 		checks.ErrorIs(t, err, errTestRequestBuilderFailed, "CreateEditImage should return error if request builder fails")
 	})
 }
@@ -1,6 +1,9 @@
 package openai
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -16,12 +19,14 @@ type FormBuilder interface {
 	CreateFormFileContentType(fieldname string, file *os.File) error
 	CreateFormFileReader(fieldname string, r io.Reader, filename string) error
 	WriteField(fieldname, value string) error
+	WriteJSONField(fieldname string, v any) error
 	Close() error
 	FormDataContentType() string
 }
 
 type DefaultFormBuilder struct {
-	writer *multipart.Writer
+	writer   *multipart.Writer
+	progress func(written, total int64)
 }
 
 func NewFormBuilder(body io.Writer) *DefaultFormBuilder {
@@ -30,14 +35,70 @@ func NewFormBuilder(body io.Writer) *DefaultFormBuilder {
 	}
 }
 
+// NewFormBuilderWithProgress returns a FormBuilder that calls fn as file contents
+// are copied into the form. written is the number of bytes of the current file
+// copied so far and total its size, or -1 when the size cannot be determined.
+func NewFormBuilderWithProgress(body io.Writer, fn func(written, total int64)) *DefaultFormBuilder {
+	return &DefaultFormBuilder{
+		writer:   multipart.NewWriter(body),
+		progress: fn,
+	}
+}
+
+// StreamingFormBuilder is a FormBuilder writing its form into a pipe, so that
+// the form is sent while it is built instead of being held in memory. The form
+// is built in a goroutine, while the read end of the pipe is sent as the body
+// of a request.
+type StreamingFormBuilder struct {
+	FormBuilder
+	pw *io.PipeWriter
+}
+
+// NewStreamingFormBuilder returns a StreamingFormBuilder and the body of the
+// request sending its form. Closing the body terminates the pipe, so that the
+// builder of a form whose request failed returns io.ErrClosedPipe instead of
+// blocking.
+func NewStreamingFormBuilder() (*StreamingFormBuilder, io.ReadCloser) {
+	return NewStreamingFormBuilderWith(func(w io.Writer) FormBuilder {
+		return NewFormBuilder(w)
+	})
+}
+
+// NewStreamingFormBuilderWith is NewStreamingFormBuilder with the FormBuilder
+// returned by newBuilder for the pipe, such as one reporting progress.
+func NewStreamingFormBuilderWith(newBuilder func(io.Writer) FormBuilder) (*StreamingFormBuilder, io.ReadCloser) {
+	pr, pw := io.Pipe()
+	return &StreamingFormBuilder{FormBuilder: newBuilder(pw), pw: pw}, pr
+}
+
+// Close closes the form and ends the body.
+func (fb *StreamingFormBuilder) Close() error {
+	err := fb.FormBuilder.Close()
+	_ = fb.pw.CloseWithError(err)
+	return err
+}
+
+// CloseWithError ends the body with err, which the reads of the body, and so
+// the request sending it, then fail with. It does not replace the end of a
+// body already closed, and a nil err ends the body normally.
+func (fb *StreamingFormBuilder) CloseWithError(err error) error {
+	return fb.pw.CloseWithError(err)
+}
+
+// CreateFormFile creates a form field with the contents of file. Only the base
+// name of the file is sent, never its local directory.
 func (fb *DefaultFormBuilder) CreateFormFile(fieldname string, file *os.File) error {
-	return fb.createFormFile(fieldname, file, file.Name())
+	return fb.createFormFile(fieldname, file, filepath.Base(file.Name()))
 }
 
-var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+// quoteEscaper escapes a value for use inside a quoted header parameter. CR and LF
+// are percent-encoded, as browsers do, since they cannot appear in a header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"", "\r", "%0D", "\n", "%0A")
 
 // CreateFormFileReader creates a form field with a file reader.
-// The filename in Content-Disposition is required.
+// The filename in Content-Disposition is required. The part's Content-Type is
+// taken from r when it has a ContentType method, and otherwise from the
+// extension of filename, since a reader cannot in general be sniffed and rewound.
 func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
 	if filename == "" {
 		if f, ok := r.(interface{ Name() string }); ok {
@@ -48,16 +109,12 @@ func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader
 	if f, ok := r.(interface{ ContentType() string }); ok {
 		contentType = f.ContentType()
 	}
+	if contentType == "" {
+		contentType = extensionContentTypes[strings.ToLower(filepath.Ext(filename))]
+	}
 
 	h := make(textproto.MIMEHeader)
-	h.Set(
-		"Content-Disposition",
-		fmt.Sprintf(
-			`form-data; name="%s"; filename="%s"`,
-			escapeQuotes(fieldname),
-			escapeQuotes(filepath.Base(filename)),
-		),
-	)
+	h.Set("Content-Disposition", contentDisposition(fieldname, filepath.Base(filename)))
 	// content type is optional, but it can be set
 	if contentType != "" {
 		h.Set("Content-Type", contentType)
@@ -68,12 +125,7 @@ func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader
 		return err
 	}
 
-	_, err = io.Copy(fieldWriter, r)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return fb.copyFile(fieldWriter, r)
 }
 
 func (fb *DefaultFormBuilder) createFormFile(fieldname string, r io.Reader, filename string) error {
@@ -81,17 +133,59 @@ func (fb *DefaultFormBuilder) createFormFile(fieldname string, r io.Reader, file
 		return fmt.Errorf("filename cannot be empty")
 	}
 
-	fieldWriter, err := fb.writer.CreateFormFile(fieldname, filename)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", contentDisposition(fieldname, filename))
+	h.Set("Content-Type", "application/octet-stream")
+
+	fieldWriter, err := fb.writer.CreatePart(h)
 	if err != nil {
 		return err
 	}
 
-	_, err = io.Copy(fieldWriter, r)
-	if err != nil {
-		return err
+	return fb.copyFile(fieldWriter, r)
+}
+
+// copyFile copies the contents of a file part from r to w, reporting progress
+// when the builder was created with a progress callback.
+func (fb *DefaultFormBuilder) copyFile(w io.Writer, r io.Reader) error {
+	if fb.progress != nil {
+		w = &progressWriter{w: w, total: ReaderSize(r), fn: fb.progress}
 	}
+	_, err := io.Copy(w, r)
+	return err
+}
+
+// progressWriter counts the bytes written through it and reports them after
+// every write, which io.Copy issues once per buffered chunk.
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	fn      func(written, total int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.written += int64(n)
+	pw.fn(pw.written, pw.total)
+	return n, err
+}
 
-	return nil
+// ReaderSize returns the number of bytes remaining in r, or -1 if it is unknown.
+func ReaderSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil {
+			return -1
+		}
+		return info.Size()
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case interface{ Size() int64 }:
+		return v.Size()
+	}
+	return -1
 }
 
 func (fb *DefaultFormBuilder) WriteField(fieldname, value string) error {
@@ -101,6 +195,31 @@ func (fb *DefaultFormBuilder) WriteField(fieldname, value string) error {
 	return fb.writer.WriteField(fieldname, value)
 }
 
+// WriteJSONField marshals v to JSON and writes it as a form field whose part
+// carries a Content-Type of application/json.
+func (fb *DefaultFormBuilder) WriteJSONField(fieldname string, v any) error {
+	if fieldname == "" {
+		return fmt.Errorf("fieldname cannot be empty")
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling field %s: %w", fieldname, err)
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(fieldname)))
+	h.Set("Content-Type", "application/json")
+
+	fieldWriter, err := fb.writer.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	_, err = fieldWriter.Write(data)
+	return err
+}
+
 func (fb *DefaultFormBuilder) Close() error {
 	return fb.writer.Close()
 }
@@ -128,9 +247,7 @@ func (fb *DefaultFormBuilder) CreateFormFileContentType(fieldname string, file *
 
 	// 创建 multipart writer
 	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition",
-		fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-			escapeQuotes(fieldname), escapeQuotes(filename)))
+	h.Set("Content-Disposition", contentDisposition(fieldname, filename))
 	h.Set("Content-Type", contentType)
 
 	// 创建表单字段
@@ -145,15 +262,52 @@ func (fb *DefaultFormBuilder) CreateFormFileContentType(fieldname string, file *
 	}
 
 	// 复制文件内容
-	_, err = io.Copy(fieldWriter, file)
-	if err != nil {
-		return err
-	}
+	return fb.copyFile(fieldWriter, file)
+}
+
+// extensionContentTypes maps file extensions to the MIME types sent for them. It covers
+// the image formats accepted by the images API, the audio/video containers accepted
+// by the transcription and translation endpoints, and the common documents uploaded
+// to the files API.
+var extensionContentTypes = map[string]string{
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".png":   "image/png",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".bmp":   "image/bmp",
+	".svg":   "image/svg+xml",
+	".tiff":  "image/tiff",
+	".tif":   "image/tiff",
+	".mp3":   "audio/mpeg",
+	".mpga":  "audio/mpeg",
+	".mpeg":  "audio/mpeg",
+	".mp4":   "audio/mp4",
+	".m4a":   "audio/mp4",
+	".wav":   "audio/wav",
+	".flac":  "audio/flac",
+	".ogg":   "audio/ogg",
+	".oga":   "audio/ogg",
+	".opus":  "audio/ogg",
+	".webm":  "audio/webm",
+	".jsonl": "application/jsonl",
+	".json":  "application/json",
+	".txt":   "text/plain",
+	".csv":   "text/csv",
+	".pdf":   "application/pdf",
+}
 
-	return nil
+// FileContentType returns the MIME type of file, detected the same way as for the
+// parts written by CreateFormFileContentType. The file offset is left unchanged.
+func FileContentType(file *os.File) (string, error) {
+	return getFileContentType(file)
 }
 
 // getFileContentType 检测文件的 MIME 类型
+// Recognised audio signatures take precedence over the extension, so that a file with
+// a misleading extension is still labelled by its actual content. A bare MPEG frame
+// sync only does for files without a known non-audio extension, as it also matches
+// the byte order mark of UTF-16LE text.
 func getFileContentType(file *os.File) (string, error) {
 	// 保存当前文件位置
 	currentPos, err := file.Seek(0, io.SeekCurrent)
@@ -162,54 +316,144 @@ func getFileContentType(file *os.File) (string, error) {
 	}
 	defer file.Seek(currentPos, io.SeekStart)
 
-	// 首先通过文件扩展名判断
-	ext := strings.ToLower(filepath.Ext(file.Name()))
-	switch ext {
-	case ".jpg", ".jpeg":
-		return "image/jpeg", nil
-	case ".png":
-		return "image/png", nil
-	case ".gif":
-		return "image/gif", nil
-	case ".webp":
-		return "image/webp", nil
-	case ".bmp":
-		return "image/bmp", nil
-	case ".svg":
-		return "image/svg+xml", nil
-	case ".tiff", ".tif":
-		return "image/tiff", nil
-	}
-
-	// 通过文件头检测
 	// 读取文件头部分
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil && err != io.EOF {
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
 		return "", err
 	}
+	buffer = buffer[:n]
 
-	// 检测内容类型
-	contentType := http.DetectContentType(buffer)
+	ext := strings.ToLower(filepath.Ext(file.Name()))
+	extContentType, known := extensionContentTypes[ext]
+	sniffed := sniffAudioSignature(buffer)
+	if sniffed == "" && (!known || strings.HasPrefix(extContentType, "audio/")) && isMPEGFrameHeader(buffer) {
+		sniffed = "audio/mpeg"
+	}
+	if sniffed != "" {
+		return sniffed, nil
+	}
 
-	// 如果检测到的是通用二进制流，且有文件扩展名，使用扩展名判断
-	if contentType == "application/octet-stream" && ext != "" {
-		// 可以添加更多的扩展名映射
-		mimeTypes := map[string]string{
-			".webp": "image/webp",
-			".svg":  "image/svg+xml",
-			".tiff": "image/tiff",
-			".tif":  "image/tiff",
-		}
-		if mime, ok := mimeTypes[ext]; ok {
-			return mime, nil
-		}
+	// 通过文件扩展名判断
+	if known {
+		return extContentType, nil
+	}
+
+	// 通过文件头检测
+	return http.DetectContentType(buffer), nil
+}
+
+// SniffAudioContentType returns the MIME type of the audio starting with header,
+// detected the same way as for the parts written by CreateFormFileContentType,
+// or an empty string when the header is not recognised.
+func SniffAudioContentType(header []byte) string {
+	return sniffAudioContentType(header)
+}
+
+// sniffAudioContentType detects common audio containers from their magic bytes,
+// and MPEG audio from its first frame header. It returns an empty string when the
+// header is not recognised.
+func sniffAudioContentType(header []byte) string {
+	if contentType := sniffAudioSignature(header); contentType != "" {
+		return contentType
+	}
+	if isMPEGFrameHeader(header) {
+		return "audio/mpeg"
+	}
+	return ""
+}
+
+// isMPEGFrameHeader reports whether header starts with a valid MPEG audio frame
+// header, as files without an ID3 tag do: a frame sync followed by a version,
+// layer, bitrate and sample rate that are not reserved.
+func isMPEGFrameHeader(header []byte) bool {
+	if len(header) < 3 || header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return false
 	}
+	version, layer := header[1]>>3&0x3, header[1]>>1&0x3
+	bitrate, sampleRate := header[2]>>4, header[2]>>2&0x3
+	return version != 0x1 && layer != 0x0 && bitrate != 0xF && sampleRate != 0x3
+}
 
-	return contentType, nil
+// sniffAudioSignature detects the audio containers that start with a magic
+// signature.
+func sniffAudioSignature(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return "audio/mpeg"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return "audio/ogg"
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && string(header[8:12]) == "WAVE":
+		return "audio/wav"
+	}
+	return ""
 }
 
 // escapeQuotes 转义引号
 func escapeQuotes(s string) string {
-	return strings.Replace(s, `"`, `\"`, -1)
+	return quoteEscaper.Replace(s)
+}
+
+// contentDisposition formats the Content-Disposition of a file part. Filenames
+// that are not plain printable ASCII are sent percent-encoded in a filename*
+// parameter (RFC 6266, RFC 5987), with an ASCII approximation in filename for
+// servers that do not understand it.
+func contentDisposition(fieldname, filename string) string {
+	if isPrintableASCII(filename) {
+		return fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(fieldname), escapeQuotes(filename))
+	}
+	return fmt.Sprintf(`form-data; name="%s"; filename="%s"; filename*=UTF-8''%s`,
+		escapeQuotes(fieldname), escapeQuotes(asciiFallback(filename)), encodeRFC5987(filename))
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiFallback replaces every character of s outside printable ASCII with an
+// underscore, keeping the extension intact.
+func asciiFallback(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// encodeRFC5987 percent-encodes the UTF-8 bytes of s that are not attr-char as
+// defined by RFC 5987.
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
 }
@@ -1,13 +1,17 @@
 package openai //nolint:testpackage // testing private field
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
+	"mime"
+	"mime/multipart"
 
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -15,6 +19,7 @@ import (
 type mockFormBuilder struct {
 	mockCreateFormFile func(string, *os.File) error
 	mockWriteField     func(string, string) error
+	mockWriteJSONField func(string, any) error
 	mockClose          func() error
 }
 
@@ -26,6 +31,10 @@ func (m *mockFormBuilder) WriteField(fieldname, value string) error {
 	return m.mockWriteField(fieldname, value)
 }
 
+func (m *mockFormBuilder) WriteJSONField(fieldname string, v any) error {
+	return m.mockWriteJSONField(fieldname, v)
+}
+
 func (m *mockFormBuilder) Close() error {
 	return m.mockClose()
 }
@@ -133,6 +142,27 @@ func TestFormBuilderWithReader(t *testing.T) {
 	checks.NoError(t, err, "formbuilder should not return error")
 }
 
+func TestFormBuilderReaderContentType(t *testing.T) {
+	buf := &bytes.Buffer{}
+	builder := NewFormBuilder(buf)
+	checks.NoError(t, builder.CreateFormFileReader("file", strings.NewReader("data"), "memo.MP3"),
+		"CreateFormFileReader error")
+	checks.NoError(t, builder.CreateFormFileReader("file", strings.NewReader("data"), "notes.unknown"),
+		"CreateFormFileReader error")
+	checks.NoError(t, builder.CreateFormFileReader("file", &readerWithNameAndContentType{strings.NewReader("")},
+		"image.webp"), "CreateFormFileReader error")
+	checks.NoError(t, builder.Close(), "Close error")
+
+	reader := multipart.NewReader(buf, builder.writer.Boundary())
+	for _, want := range []string{"audio/mpeg", "", "image/png"} {
+		part, err := reader.NextPart()
+		checks.NoError(t, err, "failed to read part")
+		if got := part.Header.Get("Content-Type"); got != want {
+			t.Errorf("%s: Content-Type %q, want %q", part.FileName(), got, want)
+		}
+	}
+}
+
 func TestFormDataContentType(t *testing.T) {
 	t.Run("ReturnsUnderlyingWriterContentType", func(t *testing.T) {
 		buf := &bytes.Buffer{}
@@ -163,6 +193,51 @@ func TestWriteField(t *testing.T) {
 	})
 }
 
+func TestWriteJSONField(t *testing.T) {
+	t.Run("EmptyFieldNameShouldReturnError", func(t *testing.T) {
+		builder := NewFormBuilder(&bytes.Buffer{})
+		err := builder.WriteJSONField("", map[string]string{"a": "b"})
+		checks.HasError(t, err, "should reject empty fieldname")
+	})
+
+	t.Run("MarshalErrorShouldReturnError", func(t *testing.T) {
+		builder := NewFormBuilder(&bytes.Buffer{})
+		err := builder.WriteJSONField("key", make(chan int))
+		var jsonErr *json.UnsupportedTypeError
+		if !errors.As(err, &jsonErr) {
+			t.Fatalf("expected json.UnsupportedTypeError, got %v", err)
+		}
+	})
+
+	t.Run("WriterErrorShouldPropagate", func(t *testing.T) {
+		builder := NewFormBuilder(&failingWriter{})
+		err := builder.WriteJSONField("key", 1)
+		checks.ErrorIs(t, err, errMockFailingWriterError, "should propagate writer error")
+	})
+
+	t.Run("WritesJSONPart", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		builder := NewFormBuilder(buf)
+		err := builder.WriteJSONField("metadata", map[string]any{"size": "1024x1024", "n": 2})
+		checks.NoError(t, err, "should write json field without error")
+		checks.NoError(t, builder.Close(), "should close builder")
+
+		reader := multipart.NewReader(buf, builder.writer.Boundary())
+		part, err := reader.NextPart()
+		checks.NoErrorF(t, err, "should read part")
+		if part.FormName() != "metadata" {
+			t.Fatalf("unexpected field name %q", part.FormName())
+		}
+		if ct := part.Header.Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("unexpected content type %q", ct)
+		}
+		body, _ := io.ReadAll(part)
+		if string(body) != `{"n":2,"size":"1024x1024"}` {
+			t.Fatalf("unexpected body %s", body)
+		}
+	})
+}
+
 func TestCreateFormFile(t *testing.T) {
 	buf := &bytes.Buffer{}
 	builder := NewFormBuilder(buf)
@@ -188,3 +263,257 @@ func TestCreateFormFileSuccess(t *testing.T) {
 		t.Fatalf("expected filename header, got %q", buf.String())
 	}
 }
+
+func TestGetFileContentTypeAudio(t *testing.T) {
+	riff := append([]byte("RIFF\x24\x00\x00\x00WAVEfmt "), make([]byte, 16)...)
+	tests := []struct {
+		name    string
+		pattern string
+		content []byte
+		want    string
+	}{
+		{"mp3 by extension", "*.mp3", []byte("not really audio"), "audio/mpeg"},
+		{"m4a by extension", "*.m4a", []byte("not really audio"), "audio/mp4"},
+		{"webm by extension", "*.webm", []byte("not really audio"), "audio/webm"},
+		{"mpeg by extension", "*.mpeg", []byte("not really audio"), "audio/mpeg"},
+		{"extensionless id3", "audio", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), "audio/mpeg"},
+		{"extensionless mpeg frame", "audio", []byte{0xFF, 0xFB, 0x90, 0x00}, "audio/mpeg"},
+		{"extensionless flac", "audio", []byte("fLaC\x00\x00\x00\x22"), "audio/flac"},
+		{"extensionless ogg", "audio", []byte("OggS\x00\x02"), "audio/ogg"},
+		{"extensionless wav", "audio", riff, "audio/wav"},
+		{"flac named mp3", "*.mp3", []byte("fLaC\x00\x00\x00\x22"), "audio/flac"},
+		{"wav named txt", "*.txt", riff, "audio/wav"},
+		{"png by extension", "*.png", []byte("whatever"), "image/png"},
+		{"utf-16le text", "*.txt", []byte("\xFF\xFEh\x00i\x00"), "text/plain"},
+		{"utf-16le jsonl", "*.jsonl", []byte("\xFF\xFE{\x00}\x00"), "application/jsonl"},
+		{"invalid mpeg frame", "audio", []byte{0xFF, 0xFB, 0xF0, 0x00}, "application/octet-stream"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := os.CreateTemp(t.TempDir(), tc.pattern)
+			checks.NoErrorF(t, err, "failed to create temp file")
+			defer file.Close()
+			_, err = file.Write(tc.content)
+			checks.NoErrorF(t, err, "failed to write temp file")
+
+			got, err := getFileContentType(file)
+			checks.NoError(t, err, "getFileContentType should not fail")
+			if got != tc.want {
+				t.Fatalf("getFileContentType() = %q, want %q", got, tc.want)
+			}
+
+			pos, _ := file.Seek(0, io.SeekCurrent)
+			if pos != int64(len(tc.content)) {
+				t.Fatalf("file offset should be restored, got %d", pos)
+			}
+		})
+	}
+}
+
+func TestCreateFormFileContentTypeAudio(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "recording")
+	checks.NoErrorF(t, err, "failed to create temp file")
+	defer file.Close()
+	_, err = file.Write([]byte("OggS\x00\x02rest of the stream"))
+	checks.NoErrorF(t, err, "failed to write temp file")
+
+	buf := &bytes.Buffer{}
+	builder := NewFormBuilder(buf)
+	checks.NoError(t, builder.CreateFormFileContentType("file", file), "should create form file")
+	checks.NoError(t, builder.Close(), "should close builder")
+
+	if !strings.Contains(buf.String(), "Content-Type: audio/ogg") {
+		t.Fatalf("expected audio/ogg part content type, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "rest of the stream") {
+		t.Fatalf("expected file contents in form, got %q", buf.String())
+	}
+}
+
+func TestFormBuilderWithProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100<<10)
+
+	type report struct{ written, total int64 }
+	collect := func(reports *[]report) func(int64, int64) {
+		return func(written, total int64) {
+			*reports = append(*reports, report{written, total})
+		}
+	}
+
+	t.Run("File", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "upload-*.jsonl")
+		checks.NoError(t, err, "failed to create temp file")
+		defer file.Close()
+		_, err = file.Write(content)
+		checks.NoError(t, err, "failed to write temp file")
+		_, err = file.Seek(0, io.SeekStart)
+		checks.NoError(t, err, "failed to rewind temp file")
+
+		var reports []report
+		builder := NewFormBuilderWithProgress(&bytes.Buffer{}, collect(&reports))
+		checks.NoError(t, builder.CreateFormFile("file", file), "CreateFormFile error")
+
+		if len(reports) < 2 {
+			t.Fatalf("expected several progress reports, got %d", len(reports))
+		}
+		last := reports[len(reports)-1]
+		if last.written != int64(len(content)) || last.total != int64(len(content)) {
+			t.Fatalf("unexpected final progress %+v", last)
+		}
+		for i := 1; i < len(reports); i++ {
+			if reports[i].written <= reports[i-1].written {
+				t.Fatalf("progress is not increasing: %+v", reports)
+			}
+		}
+	})
+
+	t.Run("UnknownSize", func(t *testing.T) {
+		var reports []report
+		builder := NewFormBuilderWithProgress(&bytes.Buffer{}, collect(&reports))
+		r := io.MultiReader(bytes.NewReader(content))
+		checks.NoError(t, builder.CreateFormFileReader("file", r, "upload.bin"), "CreateFormFileReader error")
+
+		last := reports[len(reports)-1]
+		if last.written != int64(len(content)) || last.total != -1 {
+			t.Fatalf("unexpected final progress %+v", last)
+		}
+	})
+
+	t.Run("SizeMethod", func(t *testing.T) {
+		var reports []report
+		builder := NewFormBuilderWithProgress(&bytes.Buffer{}, collect(&reports))
+		r := io.NewSectionReader(bytes.NewReader(content), 0, int64(len(content)))
+		checks.NoError(t, builder.CreateFormFileReader("file", r, "upload.bin"), "CreateFormFileReader error")
+
+		last := reports[len(reports)-1]
+		if last.written != int64(len(content)) || last.total != int64(len(content)) {
+			t.Fatalf("unexpected final progress %+v", last)
+		}
+	})
+
+	t.Run("NoCallback", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		builder := NewFormBuilder(buf)
+		checks.NoError(t, builder.CreateFormFileReader("file", bytes.NewReader(content), "upload.bin"),
+			"CreateFormFileReader error")
+		if buf.Len() < len(content) {
+			t.Fatalf("form body is missing file contents")
+		}
+	})
+}
+
+func TestStreamingFormBuilder(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100<<10)
+	builder, body := NewStreamingFormBuilder()
+	go func() {
+		err := builder.CreateFormFileReader("file", bytes.NewReader(content), "upload.bin")
+		if err == nil {
+			err = builder.WriteField("purpose", "batch")
+		}
+		if err == nil {
+			err = builder.Close()
+		}
+		_ = builder.CloseWithError(err)
+	}()
+
+	_, params, err := mime.ParseMediaType(builder.FormDataContentType())
+	checks.NoError(t, err, "ParseMediaType error")
+	form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+	checks.NoError(t, err, "ReadForm error")
+	if form.Value["purpose"][0] != "batch" || form.File["file"][0].Size != int64(len(content)) {
+		t.Fatalf("unexpected form %+v", form)
+	}
+
+	errBuild := errors.New("build failed")
+	failing, failingBody := NewStreamingFormBuilder()
+	go func() {
+		_ = failing.WriteField("purpose", "batch")
+		_ = failing.CloseWithError(errBuild)
+	}()
+	_, err = io.ReadAll(failingBody)
+	checks.ErrorIs(t, err, errBuild, "the body should fail with the error of the build")
+
+	// Closing the body unblocks the builder.
+	closed, closedBody := NewStreamingFormBuilder()
+	closedBody.Close()
+	err = closed.CreateFormFileReader("file", bytes.NewReader(content), "upload.bin")
+	checks.ErrorIs(t, err, io.ErrClosedPipe, "writes should fail once the body is closed")
+}
+
+func TestContentDispositionFilenameEncoding(t *testing.T) {
+	cases := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"ASCII", "audio.mp3", `form-data; name="file"; filename="audio.mp3"`},
+		{"Quotes", `say "hi".txt`, `form-data; name="file"; filename="say \"hi\".txt"`},
+		{"Backslash", `dir\name.txt`, `form-data; name="file"; filename="dir\\name.txt"`},
+		{
+			"CJK", "会议录音.mp3",
+			`form-data; name="file"; filename="____.mp3"; filename*=UTF-8''%E4%BC%9A%E8%AE%AE%E5%BD%95%E9%9F%B3.mp3`,
+		},
+		{
+			"Emoji", "🎤 note.m4a",
+			`form-data; name="file"; filename="_ note.m4a"; filename*=UTF-8''%F0%9F%8E%A4%20note.m4a`,
+		},
+		{
+			"Newline", "a\nb.txt",
+			`form-data; name="file"; filename="a_b.txt"; filename*=UTF-8''a%0Ab.txt`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := contentDisposition("file", tc.filename); got != tc.want {
+				t.Fatalf("contentDisposition() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormFileFilenameRoundTrip(t *testing.T) {
+	names := []string{"会议录音.mp3", "🎤.wav", `quote"back\slash.txt`, "plain.jsonl"}
+	for _, name := range names {
+		buf := &bytes.Buffer{}
+		builder := NewFormBuilder(buf)
+		checks.NoError(t, builder.CreateFormFileReader("file", strings.NewReader("data"), name), "CreateFormFileReader error")
+		checks.NoError(t, builder.Close(), "Close error")
+
+		part, err := multipart.NewReader(buf, builder.writer.Boundary()).NextPart()
+		checks.NoError(t, err, "failed to read part")
+		if part.FileName() != name {
+			t.Fatalf("parsed filename %q, want %q", part.FileName(), name)
+		}
+	}
+}
+
+func TestCreateFormFileUsesBaseName(t *testing.T) {
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, "train.jsonl"))
+	checks.NoError(t, err, "failed to create file")
+	defer file.Close()
+
+	buf := &bytes.Buffer{}
+	builder := NewFormBuilder(buf)
+	checks.NoError(t, builder.CreateFormFile("file", file), "CreateFormFile error")
+	checks.NoError(t, builder.Close(), "Close error")
+
+	if strings.Contains(buf.String(), dir) {
+		t.Fatal("form body leaks the local directory of the file")
+	}
+	part, err := multipart.NewReader(buf, builder.writer.Boundary()).NextPart()
+	checks.NoError(t, err, "failed to read part")
+	if part.FileName() != "train.jsonl" {
+		t.Fatalf("unexpected filename %q", part.FileName())
+	}
+
+	// Path separators in a reader's filename are reduced to the base name too.
+	buf.Reset()
+	builder = NewFormBuilder(buf)
+	checks.NoError(t, builder.CreateFormFileReader("file", strings.NewReader("x"), "/home/user/会议.mp3"),
+		"CreateFormFileReader error")
+	if strings.Contains(buf.String(), "/home/user") {
+		t.Fatal("form body leaks the directory of the filename")
+	}
+}
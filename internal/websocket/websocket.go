@@ -0,0 +1,607 @@
+// Package websocket is a minimal WebSocket (RFC 6455) implementation, enough for
+// the JSON messages of the Realtime API: it has no extensions nor subprotocols.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // mandated by RFC 6455 for the handshake
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessageType is the opcode of a frame.
+type MessageType byte
+
+const (
+	continuationMessage MessageType = 0x0
+	TextMessage         MessageType = 0x1
+	BinaryMessage       MessageType = 0x2
+	CloseMessage        MessageType = 0x8
+	PingMessage         MessageType = 0x9
+	PongMessage         MessageType = 0xA
+)
+
+const (
+	CloseNormalClosure = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseNoStatus      = 1005
+	CloseMessageTooBig = 1009
+)
+
+// DefaultReadLimit is the default maximum size of a message.
+const DefaultReadLimit = 64 << 20
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrBadHandshake is returned by Dial when the server does not switch
+	// protocols. The response is returned along with it.
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+	// ErrClosed is returned when writing after the close frame was sent.
+	ErrClosed = errors.New("websocket: connection closed")
+	// ErrMessageTooBig is returned when a message exceeds the read limit.
+	ErrMessageTooBig = errors.New("websocket: message too big")
+
+	errProtocol = errors.New("websocket: protocol error")
+)
+
+// CloseError is returned by ReadMessage when the peer closes the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. One goroutine may read while others write.
+type Conn struct {
+	// ReadLimit is the maximum size of a message read.
+	ReadLimit int64
+	// PongHandler, if set, is called by ReadMessage with the data of the pongs
+	// it skips. Set it before reading.
+	PongHandler func(data []byte)
+
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool
+
+	readMu    sync.Mutex
+	writeMu   sync.Mutex
+	closeSent bool
+
+	closeOnce     sync.Once
+	closeReceived chan struct{}
+}
+
+func newConn(conn net.Conn, reader *bufio.Reader, client bool) *Conn {
+	return &Conn{
+		ReadLimit:     DefaultReadLimit,
+		conn:          conn,
+		reader:        reader,
+		client:        client,
+		closeReceived: make(chan struct{}),
+	}
+}
+
+// Dialer opens WebSocket connections. Its zero value dials directly, with the
+// default TLS configuration.
+type Dialer struct {
+	// NetDialContext dials the TCP connections. Nil uses a net.Dialer.
+	NetDialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// TLSClientConfig is the TLS configuration of wss:// connections and of
+	// https:// proxies. Its ServerName defaults to the host dialed.
+	TLSClientConfig *tls.Config
+	// Proxy returns the URL of the HTTP proxy to tunnel the connection through
+	// with CONNECT, like that of http.Transport. Nil, or a nil URL, dials the
+	// server directly.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// DefaultDialer dials through the proxy set by the environment, see
+// http.ProxyFromEnvironment.
+var DefaultDialer = &Dialer{Proxy: http.ProxyFromEnvironment}
+
+// Dial opens a WebSocket connection with DefaultDialer.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	return DefaultDialer.Dial(ctx, rawURL, header)
+}
+
+// Dial opens a WebSocket connection to a ws:// or wss:// URL, sending header
+// with the handshake. When the server refuses the upgrade, the error wraps
+// ErrBadHandshake and the response is returned with its body read.
+func (d *Dialer) Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var secure bool
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	case "wss", "https":
+		u.Scheme, secure = "https", true
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	addr := hostPort(u)
+
+	var proxyURL *url.URL
+	if d.Proxy != nil {
+		proxyURL, err = d.Proxy(&http.Request{Method: http.MethodGet, URL: u, Header: make(http.Header)})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var conn net.Conn
+	if proxyURL != nil {
+		conn, err = d.dialProxy(ctx, proxyURL, addr)
+	} else {
+		conn, err = d.dial(ctx, addr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if secure {
+		if conn, err = d.tlsClient(ctx, conn, u.Hostname()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ws, resp, err := handshake(ctx, conn, u, header)
+	if err != nil {
+		conn.Close()
+		return nil, resp, err
+	}
+	return ws, resp, nil
+}
+
+// hostPort returns the address of the host of u, with the default port of its
+// http or https scheme if it has none.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+func (d *Dialer) dial(ctx context.Context, addr string) (net.Conn, error) {
+	if d.NetDialContext != nil {
+		return d.NetDialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// tlsClient runs the TLS handshake with serverName over conn, closing conn
+// when it fails.
+func (d *Dialer) tlsClient(ctx context.Context, conn net.Conn, serverName string) (net.Conn, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if d.TLSClientConfig != nil {
+		config = d.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = serverName
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// dialProxy opens a tunnel to addr through the HTTP proxy at proxyURL.
+func (d *Dialer) dialProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("websocket: unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	conn, err := d.dial(ctx, hostPort(proxyURL))
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		if conn, err = d.tlsClient(ctx, conn, proxyURL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	if err = connect(ctx, conn, proxyURL, addr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// connect asks the proxy conn is connected to for a tunnel to addr.
+func connect(ctx context.Context, conn net.Conn, proxyURL *url.URL, addr string) error {
+	defer interruptOnDone(ctx, conn)()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return ctxErr(ctx, err)
+	}
+	// The proxy sends nothing after its response until the tunnel is used, so
+	// the reader buffers nothing else.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return ctxErr(ctx, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("websocket: proxy refused the connection: %s", resp.Status)
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// interruptOnDone interrupts the reads and writes of conn when ctx is done,
+// until the returned func is called.
+func interruptOnDone(ctx context.Context, conn net.Conn) func() {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+func handshake(ctx context.Context, conn net.Conn, u *url.URL, header http.Header) (*Conn, *http.Response, error) {
+	defer interruptOnDone(ctx, conn)()
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		return nil, nil, ctxErr(ctx, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, nil, ctxErr(ctx, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(strings.NewReader(string(body)))
+		return nil, resp, fmt.Errorf("%w: status %s", ErrBadHandshake, resp.Status)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, resp, fmt.Errorf("%w: invalid upgrade response", ErrBadHandshake)
+	}
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		return nil, resp, err
+	}
+	return newConn(conn, reader, true), resp, nil
+}
+
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func acceptKey(key string) string {
+	h := sha1.New() //nolint:gosec // mandated by RFC 6455
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Accept upgrades a server request to a WebSocket connection.
+func Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket upgrade unsupported", http.StatusInternalServerError)
+		return nil, ErrBadHandshake
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newConn(conn, rw.Reader, false), nil
+}
+
+// ReadMessage reads the next text or binary message. Pings are answered, and
+// pongs skipped. When the peer closes the connection, its close frame is
+// echoed and a *CloseError is returned.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	return c.readMessage()
+}
+
+func (c *Conn) readMessage() (MessageType, []byte, error) {
+	var (
+		messageType MessageType
+		message     []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			if err = c.writeFrame(PongMessage, payload); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.PongHandler != nil {
+				c.PongHandler(payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.handleClose(payload)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(errProtocol)
+			}
+			messageType = opcode
+		case continuationMessage:
+			if messageType == 0 {
+				return 0, nil, c.fail(errProtocol)
+			}
+		default:
+			return 0, nil, c.fail(errProtocol)
+		}
+		if int64(len(message)+len(payload)) > c.ReadLimit {
+			return 0, nil, c.fail(ErrMessageTooBig)
+		}
+		message = append(message, payload...)
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode MessageType, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = MessageType(header[0] & 0x0F)
+	if header[0]&0x70 != 0 {
+		err = c.fail(errProtocol)
+		return
+	}
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(c.reader, extended[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(c.reader, extended[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode >= CloseMessage && (length > 125 || !fin) {
+		err = c.fail(errProtocol)
+		return
+	}
+	if length > uint64(c.ReadLimit) {
+		err = c.fail(ErrMessageTooBig)
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatus}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+	}
+	c.closeOnce.Do(func() { close(c.closeReceived) })
+	// Echo the close frame, unless this answers the one sent by Close.
+	echo := payload
+	if len(echo) > 2 {
+		echo = echo[:2]
+	}
+	_ = c.writeFrame(CloseMessage, echo)
+	return closeErr
+}
+
+// fail closes the connection with the close code matching err.
+func (c *Conn) fail(err error) error {
+	code := CloseProtocolError
+	if errors.Is(err, ErrMessageTooBig) {
+		code = CloseMessageTooBig
+	}
+	_ = c.writeFrame(CloseMessage, closePayload(code, ""))
+	c.conn.Close()
+	return err
+}
+
+// WriteMessage writes a text or binary message in a single frame.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	return c.writeFrame(messageType, data)
+}
+
+// Ping sends a ping, which the peer answers with a pong.
+func (c *Conn) Ping(data []byte) error {
+	return c.writeFrame(PingMessage, data)
+}
+
+func (c *Conn) writeFrame(opcode MessageType, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|byte(opcode))
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[len(frame)-2:], uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(len(payload)))
+	}
+	start := len(frame)
+	if c.client {
+		// Frames sent by clients are masked.
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start = len(frame)
+		frame = append(frame, payload...)
+		maskBytes(mask, frame[start:])
+	} else {
+		frame = append(frame, payload...)
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
+
+func closePayload(code int, reason string) []byte {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return append(payload, reason...)
+}
+
+// Close closes the connection gracefully: it sends a close frame with the
+// given code and reason and waits, at most timeout, for the peer to answer it
+// before closing the underlying connection. A concurrent ReadMessage returns
+// the answer as a *CloseError.
+func (c *Conn) Close(code int, reason string, timeout time.Duration) error {
+	err := c.writeFrame(CloseMessage, closePayload(code, reason))
+	if err == nil {
+		if c.readMu.TryLock() {
+			// Nobody reads, so read up to the answer.
+			_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+			for {
+				if _, _, readErr := c.readMessage(); readErr != nil {
+					break
+				}
+			}
+			c.readMu.Unlock()
+		} else {
+			timer := time.NewTimer(timeout)
+			select {
+			case <-c.closeReceived:
+			case <-timer.C:
+			}
+			timer.Stop()
+		}
+	} else if !errors.Is(err, ErrClosed) {
+		c.conn.Close()
+		return err
+	}
+	if closeErr := c.conn.Close(); closeErr != nil && !errors.Is(closeErr, net.ErrClosed) {
+		return closeErr
+	}
+	return nil
+}
+
+// CloseNow closes the underlying connection without the close handshake, as
+// when the peer stopped answering.
+func (c *Conn) CloseNow() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,157 @@
+package websocket_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/websocket"
+)
+
+func TestEcho(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"error":{"message":"unauthorized"}}`, http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(messageType, message); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	ctx := context.Background()
+
+	_, resp, err := websocket.Dial(ctx, url, nil)
+	if !errors.Is(err, websocket.ErrBadHandshake) || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a bad handshake, got %v", err)
+	}
+
+	conn, _, err := websocket.Dial(ctx, url, http.Header{"Authorization": {"Bearer token"}})
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	if err = conn.Ping([]byte("ping")); err != nil {
+		t.Fatalf("Ping error: %v", err)
+	}
+	for _, message := range [][]byte{[]byte("hello"), bytes.Repeat([]byte("a"), 200), bytes.Repeat([]byte("b"), 70000)} {
+		if err = conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			t.Fatalf("WriteMessage error: %v", err)
+		}
+		messageType, echo, err := conn.ReadMessage()
+		if err != nil || messageType != websocket.TextMessage || !bytes.Equal(echo, message) {
+			t.Fatalf("unexpected echo of %d bytes: %d bytes, %v", len(message), len(echo), err)
+		}
+	}
+
+	start := time.Now()
+	if err = conn.Close(websocket.CloseNormalClosure, "bye", time.Second); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("the close handshake should not wait for the timeout")
+	}
+	if err = conn.WriteMessage(websocket.TextMessage, []byte("late")); !errors.Is(err, websocket.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		_ = conn.Close(websocket.CloseGoingAway, "restarting", time.Second)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway || closeErr.Reason != "restarting" {
+		t.Fatalf("expected a close error, got %v", err)
+	}
+	if err = conn.Close(websocket.CloseNormalClosure, "", time.Second); err != nil {
+		t.Errorf("Close error: %v", err)
+	}
+}
+
+func TestDialProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+		_ = conn.Close(websocket.CloseNormalClosure, "", time.Second)
+	}))
+	defer server.Close()
+
+	var tunneled string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		tunneled = r.Host
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, rw, _ := w.(http.Hijacker).Hijack()
+		_, _ = rw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+		_ = rw.Flush()
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "pass")
+	dialer := &websocket.Dialer{Proxy: http.ProxyURL(proxyURL)}
+	serverURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := dialer.Dial(context.Background(), serverURL, nil)
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	defer conn.Close(websocket.CloseNormalClosure, "", time.Second)
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != "hello" {
+		t.Fatalf("unexpected message %q, %v", message, err)
+	}
+	if tunneled != strings.TrimPrefix(server.URL, "http://") {
+		t.Errorf("tunneled to %q", tunneled)
+	}
+
+	proxyURL.User = nil
+	if _, _, err = dialer.Dial(context.Background(), serverURL, nil); err == nil ||
+		!strings.Contains(err.Error(), "403") {
+		t.Errorf("expected the proxy to refuse the connection, got %v", err)
+	}
+}
@@ -48,6 +48,8 @@ type Definition struct {
 	AdditionalProperties any `json:"additionalProperties,omitempty"`
 	// Whether the schema is nullable or not.
 	Nullable bool `json:"nullable,omitempty"`
+	// AnyOf is satisfied by a value matching at least one of the definitions.
+	AnyOf []Definition `json:"anyOf,omitempty"`
 
 	// Ref Reference to a definition in $defs or external schema.
 	Ref string `json:"$ref,omitempty"`
@@ -72,8 +74,32 @@ func (d *Definition) Unmarshal(content string, v any) error {
 }
 
 func GenerateSchemaForType(v any) (*Definition, error) {
+	return generateSchema(reflect.TypeOf(v), false)
+}
+
+// GenerateStrictSchemaForType generates the schema of the type of v for the
+// strict mode of Structured Outputs. Every object in it forbids additional
+// properties and lists all of its properties as required; optional fields
+// (omitempty, pointers, or tagged required:"false" or nullable:"true") accept
+// null instead of being left out. Maps cannot be described in strict mode and
+// are reported as an error, as is a type that is not a struct.
+func GenerateStrictSchemaForType(v any) (*Definition, error) {
+	def, err := generateSchema(reflect.TypeOf(v), true)
+	if err != nil {
+		return nil, err
+	}
+	if def.Type != Object {
+		return nil, fmt.Errorf("strict schema root must be an object, got %s", reflect.TypeOf(v))
+	}
+	return def, nil
+}
+
+func generateSchema(t reflect.Type, strict bool) (*Definition, error) {
+	if t == nil {
+		return nil, fmt.Errorf("unsupported type: %s", reflect.Invalid)
+	}
 	var defs = make(map[string]Definition)
-	def, err := reflectSchema(reflect.TypeOf(v), defs)
+	def, err := reflectSchema(t, defs, strict)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +128,7 @@ func GenerateSchemaForType(v any) (*Definition, error) {
 	return def, nil
 }
 
-func reflectSchema(t reflect.Type, defs map[string]Definition) (*Definition, error) {
+func reflectSchema(t reflect.Type, defs map[string]Definition, strict bool) (*Definition, error) {
 	var d Definition
 	switch t.Kind() {
 	case reflect.String:
@@ -116,7 +142,7 @@ func reflectSchema(t reflect.Type, defs map[string]Definition) (*Definition, err
 		d.Type = Boolean
 	case reflect.Slice, reflect.Array:
 		d.Type = Array
-		items, err := reflectSchema(t.Elem(), defs)
+		items, err := reflectSchema(t.Elem(), defs, strict)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +151,7 @@ func reflectSchema(t reflect.Type, defs map[string]Definition) (*Definition, err
 		if t.Name() != "" {
 			if _, ok := defs[t.Name()]; !ok {
 				defs[t.Name()] = Definition{}
-				object, err := reflectSchemaObject(t, defs)
+				object, err := reflectSchemaObject(t, defs, strict)
 				if err != nil {
 					return nil, err
 				}
@@ -135,19 +161,32 @@ func reflectSchema(t reflect.Type, defs map[string]Definition) (*Definition, err
 		}
 		d.Type = Object
 		d.AdditionalProperties = false
-		object, err := reflectSchemaObject(t, defs)
+		object, err := reflectSchemaObject(t, defs, strict)
 		if err != nil {
 			return nil, err
 		}
 		d = *object
 	case reflect.Ptr:
-		definition, err := reflectSchema(t.Elem(), defs)
+		definition, err := reflectSchema(t.Elem(), defs, strict)
 		if err != nil {
 			return nil, err
 		}
 		d = *definition
+	case reflect.Map:
+		if strict {
+			return nil, fmt.Errorf("unsupported type in strict mode: %s", t)
+		}
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type: %s", t.Key())
+		}
+		values, err := reflectSchema(t.Elem(), defs, strict)
+		if err != nil {
+			return nil, err
+		}
+		d.Type = Object
+		d.AdditionalProperties = *values
 	case reflect.Invalid, reflect.Uintptr, reflect.Complex64, reflect.Complex128,
-		reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Chan, reflect.Func, reflect.Interface,
 		reflect.UnsafePointer:
 		return nil, fmt.Errorf("unsupported type: %s", t.Kind().String())
 	default:
@@ -155,7 +194,7 @@ func reflectSchema(t reflect.Type, defs map[string]Definition) (*Definition, err
 	return &d, nil
 }
 
-func reflectSchemaObject(t reflect.Type, defs map[string]Definition) (*Definition, error) {
+func reflectSchemaObject(t reflect.Type, defs map[string]Definition, strict bool) (*Definition, error) {
 	var d = Definition{
 		Type:                 Object,
 		AdditionalProperties: false,
@@ -179,7 +218,7 @@ func reflectSchemaObject(t reflect.Type, defs map[string]Definition) (*Definitio
 			required = false
 		}
 
-		item, err := reflectSchema(field.Type, defs)
+		item, err := reflectSchema(field.Type, defs, strict)
 		if err != nil {
 			return nil, err
 		}
@@ -192,16 +231,25 @@ func reflectSchemaObject(t reflect.Type, defs map[string]Definition) (*Definitio
 			item.Enum = strings.Split(enum, ",")
 		}
 
+		var nullable bool
 		if n := field.Tag.Get("nullable"); n != "" {
-			nullable, _ := strconv.ParseBool(n)
+			nullable, _ = strconv.ParseBool(n)
 			item.Nullable = nullable
 		}
 
-		properties[jsonTag] = *item
-
 		if s := field.Tag.Get("required"); s != "" {
 			required, _ = strconv.ParseBool(s)
 		}
+		if strict {
+			// Strict mode requires every property; optional ones accept null.
+			if !required || nullable || field.Type.Kind() == reflect.Ptr {
+				item = nullableDefinition(item)
+			}
+			required = true
+		}
+
+		properties[jsonTag] = *item
+
 		if required {
 			requiredFields = append(requiredFields, jsonTag)
 		}
@@ -211,6 +259,17 @@ func reflectSchemaObject(t reflect.Type, defs map[string]Definition) (*Definitio
 	return &d, nil
 }
 
+// nullableDefinition returns a definition accepting null as well as values of d.
+func nullableDefinition(d *Definition) *Definition {
+	description := d.Description
+	d.Description = ""
+	d.Nullable = false
+	return &Definition{
+		Description: description,
+		AnyOf:       []Definition{*d, {Type: Null}},
+	}
+}
+
 func containsRef(def Definition, targetRef string) bool {
 	if def.Ref == targetRef {
 		return true
@@ -228,8 +287,17 @@ func containsRef(def Definition, targetRef string) bool {
 		}
 	}
 
+	for _, d := range def.AnyOf {
+		if containsRef(d, targetRef) {
+			return true
+		}
+	}
+
 	if def.Items != nil && containsRef(*def.Items, targetRef) {
 		return true
 	}
+	if values, ok := def.AdditionalProperties.(Definition); ok && containsRef(values, targetRef) {
+		return true
+	}
 	return false
 }
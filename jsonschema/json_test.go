@@ -668,3 +668,116 @@ func structToMap(t *testing.T, v any) map[string]any {
 	}
 	return got
 }
+
+type strictAddress struct {
+	City    string  `json:"city"`
+	Country string  `json:"country" enum:"FR,JP,US"`
+	Zip     *string `json:"zip"`
+}
+
+type strictPerson struct {
+	Name     string         `json:"name" description:"Full name"`
+	Nickname string         `json:"nickname,omitempty"`
+	Address  *strictAddress `json:"address,omitempty"`
+	Tags     []string       `json:"tags"`
+}
+
+func TestGenerateStrictSchemaForType(t *testing.T) {
+	schema, err := jsonschema.GenerateStrictSchemaForType(strictPerson{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantJSON := `{
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["name", "nickname", "address", "tags"],
+		"properties": {
+			"name": {"type": "string", "description": "Full name"},
+			"nickname": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+			"address": {"anyOf": [{"$ref": "#/$defs/strictAddress"}, {"type": "null"}]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"$defs": {
+			"strictAddress": {
+				"type": "object",
+				"additionalProperties": false,
+				"required": ["city", "country", "zip"],
+				"properties": {
+					"city": {"type": "string"},
+					"country": {"type": "string", "enum": ["FR", "JP", "US"]},
+					"zip": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+				}
+			}
+		}
+	}`
+	var want map[string]any
+	if err = json.Unmarshal([]byte(wantJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if got := structToMap(t, schema); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected strict schema:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestGenerateStrictSchemaForTypeErrors(t *testing.T) {
+	type withMap struct {
+		Scores map[string]int `json:"scores"`
+	}
+	if _, err := jsonschema.GenerateStrictSchemaForType(withMap{}); err == nil {
+		t.Error("expected maps to be rejected in strict mode")
+	}
+	if _, err := jsonschema.GenerateStrictSchemaForType("not an object"); err == nil {
+		t.Error("expected a non-object root to be rejected")
+	}
+}
+
+func TestGenerateSchemaForTypeMap(t *testing.T) {
+	type withMap struct {
+		Scores map[string]int `json:"scores"`
+	}
+	schema, err := jsonschema.GenerateSchemaForType(withMap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := schema.Properties["scores"]
+	if scores.Type != jsonschema.Object ||
+		!reflect.DeepEqual(scores.AdditionalProperties, jsonschema.Definition{Type: jsonschema.Integer}) {
+		t.Fatalf("unexpected map schema %+v", scores)
+	}
+	if !jsonschema.Validate(*schema, map[string]any{"scores": map[string]any{"a": 1.0}}) {
+		t.Error("expected integer map values to validate")
+	}
+	if jsonschema.Validate(*schema, map[string]any{"scores": map[string]any{"a": "x"}}) {
+		t.Error("expected string map values to fail validation")
+	}
+
+	type withIntKeys struct {
+		M map[int]string `json:"m"`
+	}
+	if _, err = jsonschema.GenerateSchemaForType(withIntKeys{}); err == nil {
+		t.Error("expected non-string map keys to be rejected")
+	}
+}
+
+func TestValidateStrictSchema(t *testing.T) {
+	schema, err := jsonschema.GenerateStrictSchemaForType(strictPerson{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := `{"name":"Ann","nickname":null,"tags":[],
+		"address":{"city":"Paris","country":"FR","zip":null}}`
+	var v strictPerson
+	if err = schema.Unmarshal(valid, &v); err != nil {
+		t.Fatalf("expected valid data, got %v", err)
+	}
+	if v.Address == nil || v.Address.Country != "FR" || v.Address.Zip != nil {
+		t.Fatalf("unexpected decoded value %+v", v)
+	}
+
+	invalid := `{"name":"Ann","nickname":null,"tags":[],
+		"address":{"city":"Paris","country":"DE","zip":null}}`
+	if err = schema.Unmarshal(invalid, &v); err == nil {
+		t.Fatal("expected an enum violation to fail validation")
+	}
+}
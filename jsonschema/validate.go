@@ -20,6 +20,9 @@ func collectDefsRecursive(def Definition, result map[string]Definition, prefix s
 	for k, sub := range def.Properties {
 		collectDefsRecursive(sub, result, prefix+"/properties/"+k)
 	}
+	for _, sub := range def.AnyOf {
+		collectDefsRecursive(sub, result, prefix)
+	}
 	if def.Items != nil {
 		collectDefsRecursive(*def.Items, result, prefix)
 	}
@@ -57,6 +60,17 @@ func Validate(schema Definition, data any, opts ...ValidateOption) bool {
 	if len(opts) == 0 {
 		args.Defs = CollectDefs(schema)
 	}
+	if data == nil && schema.Nullable {
+		return true
+	}
+	if len(schema.AnyOf) > 0 {
+		for _, sub := range schema.AnyOf {
+			if Validate(sub, data, WithDefs(args.Defs)) {
+				return true
+			}
+		}
+		return false
+	}
 	switch schema.Type {
 	case Object:
 		return validateObject(schema, data, args.Defs)
@@ -114,6 +128,13 @@ func validateObject(schema Definition, data any, defs map[string]Definition) boo
 			return false
 		}
 	}
+	if additional, ok := schema.AdditionalProperties.(Definition); ok {
+		for key, value := range dataMap {
+			if _, defined := schema.Properties[key]; !defined && !Validate(additional, value, WithDefs(defs)) {
+				return false
+			}
+		}
+	}
 	return true
 }
 
@@ -0,0 +1,171 @@
+package openai
+
+import "context"
+
+// ListIterator iterates over the items of a list endpoint paginated with
+// Pagination, fetching the pages as needed by following their last ID.
+//
+//	it := client.ListRunsIterator(ctx, threadID, openai.Pagination{})
+//	for it.Next() {
+//		run := it.Current()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ListIterator[T any] struct {
+	ctx   context.Context
+	after string
+	fetch func(ctx context.Context, after string) (items []T, next string, hasMore bool, err error)
+
+	page    []T
+	index   int
+	current T
+	more    bool
+	err     error
+}
+
+// newListIterator returns an iterator over the pages returned by fetch, starting
+// after the cursor after. fetch returns the items of the page following after,
+// and the cursor of the next page.
+func newListIterator[T any](
+	ctx context.Context,
+	after string,
+	fetch func(ctx context.Context, after string) ([]T, string, bool, error),
+) *ListIterator[T] {
+	return &ListIterator[T]{ctx: ctx, after: after, fetch: fetch, more: true}
+}
+
+// newPaginationIterator returns an iterator over the pages of an endpoint
+// paginated with Pagination.
+func newPaginationIterator[T any](
+	ctx context.Context,
+	pagination Pagination,
+	fetch func(context.Context, Pagination) ([]T, string, bool, error),
+) *ListIterator[T] {
+	return newListIterator(ctx, stringValue(pagination.After),
+		func(ctx context.Context, after string) ([]T, string, bool, error) {
+			if after != "" {
+				pagination.After = &after
+			}
+			return fetch(ctx, pagination)
+		})
+}
+
+// Next advances to the next item, fetching the next page if needed. It returns
+// false when the items run out or a page cannot be fetched, see Err.
+func (it *ListIterator[T]) Next() bool {
+	for it.index >= len(it.page) {
+		if !it.more || it.err != nil {
+			return false
+		}
+		var next string
+		it.page, next, it.more, it.err = it.fetch(it.ctx, it.after)
+		it.index = 0
+		if it.err != nil {
+			return false
+		}
+		if next == "" || next == it.after {
+			// Without a new cursor the same page would be fetched forever.
+			it.more = false
+		}
+		it.after = next
+	}
+	it.current = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Current returns the item Next advanced to.
+func (it *ListIterator[T]) Current() T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+// ForEach calls fn with each of the remaining items, until fn returns an error
+// or the items run out. It returns the error of fn or of fetching a page.
+func (it *ListIterator[T]) ForEach(fn func(T) error) error {
+	for it.Next() {
+		if err := fn(it.Current()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// all returns the remaining items, and those listed before an error.
+func (it *ListIterator[T]) all() (items []T, err error) {
+	err = it.ForEach(func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// lastID returns the ID of the last item of a page, the cursor of the next page
+// of the lists that do not report it.
+func lastID[T any](items []T, id func(T) string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return id(items[len(items)-1])
+}
+
+// ListAssistantsIterator iterates over the assistants listed by
+// ListAssistantsWithPagination.
+func (c *Client) ListAssistantsIterator(ctx context.Context, pagination Pagination) *ListIterator[Assistant] {
+	return newPaginationIterator(ctx, pagination,
+		func(ctx context.Context, pagination Pagination) ([]Assistant, string, bool, error) {
+			page, err := c.ListAssistantsWithPagination(ctx, pagination)
+			return page.Assistants, stringValue(page.LastID), page.HasMore, err
+		})
+}
+
+// ListMessagesIterator iterates over the messages listed by
+// ListMessagesWithPagination.
+func (c *Client) ListMessagesIterator(
+	ctx context.Context,
+	threadID string,
+	pagination Pagination,
+	runID string,
+) *ListIterator[Message] {
+	return newPaginationIterator(ctx, pagination,
+		func(ctx context.Context, pagination Pagination) ([]Message, string, bool, error) {
+			page, err := c.ListMessagesWithPagination(ctx, threadID, pagination, runID)
+			return page.Messages, stringValue(page.LastID), page.HasMore, err
+		})
+}
+
+// ListRunsIterator iterates over the runs of a thread listed by ListRuns.
+func (c *Client) ListRunsIterator(ctx context.Context, threadID string, pagination Pagination) *ListIterator[Run] {
+	return newPaginationIterator(ctx, pagination,
+		func(ctx context.Context, pagination Pagination) ([]Run, string, bool, error) {
+			page, err := c.ListRuns(ctx, threadID, pagination)
+			return page.Runs, page.LastID, page.HasMore, err
+		})
+}
+
+// ListRunStepsIterator iterates over the steps of a run listed by ListRunSteps.
+func (c *Client) ListRunStepsIterator(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	pagination Pagination,
+) *ListIterator[RunStep] {
+	return newPaginationIterator(ctx, pagination,
+		func(ctx context.Context, pagination Pagination) ([]RunStep, string, bool, error) {
+			page, err := c.ListRunSteps(ctx, threadID, runID, pagination)
+			return page.RunSteps, page.LastID, page.HasMore, err
+		})
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
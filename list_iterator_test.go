@@ -0,0 +1,100 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestPaginationQuery(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		fmt.Fprint(w, `{"object":"list","data":[]}`)
+	}
+	server.RegisterHandler("/v1/assistants", handler)
+	server.RegisterHandler("/v1/threads/thread_abc123/messages", handler)
+	server.RegisterHandler("/v1/threads/thread_abc123/runs", handler)
+	server.RegisterHandler("/v1/threads/thread_abc123/runs/run_abc123/steps", handler)
+	ctx := context.Background()
+	limit, order, after, before := 20, "asc", "obj_1", "obj_9"
+	pagination := openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before}
+
+	for _, p := range []openai.Pagination{{}, pagination} {
+		_, err := client.ListAssistantsWithPagination(ctx, p)
+		checks.NoError(t, err, "ListAssistantsWithPagination error")
+		_, err = client.ListMessagesWithPagination(ctx, "thread_abc123", p, "")
+		checks.NoError(t, err, "ListMessagesWithPagination error")
+		_, err = client.ListRuns(ctx, "thread_abc123", p)
+		checks.NoError(t, err, "ListRuns error")
+		_, err = client.ListRunSteps(ctx, "thread_abc123", "run_abc123", p)
+		checks.NoError(t, err, "ListRunSteps error")
+	}
+	_, err := client.ListMessagesWithPagination(ctx, "thread_abc123", openai.Pagination{}, "run_abc123")
+	checks.NoError(t, err, "ListMessagesWithPagination error")
+
+	want := "after=obj_1&before=obj_9&limit=20&order=asc"
+	for i, query := range queries {
+		switch {
+		case i < 4 && query != "":
+			t.Errorf("empty pagination should add no query, got %q", query)
+		case i >= 4 && i < 8 && query != want:
+			t.Errorf("got query %q, want %q", query, want)
+		case i == 8 && query != "run_id=run_abc123":
+			t.Errorf("unexpected query %q", query)
+		}
+	}
+}
+
+func TestListIterator(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var afters []string
+	server.RegisterHandler("/v1/threads/thread_abc123/runs", func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("after")
+		afters = append(afters, after)
+		switch after {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"run_1"},{"id":"run_2"}],"first_id":"run_1","last_id":"run_2","has_more":true}`)
+		case "run_2":
+			fmt.Fprint(w, `{"data":[{"id":"run_3"}],"first_id":"run_3","last_id":"run_3","has_more":false}`)
+		default:
+			http.Error(w, `{"error":{"message":"unexpected cursor"}}`, http.StatusBadRequest)
+		}
+	})
+	ctx := context.Background()
+
+	var ids []string
+	it := client.ListRunsIterator(ctx, "thread_abc123", openai.Pagination{})
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	checks.NoError(t, it.Err(), "iteration error")
+	if fmt.Sprint(ids) != "[run_1 run_2 run_3]" || fmt.Sprint(afters) != "[ run_2]" {
+		t.Errorf("unexpected runs %v fetched after %q", ids, afters)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := client.ListRunsIterator(ctx, "thread_abc123", openai.Pagination{}).ForEach(func(openai.Run) error {
+		calls++
+		return stop
+	})
+	checks.ErrorIs(t, err, stop, "ForEach should return the error of the callback")
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+
+	after := "run_9"
+	it = client.ListRunsIterator(ctx, "thread_abc123", openai.Pagination{After: &after})
+	if it.Next() || it.Err() == nil {
+		t.Error("expected the error of the failed page")
+	}
+}
@@ -0,0 +1,183 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type MessageAnnotationType string
+
+const (
+	// MessageAnnotationTypeFileCitation cites a quote of a file searched by the
+	// assistant.
+	MessageAnnotationTypeFileCitation MessageAnnotationType = "file_citation"
+	// MessageAnnotationTypeFilePath links to a file generated by the code
+	// interpreter.
+	MessageAnnotationTypeFilePath MessageAnnotationType = "file_path"
+)
+
+// MessageAnnotation marks the placeholder Text of a message, between the
+// character indexes StartIndex and EndIndex of its text value.
+type MessageAnnotation struct {
+	Type         MessageAnnotationType `json:"type"`
+	Text         string                `json:"text"`
+	StartIndex   int                   `json:"start_index"`
+	EndIndex     int                   `json:"end_index"`
+	FileCitation *MessageFileCitation  `json:"file_citation,omitempty"`
+	FilePath     *MessageFilePath      `json:"file_path,omitempty"`
+}
+
+type MessageFileCitation struct {
+	FileID string `json:"file_id"`
+	Quote  string `json:"quote,omitempty"`
+}
+
+type MessageFilePath struct {
+	FileID string `json:"file_id"`
+}
+
+// MessageCitation is a file cited by a message, numbered as the footnote that
+// replaced its placeholders.
+type MessageCitation struct {
+	Number int
+	Type   MessageAnnotationType
+	FileID string
+	// Quote is the quote of the file, for file citations.
+	Quote string
+	// Content is the content of the file, for file paths, once downloaded with
+	// DownloadMessageCitations.
+	Content []byte
+}
+
+// ResolvedMessage is the text of a message with its annotations resolved.
+type ResolvedMessage struct {
+	// Text is the text of the message, with its text content parts joined by
+	// newlines and the placeholders of annotations replaced by footnote markers
+	// such as "[1]".
+	Text string
+	// Citations are the files cited by Text, in footnote order.
+	Citations []MessageCitation
+}
+
+// ResolveMessageAnnotations replaces the placeholders of the annotations of a
+// message by footnote markers, and lists the files they cite. Footnotes are
+// numbered in reading order, and annotations of the same file and quote share
+// their footnote. An annotation overlapping the
+// previous one only replaces the rest of its range, and one whose indexes do
+// not match its text is looked up by its text instead.
+func ResolveMessageAnnotations(message Message) ResolvedMessage {
+	var (
+		resolved ResolvedMessage
+		texts    []string
+		numbers  = make(map[citationKey]int)
+	)
+	for _, content := range message.Content {
+		if content.Text == nil {
+			continue
+		}
+		text := resolveTextAnnotations(content.Text.Value, content.Text.MessageAnnotations,
+			func(annotation MessageAnnotation) string {
+				citation := annotationCitation(annotation)
+				key := citationKey{citation.Type, citation.FileID, citation.Quote}
+				number, ok := numbers[key]
+				if !ok {
+					number = len(resolved.Citations) + 1
+					numbers[key] = number
+					citation.Number = number
+					resolved.Citations = append(resolved.Citations, citation)
+				}
+				return fmt.Sprintf("[%d]", number)
+			})
+		texts = append(texts, text)
+	}
+	resolved.Text = strings.Join(texts, "\n")
+	return resolved
+}
+
+type citationKey struct {
+	annotationType MessageAnnotationType
+	fileID, quote  string
+}
+
+func annotationCitation(annotation MessageAnnotation) MessageCitation {
+	citation := MessageCitation{Type: annotation.Type}
+	switch {
+	case annotation.FileCitation != nil:
+		citation.FileID = annotation.FileCitation.FileID
+		citation.Quote = annotation.FileCitation.Quote
+	case annotation.FilePath != nil:
+		citation.FileID = annotation.FilePath.FileID
+	}
+	return citation
+}
+
+// resolveTextAnnotations replaces the ranges of annotations in value, indexed in
+// characters, by the markers returned by marker.
+func resolveTextAnnotations(
+	value string,
+	annotations []MessageAnnotation,
+	marker func(MessageAnnotation) string,
+) string {
+	type span struct {
+		start, end int
+		annotation MessageAnnotation
+	}
+	text := []rune(value)
+	spans := make([]span, 0, len(annotations))
+	for _, annotation := range annotations {
+		start, end := annotation.StartIndex, annotation.EndIndex
+		if start < 0 || end < start || end > len(text) ||
+			(annotation.Text != "" && string(text[start:end]) != annotation.Text) {
+			// Fall back to the placeholder text when the indexes do not match it.
+			i := strings.Index(value, annotation.Text)
+			if annotation.Text == "" || i < 0 {
+				continue
+			}
+			start = len([]rune(value[:i]))
+			end = start + len([]rune(annotation.Text))
+		}
+		spans = append(spans, span{start, end, annotation})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var (
+		b      strings.Builder
+		cursor int
+	)
+	for _, s := range spans {
+		if s.start > cursor {
+			b.WriteString(string(text[cursor:s.start]))
+			cursor = s.start
+		}
+		b.WriteString(marker(s.annotation))
+		if s.end > cursor {
+			cursor = s.end
+		}
+	}
+	b.WriteString(string(text[cursor:]))
+	return b.String()
+}
+
+// DownloadMessageCitations downloads the content of the files generated by the
+// code interpreter that are cited by a resolved message.
+func (c *Client) DownloadMessageCitations(ctx context.Context, resolved *ResolvedMessage) error {
+	for i, citation := range resolved.Citations {
+		if citation.Type != MessageAnnotationTypeFilePath || citation.Content != nil {
+			continue
+		}
+		content, err := c.GetFileContent(ctx, citation.FileID)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", citation.FileID, err)
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", citation.FileID, err)
+		}
+		resolved.Citations[i].Content = data
+	}
+	return nil
+}
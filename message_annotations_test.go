@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// annotationJSON returns the JSON of an annotation of the first occurrence of
+// placeholder in text at or after the byte offset from, indexed in characters.
+func annotationJSON(text, placeholder string, from int, details string) string {
+	i := from + strings.Index(text[from:], placeholder)
+	start := utf8.RuneCountInString(text[:i])
+	end := start + utf8.RuneCountInString(placeholder)
+	return fmt.Sprintf(`{"text":%q,"start_index":%d,"end_index":%d,%s}`, placeholder, start, end, details)
+}
+
+func TestResolveMessageAnnotations(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file-csv/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "a,b\n1,2\n")
+	})
+	text := "Le café coûte 3€【4:0†source】【4:1†source】, comme dit【4:0†source】. " +
+		"Téléchargez sandbox:/mnt/data/prix.csv"
+	first := `"type":"file_citation","file_citation":{"file_id":"file-menu","quote":"café 3€"}`
+	second := `"type":"file_citation","file_citation":{"file_id":"file-faq"}`
+	path := `"type":"file_path","file_path":{"file_id":"file-csv"}`
+	repeat := strings.LastIndex(text, "【4:0†source】")
+	annotations := []string{
+		annotationJSON(text, "sandbox:/mnt/data/prix.csv", 0, path),
+		annotationJSON(text, "【4:0†source】", 0, first),
+		annotationJSON(text, "【4:1†source】", 0, second),
+		annotationJSON(text, "【4:0†source】", repeat, first),
+		// A bogus range overlapping the first citation only adds its marker.
+		`{"type":"file_citation","text":"","start_index":17,"end_index":20,` +
+			`"file_citation":{"file_id":"file-faq"}}`,
+	}
+	data := fmt.Sprintf(`{"id":"msg_abc123","role":"assistant","content":[`+
+		`{"type":"text","text":{"value":%q,"annotations":[%s]}},`+
+		`{"type":"text","text":{"value":"Fin.","annotations":[]}}]}`, text, strings.Join(annotations, ","))
+	var message openai.Message
+	checks.NoError(t, json.Unmarshal([]byte(data), &message), "Unmarshal error")
+	if len(message.Content[0].Text.Annotations) != 5 || len(message.Content[0].Text.MessageAnnotations) != 5 {
+		t.Fatalf("unexpected annotations %+v", message.Content[0].Text)
+	}
+
+	resolved := openai.ResolveMessageAnnotations(message)
+	want := "Le café coûte 3€[1][2][2], comme dit[1]. Téléchargez [3]\nFin."
+	if resolved.Text != want {
+		t.Errorf("got text %q, want %q", resolved.Text, want)
+	}
+	// Footnotes are numbered in reading order.
+	if len(resolved.Citations) != 3 || resolved.Citations[0].Quote != "café 3€" ||
+		resolved.Citations[2].FileID != "file-csv" || resolved.Citations[2].Number != 3 {
+		t.Errorf("unexpected citations %+v", resolved.Citations)
+	}
+
+	err := client.DownloadMessageCitations(context.Background(), &resolved)
+	checks.NoError(t, err, "DownloadMessageCitations error")
+	if string(resolved.Citations[2].Content) != "a,b\n1,2\n" || resolved.Citations[0].Content != nil {
+		t.Errorf("unexpected downloads %+v", resolved.Citations)
+	}
+}
@@ -2,9 +2,9 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
 )
 
 const (
@@ -22,6 +22,8 @@ type Message struct {
 	AssistantID *string          `json:"assistant_id,omitempty"`
 	RunID       *string          `json:"run_id,omitempty"`
 	Metadata    map[string]any   `json:"metadata"`
+	// Attachments are the files attached to the message for its tools.
+	Attachments []ThreadAttachment `json:"attachments,omitempty"`
 
 	httpHeader
 }
@@ -46,6 +48,23 @@ type MessageContent struct {
 type MessageText struct {
 	Value       string `json:"value"`
 	Annotations []any  `json:"annotations"`
+	// MessageAnnotations are the annotations of Annotations, typed.
+	MessageAnnotations []MessageAnnotation `json:"-"`
+}
+
+func (t *MessageText) UnmarshalJSON(data []byte) error {
+	type messageText MessageText
+	if err := json.Unmarshal(data, (*messageText)(t)); err != nil {
+		return err
+	}
+	var annotations struct {
+		Annotations []MessageAnnotation `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return err
+	}
+	t.MessageAnnotations = annotations.Annotations
+	return nil
 }
 
 type ImageFile struct {
@@ -58,8 +77,10 @@ type ImageURL struct {
 }
 
 type MessageRequest struct {
-	Role        string             `json:"role"`
-	Content     string             `json:"content"`
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Deprecated: FileIds is not supported by the v2 API. Set Attachments
+	// with NewThreadAttachments instead.
 	FileIds     []string           `json:"file_ids,omitempty"` //nolint:revive // backwards-compatibility
 	Metadata    map[string]any     `json:"metadata,omitempty"`
 	Attachments []ThreadAttachment `json:"attachments,omitempty"`
@@ -109,29 +130,28 @@ func (c *Client) ListMessage(ctx context.Context, threadID string,
 	before *string,
 	runID *string,
 ) (messages MessagesList, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if order != nil {
-		urlValues.Add("order", *order)
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	if before != nil {
-		urlValues.Add("before", *before)
-	}
+	var run string
 	if runID != nil {
-		urlValues.Add("run_id", *runID)
+		run = *runID
 	}
+	pagination := Pagination{Limit: limit, Order: order, After: after, Before: before}
+	return c.ListMessagesWithPagination(ctx, threadID, pagination, run)
+}
 
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
+// ListMessagesWithPagination lists a page of the messages of a thread, only those
+// created by the run runID if it is not empty.
+func (c *Client) ListMessagesWithPagination(
+	ctx context.Context,
+	threadID string,
+	pagination Pagination,
+	runID string,
+) (messages MessagesList, err error) {
+	urlValues := paginationValues(pagination)
+	if runID != "" {
+		urlValues.Add("run_id", runID)
 	}
 
-	urlSuffix := fmt.Sprintf("/threads/%s/%s%s", threadID, messagesSuffix, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s%s", threadID, messagesSuffix, encodeQuery(urlValues))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
 	if err != nil {
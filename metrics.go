@@ -0,0 +1,222 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsHook is notified of every API call made by the client. Each call is
+// reported once, however many times it is retried. Panics in a hook are
+// recovered and do not affect the call.
+type MetricsHook interface {
+	// OnRequestStart is called before the request is sent. endpoint is the path of
+	// the API operation, such as /chat/completions; model is empty when the
+	// request does not name one.
+	OnRequestStart(endpoint, model string)
+	// OnRequestEnd is called once the call is complete; for streams, when the
+	// stream ends, fails or is closed. status is 0 if no response was received,
+	// and usage is nil when the response does not report it.
+	OnRequestEnd(endpoint, model string, status int, duration time.Duration, usage *Usage, err error)
+	// OnStreamEvent is called for each event received on a stream.
+	OnStreamEvent(endpoint, model string)
+}
+
+// callTracker follows a single API call for the configured MetricsHook and
+// Tracer. A nil *callTracker is valid and does nothing.
+type callTracker struct {
+	hook     MetricsHook
+	span     Span
+	endpoint string
+	model    string
+	start    time.Time
+	status   int
+	usage    *Usage
+	events   int
+	once     sync.Once
+	// prices is set when hook is a CostMetricsHook.
+	prices PriceTable
+
+	// Recorded on spans only.
+	requestID     string
+	responseID    string
+	responseModel string
+	finishReasons []string
+	bodySize      int64
+}
+
+// trackCall starts tracking the call sending req. It returns the request to
+// send, which carries the span context when tracing, and a nil tracker if
+// neither a hook nor a tracer is configured.
+func (c *Client) trackCall(req *http.Request) (*http.Request, *callTracker) {
+	if c.config.MetricsHook == nil && c.config.Tracer == nil {
+		return req, nil
+	}
+	t := &callTracker{
+		hook:     c.config.MetricsHook,
+		endpoint: c.endpointOf(req),
+		model:    requestModel(req),
+		start:    time.Now(),
+	}
+	if c.config.Tracer != nil {
+		req = req.WithContext(t.startSpan(req.Context(), c.config.Tracer, req))
+	}
+	if _, ok := t.hook.(CostMetricsHook); ok {
+		t.prices = c.config.PriceTable
+		if t.prices == nil {
+			t.prices = DefaultPriceTable()
+		}
+	}
+	if t.hook != nil {
+		safeHookCall(func() { t.hook.OnRequestStart(t.endpoint, t.model) })
+	}
+	return req, t
+}
+
+// response records the response of the call, if any.
+func (t *callTracker) response(resp *http.Response) {
+	if t == nil || resp == nil {
+		return
+	}
+	t.status = resp.StatusCode
+	t.requestID = resp.Header.Get("X-Request-Id")
+}
+
+// event records an event received on a stream, taking the usage from it when
+// the stream reports one.
+func (t *callTracker) event(data []byte) {
+	if t == nil {
+		return
+	}
+	t.events++
+	if t.span != nil {
+		if t.events == 1 {
+			t.firstEvent()
+		}
+		t.record(data)
+	} else if bytes.Contains(data, []byte(`"usage"`)) {
+		if usage := parseUsage(data); usage != nil {
+			t.usage = usage
+		}
+	}
+	if t.hook != nil {
+		safeHookCall(func() { t.hook.OnStreamEvent(t.endpoint, t.model) })
+	}
+}
+
+// finish reports the end of the call. resp holds the parts of the decoded
+// response, if one was decoded. Only the first call has an effect.
+func (t *callTracker) finish(resp *callResponse, err error) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		if resp != nil {
+			if t.span != nil {
+				t.apply(*resp)
+			} else {
+				t.usage = resp.Usage
+			}
+		}
+		duration := time.Since(t.start)
+		if costHook, ok := t.hook.(CostMetricsHook); ok {
+			cost, costErr := t.cost()
+			safeHookCall(func() {
+				costHook.OnRequestEndWithCost(t.endpoint, t.model, t.status, duration, t.usage, cost, costErr, err)
+			})
+		} else if t.hook != nil {
+			safeHookCall(func() { t.hook.OnRequestEnd(t.endpoint, t.model, t.status, duration, t.usage, err) })
+		}
+		if t.span != nil {
+			t.endSpan(err)
+		}
+	})
+}
+
+// cost returns the estimated cost of the call, nil if its usage is unknown.
+func (t *callTracker) cost() (*Cost, error) {
+	if t.usage == nil {
+		return nil, nil
+	}
+	cost, err := t.prices.EstimateCost(t.model, *t.usage)
+	if err != nil {
+		return nil, err
+	}
+	return &cost, nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// safeHookCall runs a user supplied hook, recovering from panics in it.
+func safeHookCall(fn func()) {
+	defer func() {
+		_ = recover()
+	}()
+	fn()
+}
+
+// endpointOf returns the path of req relative to the configured base URL. For
+// Azure, the API prefix and deployment name are left out as well, so that
+// endpoints read the same for every API type.
+func (c *Client) endpointOf(req *http.Request) string {
+	endpoint := req.URL.Path
+	if base, err := url.Parse(c.config.BaseURL); err == nil {
+		endpoint = strings.TrimPrefix(endpoint, strings.TrimRight(base.Path, "/"))
+	}
+	if c.config.APIType != APITypeAzure && c.config.APIType != APITypeAzureAD {
+		return endpoint
+	}
+	endpoint = strings.TrimPrefix(endpoint, "/"+azureAPIPrefix)
+	deployments := "/" + azureDeploymentsPrefix + "/"
+	if strings.HasPrefix(endpoint, deployments) {
+		rest := endpoint[len(deployments):]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			endpoint = rest[i:]
+		}
+	}
+	return endpoint
+}
+
+// requestModel returns the model named in the JSON body of req, if any.
+func requestModel(req *http.Request) string {
+	if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	var fields struct {
+		Model string `json:"model"`
+	}
+	_ = json.NewDecoder(body).Decode(&fields)
+	return fields.Model
+}
+
+// parseUsage returns the usage reported in a JSON response or stream event.
+func parseUsage(data []byte) *Usage {
+	var fields struct {
+		Usage *Usage `json:"usage"`
+	}
+	if json.Unmarshal(data, &fields) != nil {
+		return nil
+	}
+	return fields.Usage
+}
@@ -0,0 +1,218 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type metricsEnd struct {
+	endpoint, model string
+	status          int
+	usage           *openai.Usage
+	err             error
+}
+
+type recordingMetricsHook struct {
+	mu     sync.Mutex
+	starts []string
+	ends   []metricsEnd
+	events int
+}
+
+func (h *recordingMetricsHook) OnRequestStart(endpoint, model string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.starts = append(h.starts, endpoint+" "+model)
+}
+
+func (h *recordingMetricsHook) OnRequestEnd(
+	endpoint, model string,
+	status int,
+	_ time.Duration,
+	usage *openai.Usage,
+	err error,
+) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ends = append(h.ends, metricsEnd{endpoint, model, status, usage, err})
+}
+
+func (h *recordingMetricsHook) OnStreamEvent(string, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events++
+}
+
+var metricsChatRequest = openai.ChatCompletionRequest{
+	Model:    openai.GPT4,
+	Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+}
+
+func TestMetricsHookRequest(t *testing.T) {
+	hook := &recordingMetricsHook{}
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.MetricsHook = hook
+		c.MaxRetries = 2
+		c.RetryBackoff = func(int) time.Duration { return 0 }
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), metricsChatRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if len(hook.starts) != 1 || hook.starts[0] != "/chat/completions "+openai.GPT4 {
+		t.Fatalf("unexpected starts %v", hook.starts)
+	}
+	if len(hook.ends) != 1 {
+		t.Fatalf("expected one end across retries, got %d", len(hook.ends))
+	}
+	end := hook.ends[0]
+	if end.status != http.StatusOK || end.err != nil || end.usage == nil || end.usage.TotalTokens != 7 {
+		t.Fatalf("unexpected end %+v", end)
+	}
+}
+
+func TestMetricsHookError(t *testing.T) {
+	hook := &recordingMetricsHook{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.MetricsHook = hook
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"nope"}}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.HasError(t, err, "ListModels should fail")
+	if len(hook.ends) != 1 || hook.ends[0].status != http.StatusNotFound || hook.ends[0].err == nil {
+		t.Fatalf("unexpected ends %+v", hook.ends)
+	}
+	if hook.starts[0] != "/models " {
+		t.Fatalf("unexpected start %q", hook.starts[0])
+	}
+}
+
+func TestMetricsHookStream(t *testing.T) {
+	hook := &recordingMetricsHook{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.MetricsHook = hook
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"a"}}],"usage":null}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"b"}}],"usage":null}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[],"usage":{"total_tokens":12}}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	req := metricsChatRequest
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+
+	if hook.events != 3 {
+		t.Fatalf("expected 3 stream events, got %d", hook.events)
+	}
+	if len(hook.ends) != 1 {
+		t.Fatalf("expected one end, got %d", len(hook.ends))
+	}
+	if end := hook.ends[0]; end.usage == nil || end.usage.TotalTokens != 12 || end.err != nil {
+		t.Fatalf("unexpected end %+v", end)
+	}
+}
+
+type panickingMetricsHook struct{}
+
+func (panickingMetricsHook) OnRequestStart(string, string) { panic("start") }
+func (panickingMetricsHook) OnRequestEnd(string, string, int, time.Duration, *openai.Usage, error) {
+	panic("end")
+}
+func (panickingMetricsHook) OnStreamEvent(string, string) { panic("event") }
+
+func TestMetricsHookPanicIsRecovered(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.MetricsHook = panickingMetricsHook{}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "a panicking hook must not fail the call")
+}
+
+type costMetricsHook struct {
+	recordingMetricsHook
+	cost    *openai.Cost
+	costErr error
+}
+
+func (h *costMetricsHook) OnRequestEndWithCost(
+	endpoint, model string,
+	status int,
+	duration time.Duration,
+	usage *openai.Usage,
+	cost *openai.Cost,
+	costErr error,
+	err error,
+) {
+	h.OnRequestEnd(endpoint, model, status, duration, usage, err)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cost, h.costErr = cost, costErr
+}
+
+func TestMetricsHookCost(t *testing.T) {
+	hook := &costMetricsHook{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.MetricsHook = hook
+		c.PriceTable = openai.PriceTable{openai.GPT4: {Input: 30, Output: 60}}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"gpt-4",` +
+			`"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`))
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), metricsChatRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(hook.ends) != 1 {
+		t.Fatalf("expected OnRequestEnd to be called once through OnRequestEndWithCost, got %d", len(hook.ends))
+	}
+	if hook.costErr != nil || hook.cost == nil || hook.cost.Total != 0.06 {
+		t.Errorf("unexpected cost %+v, error %v", hook.cost, hook.costErr)
+	}
+
+	request := metricsChatRequest
+	request.Model = openai.GPT4o
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if hook.cost != nil {
+		t.Errorf("unexpected cost %+v for a model without price", hook.cost)
+	}
+	checks.ErrorIs(t, hook.costErr, openai.ErrUnknownModelPricing)
+}
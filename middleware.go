@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrStreamBodyReserved is returned when a middleware reads the body of a
+// streamed response, which only the caller's stream reader may consume.
+var ErrStreamBodyReserved = errors.New("openai: body of a streamed response is reserved for the caller")
+
+// RoundTripFunc sends a request and returns its response. When the API answers
+// with a failure status code, both the response and the decoded error are
+// returned.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of every request made by the client, including
+// multipart uploads and streams. It may inspect or modify the request before
+// calling next, and inspect the response and error after it returns.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// doRequest sends req through the configured middlewares. When stream is true the
+// response body is withheld from the middlewares, as it is read incrementally by
+// the caller.
+func (c *Client) doRequest(req *http.Request, stream bool) (*http.Response, error) {
+	send := func(req *http.Request) (*http.Response, error) {
+		return c.sendWithRetries(req, stream)
+	}
+	if len(c.config.Middlewares) == 0 {
+		return send(req)
+	}
+
+	var body io.ReadCloser
+	next := send
+	if stream {
+		next = func(req *http.Request) (*http.Response, error) {
+			resp, err := send(req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+			body = resp.Body
+			guarded := *resp
+			guarded.Body = reservedBody{}
+			return &guarded, nil
+		}
+	}
+
+	roundTrip := RoundTripFunc(next)
+	for i := len(c.config.Middlewares) - 1; i >= 0; i-- {
+		roundTrip = c.config.Middlewares[i](roundTrip)
+	}
+
+	resp, err := roundTrip(req)
+	if body == nil {
+		return resp, err
+	}
+	if resp != nil {
+		if _, ok := resp.Body.(reservedBody); ok {
+			restored := *resp
+			restored.Body = body
+			return &restored, err
+		}
+	}
+	// A middleware replaced the streamed response, so nobody else will close it.
+	body.Close()
+	return resp, err
+}
+
+// reservedBody stands in for the body of a streamed response while it passes
+// through the middlewares.
+type reservedBody struct{}
+
+func (reservedBody) Read([]byte) (int, error) {
+	return 0, ErrStreamBodyReserved
+}
+
+func (reservedBody) Close() error {
+	return nil
+}
@@ -0,0 +1,170 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestMiddlewareOrderAndRequestMutation(t *testing.T) {
+	var calls []string
+	record := func(name string) openai.Middleware {
+		return func(next openai.RoundTripFunc) openai.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				req.Header.Add("X-Middleware", name)
+				resp, err := next(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Middlewares = []openai.Middleware{record("outer"), record("inner")}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		if got := strings.Join(r.Header.Values("X-Middleware"), ","); got != "outer,inner" {
+			t.Errorf("unexpected middleware headers %q", got)
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+
+	want := "outer before,inner before,inner after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("middlewares ran as %q, want %q", got, want)
+	}
+}
+
+func TestMiddlewareReceivesDecodedError(t *testing.T) {
+	var seenStatus int
+	var seenErr error
+	middleware := func(next openai.RoundTripFunc) openai.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if resp != nil {
+				seenStatus = resp.StatusCode
+			}
+			seenErr = err
+			return resp, err
+		}
+	}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Middlewares = []openai.Middleware{middleware}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"not found","type":"invalid_request_error"}}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if seenStatus != http.StatusNotFound || !errors.As(seenErr, &apiErr) {
+		t.Fatalf("middleware saw status %d and error %v", seenStatus, seenErr)
+	}
+}
+
+func TestMiddlewareRunsForMultipartUploads(t *testing.T) {
+	var contentType string
+	middleware := func(next openai.RoundTripFunc) openai.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			contentType = req.Header.Get("Content-Type")
+			return next(req)
+		}
+	}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Middlewares = []openai.Middleware{middleware}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"id":"file-1"}`))
+	})
+
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	checks.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644), "failed to write temp file")
+	_, err := client.CreateFile(context.Background(), openai.FileRequest{FilePath: path, Purpose: "fine-tune"})
+	checks.NoError(t, err, "CreateFile error")
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		t.Fatalf("middleware saw content type %q", contentType)
+	}
+}
+
+func TestMiddlewareCannotConsumeStream(t *testing.T) {
+	var readErr error
+	middleware := func(next openai.RoundTripFunc) openai.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil {
+				_, readErr = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			return resp, err
+		}
+	}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Middlewares = []openai.Middleware{middleware}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"hi"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT3Dot5Turbo,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	if !errors.Is(readErr, openai.ErrStreamBodyReserved) {
+		t.Fatalf("middleware read of stream body returned %v", readErr)
+	}
+	resp, err := stream.Recv()
+	checks.NoError(t, err, "stream should still be readable")
+	if resp.Choices[0].Delta.Content != "hi" {
+		t.Fatalf("unexpected stream content %q", resp.Choices[0].Delta.Content)
+	}
+}
+
+func TestMiddlewareCanReplaceResponse(t *testing.T) {
+	middleware := func(openai.RoundTripFunc) openai.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Cache": {"hit"}},
+				Body:       io.NopCloser(bytes.NewBufferString(`{"id":"cached"}`)),
+				Request:    req,
+			}, nil
+		}
+	}
+	client, _, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.Middlewares = []openai.Middleware{middleware}
+	})
+	defer teardown()
+
+	model, err := client.GetModel(context.Background(), "gpt-4")
+	checks.NoError(t, err, "GetModel error")
+	if model.ID != "cached" || model.Header().Get("X-Cache") != "hit" {
+		t.Fatalf("cached response was not used: %+v", model)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 )
 
+const defaultModelListCacheTTL = 5 * time.Minute
+
 // Model struct represents an OpenAPI model.
 type Model struct {
 	CreatedAt  int64        `json:"created"`
@@ -88,3 +92,75 @@ func (c *Client) DeleteFineTuneModel(ctx context.Context, modelID string) (
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// modelCache is the model list cached by SupportsModel.
+type modelCache struct {
+	mu      sync.Mutex
+	ids     map[string]bool
+	fetched time.Time
+	// refreshing is closed when the refresh in progress, if any, is done.
+	refreshing chan struct{}
+}
+
+// SupportsModel reports whether the model with the given ID is available, as
+// listed by ListModels, to validate configuration without listing the models
+// on every call. The list is cached for ClientConfig.ModelListCacheTTL and
+// refreshed by a single call at a time, which the others wait for until their
+// context is done.
+func (c *Client) SupportsModel(ctx context.Context, modelID string) (bool, error) {
+	cache := c.models
+	if cache == nil {
+		cache = &modelCache{}
+	}
+	ttl := c.config.ModelListCacheTTL
+	if ttl <= 0 {
+		ttl = defaultModelListCacheTTL
+	}
+
+	for {
+		cache.mu.Lock()
+		if cache.ids != nil && time.Since(cache.fetched) < ttl {
+			supported := cache.ids[modelID]
+			cache.mu.Unlock()
+			return supported, nil
+		}
+		if wait := cache.refreshing; wait != nil {
+			cache.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-wait:
+				// Look again, and refresh if the refresh failed.
+				continue
+			}
+		}
+		done := make(chan struct{})
+		cache.refreshing = done
+		cache.mu.Unlock()
+
+		ids, err := c.listModelIDs(ctx)
+		cache.mu.Lock()
+		if err == nil {
+			cache.ids, cache.fetched = ids, time.Now()
+		}
+		cache.refreshing = nil
+		cache.mu.Unlock()
+		close(done)
+		if err != nil {
+			return false, err
+		}
+		return ids[modelID], nil
+	}
+}
+
+func (c *Client) listModelIDs(ctx context.Context) (map[string]bool, error) {
+	list, err := c.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(list.Models))
+	for _, model := range list.Models {
+		ids[model.ID] = true
+	}
+	return ids, nil
+}
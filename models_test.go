@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 )
 
@@ -110,3 +113,149 @@ func handleDeleteFineTuneModelEndpoint(w http.ResponseWriter, _ *http.Request) {
 	resBytes, _ := json.Marshal(openai.FineTuneModelDeleteResponse{})
 	fmt.Fprintln(w, string(resBytes))
 }
+
+func writeModelList(w http.ResponseWriter) {
+	_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o","object":"model","created":1715367049,` +
+		`"owned_by":"system"},{"id":"ft:gpt-4o-mini:acme::abc","object":"model","owned_by":"acme"}]}`))
+}
+
+func TestSupportsModel(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.ModelListCacheTTL = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeModelList(w)
+	})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		id        string
+		supported bool
+	}{{"gpt-4o", true}, {"ft:gpt-4o-mini:acme::abc", true}, {"gpt-2", false}} {
+		supported, err := client.SupportsModel(ctx, tc.id)
+		checks.NoError(t, err, "SupportsModel error")
+		if supported != tc.supported {
+			t.Errorf("SupportsModel(%q) = %v, want %v", tc.id, supported, tc.supported)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("listed the models %d times, want once", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	_, err := client.SupportsModel(ctx, "gpt-4o")
+	checks.NoError(t, err, "SupportsModel error")
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("listed the models %d times after the TTL, want twice", n)
+	}
+}
+
+func TestSupportsModelWithOptions(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.ModelListCacheTTL = time.Minute
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeModelList(w)
+	})
+	other := test.NewTestServer()
+	other.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"llama-3","object":"model"}]}`))
+	})
+	ts := other.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	ctx := context.Background()
+
+	supported, err := client.SupportsModel(ctx, "gpt-4o")
+	checks.NoError(t, err, "SupportsModel error")
+	if !supported {
+		t.Error("gpt-4o not supported")
+	}
+	supported, err = client.WithOptions(openai.WithBaseURL(ts.URL+"/v1")).SupportsModel(ctx, "gpt-4o")
+	checks.NoError(t, err, "SupportsModel error")
+	if supported {
+		t.Error("the model list of the client was used for another base URL")
+	}
+	_, err = client.WithOptions(openai.WithIdempotencyKey("key")).SupportsModel(ctx, "gpt-4o")
+	checks.NoError(t, err, "SupportsModel error")
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("listed the models %d times, want once for the client and its clones", n)
+	}
+}
+
+func TestSupportsModelConcurrentRefresh(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.ModelListCacheTTL = time.Minute
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		writeModelList(w)
+	})
+
+	// A caller giving up while the refresh is in progress is not held by it.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		supported, err := client.SupportsModel(context.Background(), "gpt-4o")
+		if err != nil || !supported {
+			t.Errorf("SupportsModel = %v, %v", supported, err)
+		}
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.SupportsModel(ctx, "gpt-4o")
+	checks.ErrorIs(t, err, context.DeadlineExceeded)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if supported, err := client.SupportsModel(context.Background(), "gpt-4o"); err != nil || !supported {
+				t.Errorf("SupportsModel = %v, %v", supported, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("listed the models %d times, want once", n)
+	}
+}
+
+func TestSupportsModelRefreshError(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.ModelListCacheTTL = time.Minute
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":{"message":"oops","type":"server_error"}}`))
+			return
+		}
+		writeModelList(w)
+	})
+
+	_, err := client.SupportsModel(context.Background(), "gpt-4o")
+	checks.HasError(t, err, "SupportsModel should fail when listing the models fails")
+	supported, err := client.SupportsModel(context.Background(), "gpt-4o")
+	checks.NoError(t, err, "SupportsModel should list the models again after a failure")
+	if !supported {
+		t.Error("gpt-4o not supported")
+	}
+}
@@ -3,14 +3,15 @@ package openai
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 )
 
 // The moderation endpoint is a tool you can use to check whether content complies with OpenAI's usage policies.
 // Developers can thus identify content that our usage policies prohibits and take action, for instance by filtering it.
 
-// The default is text-moderation-latest which will be automatically upgraded over time.
-// This ensures you are always using our most accurate model.
+// The default is omni-moderation-latest which will be automatically upgraded over time.
+// The omni models also moderate images and classify more categories than the text models.
 // If you use text-moderation-stable, we will provide advanced notice before updating the model.
 // Accuracy of text-moderation-stable may be slightly lower than for text-moderation-latest.
 const (
@@ -24,6 +25,9 @@ const (
 
 var (
 	ErrModerationInvalidModel = errors.New("this model is not supported with moderation, please use text-moderation-stable or text-moderation-latest instead") //nolint:lll
+	// ErrModerationInvalidInput is returned for moderation inputs other than a
+	// string, a []string or a []ModerationInputPart.
+	ErrModerationInvalidInput = errors.New("moderation input must be a string, a []string or a []ModerationInputPart")
 )
 
 var validModerationModel = map[string]struct{}{
@@ -35,15 +39,72 @@ var validModerationModel = map[string]struct{}{
 
 // ModerationRequest represents a request structure for moderation API.
 type ModerationRequest struct {
-	Input string `json:"input,omitempty"`
+	// Input is a string, a []string, or a []ModerationInputPart mixing text and
+	// images for the omni models.
+	Input any    `json:"input,omitempty"`
 	Model string `json:"model,omitempty"`
 }
 
+type ModerationInputPartType string
+
+const (
+	ModerationInputPartTypeText     ModerationInputPartType = "text"
+	ModerationInputPartTypeImageURL ModerationInputPartType = "image_url"
+)
+
+// ModerationInputPart is a text or image part of a multi-modal moderation input.
+type ModerationInputPart struct {
+	Type     ModerationInputPartType `json:"type"`
+	Text     string                  `json:"text,omitempty"`
+	ImageURL *ModerationImageURL     `json:"image_url,omitempty"`
+}
+
+// ModerationImageURL is the http(s) or base64 data URL of a moderated image.
+type ModerationImageURL struct {
+	URL string `json:"url"`
+}
+
+// NewModerationTextPart returns a moderation input part for text.
+func NewModerationTextPart(text string) ModerationInputPart {
+	return ModerationInputPart{Type: ModerationInputPartTypeText, Text: text}
+}
+
+// NewModerationImagePartFromURL returns a moderation input part for the image at
+// url, which must be an http(s) URL or a data URL of a supported image type.
+func NewModerationImagePartFromURL(url string) (ModerationInputPart, error) {
+	part, err := NewImagePartFromURL(url, "")
+	if err != nil {
+		return ModerationInputPart{}, err
+	}
+	return moderationImagePart(part), nil
+}
+
+// NewModerationImagePartFromBytes returns a moderation input part embedding the
+// image data as a base64 data URL. If mimeType is empty it is detected from the
+// data.
+func NewModerationImagePartFromBytes(data []byte, mimeType string) (ModerationInputPart, error) {
+	part, err := NewImagePartFromBytes(data, mimeType, "")
+	if err != nil {
+		return ModerationInputPart{}, err
+	}
+	return moderationImagePart(part), nil
+}
+
+func moderationImagePart(part ChatMessagePart) ModerationInputPart {
+	return ModerationInputPart{
+		Type:     ModerationInputPartTypeImageURL,
+		ImageURL: &ModerationImageURL{URL: part.ImageURL.URL},
+	}
+}
+
 // Result represents one of possible moderation results.
 type Result struct {
 	Categories     ResultCategories     `json:"categories"`
 	CategoryScores ResultCategoryScores `json:"category_scores"`
 	Flagged        bool                 `json:"flagged"`
+	// CategoryAppliedInputTypes lists, for each category, the input types it was
+	// scored on, "text" or "image". It is only returned by the omni models.
+	CategoryAppliedInputTypes map[string][]string `json:"category_applied_input_types,omitempty"`
 }
 
 // ResultCategories represents Categories of Result.
@@ -59,6 +120,9 @@ type ResultCategories struct {
 	SexualMinors          bool `json:"sexual/minors"`
 	Violence              bool `json:"violence"`
 	ViolenceGraphic       bool `json:"violence/graphic"`
+	// Illicit and IllicitViolent are only classified by the omni models.
+	Illicit        bool `json:"illicit"`
+	IllicitViolent bool `json:"illicit/violent"`
 }
 
 // ResultCategoryScores represents CategoryScores of Result.
@@ -74,6 +138,8 @@ type ResultCategoryScores struct {
 	SexualMinors          float32 `json:"sexual/minors"`
 	Violence              float32 `json:"violence"`
 	ViolenceGraphic       float32 `json:"violence/graphic"`
+	Illicit               float32 `json:"illicit"`
+	IllicitViolent        float32 `json:"illicit/violent"`
 }
 
 // ModerationResponse represents a response structure for moderation API.
@@ -83,6 +149,7 @@ type ModerationResponse struct {
 	Results []Result `json:"results"`
 
 	httpHeader
+	rawJSON
 }
 
 // Moderations — perform a moderation api call over a string.
@@ -92,6 +159,12 @@ func (c *Client) Moderations(ctx context.Context, request ModerationRequest) (re
 		err = ErrModerationInvalidModel
 		return
 	}
+	switch request.Input.(type) {
+	case nil, string, []string, []ModerationInputPart:
+	default:
+		err = fmt.Errorf("%w, got %T", ErrModerationInvalidInput, request.Input)
+		return
+	}
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
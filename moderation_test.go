@@ -54,6 +54,56 @@ func TestModerationsWithDifferentModelOptions(t *testing.T) {
 	}
 }
 
+func TestModerationsMultiModal(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var body string
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		fmt.Fprint(w, `{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,`+
+			`"categories":{"violence":true,"illicit":false,"illicit/violent":true},`+
+			`"category_scores":{"violence":0.9,"illicit":0.1,"illicit/violent":0.8},`+
+			`"category_applied_input_types":{"violence":["text","image"],"illicit/violent":["text"]}}]}`)
+	})
+	ctx := context.Background()
+
+	image, err := openai.NewModerationImagePartFromBytes([]byte("\x89PNG\r\n\x1a\n"), "")
+	checks.NoError(t, err, "NewModerationImagePartFromBytes error")
+	res, err := client.Moderations(ctx, openai.ModerationRequest{
+		Model: openai.ModerationOmniLatest,
+		Input: []openai.ModerationInputPart{openai.NewModerationTextPart("I want to kill them."), image},
+	})
+	checks.NoError(t, err, "Moderations error")
+
+	want := `{"input":[{"type":"text","text":"I want to kill them."},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}],` +
+		`"model":"omni-moderation-latest"}`
+	if body != want {
+		t.Errorf("got request %s, want %s", body, want)
+	}
+	result := res.Results[0]
+	if !result.Categories.IllicitViolent || result.CategoryScores.IllicitViolent != 0.8 ||
+		strings.Join(result.CategoryAppliedInputTypes["violence"], ",") != "text,image" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// Plain strings are still sent as strings for the text models.
+	for _, input := range []any{"hello", []string{"hello", "world"}} {
+		_, err = client.Moderations(ctx, openai.ModerationRequest{Input: input})
+		checks.NoError(t, err, "Moderations error")
+		want, _ := json.Marshal(input)
+		if body != `{"input":`+string(want)+`}` {
+			t.Errorf("unexpected request %s", body)
+		}
+	}
+
+	_, err = client.Moderations(ctx, openai.ModerationRequest{Input: 42})
+	checks.ErrorIs(t, err, openai.ErrModerationInvalidInput, "unsupported inputs should fail")
+	_, err = openai.NewModerationImagePartFromURL("ftp://example.com/cat.png")
+	checks.HasError(t, err, "non-http URLs should fail")
+}
+
 func getModerationModelTestOption(model string, expect error) struct {
 	model  string
 	expect error
@@ -79,50 +129,51 @@ func handleModerationEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	input, _ := moderationReq.Input.(string)
 	resCat := openai.ResultCategories{}
 	resCatScore := openai.ResultCategoryScores{}
 	switch {
-	case strings.Contains(moderationReq.Input, "hate"):
+	case strings.Contains(input, "hate"):
 		resCat = openai.ResultCategories{Hate: true}
 		resCatScore = openai.ResultCategoryScores{Hate: 1}
 
-	case strings.Contains(moderationReq.Input, "hate more"):
+	case strings.Contains(input, "hate more"):
 		resCat = openai.ResultCategories{HateThreatening: true}
 		resCatScore = openai.ResultCategoryScores{HateThreatening: 1}
 
-	case strings.Contains(moderationReq.Input, "harass"):
+	case strings.Contains(input, "harass"):
 		resCat = openai.ResultCategories{Harassment: true}
 		resCatScore = openai.ResultCategoryScores{Harassment: 1}
 
-	case strings.Contains(moderationReq.Input, "harass hard"):
+	case strings.Contains(input, "harass hard"):
 		resCat = openai.ResultCategories{Harassment: true}
 		resCatScore = openai.ResultCategoryScores{HarassmentThreatening: 1}
 
-	case strings.Contains(moderationReq.Input, "suicide"):
+	case strings.Contains(input, "suicide"):
 		resCat = openai.ResultCategories{SelfHarm: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarm: 1}
 
-	case strings.Contains(moderationReq.Input, "wanna suicide"):
+	case strings.Contains(input, "wanna suicide"):
 		resCat = openai.ResultCategories{SelfHarmIntent: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarm: 1}
 
-	case strings.Contains(moderationReq.Input, "drink bleach"):
+	case strings.Contains(input, "drink bleach"):
 		resCat = openai.ResultCategories{SelfHarmInstructions: true}
 		resCatScore = openai.ResultCategoryScores{SelfHarmInstructions: 1}
 
-	case strings.Contains(moderationReq.Input, "porn"):
+	case strings.Contains(input, "porn"):
 		resCat = openai.ResultCategories{Sexual: true}
 		resCatScore = openai.ResultCategoryScores{Sexual: 1}
 
-	case strings.Contains(moderationReq.Input, "child porn"):
+	case strings.Contains(input, "child porn"):
 		resCat = openai.ResultCategories{SexualMinors: true}
 		resCatScore = openai.ResultCategoryScores{SexualMinors: 1}
 
-	case strings.Contains(moderationReq.Input, "kill"):
+	case strings.Contains(input, "kill"):
 		resCat = openai.ResultCategories{Violence: true}
 		resCatScore = openai.ResultCategoryScores{Violence: 1}
 
-	case strings.Contains(moderationReq.Input, "corpse"):
+	case strings.Contains(input, "corpse"):
 		resCat = openai.ResultCategories{ViolenceGraphic: true}
 		resCatScore = openai.ResultCategoryScores{ViolenceGraphic: 1}
 	}
@@ -0,0 +1,148 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+// streamingUploadThreshold is the payload size from which multipart uploads are
+// streamed to the API instead of being buffered in memory first.
+const streamingUploadThreshold int64 = 10 << 20 // 10MB
+
+// UploadProgressFunc is called while a file is uploaded. written is the number of
+// bytes of the file sent so far and total its size, or -1 when the size is unknown.
+type UploadProgressFunc func(written, total int64)
+
+// multipartStream is a request body produced by a StreamingFormBuilder while the
+// request is being sent, so the whole body never has to be held in memory.
+type multipartStream struct {
+	io.ReadCloser
+
+	contentType string
+	done        chan struct{}
+	err         error
+}
+
+// newMultipartStream starts building a multipart form with build in a background
+// goroutine. Closing the returned stream terminates the pipe, which stops the build
+// goroutine if the HTTP client gave up before consuming the body.
+func (c *Client) newMultipartStream(
+	build func(utils.FormBuilder) error,
+	progress UploadProgressFunc,
+) *multipartStream {
+	builder, body := utils.NewStreamingFormBuilderWith(func(w io.Writer) utils.FormBuilder {
+		return c.newFormBuilder(w, progress)
+	})
+	stream := &multipartStream{
+		ReadCloser:  body,
+		contentType: builder.FormDataContentType(),
+		done:        make(chan struct{}),
+	}
+
+	go func() {
+		defer close(stream.done)
+		stream.err = build(builder)
+		// A successful build has closed the form, ending the body with io.EOF.
+		_ = builder.CloseWithError(stream.err)
+	}()
+	return stream
+}
+
+// buildErr waits for the build goroutine to finish and returns its error. Errors
+// caused by the reader side being closed early are not reported, as they are a
+// consequence of the request failing rather than its cause.
+func (s *multipartStream) buildErr() error {
+	_ = s.Close()
+	<-s.done
+	if errors.Is(s.err, io.ErrClosedPipe) {
+		return nil
+	}
+	return s.err
+}
+
+// newFormBuilder returns the client's FormBuilder for body, or one reporting to
+// progress when it is set.
+func (c *Client) newFormBuilder(body io.Writer, progress UploadProgressFunc) utils.FormBuilder {
+	if progress != nil {
+		return utils.NewFormBuilderWithProgress(body, progress)
+	}
+	return c.createFormBuilder(body)
+}
+
+// sendMultipartRequest builds a multipart form with build and POSTs it to url.
+// When stream is true the form is produced while the request is sent; otherwise it
+// is buffered first so builder errors are reported before any network traffic.
+// progress, if not nil, is notified as file parts are written.
+func (c *Client) sendMultipartRequest(
+	ctx context.Context,
+	url string,
+	stream bool,
+	progress UploadProgressFunc,
+	build func(utils.FormBuilder) error,
+	v Response,
+) error {
+	req, body, err := c.newMultipartRequest(ctx, url, stream, progress, build)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return c.sendRequest(req, v)
+	}
+	defer body.Close()
+
+	err = c.sendRequest(req, v)
+	if err != nil {
+		// An error while producing the body aborts the request, so it is the root cause.
+		if buildErr := body.buildErr(); buildErr != nil {
+			return buildErr
+		}
+	}
+	return err
+}
+
+// newMultipartRequest returns a POST request to url with the multipart form built
+// by build, as sendMultipartRequest sends it. When stream is true it also returns
+// the body producing the form, to be closed once the request is done.
+func (c *Client) newMultipartRequest(
+	ctx context.Context,
+	url string,
+	stream bool,
+	progress UploadProgressFunc,
+	build func(utils.FormBuilder) error,
+) (*http.Request, *multipartStream, error) {
+	if !stream {
+		var body bytes.Buffer
+		builder := c.newFormBuilder(&body, progress)
+		if err := build(builder); err != nil {
+			return nil, nil, err
+		}
+
+		req, err := c.newRequest(ctx, http.MethodPost, url,
+			withBody(&body), withContentType(builder.FormDataContentType()))
+		return req, nil, err
+	}
+
+	body := c.newMultipartStream(build, progress)
+	req, err := c.newRequest(ctx, http.MethodPost, url,
+		withBody(body), withContentType(body.contentType))
+	if err != nil {
+		body.Close()
+		return nil, nil, err
+	}
+	return req, body, nil
+}
+
+// shouldStreamUpload reports whether an upload from r is large enough, or of
+// unknown size backed by a file, to be streamed rather than buffered.
+func shouldStreamUpload(r io.Reader) bool {
+	if _, ok := r.(*os.File); ok {
+		return true
+	}
+	return utils.ReaderSize(r) >= streamingUploadThreshold
+}
@@ -0,0 +1,148 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateFileStreamsBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	content := strings.Repeat(`{"prompt":"a","completion":"b"}`+"\n", 1024)
+	checks.NoError(t, os.WriteFile(path, []byte(content), 0644), "failed to write temp file")
+
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("expected streamed body with unknown length, got Content-Length %d", r.ContentLength)
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		b, _ := io.ReadAll(f)
+		if string(b) != content {
+			t.Errorf("uploaded content mismatch: got %d bytes, want %d", len(b), len(content))
+		}
+		if r.FormValue("purpose") != "fine-tune" {
+			t.Errorf("unexpected purpose %q", r.FormValue("purpose"))
+		}
+		_, _ = w.Write([]byte(`{"id":"file-1","purpose":"fine-tune"}`))
+	})
+
+	file, err := client.CreateFile(context.Background(), FileRequest{FilePath: path, Purpose: "fine-tune"})
+	checks.NoError(t, err, "CreateFile error")
+	if file.ID != "file-1" {
+		t.Fatalf("unexpected file ID %q", file.ID)
+	}
+}
+
+func TestCreateFileProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	content := strings.Repeat(`{"prompt":"a","completion":"b"}`+"\n", 4096)
+	checks.NoError(t, os.WriteFile(path, []byte(content), 0644), "failed to write temp file")
+
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"id":"file-1"}`))
+	})
+
+	var calls int
+	var written, total int64
+	_, err := client.CreateFile(context.Background(), FileRequest{
+		FilePath: path,
+		Purpose:  "fine-tune",
+		Progress: func(n, size int64) {
+			calls++
+			written, total = n, size
+		},
+	})
+	checks.NoError(t, err, "CreateFile error")
+	if calls == 0 {
+		t.Fatal("progress callback was not called")
+	}
+	if written != int64(len(content)) || total != int64(len(content)) {
+		t.Fatalf("unexpected final progress %d/%d, want %d", written, total, len(content))
+	}
+}
+
+func TestSendMultipartRequestStreamBuildError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	errRead := errors.New("mock read failure mid-upload")
+	build := func(b utils.FormBuilder) error {
+		r := io.MultiReader(bytes.NewReader(make([]byte, 64<<10)), &failingReader{err: errRead})
+		return b.CreateFormFileReader("file", r, "audio.mp3")
+	}
+
+	var response File
+	err := client.sendMultipartRequest(context.Background(), client.fullURL("/files"), true, nil, build, &response)
+	checks.ErrorIs(t, err, errRead, "build error should surface on the request")
+}
+
+func TestSendMultipartRequestStreamRequestBuilderError(t *testing.T) {
+	client := NewClient(test.GetTestToken())
+	client.requestBuilder = &failingRequestBuilder{}
+
+	built := make(chan struct{})
+	build := func(b utils.FormBuilder) error {
+		defer close(built)
+		// Blocks until the body is closed, as nobody reads the pipe.
+		return b.CreateFormFileReader("file", bytes.NewReader(make([]byte, 1<<20)), "audio.mp3")
+	}
+
+	var response File
+	err := client.sendMultipartRequest(context.Background(), client.fullURL("/files"), true, nil, build, &response)
+	checks.ErrorIs(t, err, errTestRequestBuilderFailed, "request builder error should be returned")
+	<-built
+}
+
+func TestShouldStreamUpload(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "")
+	checks.NoError(t, err, "failed to create temp file")
+	defer f.Close()
+
+	cases := []struct {
+		name   string
+		reader io.Reader
+		want   bool
+	}{
+		{"file", f, true},
+		{"small buffer", bytes.NewBufferString("data"), false},
+		{"large reader", bytes.NewReader(make([]byte, streamingUploadThreshold)), true},
+		{"unknown size", io.MultiReader(), false},
+	}
+	for _, tc := range cases {
+		if got := shouldStreamUpload(tc.reader); got != tc.want {
+			t.Errorf("%s: shouldStreamUpload() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
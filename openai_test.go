@@ -6,12 +6,23 @@ import (
 )
 
 func setupOpenAITestServer() (client *openai.Client, server *test.ServerTest, teardown func()) {
+	return setupOpenAITestServerWithConfig(nil)
+}
+
+// setupOpenAITestServerWithConfig is setupOpenAITestServer with a client whose
+// config is changed by configure, if not nil.
+func setupOpenAITestServerWithConfig(
+	configure func(*openai.ClientConfig),
+) (client *openai.Client, server *test.ServerTest, teardown func()) {
 	server = test.NewTestServer()
 	ts := server.OpenAITestServer()
 	ts.Start()
 	teardown = ts.Close
 	config := openai.DefaultConfig(test.GetTestToken())
 	config.BaseURL = ts.URL + "/v1"
+	if configure != nil {
+		configure(&config)
+	}
 	client = openai.NewClientWithConfig(config)
 	return
 }
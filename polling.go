@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is the longest delay between polls of the Wait helpers.
+	DefaultPollInterval = 5 * time.Second
+
+	// pollInitialInterval is the delay before the second poll, doubled after
+	// every poll up to PollOptions.Interval.
+	pollInitialInterval = 250 * time.Millisecond
+)
+
+// ErrPollTimeout is returned by the Wait helpers when PollOptions.MaxWait elapses
+// before the object they wait for reaches a final status.
+var ErrPollTimeout = errors.New("timed out waiting for a final status")
+
+// PollOptions configures how the Wait helpers poll an object, such as a file,
+// until it reaches a final status. Polls are frequent at first and back off
+// exponentially up to Interval.
+type PollOptions struct {
+	// Interval is the longest delay between polls, or DefaultPollInterval when
+	// zero.
+	Interval time.Duration
+	// MaxWait, if set, bounds how long to wait, after which ErrPollTimeout is
+	// returned. Otherwise polling goes on until the context is done.
+	MaxWait time.Duration
+}
+
+// poll calls check until it reports done or fails, waiting between calls as set
+// by opts.
+func poll(ctx context.Context, opts PollOptions, check func(ctx context.Context) (done bool, err error)) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	pollCtx := ctx
+	if opts.MaxWait > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, opts.MaxWait)
+		defer cancel()
+	}
+	timedOut := func() bool {
+		return ctx.Err() == nil && pollCtx.Err() != nil
+	}
+
+	delay := pollInitialInterval
+	for {
+		done, err := check(pollCtx)
+		if err != nil && timedOut() {
+			return fmt.Errorf("%w after %s", ErrPollTimeout, opts.MaxWait)
+		}
+		if err != nil || done {
+			return err
+		}
+
+		if delay > interval {
+			delay = interval
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-pollCtx.Done():
+			timer.Stop()
+			if timedOut() {
+				return fmt.Errorf("%w after %s", ErrPollTimeout, opts.MaxWait)
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
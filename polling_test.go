@@ -0,0 +1,28 @@
+package openai //nolint:testpackage // testing private function
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPollBackoff(t *testing.T) {
+	const interval = 600 * time.Millisecond
+	var polls []time.Time
+	err := poll(context.Background(), PollOptions{Interval: interval}, func(context.Context) (bool, error) {
+		polls = append(polls, time.Now())
+		return len(polls) == 4, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The delays double from pollInitialInterval and are capped at the interval.
+	want := []time.Duration{pollInitialInterval, 2 * pollInitialInterval, interval}
+	for i, delay := range want {
+		got := polls[i+1].Sub(polls[i])
+		if got < delay || got > delay+200*time.Millisecond {
+			t.Errorf("delay %d was %s, want %s", i+1, got, delay)
+		}
+	}
+}
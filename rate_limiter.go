@@ -0,0 +1,367 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is consulted by the client before every request it sends.
+type RateLimiter interface {
+	// Wait blocks until a request estimated to use tokens tokens may be sent, or
+	// until ctx is done.
+	Wait(ctx context.Context, tokens int) error
+	// Reconcile is called once the response is received. used is the number of
+	// tokens reported in the response's usage, or -1 if it is unknown. header is
+	// nil when no response was received. Streams report their usage later: once
+	// they end with a usage, Reconcile is called again with it and a nil header.
+	Reconcile(estimated, used int, header http.Header)
+}
+
+// TokenEstimator returns the approximate number of tokens in text.
+type TokenEstimator func(text string) int
+
+// DefaultTokenEstimator approximates the token count of text with the rule of
+// thumb of four characters per token.
+func DefaultTokenEstimator(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// TokenBucketRateLimiter is a RateLimiter enforcing separate budgets of requests
+// and tokens per minute. It is safe for concurrent use.
+type TokenBucketRateLimiter struct {
+	mu       sync.Mutex
+	requests *tokenBucket
+	tokens   *tokenBucket
+}
+
+// NewRateLimiter returns a TokenBucketRateLimiter allowing requestsPerMinute
+// requests and tokensPerMinute tokens per minute. A limit of zero or less is
+// not enforced.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *TokenBucketRateLimiter {
+	now := time.Now()
+	return &TokenBucketRateLimiter{
+		requests: newTokenBucket(requestsPerMinute, now),
+		tokens:   newTokenBucket(tokensPerMinute, now),
+	}
+}
+
+// Wait implements RateLimiter. Capacity is reserved immediately, so concurrent
+// callers are served in the order they called Wait.
+func (l *TokenBucketRateLimiter) Wait(ctx context.Context, tokens int) error {
+	l.mu.Lock()
+	now := time.Now()
+	wait := l.requests.reserve(1, now)
+	if d := l.tokens.reserve(float64(tokens), now); d > wait {
+		wait = d
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	cancel := func() {
+		l.mu.Lock()
+		l.requests.release(1)
+		l.tokens.release(float64(tokens))
+		l.mu.Unlock()
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		cancel()
+		return context.DeadlineExceeded
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Reconcile implements RateLimiter. Tokens reserved beyond what the request
+// used are returned to the budget, and the budgets never exceed what the
+// x-ratelimit-remaining-* headers report.
+func (l *TokenBucketRateLimiter) Reconcile(estimated, used int, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if used >= 0 {
+		l.tokens.release(float64(estimated - used))
+	}
+	if header == nil {
+		return
+	}
+	now := time.Now()
+	if remaining, err := strconv.Atoi(header.Get("x-ratelimit-remaining-requests")); err == nil {
+		l.requests.limitTo(float64(remaining), now)
+	}
+	if remaining, err := strconv.Atoi(header.Get("x-ratelimit-remaining-tokens")); err == nil {
+		l.tokens.limitTo(float64(remaining), now)
+	}
+}
+
+// tokenBucket refills at perMinute per minute up to a capacity of perMinute.
+// Its level may go negative, representing capacity reserved by waiting callers.
+type tokenBucket struct {
+	capacity  float64
+	perSecond float64
+	available float64
+	last      time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity:  float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+		available: float64(perMinute),
+		last:      now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.available += now.Sub(b.last).Seconds() * b.perSecond
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+	b.last = now
+}
+
+// reserve takes n from the bucket and returns how long the caller has to wait
+// for the bucket to have been able to afford it.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	if n > b.capacity {
+		n = b.capacity
+	}
+	b.refill(now)
+	b.available -= n
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.perSecond * float64(time.Second))
+}
+
+func (b *tokenBucket) release(n float64) {
+	if b == nil {
+		return
+	}
+	b.available += n
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+}
+
+func (b *tokenBucket) limitTo(remaining float64, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.refill(now)
+	if remaining < b.available {
+		b.available = remaining
+	}
+}
+
+// rateLimit wraps next so that every request waits for the configured
+// RateLimiter and reports its token usage back to it. It wraps each attempt of
+// a request, so that retries are limited too. The usage of streams is reported
+// once they end, by reportStreamUsage.
+func (c *Client) rateLimit(next RoundTripFunc, stream bool) RoundTripFunc {
+	limiter := c.config.RateLimiter
+	return func(req *http.Request) (*http.Response, error) {
+		tokens := c.estimateRequestTokens(req)
+		if err := limiter.Wait(req.Context(), tokens); err != nil {
+			return nil, err
+		}
+		if stream {
+			var once sync.Once
+			req = req.WithContext(context.WithValue(req.Context(), streamUsageReporterKey{}, func(used int) {
+				once.Do(func() { limiter.Reconcile(tokens, used, nil) })
+			}))
+		}
+
+		resp, err := next(req)
+		if resp == nil {
+			limiter.Reconcile(tokens, -1, nil)
+			return resp, err
+		}
+		used := -1
+		if !stream && err == nil && !isFailureStatusCode(resp) {
+			used = readUsageTokens(resp)
+		}
+		limiter.Reconcile(tokens, used, resp.Header)
+		return resp, err
+	}
+}
+
+// streamUsageReporterKey is the context key of the function reporting the
+// usage of a stream to the rate limiter.
+type streamUsageReporterKey struct{}
+
+// reportStreamUsage reports the usage of the stream of resp, once it ended, to
+// the rate limiter of the client that sent it, if any.
+func reportStreamUsage(resp *http.Response, usage *Usage) {
+	if resp == nil || resp.Request == nil || usage == nil {
+		return
+	}
+	if report, ok := resp.Request.Context().Value(streamUsageReporterKey{}).(func(int)); ok {
+		report(usage.TotalTokens)
+	}
+}
+
+// Estimated tokens of an image input, depending on its detail: the cost of a
+// 1024x1024 image in high detail, and the fixed cost of low detail.
+const (
+	imageTokenEstimate     = 765
+	lowDetailImageEstimate = 85
+)
+
+// estimateRequestTokens estimates the tokens a request will consume from its
+// JSON body: the text of the prompt and an estimate per image, plus the
+// completion tokens it may generate. Bodies that cannot be replayed, such as
+// streamed uploads, count as zero.
+func (c *Client) estimateRequestTokens(req *http.Request) int {
+	if req.GetBody == nil {
+		return 0
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return 0
+	}
+	defer body.Close()
+
+	var fields struct {
+		MaxTokens           int             `json:"max_tokens"`
+		MaxCompletionTokens int             `json:"max_completion_tokens"`
+		N                   int             `json:"n"`
+		Messages            json.RawMessage `json:"messages"`
+		Prompt              json.RawMessage `json:"prompt"`
+		Input               json.RawMessage `json:"input"`
+	}
+	if json.NewDecoder(body).Decode(&fields) != nil {
+		return 0
+	}
+
+	estimate := c.config.PromptTokenEstimator
+	if estimate == nil {
+		estimate = DefaultTokenEstimator
+	}
+	var prompt promptContent
+	for _, raw := range []json.RawMessage{fields.Messages, fields.Prompt, fields.Input} {
+		prompt.collect(raw)
+	}
+
+	completion := fields.MaxTokens
+	if fields.MaxCompletionTokens > completion {
+		completion = fields.MaxCompletionTokens
+	}
+	if fields.N > 1 {
+		completion *= fields.N
+	}
+	return estimate(prompt.text.String()) + prompt.imageTokens + completion
+}
+
+// promptContent is the content of a prompt counted by the rate limiter.
+type promptContent struct {
+	text        bytes.Buffer
+	imageTokens int
+}
+
+// promptTextFields are the fields holding text sent to the model: the content
+// of messages and of text parts, and the arguments of tool calls. Other
+// strings, such as roles, IDs or the data of images and audio, are not text.
+var promptTextFields = map[string]bool{"content": true, "text": true, "arguments": true}
+
+// collect adds the content of a prompt, messages or input to p: a string, a
+// list of strings, or messages and parts.
+func (p *promptContent) collect(raw json.RawMessage) {
+	if len(raw) == 0 {
+		return
+	}
+	var v any
+	if json.Unmarshal(raw, &v) != nil {
+		return
+	}
+	var walk func(v any, text bool)
+	walk = func(v any, text bool) {
+		switch v := v.(type) {
+		case string:
+			if text {
+				p.text.WriteString(v)
+				p.text.WriteByte('\n')
+			}
+		case []any:
+			for _, item := range v {
+				walk(item, text)
+			}
+		case map[string]any:
+			if p.collectImage(v) {
+				return
+			}
+			for key, item := range v {
+				walk(item, promptTextFields[key])
+			}
+		}
+	}
+	walk(v, true)
+}
+
+// collectImage adds the estimate of part to p if it is an image.
+func (p *promptContent) collectImage(part map[string]any) bool {
+	var detail any
+	switch part["type"] {
+	case "image_url":
+		if imageURL, ok := part["image_url"].(map[string]any); ok {
+			detail = imageURL["detail"]
+		}
+	case "input_image":
+		detail = part["detail"]
+	default:
+		return false
+	}
+	if detail == "low" {
+		p.imageTokens += lowDetailImageEstimate
+	} else {
+		p.imageTokens += imageTokenEstimate
+	}
+	return true
+}
+
+// readUsageTokens returns the total tokens in the usage of a JSON response, or
+// -1 if it has none. The body is buffered so it can still be decoded afterwards.
+func readUsageTokens(resp *http.Response) int {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		// Hand the read error on to whoever decodes the response.
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), erroredReader{err}))
+		return -1
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	usage := parseUsage(body)
+	if usage == nil {
+		return -1
+	}
+	return usage.TotalTokens
+}
+
+type erroredReader struct{ err error }
+
+func (r erroredReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
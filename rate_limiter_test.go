@@ -0,0 +1,257 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type recordingRateLimiter struct {
+	mu        sync.Mutex
+	waited    []int
+	estimated int
+	used      int
+	header    http.Header
+	// reconciled are the used tokens of every Reconcile call.
+	reconciled []int
+}
+
+func (l *recordingRateLimiter) Wait(_ context.Context, tokens int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waited = append(l.waited, tokens)
+	return nil
+}
+
+func (l *recordingRateLimiter) Reconcile(estimated, used int, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.estimated, l.used, l.header = estimated, used, header
+	l.reconciled = append(l.reconciled, used)
+}
+
+func TestRateLimiterConsultedAndReconciled(t *testing.T) {
+	limiter := &recordingRateLimiter{}
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("x-ratelimit-remaining-tokens", "900")
+		_, _ = w.Write([]byte(`{"id":"1","choices":[],"usage":{"total_tokens":42}}`))
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.RateLimiter = limiter
+	config.PromptTokenEstimator = func(text string) int { return strings.Count(text, "word") }
+	client := NewClientWithConfig(config)
+
+	resp, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:     GPT4,
+		MaxTokens: 10,
+		N:         2,
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "word word word"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if resp.Usage.TotalTokens != 42 {
+		t.Fatalf("response was not decoded after reading usage: %+v", resp)
+	}
+
+	// 3 prompt tokens plus 10 completion tokens for each of the 2 choices.
+	if len(limiter.waited) != 1 || limiter.waited[0] != 23 {
+		t.Fatalf("unexpected Wait calls %v", limiter.waited)
+	}
+	if limiter.estimated != 23 || limiter.used != 42 || limiter.header.Get("x-ratelimit-remaining-tokens") != "900" {
+		t.Fatalf("unexpected Reconcile(%d, %d, %v)", limiter.estimated, limiter.used, limiter.header)
+	}
+}
+
+func TestTokenBucketRateLimiterRequests(t *testing.T) {
+	limiter := NewRateLimiter(600, 0) // one request every 100ms once the burst is spent
+	ctx := context.Background()
+	for i := 0; i < 600; i++ {
+		checks.NoError(t, limiter.Wait(ctx, 1_000_000), "burst should not wait")
+	}
+
+	start := time.Now()
+	checks.NoError(t, limiter.Wait(ctx, 0), "Wait error")
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected to wait for a refill, waited %v", elapsed)
+	}
+}
+
+func TestTokenBucketRateLimiterRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(0, 60)
+	checks.NoError(t, limiter.Wait(context.Background(), 60), "first Wait error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx, 30)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+
+	// The cancelled reservation is returned, so a cheap request is only delayed
+	// by the first one.
+	limiter.mu.Lock()
+	available := limiter.tokens.available
+	limiter.mu.Unlock()
+	if available < -1 {
+		t.Fatalf("cancelled reservation was not released, available %v", available)
+	}
+}
+
+func TestTokenBucketRateLimiterReconcile(t *testing.T) {
+	limiter := NewRateLimiter(100, 1000)
+	checks.NoError(t, limiter.Wait(context.Background(), 500), "Wait error")
+
+	limiter.Reconcile(500, 100, nil)
+	if got := limiter.tokens.available; got < 899 || got > 900 {
+		t.Fatalf("unused tokens should be returned, available %v", got)
+	}
+
+	limiter.Reconcile(0, -1, http.Header{
+		"X-Ratelimit-Remaining-Requests": {"5"},
+		"X-Ratelimit-Remaining-Tokens":   {"200"},
+	})
+	if limiter.requests.available > 5 || limiter.tokens.available > 200 {
+		t.Fatalf("budgets should follow the headers: %v requests, %v tokens",
+			limiter.requests.available, limiter.tokens.available)
+	}
+}
+
+func TestTokenBucketRateLimiterConcurrent(t *testing.T) {
+	limiter := NewRateLimiter(6000, 60000)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = limiter.Wait(context.Background(), 10)
+			limiter.Reconcile(10, 5, nil)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEstimateRequestTokensWithoutReplayableBody(t *testing.T) {
+	client := NewClient(test.GetTestToken())
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost", nil)
+	if got := client.estimateRequestTokens(req); got != 0 {
+		t.Fatalf("expected 0 tokens, got %d", got)
+	}
+}
+
+func TestEstimateRequestTokensCountsTextContent(t *testing.T) {
+	config := DefaultConfig(test.GetTestToken())
+	config.PromptTokenEstimator = func(text string) int { return len(strings.Fields(text)) }
+	client := NewClientWithConfig(config)
+	image := strings.Repeat("iVBORw0KGgo", 10000)
+
+	req, err := client.newRequest(context.Background(), http.MethodPost, "http://localhost", withBody(
+		ChatCompletionRequest{
+			Model: GPT4o,
+			Messages: []ChatCompletionMessage{
+				{Role: ChatMessageRoleSystem, Content: "Be brief."},
+				{Role: ChatMessageRoleUser, MultiContent: []ChatMessagePart{
+					{Type: ChatMessagePartTypeText, Text: "What is this?"},
+					{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{
+						URL: "data:image/png;base64," + image,
+					}},
+					{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{
+						URL: "https://example.com/cat.png", Detail: ImageURLDetailLow,
+					}},
+				}},
+				{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{
+					ID: "call_1", Type: ToolTypeFunction,
+					Function: FunctionCall{Name: "look", Arguments: "cat"},
+				}}},
+			},
+			MaxTokens: 10,
+		}))
+	checks.NoError(t, err, "newRequest error")
+	// 2 + 3 + 1 words, an image in high and one in low detail, and the completion.
+	want := 6 + imageTokenEstimate + lowDetailImageEstimate + 10
+	if got := client.estimateRequestTokens(req); got != want {
+		t.Fatalf("estimated %d tokens, want %d", got, want)
+	}
+}
+
+func TestRateLimiterRetries(t *testing.T) {
+	limiter := &recordingRateLimiter{}
+	attempts := 0
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","choices":[],"usage":{"total_tokens":42}}`))
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.RateLimiter = limiter
+	config.MaxRetries = 1
+	config.RetryBackoff = func(int) time.Duration { return 0 }
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(limiter.waited) != 2 || len(limiter.reconciled) != 2 ||
+		limiter.reconciled[0] != -1 || limiter.reconciled[1] != 42 {
+		t.Fatalf("unexpected limiter calls: Wait %v, Reconcile %v", limiter.waited, limiter.reconciled)
+	}
+}
+
+func TestRateLimiterStreamUsage(t *testing.T) {
+	limiter := &recordingRateLimiter{}
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n" +
+			`data: {"id":"1","choices":[],"usage":{"total_tokens":7}}` + "\n\ndata: [DONE]\n\n"))
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.RateLimiter = limiter
+	client := NewClientWithConfig(config)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:         GPT4,
+		Messages:      []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello"}},
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	checks.ErrorIs(t, err, io.EOF, "stream should end")
+	stream.Close()
+	if len(limiter.reconciled) != 2 || limiter.reconciled[0] != -1 || limiter.reconciled[1] != 7 {
+		t.Fatalf("unexpected Reconcile calls %v", limiter.reconciled)
+	}
+}
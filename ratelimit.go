@@ -23,8 +23,14 @@ func (r ResetTime) String() string {
 }
 
 func (r ResetTime) Time() time.Time {
+	return time.Now().Add(r.Duration())
+}
+
+// Duration returns how long until the limit resets, or zero if the header is
+// missing or malformed.
+func (r ResetTime) Duration() time.Duration {
 	d, _ := time.ParseDuration(string(r))
-	return time.Now().Add(d)
+	return d
 }
 
 func newRateLimitHeaders(h http.Header) RateLimitHeaders {
@@ -0,0 +1,679 @@
+package openai
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/websocket"
+)
+
+const realtimeSuffix = "/realtime"
+
+// RealtimeAudioChunkSize is the size of the chunks of audio sent by
+// AppendInputAudio: 100ms of 24kHz mono PCM16 audio.
+const RealtimeAudioChunkSize = 4800
+
+// realtimeCloseTimeout is how long Close waits for the server to answer.
+const realtimeCloseTimeout = 5 * time.Second
+
+// realtimePongTimeout is how long a ping may go unanswered by default.
+const realtimePongTimeout = 30 * time.Second
+
+// ErrRealtimePongTimeout is returned by a realtime session closed because the
+// server did not answer a ping in time.
+var ErrRealtimePongTimeout = errors.New("realtime: no pong received in time")
+
+// RealtimeOptions configures a realtime session opened with ConnectRealtime.
+type RealtimeOptions struct {
+	Model string
+	// PingInterval, if positive, is the interval of the pings sent to keep the
+	// connection alive.
+	PingInterval time.Duration
+	// PongTimeout is how long a ping may go unanswered before the session is
+	// closed with ErrRealtimePongTimeout, 30s if zero. Pongs are read along
+	// with the events, so the session must be receiving them.
+	PongTimeout time.Duration
+	// Header is added to the headers of the handshake.
+	Header http.Header
+
+	// Proxy, NetDialContext and TLSClientConfig configure the connection like
+	// the fields of http.Transport. When nil, they are those of the transport
+	// of the HTTPClient of the configuration if it is an *http.Transport, and
+	// Proxy is http.ProxyFromEnvironment otherwise.
+	Proxy           func(*http.Request) (*url.URL, error)
+	NetDialContext  func(ctx context.Context, network, addr string) (net.Conn, error)
+	TLSClientConfig *tls.Config
+}
+
+// RealtimeClientEvent is an event sent by SendEvent. The events are encoded
+// with their type field.
+type RealtimeClientEvent interface {
+	json.Marshaler
+	realtimeClientEventType() string
+}
+
+// RealtimeSessionUpdateEvent updates the configuration of the session.
+type RealtimeSessionUpdateEvent struct {
+	EventID string                `json:"event_id,omitempty"`
+	Session RealtimeSessionConfig `json:"session"`
+}
+
+// RealtimeInputAudioBufferAppendEvent appends base64 encoded audio to the input
+// audio buffer. See AppendInputAudio.
+type RealtimeInputAudioBufferAppendEvent struct {
+	EventID string `json:"event_id,omitempty"`
+	Audio   string `json:"audio"`
+}
+
+// RealtimeInputAudioBufferCommitEvent commits the input audio buffer as a user
+// message, when the server does not detect turns itself.
+type RealtimeInputAudioBufferCommitEvent struct {
+	EventID string `json:"event_id,omitempty"`
+}
+
+// RealtimeInputAudioBufferClearEvent clears the input audio buffer.
+type RealtimeInputAudioBufferClearEvent struct {
+	EventID string `json:"event_id,omitempty"`
+}
+
+// RealtimeConversationItemCreateEvent adds an item to the conversation, after
+// the item with PreviousItemID if set.
+type RealtimeConversationItemCreateEvent struct {
+	EventID        string       `json:"event_id,omitempty"`
+	PreviousItemID string       `json:"previous_item_id,omitempty"`
+	Item           RealtimeItem `json:"item"`
+}
+
+// RealtimeConversationItemDeleteEvent removes an item from the conversation.
+type RealtimeConversationItemDeleteEvent struct {
+	EventID string `json:"event_id,omitempty"`
+	ItemID  string `json:"item_id"`
+}
+
+// RealtimeResponseCreateEvent asks the model for a response, configured by
+// Response if set.
+type RealtimeResponseCreateEvent struct {
+	EventID  string                  `json:"event_id,omitempty"`
+	Response *RealtimeResponseConfig `json:"response,omitempty"`
+}
+
+// RealtimeResponseCancelEvent cancels the response in progress.
+type RealtimeResponseCancelEvent struct {
+	EventID    string `json:"event_id,omitempty"`
+	ResponseID string `json:"response_id,omitempty"`
+}
+
+func (RealtimeSessionUpdateEvent) realtimeClientEventType() string { return "session.update" }
+
+func (e RealtimeSessionUpdateEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeSessionUpdateEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeInputAudioBufferAppendEvent) realtimeClientEventType() string {
+	return "input_audio_buffer.append"
+}
+
+func (e RealtimeInputAudioBufferAppendEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeInputAudioBufferAppendEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeInputAudioBufferCommitEvent) realtimeClientEventType() string {
+	return "input_audio_buffer.commit"
+}
+
+func (e RealtimeInputAudioBufferCommitEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeInputAudioBufferCommitEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeInputAudioBufferClearEvent) realtimeClientEventType() string {
+	return "input_audio_buffer.clear"
+}
+
+func (e RealtimeInputAudioBufferClearEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeInputAudioBufferClearEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeConversationItemCreateEvent) realtimeClientEventType() string {
+	return "conversation.item.create"
+}
+
+func (e RealtimeConversationItemCreateEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeConversationItemCreateEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeConversationItemDeleteEvent) realtimeClientEventType() string {
+	return "conversation.item.delete"
+}
+
+func (e RealtimeConversationItemDeleteEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeConversationItemDeleteEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeResponseCreateEvent) realtimeClientEventType() string { return "response.create" }
+
+func (e RealtimeResponseCreateEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeResponseCreateEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+func (RealtimeResponseCancelEvent) realtimeClientEventType() string { return "response.cancel" }
+
+func (e RealtimeResponseCancelEvent) MarshalJSON() ([]byte, error) {
+	type event RealtimeResponseCancelEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		event
+	}{e.realtimeClientEventType(), event(e)})
+}
+
+// RealtimeSessionConfig is the configuration of a realtime session. The
+// server sends it back with ID, Model and ExpiresAt set.
+type RealtimeSessionConfig struct {
+	ID        string `json:"id,omitempty"`
+	Model     string `json:"model,omitempty"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+	// Modalities are "text" and "audio".
+	Modalities   []string `json:"modalities,omitempty"`
+	Instructions string   `json:"instructions,omitempty"`
+	Voice        string   `json:"voice,omitempty"`
+	// InputAudioFormat and OutputAudioFormat are "pcm16", "g711_ulaw" or
+	// "g711_alaw".
+	InputAudioFormat        string                       `json:"input_audio_format,omitempty"`
+	OutputAudioFormat       string                       `json:"output_audio_format,omitempty"`
+	InputAudioTranscription *RealtimeTranscriptionConfig `json:"input_audio_transcription,omitempty"`
+	TurnDetection           *RealtimeTurnDetection       `json:"turn_detection,omitempty"`
+	Tools                   []RealtimeTool               `json:"tools,omitempty"`
+	// ToolChoice is "auto", "none", "required", or an object choosing a tool.
+	ToolChoice  any      `json:"tool_choice,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	// MaxResponseOutputTokens is a number of tokens, or "inf".
+	MaxResponseOutputTokens any `json:"max_response_output_tokens,omitempty"`
+}
+
+type RealtimeTranscriptionConfig struct {
+	Model    string `json:"model,omitempty"`
+	Language string `json:"language,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+}
+
+// RealtimeTurnDetection configures the detection of the end of the turns of the
+// user, by voice activity.
+type RealtimeTurnDetection struct {
+	// Type is "server_vad" or "semantic_vad".
+	Type              string   `json:"type"`
+	Threshold         *float64 `json:"threshold,omitempty"`
+	PrefixPaddingMs   int      `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs int      `json:"silence_duration_ms,omitempty"`
+	// CreateResponse sets whether a response is created at the end of turns.
+	CreateResponse *bool `json:"create_response,omitempty"`
+	// InterruptResponse sets whether the user speaking interrupts the response.
+	InterruptResponse *bool `json:"interrupt_response,omitempty"`
+	// Eagerness is that of semantic_vad: "low", "medium", "high" or "auto".
+	Eagerness string `json:"eagerness,omitempty"`
+}
+
+// RealtimeTool is a function the model may call.
+type RealtimeTool struct {
+	// Type is "function".
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// RealtimeResponseConfig overrides the configuration of the session for a
+// response.
+type RealtimeResponseConfig struct {
+	Modalities        []string       `json:"modalities,omitempty"`
+	Instructions      string         `json:"instructions,omitempty"`
+	Voice             string         `json:"voice,omitempty"`
+	OutputAudioFormat string         `json:"output_audio_format,omitempty"`
+	Tools             []RealtimeTool `json:"tools,omitempty"`
+	ToolChoice        any            `json:"tool_choice,omitempty"`
+	Temperature       *float32       `json:"temperature,omitempty"`
+	MaxOutputTokens   any            `json:"max_output_tokens,omitempty"`
+	// Conversation is "auto" to add the response to the conversation, or "none".
+	Conversation string            `json:"conversation,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	// Input replaces the conversation as the context of the response.
+	Input []RealtimeItem `json:"input,omitempty"`
+}
+
+// RealtimeItem is an item of the conversation: a message, a function call or
+// its output. The fields matching its Type are set.
+type RealtimeItem struct {
+	ID     string `json:"id,omitempty"`
+	Object string `json:"object,omitempty"`
+	// Type is "message", "function_call" or "function_call_output".
+	Type   string `json:"type"`
+	Status string `json:"status,omitempty"`
+	// Role and Content are those of messages.
+	Role    string            `json:"role,omitempty"`
+	Content []RealtimeContent `json:"content,omitempty"`
+	// CallID is set on function calls and their outputs. Name and Arguments are
+	// those of function calls, and Output that of function call outputs.
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+}
+
+// RealtimeContent is a content part of a message.
+type RealtimeContent struct {
+	// Type is "input_text", "input_audio", "text" or "audio".
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// Audio is base64 encoded.
+	Audio      string `json:"audio,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// RealtimeResponse is a response of the model.
+type RealtimeResponse struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	// Status is "in_progress", "completed", "cancelled", "incomplete" or
+	// "failed", detailed by StatusDetails.
+	Status        string         `json:"status"`
+	StatusDetails any            `json:"status_details,omitempty"`
+	Output        []RealtimeItem `json:"output"`
+	Usage         *RealtimeUsage `json:"usage,omitempty"`
+}
+
+// RealtimeUsage is the token usage of a realtime response. The details are nil
+// when the server does not report them.
+type RealtimeUsage struct {
+	TotalTokens        int                        `json:"total_tokens"`
+	InputTokens        int                        `json:"input_tokens"`
+	OutputTokens       int                        `json:"output_tokens"`
+	InputTokenDetails  *RealtimeInputTokenDetails `json:"input_token_details,omitempty"`
+	OutputTokenDetails *RealtimeTokenDetails      `json:"output_token_details,omitempty"`
+}
+
+// RealtimeTokenDetails splits the tokens of a realtime response by modality.
+type RealtimeTokenDetails struct {
+	TextTokens  int `json:"text_tokens"`
+	AudioTokens int `json:"audio_tokens"`
+}
+
+// RealtimeInputTokenDetails splits the input tokens of a realtime response by
+// modality, and tells how many were cached.
+type RealtimeInputTokenDetails struct {
+	RealtimeTokenDetails
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// RealtimeRateLimit is the state of a rate limit, sent with the
+// rate_limits.updated events.
+type RealtimeRateLimit struct {
+	Name         string  `json:"name"`
+	Limit        int     `json:"limit"`
+	Remaining    int     `json:"remaining"`
+	ResetSeconds float64 `json:"reset_seconds"`
+}
+
+// RealtimeServerEventType is the type of a RealtimeServerEvent.
+type RealtimeServerEventType string
+
+const (
+	RealtimeServerEventError                         RealtimeServerEventType = "error"
+	RealtimeServerEventSessionCreated                RealtimeServerEventType = "session.created"
+	RealtimeServerEventSessionUpdated                RealtimeServerEventType = "session.updated"
+	RealtimeServerEventConversationCreated           RealtimeServerEventType = "conversation.created"
+	RealtimeServerEventConversationItemCreated       RealtimeServerEventType = "conversation.item.created"
+	RealtimeServerEventConversationItemDeleted       RealtimeServerEventType = "conversation.item.deleted"
+	RealtimeServerEventInputAudioTranscriptionDone   RealtimeServerEventType = "conversation.item.input_audio_transcription.completed" //nolint:lll
+	RealtimeServerEventInputAudioBufferCommitted     RealtimeServerEventType = "input_audio_buffer.committed"
+	RealtimeServerEventInputAudioBufferCleared       RealtimeServerEventType = "input_audio_buffer.cleared"
+	RealtimeServerEventInputAudioBufferSpeechStarted RealtimeServerEventType = "input_audio_buffer.speech_started"
+	RealtimeServerEventInputAudioBufferSpeechStopped RealtimeServerEventType = "input_audio_buffer.speech_stopped"
+	RealtimeServerEventResponseCreated               RealtimeServerEventType = "response.created"
+	RealtimeServerEventResponseDone                  RealtimeServerEventType = "response.done"
+	RealtimeServerEventResponseOutputItemAdded       RealtimeServerEventType = "response.output_item.added"
+	RealtimeServerEventResponseOutputItemDone        RealtimeServerEventType = "response.output_item.done"
+	RealtimeServerEventResponseContentPartAdded      RealtimeServerEventType = "response.content_part.added"
+	RealtimeServerEventResponseContentPartDone       RealtimeServerEventType = "response.content_part.done"
+	RealtimeServerEventResponseTextDelta             RealtimeServerEventType = "response.text.delta"
+	RealtimeServerEventResponseTextDone              RealtimeServerEventType = "response.text.done"
+	RealtimeServerEventResponseAudioTranscriptDelta  RealtimeServerEventType = "response.audio_transcript.delta"
+	RealtimeServerEventResponseAudioTranscriptDone   RealtimeServerEventType = "response.audio_transcript.done"
+	RealtimeServerEventResponseAudioDelta            RealtimeServerEventType = "response.audio.delta"
+	RealtimeServerEventResponseAudioDone             RealtimeServerEventType = "response.audio.done"
+	RealtimeServerEventResponseFunctionCallArgsDelta RealtimeServerEventType = "response.function_call_arguments.delta"
+	RealtimeServerEventResponseFunctionCallArgsDone  RealtimeServerEventType = "response.function_call_arguments.done"
+	RealtimeServerEventRateLimitsUpdated             RealtimeServerEventType = "rate_limits.updated"
+)
+
+// RealtimeServerEvent is an event received by RecvEvent. The fields set depend
+// on its Type: Session for session events, Item for conversation items,
+// Response for response.created and response.done, Part for content parts, and
+// Delta, Text, Transcript or Arguments for the fragments of the output item
+// ItemID of the response ResponseID. Raw is the data of every event.
+type RealtimeServerEvent struct {
+	Type           RealtimeServerEventType `json:"type"`
+	EventID        string                  `json:"event_id"`
+	Session        *RealtimeSessionConfig  `json:"session,omitempty"`
+	Item           *RealtimeItem           `json:"item,omitempty"`
+	PreviousItemID string                  `json:"previous_item_id,omitempty"`
+	Response       *RealtimeResponse       `json:"response,omitempty"`
+	ResponseID     string                  `json:"response_id,omitempty"`
+	ItemID         string                  `json:"item_id,omitempty"`
+	OutputIndex    int                     `json:"output_index"`
+	ContentIndex   int                     `json:"content_index"`
+	Part           *RealtimeContent        `json:"part,omitempty"`
+	// Delta is base64 encoded audio for response.audio.delta events, see
+	// DecodeAudio.
+	Delta        string              `json:"delta,omitempty"`
+	Text         string              `json:"text,omitempty"`
+	Transcript   string              `json:"transcript,omitempty"`
+	CallID       string              `json:"call_id,omitempty"`
+	Name         string              `json:"name,omitempty"`
+	Arguments    string              `json:"arguments,omitempty"`
+	AudioStartMs int                 `json:"audio_start_ms,omitempty"`
+	AudioEndMs   int                 `json:"audio_end_ms,omitempty"`
+	RateLimits   []RealtimeRateLimit `json:"rate_limits,omitempty"`
+	Error        *APIError           `json:"error,omitempty"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// DecodeAudio decodes the audio of a response.audio.delta event.
+func (e RealtimeServerEvent) DecodeAudio() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(e.Delta)
+}
+
+// RealtimeSession is a realtime session opened with ConnectRealtime. One
+// goroutine may receive events while others send them.
+type RealtimeSession struct {
+	conn *websocket.Conn
+
+	closeOnce sync.Once
+	done      chan struct{}
+	pongs     chan struct{}
+	errMu     sync.Mutex
+	err       error
+	// audioRemainder is the odd byte of the audio appended last, sent with the
+	// next chunk to keep the PCM16 samples whole.
+	audioMu        sync.Mutex
+	audioRemainder []byte
+}
+
+// ConnectRealtime opens a realtime session over a WebSocket, authenticated like
+// the other requests of the client. The HTTPClient of the configuration is not
+// used, only the proxy, dialer and TLS configuration of its transport.
+func (c *Client) ConnectRealtime(ctx context.Context, opts RealtimeOptions) (*RealtimeSession, error) {
+	u, err := url.Parse(c.fullURL(realtimeSuffix))
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	if opts.Model != "" {
+		query.Set("model", opts.Model)
+	}
+	u.RawQuery = query.Encode()
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, u.String(), withRealtimeBeta())
+	if err != nil {
+		return nil, err
+	}
+	header := req.Header.Clone()
+	for k, v := range opts.Header {
+		header[k] = v
+	}
+
+	conn, resp, err := c.realtimeDialer(opts).Dial(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil && errors.Is(err, websocket.ErrBadHandshake) {
+			return nil, c.handleErrorResp(resp)
+		}
+		return nil, err
+	}
+
+	session := &RealtimeSession{conn: conn, done: make(chan struct{}), pongs: make(chan struct{}, 1)}
+	conn.PongHandler = func([]byte) {
+		select {
+		case session.pongs <- struct{}{}:
+		default:
+		}
+	}
+	if opts.PingInterval > 0 {
+		timeout := opts.PongTimeout
+		if timeout <= 0 {
+			timeout = realtimePongTimeout
+		}
+		go session.keepAlive(opts.PingInterval, timeout)
+	}
+	return session, nil
+}
+
+// realtimeDialer returns the dialer of the options, completed with the
+// transport of the HTTP client.
+func (c *Client) realtimeDialer(opts RealtimeOptions) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:           opts.Proxy,
+		NetDialContext:  opts.NetDialContext,
+		TLSClientConfig: opts.TLSClientConfig,
+	}
+	var transport http.RoundTripper
+	if httpClient, ok := c.config.HTTPClient.(*http.Client); ok {
+		transport = httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		if dialer.Proxy == nil {
+			dialer.Proxy = t.Proxy
+		}
+		if dialer.NetDialContext == nil {
+			dialer.NetDialContext = t.DialContext
+		}
+		if dialer.TLSClientConfig == nil {
+			dialer.TLSClientConfig = t.TLSClientConfig
+		}
+	} else if dialer.Proxy == nil {
+		dialer.Proxy = http.ProxyFromEnvironment
+	}
+	return dialer
+}
+
+// keepAlive pings the server every interval, and closes the session when a
+// ping is not answered within timeout.
+func (s *RealtimeSession) keepAlive(interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+		// Forget the pong of an earlier ping.
+		select {
+		case <-s.pongs:
+		default:
+		}
+		if s.conn.Ping(nil) != nil {
+			return
+		}
+		timer := time.NewTimer(timeout)
+		select {
+		case <-s.pongs:
+			timer.Stop()
+		case <-timer.C:
+			s.fail(ErrRealtimePongTimeout)
+			return
+		case <-s.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// fail closes the connection, making its reads and writes return err.
+func (s *RealtimeSession) fail(err error) {
+	s.errMu.Lock()
+	s.err = err
+	s.errMu.Unlock()
+	_ = s.conn.CloseNow()
+}
+
+// connErr returns the error the session failed with instead of err, if any.
+func (s *RealtimeSession) connErr(err error) error {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return err
+}
+
+// SendEvent sends a client event.
+func (s *RealtimeSession) SendEvent(event RealtimeClientEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err = s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return s.connErr(err)
+	}
+	return nil
+}
+
+// RecvEvent receives the next server event. An error event is returned with
+// its Error, which does not end the session. io.EOF is returned once the
+// session is closed normally.
+func (s *RealtimeSession) RecvEvent() (RealtimeServerEvent, error) {
+	var event RealtimeServerEvent
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && (closeErr.Code == websocket.CloseNormalClosure ||
+				closeErr.Code == websocket.CloseNoStatus) {
+				return event, io.EOF
+			}
+			return event, s.connErr(err)
+		}
+		if messageType == websocket.TextMessage {
+			if err = json.Unmarshal(data, &event); err != nil {
+				return event, fmt.Errorf("invalid realtime event %s: %w", data, err)
+			}
+			event.Raw = data
+			break
+		}
+	}
+	if event.Type == RealtimeServerEventError {
+		if event.Error == nil {
+			return event, &APIError{Message: "unknown realtime error"}
+		}
+		return event, event.Error
+	}
+	return event, nil
+}
+
+// AppendInputAudio appends PCM16 audio to the input audio buffer, in chunks of
+// RealtimeAudioChunkSize bytes.
+func (s *RealtimeSession) AppendInputAudio(audio []byte) error {
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
+	if len(s.audioRemainder) > 0 {
+		audio = append(s.audioRemainder, audio...)
+		s.audioRemainder = nil
+	}
+	if len(audio)%2 == 1 {
+		s.audioRemainder = []byte{audio[len(audio)-1]}
+		audio = audio[:len(audio)-1]
+	}
+	for len(audio) > 0 {
+		n := RealtimeAudioChunkSize
+		if n > len(audio) {
+			n = len(audio)
+		}
+		event := RealtimeInputAudioBufferAppendEvent{Audio: base64.StdEncoding.EncodeToString(audio[:n])}
+		if err := s.SendEvent(event); err != nil {
+			return err
+		}
+		audio = audio[n:]
+	}
+	return nil
+}
+
+// AppendInputAudioFrom appends the PCM16 audio read from r to the input audio
+// buffer until r is exhausted, in chunks of RealtimeAudioChunkSize bytes.
+func (s *RealtimeSession) AppendInputAudioFrom(r io.Reader) error {
+	chunk := make([]byte, RealtimeAudioChunkSize)
+	for {
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			if appendErr := s.AppendInputAudio(chunk[:n]); appendErr != nil {
+				return appendErr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// CommitInputAudio commits the input audio buffer as a user message.
+func (s *RealtimeSession) CommitInputAudio() error {
+	return s.SendEvent(RealtimeInputAudioBufferCommitEvent{})
+}
+
+// Close closes the session gracefully, waiting briefly for the server to
+// acknowledge it. A concurrent RecvEvent returns io.EOF.
+func (s *RealtimeSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close(websocket.CloseNormalClosure, "", realtimeCloseTimeout)
+	})
+	return err
+}
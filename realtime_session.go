@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// RealtimeSessionRequest configures a realtime session created with
+// CreateRealtimeSession.
+type RealtimeSessionRequest struct {
+	RealtimeSessionConfig
+	// ExtraBody is merged into the request body, for the fields of newer
+	// revisions of the API.
+	ExtraBody map[string]any `json:"-"`
+}
+
+// RealtimeTranscriptionSessionRequest configures a realtime transcription
+// session created with CreateRealtimeTranscriptionSession.
+type RealtimeTranscriptionSessionRequest struct {
+	InputAudioFormat         string                       `json:"input_audio_format,omitempty"`
+	InputAudioTranscription  *RealtimeTranscriptionConfig `json:"input_audio_transcription,omitempty"`
+	TurnDetection            *RealtimeTurnDetection       `json:"turn_detection,omitempty"`
+	InputAudioNoiseReduction *RealtimeNoiseReduction      `json:"input_audio_noise_reduction,omitempty"`
+	// Include lists additional fields of the transcriptions, such as
+	// "item.input_audio_transcription.logprobs".
+	Include []string `json:"include,omitempty"`
+	// ExtraBody is merged into the request body, for the fields of newer
+	// revisions of the API.
+	ExtraBody map[string]any `json:"-"`
+}
+
+type RealtimeNoiseReduction struct {
+	// Type is "near_field" or "far_field".
+	Type string `json:"type"`
+}
+
+// RealtimeClientSecret is an ephemeral key for a realtime session, to hand to a
+// client instead of the API key.
+type RealtimeClientSecret struct {
+	Value string `json:"value"`
+	// ExpiresAt is the Unix time after which the key cannot open a session.
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// RealtimeSessionResponse is a realtime session created with an ephemeral
+// ClientSecret. Its RawJSON method returns the JSON it was decoded from, to read
+// the fields the library does not know about.
+type RealtimeSessionResponse struct {
+	RealtimeSessionConfig
+	Object       string               `json:"object"`
+	ClientSecret RealtimeClientSecret `json:"client_secret"`
+
+	httpHeader
+	rawJSON
+}
+
+// CreateRealtimeSession creates a realtime session and an ephemeral key to
+// connect to it, for clients such as browsers that must not hold the API key.
+func (c *Client) CreateRealtimeSession(
+	ctx context.Context,
+	request RealtimeSessionRequest,
+) (RealtimeSessionResponse, error) {
+	return c.createRealtimeSession(ctx, realtimeSuffix+"/sessions", request, request.ExtraBody)
+}
+
+// CreateRealtimeTranscriptionSession creates a realtime transcription session
+// and an ephemeral key to connect to it.
+func (c *Client) CreateRealtimeTranscriptionSession(
+	ctx context.Context,
+	request RealtimeTranscriptionSessionRequest,
+) (RealtimeSessionResponse, error) {
+	return c.createRealtimeSession(ctx, realtimeSuffix+"/transcription_sessions", request, request.ExtraBody)
+}
+
+func (c *Client) createRealtimeSession(
+	ctx context.Context,
+	urlSuffix string,
+	request any,
+	extraBody map[string]any,
+) (response RealtimeSessionResponse, err error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return
+	}
+	var body map[string]any
+	if err = json.Unmarshal(data, &body); err != nil {
+		return
+	}
+
+	// The raw JSON is always kept, as the fields of sessions change often.
+	c = c.WithOptions(WithRawJSON())
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBody(body),
+		withExtraBody(extraBody),
+		withRealtimeBeta())
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+func withRealtimeBeta() requestOption {
+	return func(args *requestOptions) {
+		args.header.Set("OpenAI-Beta", "realtime=v1")
+	}
+}
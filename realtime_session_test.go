@@ -0,0 +1,80 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateRealtimeSession(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/realtime/sessions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("OpenAI-Beta") != "realtime=v1" {
+			t.Error("missing the realtime beta header")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"sess_1","object":"realtime.session","model":"gpt-4o-realtime-preview",`+
+			`"voice":"verse","modalities":["audio","text"],"turn_detection":{"type":"semantic_vad","eagerness":"low"},`+
+			`"tracing":"auto","client_secret":{"value":"ek_abc123","expires_at":1234567890}}`)
+	})
+	threshold := 0.6
+
+	session, err := client.CreateRealtimeSession(context.Background(), openai.RealtimeSessionRequest{
+		RealtimeSessionConfig: openai.RealtimeSessionConfig{
+			Model:                   "gpt-4o-realtime-preview",
+			Modalities:              []string{"audio", "text"},
+			Voice:                   "verse",
+			Instructions:            "Be brief.",
+			InputAudioFormat:        "pcm16",
+			InputAudioTranscription: &openai.RealtimeTranscriptionConfig{Model: "whisper-1"},
+			TurnDetection:           &openai.RealtimeTurnDetection{Type: "server_vad", Threshold: &threshold},
+			Tools:                   []openai.RealtimeTool{{Type: "function", Name: "get_weather"}},
+		},
+		ExtraBody: map[string]any{"tracing": "auto"},
+	})
+	checks.NoError(t, err, "CreateRealtimeSession error")
+
+	turnDetection, _ := request["turn_detection"].(map[string]any)
+	if request["voice"] != "verse" || request["tracing"] != "auto" || turnDetection["threshold"] != 0.6 ||
+		request["ExtraBody"] != nil || request["id"] != nil {
+		t.Errorf("unexpected request %v", request)
+	}
+	if session.ClientSecret.Value != "ek_abc123" || session.ClientSecret.ExpiresAt != 1234567890 ||
+		session.ID != "sess_1" || session.TurnDetection.Eagerness != "low" {
+		t.Errorf("unexpected session %+v", session)
+	}
+	if !strings.Contains(string(session.RawJSON()), `"tracing":"auto"`) {
+		t.Errorf("unknown fields should be kept in the raw JSON, got %s", session.RawJSON())
+	}
+}
+
+func TestCreateRealtimeTranscriptionSession(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/realtime/transcription_sessions", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"sess_2","object":"realtime.transcription_session",`+
+			`"client_secret":{"value":"ek_def456","expires_at":1234567890}}`)
+	})
+
+	session, err := client.CreateRealtimeTranscriptionSession(context.Background(),
+		openai.RealtimeTranscriptionSessionRequest{
+			InputAudioTranscription:  &openai.RealtimeTranscriptionConfig{Model: "gpt-4o-transcribe", Language: "en"},
+			InputAudioNoiseReduction: &openai.RealtimeNoiseReduction{Type: "near_field"},
+		})
+	checks.NoError(t, err, "CreateRealtimeTranscriptionSession error")
+	noiseReduction, _ := request["input_audio_noise_reduction"].(map[string]any)
+	if noiseReduction["type"] != "near_field" || session.Object != "realtime.transcription_session" ||
+		session.ClientSecret.Value != "ek_def456" {
+		t.Errorf("unexpected session %+v for request %v", session, request)
+	}
+}
@@ -0,0 +1,158 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/internal/websocket"
+)
+
+func TestRealtimeSession(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	type clientEvent struct {
+		Type    string `json:"type"`
+		Audio   string `json:"audio"`
+		Session struct {
+			Voice string `json:"voice"`
+		} `json:"session"`
+	}
+	received := make(chan []clientEvent, 1)
+	server.RegisterHandler("/v1/realtime", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("OpenAI-Beta") != "realtime=v1" || r.URL.Query().Get("model") != "gpt-4o-realtime-preview" {
+			http.Error(w, `{"error":{"message":"bad handshake"}}`, http.StatusBadRequest)
+			return
+		}
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		send := func(event string) { _ = conn.WriteMessage(websocket.TextMessage, []byte(event)) }
+		send(`{"type":"session.created","event_id":"ev_1","session":{"id":"sess_1","model":"gpt-4o-realtime-preview"}}`)
+
+		var events []clientEvent
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event clientEvent
+			_ = json.Unmarshal(data, &event)
+			events = append(events, event)
+			if event.Type == "input_audio_buffer.commit" {
+				break
+			}
+		}
+		received <- events
+
+		send(`{"type":"response.audio.delta","event_id":"ev_2","response_id":"resp_1","item_id":"item_1",` +
+			`"delta":"` + base64.StdEncoding.EncodeToString([]byte("pcm")) + `"}`)
+		send(`{"type":"error","event_id":"ev_3","error":{"type":"invalid_request_error",` +
+			`"code":"invalid_value","message":"Invalid voice."}}`)
+		send(`{"type":"response.function_call_arguments.done","event_id":"ev_4","call_id":"call_1",` +
+			`"name":"get_weather","arguments":"{}"}`)
+		_ = conn.Close(websocket.CloseNormalClosure, "", time.Second)
+	})
+	ctx := context.Background()
+
+	_, err := client.ConnectRealtime(ctx, openai.RealtimeOptions{Model: "other"})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "bad handshake" {
+		t.Fatalf("expected the handshake error as an APIError, got %v", err)
+	}
+
+	session, err := client.ConnectRealtime(ctx, openai.RealtimeOptions{
+		Model:        "gpt-4o-realtime-preview",
+		PingInterval: time.Millisecond,
+	})
+	checks.NoError(t, err, "ConnectRealtime error")
+	defer session.Close()
+
+	event, err := session.RecvEvent()
+	checks.NoError(t, err, "RecvEvent error")
+	if event.Type != openai.RealtimeServerEventSessionCreated || event.Session.ID != "sess_1" || len(event.Raw) == 0 {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	checks.NoError(t, session.SendEvent(openai.RealtimeSessionUpdateEvent{
+		Session: openai.RealtimeSessionConfig{Voice: "alloy"},
+	}), "SendEvent error")
+	audio := bytes.Repeat([]byte{1}, 2*openai.RealtimeAudioChunkSize+401)
+	checks.NoError(t, session.AppendInputAudio(audio), "AppendInputAudio error")
+	checks.NoError(t, session.AppendInputAudioFrom(bytes.NewReader([]byte{2, 3, 4})), "AppendInputAudioFrom error")
+	checks.NoError(t, session.CommitInputAudio(), "CommitInputAudio error")
+
+	events := <-received
+	var sizes []int
+	for _, e := range events[1 : len(events)-1] {
+		decoded, _ := base64.StdEncoding.DecodeString(e.Audio)
+		sizes = append(sizes, len(decoded))
+	}
+	if events[0].Type != "session.update" || events[0].Session.Voice != "alloy" ||
+		len(sizes) != 4 || sizes[0] != openai.RealtimeAudioChunkSize || sizes[2] != 400 || sizes[3] != 4 {
+		t.Errorf("unexpected client events %+v with audio sizes %v", events, sizes)
+	}
+
+	event, err = session.RecvEvent()
+	checks.NoError(t, err, "RecvEvent error")
+	pcm, err := event.DecodeAudio()
+	if event.Type != openai.RealtimeServerEventResponseAudioDelta || err != nil || string(pcm) != "pcm" {
+		t.Errorf("unexpected audio event %+v", event)
+	}
+	_, err = session.RecvEvent()
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_value" || apiErr.Message != "Invalid voice." {
+		t.Errorf("expected the error event as an APIError, got %v", err)
+	}
+	event, err = session.RecvEvent()
+	checks.NoError(t, err, "error events should not end the session")
+	if event.Type != openai.RealtimeServerEventResponseFunctionCallArgsDone || event.CallID != "call_1" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	_, err = session.RecvEvent()
+	checks.ErrorIs(t, err, io.EOF, "a normal closure should end the session")
+	checks.NoError(t, session.Close(), "Close error")
+}
+
+func TestRealtimePongTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	stop := make(chan struct{})
+	defer close(stop)
+	server.RegisterHandler("/v1/realtime", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		// The server never reads, so the pings are not answered.
+		<-stop
+		_ = conn.CloseNow()
+	})
+
+	session, err := client.ConnectRealtime(context.Background(), openai.RealtimeOptions{
+		PingInterval: time.Millisecond,
+		PongTimeout:  10 * time.Millisecond,
+	})
+	checks.NoError(t, err, "ConnectRealtime error")
+	defer session.Close()
+	_, err = session.RecvEvent()
+	checks.ErrorIs(t, err, openai.ErrRealtimePongTimeout, "an unanswered ping should end the session")
+	err = session.SendEvent(openai.RealtimeInputAudioBufferClearEvent{})
+	checks.ErrorIs(t, err, openai.ErrRealtimePongTimeout, "an unanswered ping should end the session")
+}
+
+func TestRealtimeClientEventJSON(t *testing.T) {
+	data, err := json.Marshal(openai.RealtimeConversationItemDeleteEvent{EventID: "ev_1", ItemID: "item_1"})
+	checks.NoError(t, err, "Marshal error")
+	if string(data) != `{"type":"conversation.item.delete","event_id":"ev_1","item_id":"item_1"}` {
+		t.Errorf("unexpected event JSON %s", data)
+	}
+}
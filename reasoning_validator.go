@@ -26,6 +26,14 @@ var (
 	ErrReasoningModelMaxTokensDeprecated = errors.New("this model is not supported MaxTokens, please use MaxCompletionTokens")
 	ErrReasoningModelLimitationsLogprobs = errors.New("this model has beta-limitations, logprobs not supported")                                                                               //nolint:lll
 	ErrReasoningModelLimitationsOther    = errors.New("this model has beta-limitations, temperature, top_p and n are fixed at 1, while presence_penalty and frequency_penalty are fixed at 0") //nolint:lll
+	ErrReasoningModelSystemMessage       = errors.New("this model does not support system messages, please use developer messages instead")                                                    //nolint:lll
+)
+
+// Reasoning efforts of ChatCompletionRequest.ReasoningEffort.
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
 )
 
 // ReasoningValidator handles validation for o-series model requests.
@@ -38,11 +46,7 @@ func NewReasoningValidator() *ReasoningValidator {
 
 // Validate performs all validation checks for o-series models.
 func (v *ReasoningValidator) Validate(request ChatCompletionRequest) error {
-	o1Series := strings.HasPrefix(request.Model, "o1")
-	o3Series := strings.HasPrefix(request.Model, "o3")
-	o4Series := strings.HasPrefix(request.Model, "o4")
-
-	if !o1Series && !o3Series && !o4Series {
+	if !isReasoningModel(request.Model) {
 		return nil
 	}
 
@@ -61,21 +65,48 @@ func (v *ReasoningValidator) validateReasoningModelParams(request ChatCompletion
 	if request.LogProbs {
 		return ErrReasoningModelLimitationsLogprobs
 	}
-	if request.Temperature > 0 && request.Temperature != 1 {
+	if request.Temperature != nil && *request.Temperature != 1 {
 		return ErrReasoningModelLimitationsOther
 	}
-	if request.TopP > 0 && request.TopP != 1 {
+	if request.TopP != nil && *request.TopP != 1 {
 		return ErrReasoningModelLimitationsOther
 	}
 	if request.N > 0 && request.N != 1 {
 		return ErrReasoningModelLimitationsOther
 	}
-	if request.PresencePenalty > 0 {
+	if request.PresencePenalty != nil && *request.PresencePenalty != 0 {
 		return ErrReasoningModelLimitationsOther
 	}
-	if request.FrequencyPenalty > 0 {
+	if request.FrequencyPenalty != nil && *request.FrequencyPenalty != 0 {
 		return ErrReasoningModelLimitationsOther
 	}
+	for _, message := range request.Messages {
+		if message.Role == ChatMessageRoleSystem {
+			return ErrReasoningModelSystemMessage
+		}
+	}
 
 	return nil
 }
+
+// isReasoningModel reports whether model is an o-series model.
+func isReasoningModel(model string) bool {
+	return strings.HasPrefix(model, "o1") || strings.HasPrefix(model, "o3") || strings.HasPrefix(model, "o4")
+}
+
+// withDeveloperMessages returns the request with its system messages turned into
+// developer messages, when the client is configured to do so for o-series models.
+func (c *Client) withDeveloperMessages(request ChatCompletionRequest) ChatCompletionRequest {
+	if !c.config.ReasoningDeveloperMessages || !isReasoningModel(request.Model) {
+		return request
+	}
+	messages := make([]ChatCompletionMessage, len(request.Messages))
+	for i, message := range request.Messages {
+		if message.Role == ChatMessageRoleSystem {
+			message.Role = ChatMessageRoleDeveloper
+		}
+		messages[i] = message
+	}
+	request.Messages = messages
+	return request
+}
@@ -0,0 +1,497 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const responsesSuffix = "/responses"
+
+var (
+	// ErrResponseInvalidInput is returned when the input of a ResponseRequest is
+	// neither a string nor a []ResponseItem.
+	ErrResponseInvalidInput = errors.New("response input must be a string or a []ResponseItem")
+	// ErrResponseFailed is returned by WaitForResponse when the response fails.
+	ErrResponseFailed = errors.New("response failed")
+)
+
+// ResponseItemType is the type of an item of the input or output of a response.
+type ResponseItemType string
+
+const (
+	ResponseItemTypeMessage             ResponseItemType = "message"
+	ResponseItemTypeFunctionCall        ResponseItemType = "function_call"
+	ResponseItemTypeFunctionCallOutput  ResponseItemType = "function_call_output"
+	ResponseItemTypeReasoning           ResponseItemType = "reasoning"
+	ResponseItemTypeWebSearchCall       ResponseItemType = "web_search_call"
+	ResponseItemTypeFileSearchCall      ResponseItemType = "file_search_call"
+	ResponseItemTypeComputerCall        ResponseItemType = "computer_call"
+	ResponseItemTypeComputerCallOutput  ResponseItemType = "computer_call_output"
+	ResponseItemTypeMCPListTools        ResponseItemType = "mcp_list_tools"
+	ResponseItemTypeMCPCall             ResponseItemType = "mcp_call"
+	ResponseItemTypeMCPApprovalRequest  ResponseItemType = "mcp_approval_request"
+	ResponseItemTypeMCPApprovalResponse ResponseItemType = "mcp_approval_response"
+)
+
+// ResponseContentType is the type of a content part of a message item.
+type ResponseContentType string
+
+const (
+	ResponseContentTypeInputText  ResponseContentType = "input_text"
+	ResponseContentTypeInputImage ResponseContentType = "input_image"
+	ResponseContentTypeInputFile  ResponseContentType = "input_file"
+	ResponseContentTypeOutputText ResponseContentType = "output_text"
+	ResponseContentTypeRefusal    ResponseContentType = "refusal"
+)
+
+// ResponseItem is an item of the input or the output of a response: a message,
+// a function or built-in tool call or its output, or the reasoning of the model.
+// The fields matching its Type are set. Output items can be sent back as input
+// items.
+type ResponseItem struct {
+	Type   ResponseItemType `json:"type"`
+	ID     string           `json:"id,omitempty"`
+	Status string           `json:"status,omitempty"`
+
+	// Role and Content are those of message items.
+	Role    string            `json:"role,omitempty"`
+	Content []ResponseContent `json:"content,omitempty"`
+
+	// CallID is set on function calls and their outputs. Name and Arguments are
+	// those of function calls, and Output that of function call outputs.
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+
+	// Summary and EncryptedContent are those of reasoning items.
+	Summary          []ResponseReasoningSummary `json:"summary,omitempty"`
+	EncryptedContent string                     `json:"encrypted_content,omitempty"`
+
+	// Queries and Results are those of file_search_call items. Results are only
+	// set when requested with the include parameter.
+	Queries []string                   `json:"queries,omitempty"`
+	Results []ResponseFileSearchResult `json:"results,omitempty"`
+
+	// Action and PendingSafetyChecks are those of computer_call items, and
+	// Screenshot and AcknowledgedSafetyChecks those of computer_call_output
+	// items, whose output is the Screenshot instead of Output.
+	Action                   *ResponseComputerAction     `json:"action,omitempty"`
+	PendingSafetyChecks      []ResponseSafetyCheck       `json:"pending_safety_checks,omitempty"`
+	Screenshot               *ResponseComputerScreenshot `json:"-"`
+	AcknowledgedSafetyChecks []ResponseSafetyCheck       `json:"acknowledged_safety_checks,omitempty"`
+
+	// ServerLabel is set on mcp_list_tools, mcp_call and mcp_approval_request
+	// items, which call tools by Name with Arguments like function calls. Tools
+	// and Error are those of mcp_list_tools items, and Output and Error those of
+	// mcp_call items.
+	ServerLabel string            `json:"server_label,omitempty"`
+	Tools       []ResponseMCPTool `json:"tools,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	// ApprovalRequestID, Approve and Reason are those of mcp_approval_response
+	// items.
+	ApprovalRequestID string `json:"approval_request_id,omitempty"`
+	Approve           *bool  `json:"approve,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// ResponseContent is a content part of a message item. The fields matching its
+// Type are set.
+type ResponseContent struct {
+	Type ResponseContentType `json:"type"`
+	// Text is that of input_text and output_text parts.
+	Text        string `json:"text,omitempty"`
+	Annotations []any  `json:"annotations,omitempty"`
+	Refusal     string `json:"refusal,omitempty"`
+	// ResponseAnnotations are the Annotations of output_text parts, typed.
+	ResponseAnnotations []ResponseAnnotation `json:"-"`
+	// ImageURL, FileID and Detail are those of input_image parts. FileID,
+	// FileData and Filename are those of input_file parts.
+	ImageURL string         `json:"image_url,omitempty"`
+	FileID   string         `json:"file_id,omitempty"`
+	Detail   ImageURLDetail `json:"detail,omitempty"`
+	FileData string         `json:"file_data,omitempty"`
+	Filename string         `json:"filename,omitempty"`
+}
+
+type ResponseReasoningSummary struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// NewResponseInputMessage returns a message item with the given role, "user",
+// "assistant", "system" or "developer", and content parts.
+func NewResponseInputMessage(role string, content ...ResponseContent) ResponseItem {
+	return ResponseItem{Type: ResponseItemTypeMessage, Role: role, Content: content}
+}
+
+// NewResponseInputText returns an input_text content part.
+func NewResponseInputText(text string) ResponseContent {
+	return ResponseContent{Type: ResponseContentTypeInputText, Text: text}
+}
+
+// NewResponseInputImageURL returns an input_image content part for the image at
+// url, which can be a data URL.
+func NewResponseInputImageURL(url string, detail ImageURLDetail) ResponseContent {
+	return ResponseContent{Type: ResponseContentTypeInputImage, ImageURL: url, Detail: detail}
+}
+
+// NewResponseInputFile returns an input_file content part for an uploaded file.
+func NewResponseInputFile(fileID string) ResponseContent {
+	return ResponseContent{Type: ResponseContentTypeInputFile, FileID: fileID}
+}
+
+// NewResponseFunctionCallOutput returns the output of the function call with the
+// given call ID, to send back as input.
+func NewResponseFunctionCallOutput(callID, output string) ResponseItem {
+	return ResponseItem{Type: ResponseItemTypeFunctionCallOutput, CallID: callID, Output: output}
+}
+
+// ResponseToolType is the type of a tool of a response.
+type ResponseToolType string
+
+const (
+	ResponseToolTypeFunction   ResponseToolType = "function"
+	ResponseToolTypeWebSearch  ResponseToolType = "web_search_preview"
+	ResponseToolTypeFileSearch ResponseToolType = "file_search"
+	ResponseToolTypeComputer   ResponseToolType = "computer_use_preview"
+	ResponseToolTypeMCP        ResponseToolType = "mcp"
+)
+
+// ResponseTool is a tool the model may call. The fields matching its Type are
+// set.
+type ResponseTool struct {
+	Type ResponseToolType `json:"type"`
+
+	// Name, Description, Parameters and Strict are those of function tools.
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON schema of the arguments, such as a
+	// jsonschema.Definition.
+	Parameters any  `json:"parameters,omitempty"`
+	Strict     bool `json:"strict,omitempty"`
+
+	// SearchContextSize and UserLocation are those of web_search_preview tools.
+	// SearchContextSize is "low", "medium" or "high".
+	SearchContextSize string                `json:"search_context_size,omitempty"`
+	UserLocation      *ResponseUserLocation `json:"user_location,omitempty"`
+
+	// VectorStoreIDs, MaxNumResults, Filters and RankingOptions are those of
+	// file_search tools.
+	VectorStoreIDs []string                         `json:"vector_store_ids,omitempty"`
+	MaxNumResults  int                              `json:"max_num_results,omitempty"`
+	Filters        VectorStoreFilter                `json:"filters,omitempty"`
+	RankingOptions *VectorStoreSearchRankingOptions `json:"ranking_options,omitempty"`
+
+	// DisplayWidth, DisplayHeight and Environment are those of
+	// computer_use_preview tools. Environment is "browser", "mac", "windows" or
+	// "ubuntu".
+	DisplayWidth  int    `json:"display_width,omitempty"`
+	DisplayHeight int    `json:"display_height,omitempty"`
+	Environment   string `json:"environment,omitempty"`
+
+	// ServerLabel, ServerURL, Headers, AllowedTools and RequireApproval are those
+	// of mcp tools. Headers, such as the authorization of the server, are
+	// redacted in debug output. RequireApproval is "always", "never" or a
+	// ResponseMCPApprovalFilter.
+	ServerLabel     string            `json:"server_label,omitempty"`
+	ServerURL       string            `json:"server_url,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	AllowedTools    []string          `json:"allowed_tools,omitempty"`
+	RequireApproval any               `json:"require_approval,omitempty"`
+}
+
+// ResponseReasoning configures the reasoning of reasoning models.
+type ResponseReasoning struct {
+	// Effort is "low", "medium" or "high".
+	Effort string `json:"effort,omitempty"`
+	// Summary is "auto", "concise" or "detailed".
+	Summary string `json:"summary,omitempty"`
+}
+
+// ResponseTextConfig configures the text output of a response.
+type ResponseTextConfig struct {
+	Format *ResponseTextFormat `json:"format,omitempty"`
+}
+
+// ResponseTextFormat is the format of the text output: "text", "json_object",
+// or "json_schema" with a Name and Schema.
+type ResponseTextFormat struct {
+	Type        ChatCompletionResponseFormatType `json:"type"`
+	Name        string                           `json:"name,omitempty"`
+	Description string                           `json:"description,omitempty"`
+	Schema      any                              `json:"schema,omitempty"`
+	Strict      bool                             `json:"strict,omitempty"`
+}
+
+// ResponseRequest is a request to create a response.
+type ResponseRequest struct {
+	Model string `json:"model"`
+	// Input is a string, or a []ResponseItem.
+	Input        any            `json:"input"`
+	Instructions string         `json:"instructions,omitempty"`
+	Tools        []ResponseTool `json:"tools,omitempty"`
+	// ToolChoice is a ToolChoiceMode, or an object choosing a tool.
+	ToolChoice        any                 `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+	Temperature       *float32            `json:"temperature,omitempty"`
+	TopP              *float32            `json:"top_p,omitempty"`
+	MaxOutputTokens   int                 `json:"max_output_tokens,omitempty"`
+	Reasoning         *ResponseReasoning  `json:"reasoning,omitempty"`
+	Text              *ResponseTextConfig `json:"text,omitempty"`
+	Metadata          map[string]string   `json:"metadata,omitempty"`
+	// Store sets whether the response is stored, which it is by default. Stored
+	// responses can be retrieved and continued with PreviousResponseID.
+	Store *bool `json:"store,omitempty"`
+	// PreviousResponseID continues the conversation of a stored response, whose
+	// input and output need not be sent again.
+	PreviousResponseID string `json:"previous_response_id,omitempty"`
+	User               string `json:"user,omitempty"`
+	// Truncation is "auto" to drop the oldest items of a conversation that
+	// overflows the context window, which computer use requires, or "disabled".
+	Truncation string `json:"truncation,omitempty"`
+	// Stream is set by CreateResponseStream.
+	Stream bool `json:"stream,omitempty"`
+	// Background runs the response asynchronously: it is returned queued, to be
+	// polled with WaitForResponse, or streamed with ResumeResponseStream, and can
+	// be cancelled with CancelResponse. Background responses must be stored.
+	Background bool `json:"background,omitempty"`
+}
+
+func (r ResponseRequest) validate() error {
+	switch r.Input.(type) {
+	case string, []ResponseItem:
+		return nil
+	default:
+		return fmt.Errorf("%w, got %T", ErrResponseInvalidInput, r.Input)
+	}
+}
+
+// ResponseStatus is the status of a response.
+type ResponseStatus string
+
+const (
+	ResponseStatusQueued     ResponseStatus = "queued"
+	ResponseStatusInProgress ResponseStatus = "in_progress"
+	ResponseStatusCompleted  ResponseStatus = "completed"
+	ResponseStatusIncomplete ResponseStatus = "incomplete"
+	ResponseStatusFailed     ResponseStatus = "failed"
+	ResponseStatusCancelled  ResponseStatus = "cancelled"
+)
+
+// Done reports whether a response with status s has stopped for good: it is
+// completed, failed, cancelled or incomplete.
+func (s ResponseStatus) Done() bool {
+	switch s {
+	case ResponseStatusCompleted, ResponseStatusFailed, ResponseStatusCancelled, ResponseStatusIncomplete:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResponseUsage is the token usage of a response.
+// The details are nil when the API does not report them.
+type ResponseUsage struct {
+	InputTokens         int                          `json:"input_tokens"`
+	InputTokensDetails  *ResponseInputTokensDetails  `json:"input_tokens_details,omitempty"`
+	OutputTokens        int                          `json:"output_tokens"`
+	OutputTokensDetails *ResponseOutputTokensDetails `json:"output_tokens_details,omitempty"`
+	TotalTokens         int                          `json:"total_tokens"`
+}
+
+type ResponseInputTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+type ResponseOutputTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// ResponseError is the error of a failed response.
+type ResponseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ResponseIncompleteDetails tells why a response is incomplete, such as
+// "max_output_tokens" or "content_filter".
+type ResponseIncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+// ModelResponse is a response of the model, created with CreateResponse. It is
+// named so as not to clash with the Response interface of the client.
+type ModelResponse struct {
+	ID                 string                     `json:"id"`
+	Object             string                     `json:"object"`
+	CreatedAt          int64                      `json:"created_at"`
+	Status             ResponseStatus             `json:"status"`
+	Model              string                     `json:"model"`
+	Output             []ResponseItem             `json:"output"`
+	Usage              *ResponseUsage             `json:"usage,omitempty"`
+	Error              *ResponseError             `json:"error,omitempty"`
+	IncompleteDetails  *ResponseIncompleteDetails `json:"incomplete_details,omitempty"`
+	Instructions       any                        `json:"instructions,omitempty"`
+	Tools              []ResponseTool             `json:"tools,omitempty"`
+	ToolChoice         any                        `json:"tool_choice,omitempty"`
+	ParallelToolCalls  bool                       `json:"parallel_tool_calls"`
+	Temperature        *float32                   `json:"temperature,omitempty"`
+	TopP               *float32                   `json:"top_p,omitempty"`
+	MaxOutputTokens    *int                       `json:"max_output_tokens,omitempty"`
+	PreviousResponseID *string                    `json:"previous_response_id,omitempty"`
+	Reasoning          *ResponseReasoning         `json:"reasoning,omitempty"`
+	Text               *ResponseTextConfig        `json:"text,omitempty"`
+	Metadata           map[string]string          `json:"metadata,omitempty"`
+	Store              bool                       `json:"store"`
+	User               string                     `json:"user,omitempty"`
+	// Background is set on responses created in background mode.
+	Background bool `json:"background,omitempty"`
+
+	httpHeader
+}
+
+// OutputText returns the text of the output_text parts of the message items of
+// the output, concatenated.
+func (r ModelResponse) OutputText() string {
+	var text strings.Builder
+	for _, item := range r.Output {
+		if item.Type != ResponseItemTypeMessage {
+			continue
+		}
+		for _, content := range item.Content {
+			if content.Type == ResponseContentTypeOutputText {
+				text.WriteString(content.Text)
+			}
+		}
+	}
+	return text.String()
+}
+
+// FunctionCalls returns the function call items of the output.
+func (r ModelResponse) FunctionCalls() []ResponseItem {
+	var calls []ResponseItem
+	for _, item := range r.Output {
+		if item.Type == ResponseItemTypeFunctionCall {
+			calls = append(calls, item)
+		}
+	}
+	return calls
+}
+
+// ResponseDeleteResponse is the status of a deleted response.
+type ResponseDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+
+	httpHeader
+}
+
+// ResponseItemList is a list of the input items of a response.
+type ResponseItemList struct {
+	Object  string         `json:"object"`
+	Data    []ResponseItem `json:"data"`
+	FirstID string         `json:"first_id"`
+	LastID  string         `json:"last_id"`
+	HasMore bool           `json:"has_more"`
+
+	httpHeader
+}
+
+// CreateResponse creates a model response.
+func (c *Client) CreateResponse(ctx context.Context, request ResponseRequest) (response ModelResponse, err error) {
+	if err = request.validate(); err != nil {
+		return
+	}
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(responsesSuffix, withModel(request.Model)),
+		withBody(request),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// GetResponse retrieves a stored response.
+func (c *Client) GetResponse(ctx context.Context, responseID string) (response ModelResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(responsesSuffix+"/"+responseID))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// DeleteResponse deletes a stored response.
+func (c *Client) DeleteResponse(ctx context.Context, responseID string) (response ResponseDeleteResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(responsesSuffix+"/"+responseID))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CancelResponse cancels a background response.
+func (c *Client) CancelResponse(ctx context.Context, responseID string) (response ModelResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(responsesSuffix+"/"+responseID+"/cancel"))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// WaitForResponse polls a background response until it is done, and returns
+// its last state. A failed response is returned with an error wrapping
+// ErrResponseFailed; cancelled and incomplete responses are returned without
+// error.
+func (c *Client) WaitForResponse(
+	ctx context.Context,
+	responseID string,
+	opts PollOptions,
+) (response ModelResponse, err error) {
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.GetResponse(ctx, responseID)
+		if err != nil {
+			return false, err
+		}
+		response = polled
+		if response.Status == ResponseStatusFailed {
+			if response.Error == nil {
+				return true, fmt.Errorf("%w: no reason given", ErrResponseFailed)
+			}
+			return true, fmt.Errorf("%w: %s (code: %s)", ErrResponseFailed, response.Error.Message, response.Error.Code)
+		}
+		return response.Status.Done(), nil
+	})
+	return
+}
+
+// ListResponseInputItems lists a page of the input items of a stored response.
+func (c *Client) ListResponseInputItems(
+	ctx context.Context,
+	responseID string,
+	pagination Pagination,
+) (response ResponseItemList, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/input_items%s", responsesSuffix, responseID, encodeQuery(paginationValues(pagination)))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
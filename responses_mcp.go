@@ -0,0 +1,67 @@
+package openai
+
+// ResponseMCPTool is a tool of a remote MCP server, listed by an mcp_list_tools
+// item.
+type ResponseMCPTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// InputSchema is the JSON schema of the arguments of the tool.
+	InputSchema any `json:"input_schema,omitempty"`
+	Annotations any `json:"annotations,omitempty"`
+}
+
+// ResponseMCPApprovalFilter requires approval for the calls of some tools of an
+// MCP server only: those listed in Always, and not those listed in Never.
+type ResponseMCPApprovalFilter struct {
+	Always *ResponseMCPToolNames `json:"always,omitempty"`
+	Never  *ResponseMCPToolNames `json:"never,omitempty"`
+}
+
+type ResponseMCPToolNames struct {
+	ToolNames []string `json:"tool_names"`
+}
+
+// NewResponseMCPTool returns an mcp tool calling the MCP server at serverURL,
+// with the given headers, such as its authorization, and approval requirement:
+// "always", "never" or a ResponseMCPApprovalFilter.
+func NewResponseMCPTool(
+	serverLabel string,
+	serverURL string,
+	headers map[string]string,
+	requireApproval any,
+) ResponseTool {
+	return ResponseTool{
+		Type:            ResponseToolTypeMCP,
+		ServerLabel:     serverLabel,
+		ServerURL:       serverURL,
+		Headers:         headers,
+		RequireApproval: requireApproval,
+	}
+}
+
+// MCPApprovalRequests returns the mcp_approval_request items of the output: the
+// MCP tool calls waiting for approval, with the ServerLabel of their server and
+// the Name and Arguments of their tool. Answer them with
+// NewResponseMCPApprovalResponse items in the input of a request continuing the
+// response with PreviousResponseID.
+func (r ModelResponse) MCPApprovalRequests() []ResponseItem {
+	var requests []ResponseItem
+	for _, item := range r.Output {
+		if item.Type == ResponseItemTypeMCPApprovalRequest {
+			requests = append(requests, item)
+		}
+	}
+	return requests
+}
+
+// NewResponseMCPApprovalResponse returns the answer to the mcp_approval_request
+// item with the given ID, approving or denying the call, for reason if not
+// empty.
+func NewResponseMCPApprovalResponse(approvalRequestID string, approve bool, reason string) ResponseItem {
+	return ResponseItem{
+		Type:              ResponseItemTypeMCPApprovalResponse,
+		ApprovalRequestID: approvalRequestID,
+		Approve:           &approve,
+		Reason:            reason,
+	}
+}
@@ -0,0 +1,82 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestResponseMCPApprovals(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var requests []map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		if len(requests) == 1 {
+			fmt.Fprint(w, `{"id":"resp_1","status":"completed","output":[`+
+				`{"type":"mcp_list_tools","id":"mcpl_1","server_label":"dice","tools":[{"name":"roll",`+
+				`"input_schema":{"type":"object","properties":{"sides":{"type":"integer"}}}}]},`+
+				`{"type":"mcp_approval_request","id":"mcpr_1","server_label":"dice","name":"roll",`+
+				`"arguments":"{\"sides\":6}"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"resp_2","status":"completed","output":[`+
+			`{"type":"mcp_call","id":"mcp_1","approval_request_id":"mcpr_1","server_label":"dice","name":"roll",`+
+			`"arguments":"{\"sides\":6}","output":"4"},`+
+			`{"type":"message","role":"assistant","content":[{"type":"output_text","text":"You rolled 4."}]}]}`)
+	})
+	ctx := context.Background()
+
+	request := openai.ResponseRequest{
+		Model: openai.GPT4Dot1,
+		Input: "Roll a die.",
+		Tools: []openai.ResponseTool{
+			openai.NewResponseMCPTool("dice", "https://dice.example.com/mcp", nil,
+				openai.ResponseMCPApprovalFilter{Never: &openai.ResponseMCPToolNames{ToolNames: []string{"list"}}}),
+		},
+	}
+	response, err := client.CreateResponse(ctx, request)
+	checks.NoError(t, err, "CreateResponse error")
+
+	tools, _ := requests[0]["tools"].([]any)
+	tool, _ := tools[0].(map[string]any)
+	approval, _ := tool["require_approval"].(map[string]any)
+	if tool["type"] != "mcp" || tool["server_label"] != "dice" || approval["never"] == nil || tool["headers"] != nil {
+		t.Errorf("unexpected tool %v", tool)
+	}
+	if listed := response.Output[0]; listed.Type != openai.ResponseItemTypeMCPListTools ||
+		len(listed.Tools) != 1 || listed.Tools[0].Name != "roll" {
+		t.Errorf("unexpected tools listed %+v", listed)
+	}
+
+	pending := response.MCPApprovalRequests()
+	if len(pending) != 1 || pending[0].ServerLabel != "dice" || pending[0].Name != "roll" ||
+		pending[0].Arguments != `{"sides":6}` {
+		t.Fatalf("unexpected approval requests %+v", pending)
+	}
+	request.PreviousResponseID = response.ID
+	request.Input = []openai.ResponseItem{openai.NewResponseMCPApprovalResponse(pending[0].ID, false, "")}
+	_, err = client.CreateResponse(ctx, request)
+	checks.NoError(t, err, "CreateResponse error")
+	input, _ := requests[1]["input"].([]any)
+	reply, _ := input[0].(map[string]any)
+	if requests[1]["previous_response_id"] != "resp_1" || reply["type"] != "mcp_approval_response" ||
+		reply["approval_request_id"] != "mcpr_1" || reply["approve"] != false {
+		t.Errorf("unexpected request %v", requests[1])
+	}
+
+	request.Input = []openai.ResponseItem{openai.NewResponseMCPApprovalResponse(pending[0].ID, true, "")}
+	response, err = client.CreateResponse(ctx, request)
+	checks.NoError(t, err, "CreateResponse error")
+	if call := response.Output[0]; call.Type != openai.ResponseItemTypeMCPCall || call.Output != "4" ||
+		call.ApprovalRequestID != "mcpr_1" || response.OutputText() != "You rolled 4." {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
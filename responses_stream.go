@@ -0,0 +1,359 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ResponseStreamEventType is the type of an event of a ResponseStream.
+type ResponseStreamEventType string
+
+const (
+	ResponseStreamEventCreated                    ResponseStreamEventType = "response.created"
+	ResponseStreamEventQueued                     ResponseStreamEventType = "response.queued"
+	ResponseStreamEventInProgress                 ResponseStreamEventType = "response.in_progress"
+	ResponseStreamEventCompleted                  ResponseStreamEventType = "response.completed"
+	ResponseStreamEventIncomplete                 ResponseStreamEventType = "response.incomplete"
+	ResponseStreamEventFailed                     ResponseStreamEventType = "response.failed"
+	ResponseStreamEventOutputItemAdded            ResponseStreamEventType = "response.output_item.added"
+	ResponseStreamEventOutputItemDone             ResponseStreamEventType = "response.output_item.done"
+	ResponseStreamEventContentPartAdded           ResponseStreamEventType = "response.content_part.added"
+	ResponseStreamEventContentPartDone            ResponseStreamEventType = "response.content_part.done"
+	ResponseStreamEventOutputTextDelta            ResponseStreamEventType = "response.output_text.delta"
+	ResponseStreamEventOutputTextDone             ResponseStreamEventType = "response.output_text.done"
+	ResponseStreamEventRefusalDelta               ResponseStreamEventType = "response.refusal.delta"
+	ResponseStreamEventRefusalDone                ResponseStreamEventType = "response.refusal.done"
+	ResponseStreamEventFunctionCallArgumentsDelta ResponseStreamEventType = "response.function_call_arguments.delta"
+	ResponseStreamEventFunctionCallArgumentsDone  ResponseStreamEventType = "response.function_call_arguments.done"
+	ResponseStreamEventReasoningSummaryPartAdded  ResponseStreamEventType = "response.reasoning_summary_part.added"
+	ResponseStreamEventReasoningSummaryPartDone   ResponseStreamEventType = "response.reasoning_summary_part.done"
+	ResponseStreamEventReasoningSummaryTextDelta  ResponseStreamEventType = "response.reasoning_summary_text.delta"
+	ResponseStreamEventReasoningSummaryTextDone   ResponseStreamEventType = "response.reasoning_summary_text.done"
+	ResponseStreamEventError                      ResponseStreamEventType = "error"
+)
+
+// Terminal reports whether the event ends the stream.
+func (t ResponseStreamEventType) Terminal() bool {
+	switch t {
+	case ResponseStreamEventCompleted, ResponseStreamEventIncomplete, ResponseStreamEventFailed:
+		return true
+	}
+	return false
+}
+
+func (t ResponseStreamEventType) known() bool {
+	switch t {
+	case ResponseStreamEventCreated, ResponseStreamEventQueued, ResponseStreamEventInProgress,
+		ResponseStreamEventCompleted, ResponseStreamEventIncomplete, ResponseStreamEventFailed,
+		ResponseStreamEventOutputItemAdded, ResponseStreamEventOutputItemDone,
+		ResponseStreamEventContentPartAdded, ResponseStreamEventContentPartDone,
+		ResponseStreamEventOutputTextDelta, ResponseStreamEventOutputTextDone,
+		ResponseStreamEventRefusalDelta, ResponseStreamEventRefusalDone,
+		ResponseStreamEventFunctionCallArgumentsDelta, ResponseStreamEventFunctionCallArgumentsDone,
+		ResponseStreamEventReasoningSummaryPartAdded, ResponseStreamEventReasoningSummaryPartDone,
+		ResponseStreamEventReasoningSummaryTextDelta, ResponseStreamEventReasoningSummaryTextDone:
+		return true
+	}
+	return false
+}
+
+// ResponseStreamEvent is an event of a ResponseStream. The fields set depend on
+// its Type: Response for the response.* lifecycle events, Item for output
+// items, Part for content and summary parts, and Delta, Text, Refusal or
+// Arguments for the fragments of the item at OutputIndex. Events of unknown
+// types, and events received out of order, only have Type, SequenceNumber and
+// Raw.
+type ResponseStreamEvent struct {
+	Type           ResponseStreamEventType `json:"type"`
+	SequenceNumber int                     `json:"sequence_number"`
+	Response       *ModelResponse          `json:"response,omitempty"`
+	OutputIndex    int                     `json:"output_index"`
+	ItemID         string                  `json:"item_id,omitempty"`
+	ContentIndex   int                     `json:"content_index"`
+	SummaryIndex   int                     `json:"summary_index"`
+	Item           *ResponseItem           `json:"item,omitempty"`
+	Part           *ResponseContent        `json:"part,omitempty"`
+	Delta          string                  `json:"delta,omitempty"`
+	Text           string                  `json:"text,omitempty"`
+	Refusal        string                  `json:"refusal,omitempty"`
+	Arguments      string                  `json:"arguments,omitempty"`
+
+	Raw *ResponseStreamRawEvent `json:"-"`
+}
+
+// ResponseStreamRawEvent is the data of an event that could not be typed.
+type ResponseStreamRawEvent struct {
+	Data json.RawMessage
+	// OutOfOrder is set when the event is of a known type but its sequence
+	// number is not greater than that of the previous event.
+	OutOfOrder bool
+}
+
+// ResponseStream is the stream of events of a response created with
+// CreateResponseStream. Recv returns the events in order, then io.EOF after the
+// response.completed, response.incomplete or response.failed event, whether or
+// not the server sends [DONE]. An error event is returned as an *APIError and
+// ends the stream.
+type ResponseStream struct {
+	*streamReader[ResponseStreamEvent]
+
+	sequenced    bool
+	lastSequence int
+}
+
+// Recv returns the next event of the stream.
+func (s *ResponseStream) Recv() (ResponseStreamEvent, error) {
+	return s.RecvContext(context.Background())
+}
+
+// RecvContext is Recv returning early with the error of ctx when it is done
+// before the next event arrives, leaving the stream as is.
+func (s *ResponseStream) RecvContext(ctx context.Context) (ResponseStreamEvent, error) {
+	if s.streamReader.events != nil {
+		return ResponseStreamEvent{}, ErrStreamEventsInUse
+	}
+	return s.recvContext(ctx)
+}
+
+// Events is the Events method of other streams, for the events of this stream.
+func (s *ResponseStream) Events() <-chan ResponseStreamEvent {
+	return s.startEvents(s.recvContext)
+}
+
+func (s *ResponseStream) recvContext(ctx context.Context) (ResponseStreamEvent, error) {
+	data, err := s.recvRawContext(ctx)
+	if err != nil {
+		return ResponseStreamEvent{}, err
+	}
+
+	var event ResponseStreamEvent
+	if err = json.Unmarshal(data, &event); err != nil {
+		if s.rawEvents {
+			return event, &RawStreamEvent{Event: s.event, Data: data, Err: err}
+		}
+		return event, fmt.Errorf("invalid %s event: %w", s.event, err)
+	}
+	if s.event != "" {
+		// The event: field names the event, the type of the data only repeats it.
+		event.Type = ResponseStreamEventType(s.event)
+	}
+	if event.Type == ResponseStreamEventError {
+		s.isFinished = true
+		return event, decodeResponseStreamError(data)
+	}
+
+	outOfOrder := s.sequenced && event.SequenceNumber <= s.lastSequence
+	if !outOfOrder {
+		s.sequenced, s.lastSequence = true, event.SequenceNumber
+	}
+	if outOfOrder || !event.Type.known() {
+		return ResponseStreamEvent{
+			Type:           event.Type,
+			SequenceNumber: event.SequenceNumber,
+			Raw:            &ResponseStreamRawEvent{Data: data, OutOfOrder: outOfOrder},
+		}, nil
+	}
+	if event.Type.Terminal() {
+		s.isFinished = true
+	}
+	return event, nil
+}
+
+func decodeResponseStreamError(data []byte) error {
+	var errRes ErrorResponse
+	if err := json.Unmarshal(data, &errRes); err == nil && errRes.Error != nil {
+		return errRes.Error
+	}
+	apiErr := &APIError{}
+	if err := json.Unmarshal(data, apiErr); err != nil {
+		return fmt.Errorf("invalid error event %s: %w", data, err)
+	}
+	return apiErr
+}
+
+// CreateResponseStream creates a model response and streams its events. The
+// stream of a background response can be resumed with ResumeResponseStream
+// if the connection drops.
+func (c *Client) CreateResponseStream(ctx context.Context, request ResponseRequest) (*ResponseStream, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(responsesSuffix, withModel(request.Model)),
+		withBody(request),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[ResponseStreamEvent](c, req)
+	if err != nil {
+		return nil, err
+	}
+	return &ResponseStream{streamReader: resp}, nil
+}
+
+// ResumeResponseStream streams the events of a background response created
+// with Stream set, from the event following the one with sequence number
+// startingAfter, such as the LastSequenceNumber of a stream that dropped.
+func (c *Client) ResumeResponseStream(
+	ctx context.Context,
+	responseID string,
+	startingAfter int,
+) (*ResponseStream, error) {
+	query := url.Values{}
+	query.Set("stream", "true")
+	query.Set("starting_after", strconv.Itoa(startingAfter))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(responsesSuffix+"/"+responseID+encodeQuery(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[ResponseStreamEvent](c, req)
+	if err != nil {
+		return nil, err
+	}
+	return &ResponseStream{streamReader: resp, sequenced: true, lastSequence: startingAfter}, nil
+}
+
+// LastSequenceNumber returns the sequence number of the last event received in
+// order, or -1 before the first one.
+func (s *ResponseStream) LastSequenceNumber() int {
+	if !s.sequenced {
+		return -1
+	}
+	return s.lastSequence
+}
+
+// ResponseStreamAccumulator folds the events of a ResponseStream into the
+// response they describe, which once the stream ends is the response
+// CreateResponse would have returned. Feed it every event received with Add.
+// The zero value is ready to use.
+//
+//	var acc openai.ResponseStreamAccumulator
+//	for {
+//		event, err := stream.Recv()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//		acc.Add(event)
+//	}
+//	response := acc.Response()
+type ResponseStreamAccumulator struct {
+	response *ModelResponse
+	output   []ResponseItem
+}
+
+// Add folds event into the accumulated response. Raw events are ignored.
+func (a *ResponseStreamAccumulator) Add(event ResponseStreamEvent) {
+	if event.Raw != nil {
+		return
+	}
+	if event.Response != nil {
+		response := *event.Response
+		if len(response.Output) == 0 && len(a.output) > 0 {
+			// Lifecycle events sent before the end have no output yet.
+			response.Output = a.output
+		}
+		a.response = &response
+		a.output = response.Output
+		return
+	}
+
+	switch event.Type {
+	case ResponseStreamEventOutputItemAdded, ResponseStreamEventOutputItemDone:
+		if event.Item != nil && event.OutputIndex >= 0 {
+			for len(a.output) <= event.OutputIndex {
+				a.output = append(a.output, ResponseItem{})
+			}
+			a.output[event.OutputIndex] = *event.Item
+		}
+	case ResponseStreamEventContentPartAdded, ResponseStreamEventContentPartDone:
+		if part := a.content(event); part != nil && event.Part != nil {
+			*part = *event.Part
+		}
+	case ResponseStreamEventOutputTextDelta:
+		if part := a.content(event); part != nil {
+			part.Text += event.Delta
+		}
+	case ResponseStreamEventOutputTextDone:
+		if part := a.content(event); part != nil {
+			part.Text = event.Text
+		}
+	case ResponseStreamEventRefusalDelta:
+		if part := a.content(event); part != nil {
+			part.Refusal += event.Delta
+		}
+	case ResponseStreamEventRefusalDone:
+		if part := a.content(event); part != nil {
+			part.Refusal = event.Refusal
+		}
+	case ResponseStreamEventFunctionCallArgumentsDelta:
+		if item := a.item(event); item != nil {
+			item.Arguments += event.Delta
+		}
+	case ResponseStreamEventFunctionCallArgumentsDone:
+		if item := a.item(event); item != nil {
+			item.Arguments = event.Arguments
+		}
+	case ResponseStreamEventReasoningSummaryPartAdded, ResponseStreamEventReasoningSummaryPartDone:
+		if summary := a.summary(event); summary != nil && event.Part != nil {
+			*summary = ResponseReasoningSummary{Type: string(event.Part.Type), Text: event.Part.Text}
+		}
+	case ResponseStreamEventReasoningSummaryTextDelta:
+		if summary := a.summary(event); summary != nil {
+			summary.Text += event.Delta
+		}
+	case ResponseStreamEventReasoningSummaryTextDone:
+		if summary := a.summary(event); summary != nil {
+			summary.Text = event.Text
+		}
+	}
+	if a.response != nil {
+		a.response.Output = a.output
+	}
+}
+
+// item returns the output item of event, or nil if it was not added.
+func (a *ResponseStreamAccumulator) item(event ResponseStreamEvent) *ResponseItem {
+	if event.OutputIndex < 0 || event.OutputIndex >= len(a.output) {
+		return nil
+	}
+	return &a.output[event.OutputIndex]
+}
+
+func (a *ResponseStreamAccumulator) content(event ResponseStreamEvent) *ResponseContent {
+	item := a.item(event)
+	if item == nil || event.ContentIndex < 0 {
+		return nil
+	}
+	for len(item.Content) <= event.ContentIndex {
+		item.Content = append(item.Content, ResponseContent{})
+	}
+	return &item.Content[event.ContentIndex]
+}
+
+func (a *ResponseStreamAccumulator) summary(event ResponseStreamEvent) *ResponseReasoningSummary {
+	item := a.item(event)
+	if item == nil || event.SummaryIndex < 0 {
+		return nil
+	}
+	for len(item.Summary) <= event.SummaryIndex {
+		item.Summary = append(item.Summary, ResponseReasoningSummary{})
+	}
+	return &item.Summary[event.SummaryIndex]
+}
+
+// Response returns the response accumulated so far, or nil before the
+// response.created event.
+func (a *ResponseStreamAccumulator) Response() *ModelResponse {
+	return a.response
+}
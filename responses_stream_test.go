@@ -0,0 +1,202 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCreateResponseStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		// The stream ends with response.completed, without [DONE].
+		writeAssistantStreamEvents(w,
+			"response.created", `{"type":"response.created","sequence_number":0,`+
+				`"response":{"id":"resp_1","object":"response","status":"in_progress","model":"gpt-4.1","output":[]}}`,
+			"response.output_item.added", `{"type":"response.output_item.added","sequence_number":1,"output_index":0,`+
+				`"item":{"type":"message","id":"msg_1","status":"in_progress","role":"assistant","content":[]}}`,
+			"response.content_part.added", `{"type":"response.content_part.added","sequence_number":2,`+
+				`"item_id":"msg_1","output_index":0,"content_index":0,"part":{"type":"output_text","text":""}}`,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":3,`+
+				`"item_id":"msg_1","output_index":0,"content_index":0,"delta":"Hello"}`,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":2,`+
+				`"item_id":"msg_1","output_index":0,"content_index":0,"delta":"stale"}`,
+			"response.audio.delta", `{"type":"response.audio.delta","sequence_number":4,"delta":"AAAA"}`,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":5,`+
+				`"item_id":"msg_1","output_index":0,"content_index":0,"delta":" there!"}`,
+			"response.output_item.added", `{"type":"response.output_item.added","sequence_number":6,"output_index":1,`+
+				`"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":""}}`,
+			"response.function_call_arguments.delta", `{"type":"response.function_call_arguments.delta",`+
+				`"sequence_number":7,"item_id":"fc_1","output_index":1,"delta":"{\"city\":"}`,
+			"response.function_call_arguments.delta", `{"type":"response.function_call_arguments.delta",`+
+				`"sequence_number":8,"item_id":"fc_1","output_index":1,"delta":"\"Paris\"}"}`,
+			"response.completed", `{"type":"response.completed","sequence_number":9,"response":{"id":"resp_1",`+
+				`"object":"response","status":"completed","model":"gpt-4.1","output":[],`+
+				`"usage":{"input_tokens":5,"output_tokens":7,"total_tokens":12}}}`,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":10,`+
+				`"item_id":"msg_1","output_index":0,"content_index":0,"delta":"ignored"}`,
+		)
+	})
+
+	stream, err := client.CreateResponseStream(context.Background(), openai.ResponseRequest{
+		Model: openai.GPT4Dot1,
+		Input: "Hi",
+	})
+	checks.NoError(t, err, "CreateResponseStream error")
+	defer stream.Close()
+
+	var (
+		acc   openai.ResponseStreamAccumulator
+		types []openai.ResponseStreamEventType
+		raw   []*openai.ResponseStreamRawEvent
+	)
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		types = append(types, event.Type)
+		if event.Raw != nil {
+			raw = append(raw, event.Raw)
+		}
+		acc.Add(event)
+	}
+
+	if request["stream"] != true {
+		t.Errorf("the request should be streamed: %v", request)
+	}
+	if len(types) != 11 || types[10] != openai.ResponseStreamEventCompleted {
+		t.Errorf("the stream should end at response.completed, got %v", types)
+	}
+	if len(raw) != 2 || !raw[0].OutOfOrder || raw[1].OutOfOrder || types[5] != "response.audio.delta" {
+		t.Errorf("unexpected raw events %+v", raw)
+	}
+
+	response := acc.Response()
+	expected := []openai.ResponseItem{
+		{Type: openai.ResponseItemTypeMessage, ID: "msg_1", Status: "in_progress", Role: "assistant",
+			Content: []openai.ResponseContent{{Type: openai.ResponseContentTypeOutputText, Text: "Hello there!"}}},
+		{Type: openai.ResponseItemTypeFunctionCall, ID: "fc_1", CallID: "call_1", Name: "get_weather",
+			Arguments: `{"city":"Paris"}`},
+	}
+	if response == nil || response.Status != openai.ResponseStatusCompleted || response.Usage.TotalTokens != 12 ||
+		!reflect.DeepEqual(response.Output, expected) {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if response.OutputText() != "Hello there!" {
+		t.Errorf("unexpected output text %q", response.OutputText())
+	}
+}
+
+func TestCreateResponseStreamError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, _ *http.Request) {
+		writeAssistantStreamEvents(w,
+			"response.created", `{"type":"response.created","sequence_number":0,"response":{"id":"resp_1"}}`,
+			"error", `{"type":"error","sequence_number":1,"code":"server_error","message":"Boom","param":null}`,
+		)
+	})
+
+	stream, err := client.CreateResponseStream(context.Background(), openai.ResponseRequest{Input: "Hi"})
+	checks.NoError(t, err, "CreateResponseStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	_, err = stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Boom" || apiErr.Code != "server_error" {
+		t.Fatalf("expected the error event as an APIError, got %v", err)
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "the stream should end after an error event")
+
+	_, err = client.CreateResponseStream(context.Background(), openai.ResponseRequest{Input: 1})
+	checks.ErrorIs(t, err, openai.ErrResponseInvalidInput, "invalid inputs should fail")
+}
+
+func TestCreateResponseStreamRawEvents(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, _ *http.Request) {
+		writeAssistantStreamEvents(w,
+			"keepalive", "ok",
+			"response.completed", `{"type":"response.completed","sequence_number":0,`+
+				`"response":{"id":"resp_1","object":"response","status":"completed","output":[]}}`,
+		)
+	})
+
+	stream, err := client.WithOptions(openai.WithRawStreamEvents()).CreateResponseStream(
+		context.Background(),
+		openai.ResponseRequest{Model: openai.GPT4Dot1, Input: "Hello!"},
+	)
+	checks.NoError(t, err, "CreateResponseStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	var raw *openai.RawStreamEvent
+	if !errors.As(err, &raw) || raw.Event != "keepalive" || string(raw.Data) != "ok" {
+		t.Fatalf("expected the raw keepalive event, got %v", err)
+	}
+	event, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if event.Type != openai.ResponseStreamEventCompleted {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestResumeResponseStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var query string
+	server.RegisterHandler("/v1/responses/resp_bg", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		query = r.URL.RawQuery
+		writeAssistantStreamEvents(w,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":3,`+
+				`"output_index":0,"content_index":0,"delta":"seen"}`,
+			"response.output_text.delta", `{"type":"response.output_text.delta","sequence_number":4,`+
+				`"output_index":0,"content_index":0,"delta":"new"}`,
+			"response.completed", `{"type":"response.completed","sequence_number":5,`+
+				`"response":{"id":"resp_bg","status":"completed","background":true}}`,
+		)
+	})
+
+	stream, err := client.ResumeResponseStream(context.Background(), "resp_bg", 3)
+	checks.NoError(t, err, "ResumeResponseStream error")
+	defer stream.Close()
+	if stream.LastSequenceNumber() != 3 {
+		t.Errorf("unexpected last sequence number %d", stream.LastSequenceNumber())
+	}
+
+	var events []openai.ResponseStreamEvent
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		events = append(events, event)
+	}
+	if query != "starting_after=3&stream=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(events) != 3 || events[0].Raw == nil || !events[0].Raw.OutOfOrder || events[1].Delta != "new" ||
+		!events[2].Response.Background || stream.LastSequenceNumber() != 5 {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
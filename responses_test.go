@@ -0,0 +1,196 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+const responseJSON = `{"id":"resp_abc123","object":"response","created_at":1741476542,"status":"completed",` +
+	`"model":"gpt-4.1","output":[` +
+	`{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Thinking."}]},` +
+	`{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[` +
+	`{"type":"output_text","text":"Hello","annotations":[]},{"type":"output_text","text":" there!"},` +
+	`{"type":"refusal","refusal":"No."}]},` +
+	`{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}],` +
+	`"usage":{"input_tokens":36,"input_tokens_details":{"cached_tokens":12},"output_tokens":87,` +
+	`"output_tokens_details":{"reasoning_tokens":64},"total_tokens":123},"store":true}`
+
+func TestCreateResponse(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var requests []map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		fmt.Fprint(w, responseJSON)
+	})
+	ctx := context.Background()
+	store := false
+
+	response, err := client.CreateResponse(ctx, openai.ResponseRequest{
+		Model:        openai.GPT4Dot1,
+		Input:        "What is the weather in Paris?",
+		Instructions: "Be brief.",
+		Tools: []openai.ResponseTool{{
+			Type: openai.ResponseToolTypeFunction,
+			Name: "get_weather",
+			Parameters: jsonschema.Definition{
+				Type:       jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{"city": {Type: jsonschema.String}},
+			},
+		}},
+		MaxOutputTokens: 256,
+		Store:           &store,
+	})
+	checks.NoError(t, err, "CreateResponse error")
+
+	if response.Status != openai.ResponseStatusCompleted || response.OutputText() != "Hello there!" ||
+		response.Usage.InputTokensDetails.CachedTokens != 12 || response.Usage.OutputTokensDetails.ReasoningTokens != 64 {
+		t.Errorf("unexpected response %+v", response)
+	}
+	calls := response.FunctionCalls()
+	if len(calls) != 1 || calls[0].CallID != "call_1" || calls[0].Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected function calls %+v", calls)
+	}
+	if response.Output[0].Summary[0].Text != "Thinking." || response.Output[1].Content[2].Refusal != "No." {
+		t.Errorf("unexpected output %+v", response.Output)
+	}
+	tools, _ := requests[0]["tools"].([]any)
+	if requests[0]["input"] != "What is the weather in Paris?" || requests[0]["store"] != false || len(tools) != 1 {
+		t.Errorf("unexpected request %v", requests[0])
+	}
+
+	_, err = client.CreateResponse(ctx, openai.ResponseRequest{
+		Model:              openai.GPT4Dot1,
+		PreviousResponseID: response.ID,
+		Input: []openai.ResponseItem{
+			openai.NewResponseFunctionCallOutput(calls[0].CallID, "sunny"),
+			openai.NewResponseInputMessage("user",
+				openai.NewResponseInputText("And this?"),
+				openai.NewResponseInputImageURL("https://example.com/sky.png", openai.ImageURLDetailLow),
+				openai.NewResponseInputFile("file-abc123")),
+		},
+	})
+	checks.NoError(t, err, "CreateResponse error")
+	input, _ := requests[1]["input"].([]any)
+	if requests[1]["previous_response_id"] != "resp_abc123" || len(input) != 2 {
+		t.Fatalf("unexpected request %v", requests[1])
+	}
+	output, _ := input[0].(map[string]any)
+	message, _ := input[1].(map[string]any)
+	content, _ := message["content"].([]any)
+	if output["type"] != "function_call_output" || output["output"] != "sunny" || len(content) != 3 {
+		t.Errorf("unexpected input %v", input)
+	}
+
+	_, err = client.CreateResponse(ctx, openai.ResponseRequest{Model: openai.GPT4Dot1, Input: 42})
+	checks.ErrorIs(t, err, openai.ErrResponseInvalidInput, "invalid inputs should fail")
+	if len(requests) != 2 {
+		t.Errorf("invalid requests should not be sent")
+	}
+}
+
+func TestStoredResponses(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/responses/resp_abc123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			fmt.Fprint(w, `{"id":"resp_abc123","object":"response.deleted","deleted":true}`)
+			return
+		}
+		fmt.Fprint(w, responseJSON)
+	})
+	var query string
+	server.RegisterHandler("/v1/responses/resp_abc123/input_items", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		fmt.Fprint(w, `{"object":"list","data":[{"type":"message","id":"msg_0","role":"user",`+
+			`"content":[{"type":"input_text","text":"Hi"}]}],"first_id":"msg_0","last_id":"msg_0","has_more":false}`)
+	})
+	ctx := context.Background()
+
+	response, err := client.GetResponse(ctx, "resp_abc123")
+	checks.NoError(t, err, "GetResponse error")
+	if response.ID != "resp_abc123" {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	limit := 10
+	items, err := client.ListResponseInputItems(ctx, "resp_abc123", openai.Pagination{Limit: &limit})
+	checks.NoError(t, err, "ListResponseInputItems error")
+	if query != "limit=10" || len(items.Data) != 1 || items.Data[0].Content[0].Text != "Hi" {
+		t.Errorf("unexpected items %+v for query %q", items, query)
+	}
+
+	deleted, err := client.DeleteResponse(ctx, "resp_abc123")
+	checks.NoError(t, err, "DeleteResponse error")
+	if !deleted.Deleted {
+		t.Errorf("unexpected deletion %+v", deleted)
+	}
+}
+
+func TestBackgroundResponse(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"resp_bg","object":"response","status":"queued","background":true,"output":[]}`)
+	})
+	polls := []string{
+		`"status":"queued"`,
+		`"status":"in_progress"`,
+		`"status":"completed","output":[{"type":"message","role":"assistant",` +
+			`"content":[{"type":"output_text","text":"Report."}]}]`,
+		`"status":"failed","error":{"code":"server_error","message":"Boom"}`,
+	}
+	poll := 0
+	server.RegisterHandler("/v1/responses/resp_bg", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":"resp_bg","background":true,%s}`, polls[poll])
+		poll++
+	})
+	server.RegisterHandler("/v1/responses/resp_bg/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		fmt.Fprint(w, `{"id":"resp_bg","background":true,"status":"cancelled"}`)
+	})
+	ctx := context.Background()
+
+	response, err := client.CreateResponse(ctx, openai.ResponseRequest{
+		Model:      "o3-deep-research",
+		Input:      "Research.",
+		Background: true,
+	})
+	checks.NoError(t, err, "CreateResponse error")
+	if request["background"] != true || response.Status != openai.ResponseStatusQueued || !response.Background {
+		t.Errorf("unexpected response %+v for request %v", response, request)
+	}
+
+	opts := openai.PollOptions{Interval: time.Millisecond}
+	response, err = client.WaitForResponse(ctx, response.ID, opts)
+	checks.NoError(t, err, "WaitForResponse error")
+	if poll != 3 || response.OutputText() != "Report." {
+		t.Errorf("unexpected response %+v after %d polls", response, poll)
+	}
+	_, err = client.WaitForResponse(ctx, response.ID, opts)
+	checks.ErrorIs(t, err, openai.ErrResponseFailed, "failed responses should fail")
+	if err == nil || !strings.Contains(err.Error(), "Boom (code: server_error)") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	response, err = client.CancelResponse(ctx, "resp_bg")
+	checks.NoError(t, err, "CancelResponse error")
+	if response.Status != openai.ResponseStatusCancelled || !response.Status.Done() {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
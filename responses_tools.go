@@ -0,0 +1,275 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrComputerUseMaxTurns is returned by RunComputerUse when the model still
+// calls the computer after the maximum number of turns.
+var ErrComputerUseMaxTurns = errors.New("computer use did not finish within the maximum number of turns")
+
+// ResponseUserLocation is the approximate location of the user, to refine web
+// searches.
+type ResponseUserLocation struct {
+	// Type is "approximate".
+	Type    string `json:"type"`
+	City    string `json:"city,omitempty"`
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	// Timezone is an IANA timezone, such as "Europe/Paris".
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// NewResponseWebSearchTool returns a web_search_preview tool.
+func NewResponseWebSearchTool(searchContextSize string, location *ResponseUserLocation) ResponseTool {
+	return ResponseTool{
+		Type:              ResponseToolTypeWebSearch,
+		SearchContextSize: searchContextSize,
+		UserLocation:      location,
+	}
+}
+
+// NewResponseFileSearchTool returns a file_search tool searching the given
+// vector stores.
+func NewResponseFileSearchTool(vectorStoreIDs ...string) ResponseTool {
+	return ResponseTool{Type: ResponseToolTypeFileSearch, VectorStoreIDs: vectorStoreIDs}
+}
+
+// NewResponseComputerTool returns a computer_use_preview tool for a display of
+// the given size in the given environment.
+func NewResponseComputerTool(displayWidth, displayHeight int, environment string) ResponseTool {
+	return ResponseTool{
+		Type:          ResponseToolTypeComputer,
+		DisplayWidth:  displayWidth,
+		DisplayHeight: displayHeight,
+		Environment:   environment,
+	}
+}
+
+// ResponseFileSearchResult is a chunk of a file found by a file_search_call.
+type ResponseFileSearchResult struct {
+	FileID     string         `json:"file_id"`
+	Filename   string         `json:"filename"`
+	Score      float64        `json:"score"`
+	Text       string         `json:"text"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// ResponseComputerActionType is the type of a ResponseComputerAction.
+type ResponseComputerActionType string
+
+const (
+	ResponseComputerActionClick       ResponseComputerActionType = "click"
+	ResponseComputerActionDoubleClick ResponseComputerActionType = "double_click"
+	ResponseComputerActionDrag        ResponseComputerActionType = "drag"
+	ResponseComputerActionKeypress    ResponseComputerActionType = "keypress"
+	ResponseComputerActionMove        ResponseComputerActionType = "move"
+	ResponseComputerActionScreenshot  ResponseComputerActionType = "screenshot"
+	ResponseComputerActionScroll      ResponseComputerActionType = "scroll"
+	ResponseComputerActionText        ResponseComputerActionType = "type" // Types Text.
+	ResponseComputerActionWait        ResponseComputerActionType = "wait"
+)
+
+// ResponseComputerAction is the action a computer_call asks to perform. The
+// fields matching its Type are set.
+type ResponseComputerAction struct {
+	Type ResponseComputerActionType `json:"type"`
+	// X and Y are the coordinates of click, double_click, move and scroll
+	// actions. Button is that of click actions: "left", "right", "wheel", "back"
+	// or "forward".
+	X      int    `json:"x,omitempty"`
+	Y      int    `json:"y,omitempty"`
+	Button string `json:"button,omitempty"`
+	// Path is that of drag actions.
+	Path []ResponseComputerPoint `json:"path,omitempty"`
+	// Keys are those of keypress actions, such as "CTRL" and "C".
+	Keys []string `json:"keys,omitempty"`
+	// ScrollX and ScrollY are the distances of scroll actions.
+	ScrollX int `json:"scroll_x,omitempty"`
+	ScrollY int `json:"scroll_y,omitempty"`
+	// Text is that of type actions.
+	Text string `json:"text,omitempty"`
+}
+
+type ResponseComputerPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ResponseSafetyCheck is a safety check of a computer_call, which must be
+// acknowledged by the computer_call_output to proceed.
+type ResponseSafetyCheck struct {
+	ID      string `json:"id"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ResponseComputerScreenshot is the screenshot sent as the output of a
+// computer_call, as an image URL, which can be a data URL, or an uploaded file.
+type ResponseComputerScreenshot struct {
+	// Type is "computer_screenshot".
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+}
+
+// NewResponseComputerCallOutput returns the output of the computer call with
+// the given call ID, a screenshot at imageURL taken after performing its action,
+// acknowledging the given safety checks.
+func NewResponseComputerCallOutput(callID, imageURL string, acknowledged ...ResponseSafetyCheck) ResponseItem {
+	return ResponseItem{
+		Type:                     ResponseItemTypeComputerCallOutput,
+		CallID:                   callID,
+		Screenshot:               &ResponseComputerScreenshot{Type: "computer_screenshot", ImageURL: imageURL},
+		AcknowledgedSafetyChecks: acknowledged,
+	}
+}
+
+// ComputerCalls returns the computer_call items of the output.
+func (r ModelResponse) ComputerCalls() []ResponseItem {
+	var calls []ResponseItem
+	for _, item := range r.Output {
+		if item.Type == ResponseItemTypeComputerCall {
+			calls = append(calls, item)
+		}
+	}
+	return calls
+}
+
+// MarshalJSON encodes the Screenshot of computer_call_output items as their
+// output.
+func (item ResponseItem) MarshalJSON() ([]byte, error) {
+	type alias ResponseItem
+	if item.Type != ResponseItemTypeComputerCallOutput {
+		return json.Marshal(alias(item))
+	}
+	return json.Marshal(struct {
+		alias
+		Output *ResponseComputerScreenshot `json:"output,omitempty"`
+	}{alias(item), item.Screenshot})
+}
+
+// UnmarshalJSON decodes the output of computer_call_output items into their
+// Screenshot.
+func (item *ResponseItem) UnmarshalJSON(data []byte) error {
+	type alias ResponseItem
+	var decoded struct {
+		alias
+		Output json.RawMessage `json:"output,omitempty"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*item = ResponseItem(decoded.alias)
+	if len(decoded.Output) == 0 || string(decoded.Output) == "null" {
+		return nil
+	}
+	if item.Type == ResponseItemTypeComputerCallOutput {
+		item.Screenshot = &ResponseComputerScreenshot{}
+		return json.Unmarshal(decoded.Output, item.Screenshot)
+	}
+	return json.Unmarshal(decoded.Output, &item.Output)
+}
+
+// ResponseAnnotationType is the type of a ResponseAnnotation.
+type ResponseAnnotationType string
+
+const (
+	ResponseAnnotationTypeURLCitation  ResponseAnnotationType = "url_citation"
+	ResponseAnnotationTypeFileCitation ResponseAnnotationType = "file_citation"
+	ResponseAnnotationTypeFilePath     ResponseAnnotationType = "file_path"
+)
+
+// ResponseAnnotation is an annotation of an output_text part: a web page cited
+// from a web search between the character indexes StartIndex and EndIndex, or a
+// file cited from a file search or generated, at Index.
+type ResponseAnnotation struct {
+	Type ResponseAnnotationType `json:"type"`
+	// StartIndex, EndIndex, URL and Title are those of url_citation
+	// annotations.
+	StartIndex int    `json:"start_index,omitempty"`
+	EndIndex   int    `json:"end_index,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	// FileID, Filename and Index are those of file_citation and file_path
+	// annotations.
+	FileID   string `json:"file_id,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Index    int    `json:"index,omitempty"`
+}
+
+// UnmarshalJSON fills ResponseAnnotations from the annotations.
+func (c *ResponseContent) UnmarshalJSON(data []byte) error {
+	type alias ResponseContent
+	var decoded struct {
+		alias
+		ResponseAnnotations []ResponseAnnotation `json:"annotations,omitempty"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*c = ResponseContent(decoded.alias)
+	if len(decoded.ResponseAnnotations) == 0 {
+		return nil
+	}
+	c.ResponseAnnotations = decoded.ResponseAnnotations
+	return json.Unmarshal(data, &struct {
+		Annotations *[]any `json:"annotations"`
+	}{&c.Annotations})
+}
+
+// ComputerActionHandler performs the action of a computer call and returns an
+// image URL, which can be a data URL, of a screenshot of the screen afterwards.
+// The pending safety checks of the call are acknowledged unless it returns an
+// error.
+type ComputerActionHandler func(
+	ctx context.Context,
+	action ResponseComputerAction,
+	pendingSafetyChecks []ResponseSafetyCheck,
+) (screenshotURL string, err error)
+
+// RunComputerUse creates a response for request, which should have a
+// computer_use_preview tool, and while the model calls the computer, performs
+// its actions with handleAction and continues the response with the resulting
+// screenshots, for at most maxTurns responses. It returns the last response,
+// with ErrComputerUseMaxTurns if the model still calls the computer.
+func (c *Client) RunComputerUse(
+	ctx context.Context,
+	request ResponseRequest,
+	handleAction ComputerActionHandler,
+	maxTurns int,
+) (ModelResponse, error) {
+	if request.Truncation == "" {
+		request.Truncation = "auto"
+	}
+	var response ModelResponse
+	for turn := 0; turn < maxTurns; turn++ {
+		var err error
+		response, err = c.CreateResponse(ctx, request)
+		if err != nil {
+			return response, err
+		}
+		calls := response.ComputerCalls()
+		if len(calls) == 0 {
+			return response, nil
+		}
+
+		outputs := make([]ResponseItem, 0, len(calls))
+		for _, call := range calls {
+			if call.Action == nil {
+				return response, fmt.Errorf("computer call %s has no action", call.CallID)
+			}
+			screenshot, err := handleAction(ctx, *call.Action, call.PendingSafetyChecks)
+			if err != nil {
+				return response, fmt.Errorf("computer call %s: %w", call.CallID, err)
+			}
+			outputs = append(outputs, NewResponseComputerCallOutput(call.CallID, screenshot, call.PendingSafetyChecks...))
+		}
+		request.Input = outputs
+		request.PreviousResponseID = response.ID
+	}
+	return response, ErrComputerUseMaxTurns
+}
@@ -0,0 +1,162 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestResponseBuiltInTools(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","status":"completed","output":[`+
+			`{"type":"web_search_call","id":"ws_1","status":"completed"},`+
+			`{"type":"file_search_call","id":"fs_1","status":"completed","queries":["refunds"],`+
+			`"results":[{"file_id":"file-1","filename":"policy.md","score":0.9,"text":"30 days",`+
+			`"attributes":{"year":2024}}]},`+
+			`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text",`+
+			`"text":"Refunds take 30 days.","annotations":[{"type":"url_citation","start_index":0,"end_index":7,`+
+			`"url":"https://example.com/refunds","title":"Refunds"},`+
+			`{"type":"file_citation","index":20,"file_id":"file-1","filename":"policy.md"}]}]}]}`)
+	})
+
+	response, err := client.CreateResponse(context.Background(), openai.ResponseRequest{
+		Model: openai.GPT4Dot1,
+		Input: "How long do refunds take?",
+		Tools: []openai.ResponseTool{
+			openai.NewResponseWebSearchTool("low", &openai.ResponseUserLocation{Type: "approximate", Country: "FR"}),
+			{
+				Type:           openai.ResponseToolTypeFileSearch,
+				VectorStoreIDs: []string{"vs_1"},
+				MaxNumResults:  5,
+				Filters:        openai.FilterGte("year", 2024),
+			},
+		},
+	})
+	checks.NoError(t, err, "CreateResponse error")
+
+	tools, _ := request["tools"].([]any)
+	webSearch, _ := tools[0].(map[string]any)
+	fileSearch, _ := tools[1].(map[string]any)
+	filters, _ := fileSearch["filters"].(map[string]any)
+	if webSearch["type"] != "web_search_preview" || webSearch["search_context_size"] != "low" ||
+		webSearch["name"] != nil || fileSearch["max_num_results"] != 5.0 || filters["type"] != "gte" {
+		t.Errorf("unexpected tools %v", tools)
+	}
+
+	fileSearchCall := response.Output[1]
+	if response.Output[0].Type != openai.ResponseItemTypeWebSearchCall || len(fileSearchCall.Results) != 1 ||
+		fileSearchCall.Results[0].Filename != "policy.md" || fileSearchCall.Queries[0] != "refunds" {
+		t.Errorf("unexpected output %+v", response.Output)
+	}
+	content := response.Output[2].Content[0]
+	annotations := content.ResponseAnnotations
+	if len(content.Annotations) != 2 || len(annotations) != 2 ||
+		annotations[0].Type != openai.ResponseAnnotationTypeURLCitation ||
+		annotations[0].URL != "https://example.com/refunds" || annotations[0].EndIndex != 7 ||
+		annotations[1].FileID != "file-1" || annotations[1].Index != 20 {
+		t.Errorf("unexpected annotations %+v", content)
+	}
+}
+
+func TestResponseComputerCallOutputJSON(t *testing.T) {
+	output := openai.NewResponseComputerCallOutput("call_1", "data:image/png;base64,AAAA",
+		openai.ResponseSafetyCheck{ID: "sc_1", Code: "malicious_instructions"})
+	data, err := json.Marshal(output)
+	checks.NoError(t, err, "Marshal error")
+	expected := `{"type":"computer_call_output","call_id":"call_1",` +
+		`"acknowledged_safety_checks":[{"id":"sc_1","code":"malicious_instructions"}],` +
+		`"output":{"type":"computer_screenshot","image_url":"data:image/png;base64,AAAA"}}`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	var decoded openai.ResponseItem
+	checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+	if decoded.Screenshot == nil || decoded.Screenshot.ImageURL != "data:image/png;base64,AAAA" || decoded.Output != "" {
+		t.Errorf("unexpected item %+v", decoded)
+	}
+
+	data, err = json.Marshal(openai.NewResponseFunctionCallOutput("call_2", "sunny"))
+	checks.NoError(t, err, "Marshal error")
+	checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+	if decoded.Output != "sunny" || decoded.Screenshot != nil {
+		t.Errorf("unexpected item %+v from %s", decoded, data)
+	}
+}
+
+func TestRunComputerUse(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var requests []map[string]any
+	server.RegisterHandler("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		switch len(requests) {
+		case 1:
+			fmt.Fprint(w, `{"id":"resp_1","status":"completed","output":[{"type":"computer_call","id":"cu_1",`+
+				`"call_id":"call_1","action":{"type":"click","x":10,"y":20,"button":"left"},`+
+				`"pending_safety_checks":[{"id":"sc_1","code":"irrelevant_domain","message":"Check the domain."}]}]}`)
+		case 2:
+			fmt.Fprint(w, `{"id":"resp_2","status":"completed","output":[{"type":"computer_call","id":"cu_2",`+
+				`"call_id":"call_2","action":{"type":"type","text":"hello"}}]}`)
+		default:
+			fmt.Fprint(w, `{"id":"resp_3","status":"completed","output":[{"type":"message","role":"assistant",`+
+				`"content":[{"type":"output_text","text":"Done."}]}]}`)
+		}
+	})
+
+	var actions []openai.ResponseComputerAction
+	handle := func(
+		_ context.Context,
+		action openai.ResponseComputerAction,
+		_ []openai.ResponseSafetyCheck,
+	) (string, error) {
+		actions = append(actions, action)
+		return fmt.Sprintf("data:image/png;base64,%d", len(actions)), nil
+	}
+	request := openai.ResponseRequest{
+		Model: "computer-use-preview",
+		Input: "Say hello.",
+		Tools: []openai.ResponseTool{openai.NewResponseComputerTool(1024, 768, "browser")},
+	}
+	response, err := client.RunComputerUse(context.Background(), request, handle, 5)
+	checks.NoError(t, err, "RunComputerUse error")
+
+	if response.OutputText() != "Done." || len(actions) != 2 ||
+		actions[0].Type != openai.ResponseComputerActionClick || actions[0].X != 10 ||
+		actions[1].Type != openai.ResponseComputerActionText || actions[1].Text != "hello" {
+		t.Errorf("unexpected response %+v after actions %+v", response, actions)
+	}
+	input, _ := requests[1]["input"].([]any)
+	output, _ := input[0].(map[string]any)
+	screenshot, _ := output["output"].(map[string]any)
+	checksAcknowledged, _ := output["acknowledged_safety_checks"].([]any)
+	if requests[0]["truncation"] != "auto" || requests[1]["previous_response_id"] != "resp_1" ||
+		output["call_id"] != "call_1" || screenshot["image_url"] != "data:image/png;base64,1" ||
+		len(checksAcknowledged) != 1 {
+		t.Errorf("unexpected request %v", requests[1])
+	}
+
+	requests = nil
+	_, err = client.RunComputerUse(context.Background(), request, handle, 1)
+	checks.ErrorIs(t, err, openai.ErrComputerUseMaxTurns, "the turns should be limited")
+
+	requests = nil
+	failure := errors.New("no browser")
+	_, err = client.RunComputerUse(context.Background(), request,
+		func(context.Context, openai.ResponseComputerAction, []openai.ResponseSafetyCheck) (string, error) {
+			return "", failure
+		}, 5)
+	checks.ErrorIs(t, err, failure, "action errors should be returned")
+}
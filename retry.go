@@ -0,0 +1,275 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+
+	// retryDrainLimit bounds how much of a failed response is read before the
+	// connection is released for the next attempt.
+	retryDrainLimit = 4 << 10
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// RetryError is returned by a client configured with MaxRetries when a request
+// fails. It records how many attempts were made before giving up and the
+// Idempotency-Key they were sent with, if any; re-issuing the call with
+// WithIdempotencyKey(IdempotencyKey) cannot create a duplicate.
+type RetryError struct {
+	Attempts       int
+	IdempotencyKey string
+	Err            error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (attempts: %d)", e.Err, e.Attempts)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultShouldRetry retries errors that do not come from the API, such as
+// network errors, and responses with status 408, 409, 429 or 5xx, except 429s
+// reporting an exhausted quota, which retrying does not fix. Errors caused by
+// the request context are never retried. It agrees with IsRetryable on API
+// errors.
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var (
+			apiErr *APIError
+			reqErr *RequestError
+		)
+		if errors.As(err, &apiErr) || errors.As(err, &reqErr) {
+			return IsRetryable(err)
+		}
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	class := classifyStatus(resp.StatusCode)
+	if class == ErrorClassRateLimited {
+		if apiErr := peekAPIError(resp); apiErr != nil && apiErr.quotaExceeded() {
+			return false
+		}
+	}
+	return class.Retryable()
+}
+
+// peekAPIError returns the error in the body of the failed response resp, or
+// nil if it has none, leaving the body to be read again.
+func peekAPIError(resp *http.Response) *APIError {
+	if resp.Body == nil {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, retryDrainLimit))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	var errRes ErrorResponse
+	if json.Unmarshal(data, &errRes) != nil {
+		return nil
+	}
+	return errRes.Error
+}
+
+// DefaultRetryBackoff returns an exponentially growing delay for the given retry
+// attempt, starting at 1, with jitter between half and the full delay.
+func DefaultRetryBackoff(attempt int) time.Duration {
+	delay := defaultRetryBaseDelay
+	for i := 1; i < attempt && delay < defaultRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > defaultRetryMaxDelay {
+		delay = defaultRetryMaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // jitter does not need crypto rand
+}
+
+// sendWithRetries sends req, retrying it as configured by ClientConfig.MaxRetries.
+// A response with a failure status code is returned together with the decoded
+// error, so callers can still read its headers.
+//
+// Only failed attempts are retried: once a successful response is returned its
+// body belongs to the caller, so a stream is never replayed after bytes of it
+// have been received.
+func (c *Client) sendWithRetries(req *http.Request, stream bool) (*http.Response, error) {
+	key, err := c.idempotencyKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	resp, attempts, err := c.doWithRetries(req, stream)
+	if err == nil && isFailureStatusCode(resp) {
+		err = c.handleErrorResp(resp)
+	}
+	if err != nil && c.config.MaxRetries > 0 {
+		err = &RetryError{Attempts: attempts, IdempotencyKey: key, Err: err}
+	}
+	return resp, err
+}
+
+// idempotencyKey returns the Idempotency-Key to send req with: the one set with
+// WithIdempotencyKey or, for a POST that may be retried, a new random one.
+// Requests that do not create anything are sent without a key.
+func (c *Client) idempotencyKey(req *http.Request) (string, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return "", nil
+	}
+	if c.config.idempotencyKey != "" {
+		return c.config.idempotencyKey, nil
+	}
+	if c.config.MaxRetries == 0 || req.Method != http.MethodPost || !canRetryRequest(req) {
+		return "", nil
+	}
+	return newIdempotencyKey()
+}
+
+// newIdempotencyKey returns a random (version 4) UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generating idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func (c *Client) doWithRetries(req *http.Request, stream bool) (*http.Response, int, error) {
+	shouldRetry := c.config.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultShouldRetry
+	}
+	backoff := c.config.RetryBackoff
+	if backoff == nil {
+		backoff = DefaultRetryBackoff
+	}
+	maxDelay := c.config.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	send := RoundTripFunc(c.sendHTTP)
+	if c.config.RateLimiter != nil {
+		send = c.rateLimit(send, stream)
+	}
+
+	attempt := 1
+	for {
+		resp, err := send(req) //nolint:bodyclose // body is closed by the caller or before retrying
+		if attempt > c.config.MaxRetries || !canRetryRequest(req) || !shouldRetry(resp, err) {
+			return resp, attempt, err
+		}
+
+		wait := backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header); ok {
+				if d > maxDelay {
+					// Rather than holding the caller for that long, report the failure.
+					return resp, attempt, err
+				}
+				wait = d
+			}
+		}
+
+		ctx := req.Context()
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			// The next attempt could not finish in time, report the last failure.
+			return resp, attempt, err
+		}
+
+		if resp != nil {
+			_, _ = io.CopyN(io.Discard, resp.Body, retryDrainLimit)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+
+		next, rewindErr := rewindRequest(req)
+		if rewindErr != nil {
+			return nil, attempt, rewindErr
+		}
+		req = next
+		attempt++
+	}
+}
+
+// canRetryRequest reports whether req can be sent again, which requires its body
+// to be reproducible. Streamed bodies such as large multipart uploads are not.
+func canRetryRequest(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// retryAfter returns how long the server asked to wait before retrying. It reads
+// retry-after-ms and Retry-After, then the x-ratelimit-reset-* header of every
+// exhausted limit, using the longest reset.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+			return time.Duration(secs * float64(time.Second)), true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			d := time.Until(t)
+			if d < 0 {
+				d = 0
+			}
+			return d, true
+		}
+	}
+
+	var wait time.Duration
+	var found bool
+	for _, limit := range []string{"requests", "tokens"} {
+		if h.Get("x-ratelimit-remaining-"+limit) != "0" {
+			continue
+		}
+		d, err := time.ParseDuration(h.Get("x-ratelimit-reset-" + limit))
+		if err != nil {
+			continue
+		}
+		if !found || d > wait {
+			wait, found = d, true
+		}
+	}
+	return wait, found
+}
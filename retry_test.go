@@ -0,0 +1,382 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func noRetryDelay(int) time.Duration { return time.Millisecond }
+
+func writeAPIError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{"error":{"message":"try again","type":"server_error"}}`))
+}
+
+func TestRetrySucceedsAfterServerErrors(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 3
+		c.RetryBackoff = noRetryDelay
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			writeAPIError(w, http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels should succeed after retries")
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 2
+		c.RetryBackoff = noRetryDelay
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeAPIError(w, http.StatusInternalServerError)
+	})
+
+	_, err := client.ListModels(context.Background())
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryError, got %T: %v", err, err)
+	}
+	if retryErr.Attempts != 3 || calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d (server saw %d)", retryErr.Attempts, calls)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected wrapped APIError, got %v", err)
+	}
+}
+
+func TestRetryNotAttemptedForClientErrors(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 3
+		c.RetryBackoff = noRetryDelay
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeAPIError(w, http.StatusBadRequest)
+	})
+
+	_, err := client.ListModels(context.Background())
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 1 || calls != 1 {
+		t.Fatalf("expected a single attempt, got %v (server saw %d)", err, calls)
+	}
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeAPIError(w, http.StatusTooManyRequests)
+	})
+
+	_, err := client.ListModels(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T", err)
+	}
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		t.Fatal("errors should not be wrapped when retries are disabled")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 1
+		c.RetryBackoff = func(int) time.Duration { return time.Hour }
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			writeAPIError(w, http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.ListModels(ctx)
+	checks.NoError(t, err, "Retry-After should override the backoff")
+}
+
+func TestRetryNotAttemptedPastMaxDelay(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxDelay time.Duration
+		header   http.Header
+	}{
+		{"default", 0, http.Header{"Retry-After": {"3600"}}},
+		{"configured", 100 * time.Millisecond, http.Header{"Retry-After-Ms": {"200"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+				c.MaxRetries = 3
+				c.RetryMaxDelay = tc.maxDelay
+			})
+			defer teardown()
+			server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				for k, v := range tc.header {
+					w.Header()[k] = v
+				}
+				writeAPIError(w, http.StatusTooManyRequests)
+			})
+
+			start := time.Now()
+			_, err := client.ListModels(context.Background())
+			var retryErr *RetryError
+			if !errors.As(err, &retryErr) || retryErr.Attempts != 1 || calls != 1 {
+				t.Fatalf("expected a single attempt, got %v (server saw %d)", err, calls)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests {
+				t.Fatalf("expected wrapped APIError, got %v", err)
+			}
+			if time.Since(start) > 50*time.Millisecond {
+				t.Fatal("client waited for a retry it did not make")
+			}
+		})
+	}
+}
+
+func TestRetryRespectsContextDeadline(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 5
+		c.RetryBackoff = func(int) time.Duration { return time.Minute }
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeAPIError(w, http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := client.ListModels(ctx)
+	checks.HasError(t, err, "ListModels should fail")
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("client waited past the context deadline")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryReplaysRequestBody(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 1
+		c.RetryBackoff = func(int) time.Duration { return 0 }
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "hello") {
+			t.Errorf("attempt %d sent body %q", atomic.LoadInt32(&calls)+1, body)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			writeAPIError(w, http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"modr-1"}`))
+	})
+
+	_, err := client.Moderations(context.Background(), ModerationRequest{Input: "hello"})
+	checks.NoError(t, err, "Moderations should succeed on retry")
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestCanRetryRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost", strings.NewReader("body"))
+	if !canRetryRequest(req) {
+		t.Fatal("request with a replayable body should be retryable")
+	}
+	pr, _ := io.Pipe()
+	req, _ = http.NewRequest(http.MethodPost, "http://localhost", pr)
+	if canRetryRequest(req) {
+		t.Fatal("request with a streamed body should not be retryable")
+	}
+}
+
+func TestRetryAfterHeaders(t *testing.T) {
+	cases := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"none", http.Header{}, 0, false},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"250"}}, 250 * time.Millisecond, true},
+		{"seconds", http.Header{"Retry-After": {"2"}}, 2 * time.Second, true},
+		{"reset not exhausted", http.Header{
+			"X-Ratelimit-Remaining-Requests": {"3"},
+			"X-Ratelimit-Reset-Requests":     {"1s"},
+		}, 0, false},
+		{"longest exhausted reset", http.Header{
+			"X-Ratelimit-Remaining-Requests": {"0"},
+			"X-Ratelimit-Reset-Requests":     {"1s"},
+			"X-Ratelimit-Remaining-Tokens":   {"0"},
+			"X-Ratelimit-Reset-Tokens":       {"6m0s"},
+		}, 6 * time.Minute, true},
+	}
+	for _, tc := range cases {
+		got, ok := retryAfter(tc.header)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: retryAfter() = %v, %v; want %v, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	got, ok := retryAfter(http.Header{"Retry-After": {date}})
+	if !ok || got < 58*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(date) = %v, %v", got, ok)
+	}
+}
+
+func TestDefaultRetryBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := DefaultRetryBackoff(attempt)
+		if d <= 0 || d > defaultRetryMaxDelay {
+			t.Fatalf("attempt %d: backoff %v out of range", attempt, d)
+		}
+	}
+	if d := DefaultRetryBackoff(1); d > defaultRetryBaseDelay {
+		t.Fatalf("first backoff %v exceeds base delay", d)
+	}
+}
+
+// recordIdempotencyKey is a handler that fails every request after recording
+// its Idempotency-Key header in keys.
+func recordIdempotencyKey(keys *[]string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*keys = append(*keys, r.Header.Get("Idempotency-Key"))
+		writeAPIError(w, http.StatusInternalServerError)
+	}
+}
+
+func TestRetryIdempotencyKeyIsStable(t *testing.T) {
+	var keys []string
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 2
+		c.RetryBackoff = noRetryDelay
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs", recordIdempotencyKey(&keys))
+
+	_, err := client.CreateFineTuningJob(context.Background(), FineTuningJobRequest{Model: "gpt-4o-mini"})
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryError, got %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(keys))
+	}
+	if len(keys[0]) != 36 || strings.Count(keys[0], "-") != 4 {
+		t.Fatalf("expected a UUID key, got %q", keys[0])
+	}
+	for _, key := range keys {
+		if key != keys[0] {
+			t.Fatalf("key changed across retries: %v", keys)
+		}
+	}
+	if retryErr.IdempotencyKey != keys[0] {
+		t.Fatalf("RetryError reports key %q, want %q", retryErr.IdempotencyKey, keys[0])
+	}
+
+	keys = nil
+	_, _ = client.CreateFineTuningJob(context.Background(), FineTuningJobRequest{Model: "gpt-4o-mini"})
+	if keys[0] == retryErr.IdempotencyKey {
+		t.Fatal("a new call must get a new key")
+	}
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs", recordIdempotencyKey(&keys))
+	server.RegisterHandler("/v1/models", recordIdempotencyKey(&keys))
+	client = client.WithOptions(WithIdempotencyKey("job-42"))
+
+	_, err := client.CreateFineTuningJob(context.Background(), FineTuningJobRequest{Model: "gpt-4o-mini"})
+	checks.HasError(t, err, "CreateFineTuningJob should fail")
+	_, err = client.ListModels(context.Background())
+	checks.HasError(t, err, "ListModels should fail")
+	if len(keys) != 2 || keys[0] != "job-42" || keys[1] != "" {
+		t.Fatalf("expected the key on the POST only, got %q", keys)
+	}
+}
+
+func TestIdempotencyKeyNotSentWithoutRetries(t *testing.T) {
+	var keys []string
+	client, server, teardown := setupOpenAITestServerWithConfig(nil)
+	defer teardown()
+	server.RegisterHandler("/v1/fine_tuning/jobs", recordIdempotencyKey(&keys))
+
+	_, err := client.CreateFineTuningJob(context.Background(), FineTuningJobRequest{Model: "gpt-4o-mini"})
+	checks.HasError(t, err, "CreateFineTuningJob should fail")
+	if keys[0] != "" {
+		t.Fatalf("unexpected key %q", keys[0])
+	}
+}
+
+func TestRetryNotAttemptedForExhaustedQuota(t *testing.T) {
+	var calls int32
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.MaxRetries = 3
+		c.RetryBackoff = noRetryDelay
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":"InsufficientQuota","message":"The specified quota has been exceeded."}}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	if !IsQuotaExceeded(err) {
+		t.Fatalf("expected a quota error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls)
+	}
+}
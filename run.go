@@ -2,6 +2,8 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -37,10 +39,18 @@ type Run struct {
 	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
 	// ThreadTruncationStrategy defines the truncation strategy to use for the thread.
 	TruncationStrategy *ThreadTruncationStrategy `json:"truncation_strategy,omitempty"`
+	// IncompleteDetails tells why a run ended with status 'incomplete'.
+	IncompleteDetails *RunIncompleteDetails `json:"incomplete_details,omitempty"`
 
 	httpHeader
 }
 
+// RunIncompleteDetails is the reason a run is incomplete, such as
+// "max_completion_tokens" or "max_prompt_tokens".
+type RunIncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
 type RunStatus string
 
 const (
@@ -55,6 +65,17 @@ const (
 	RunStatusCancelled      RunStatus = "cancelled"
 )
 
+// Done reports whether a run with status s has stopped for good: it is
+// completed, failed, cancelled, expired or incomplete.
+func (s RunStatus) Done() bool {
+	switch s {
+	case RunStatusCompleted, RunStatusFailed, RunStatusCancelled, RunStatusExpired, RunStatusIncomplete:
+		return true
+	default:
+		return false
+	}
+}
+
 type RunRequiredAction struct {
 	Type              RequiredActionType `json:"type"`
 	SubmitToolOutputs *SubmitToolOutputs `json:"submit_tool_outputs,omitempty"`
@@ -82,6 +103,14 @@ const (
 	RunErrorRateLimitExceeded RunError = "rate_limit_exceeded"
 )
 
+var (
+	// ErrRunRequiresAction is returned by WaitForRun when the run stops to wait
+	// for the outputs of its tool calls.
+	ErrRunRequiresAction = errors.New("run requires action")
+	// ErrRunFailed is returned by WaitForRun when the run fails.
+	ErrRunFailed = errors.New("run failed")
+)
+
 type RunRequest struct {
 	AssistantID            string          `json:"assistant_id"`
 	Model                  string          `json:"model,omitempty"`
@@ -107,12 +136,17 @@ type RunRequest struct {
 	// ThreadTruncationStrategy defines the truncation strategy to use for the thread.
 	TruncationStrategy *ThreadTruncationStrategy `json:"truncation_strategy,omitempty"`
 
-	// This can be either a string or a ToolChoice object.
-	ToolChoice any `json:"tool_choice,omitempty"`
-	// This can be either a string or a ResponseFormat object.
-	ResponseFormat any `json:"response_format,omitempty"`
-	// Disable the default behavior of parallel tool calls by setting it: false.
-	ParallelToolCalls any `json:"parallel_tool_calls,omitempty"`
+	// ToolChoice is best built with ToolChoiceNone, ToolChoiceAuto,
+	// ToolChoiceRequired or ToolChoiceFunction. Nil lets the model decide.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+	// ResponseFormat is as in chat completions, including json_schema formats.
+	// Nil uses the format of the assistant.
+	ResponseFormat *ChatCompletionResponseFormat `json:"response_format,omitempty"`
+	// Disable the default behavior of parallel tool calls by setting it to
+	// false. Nil leaves the default.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+	// Stream is set by CreateRunStream and CreateThreadAndRunStream.
+	Stream bool `json:"stream,omitempty"`
 }
 
 // ThreadTruncationStrategy defines the truncation strategy to use for the thread.
@@ -134,6 +168,46 @@ const (
 	TruncationStrategyLastMessages = TruncationStrategy("last_messages")
 )
 
+// NewLastMessagesTruncationStrategy returns the truncation strategy keeping the
+// n most recent messages of the thread.
+func NewLastMessagesTruncationStrategy(n int) *ThreadTruncationStrategy {
+	return &ThreadTruncationStrategy{Type: TruncationStrategyLastMessages, LastMessages: &n}
+}
+
+var (
+	// ErrRunAssistantIDRequired is returned when creating a run without an
+	// assistant.
+	ErrRunAssistantIDRequired = errors.New("assistant_id is required")
+	// ErrRunAdditionalMessageRole is returned when an additional message of a run
+	// has a role other than user or assistant.
+	ErrRunAdditionalMessageRole = errors.New("additional messages must have the user or assistant role")
+	// ErrRunInvalidTruncationStrategy is returned for a last_messages truncation
+	// strategy without a positive number of messages.
+	ErrRunInvalidTruncationStrategy = errors.New("last_messages truncation requires a positive number of messages")
+	// ErrRunInvalidResponseFormat is returned for a json_schema response format
+	// without a schema.
+	ErrRunInvalidResponseFormat = errors.New("json_schema response format requires a schema")
+)
+
+func (r RunRequest) validate() error {
+	if r.AssistantID == "" {
+		return ErrRunAssistantIDRequired
+	}
+	for i, message := range r.AdditionalMessages {
+		if message.Role != ThreadMessageRoleUser && message.Role != ThreadMessageRoleAssistant {
+			return fmt.Errorf("%w: message %d has role %q", ErrRunAdditionalMessageRole, i, message.Role)
+		}
+	}
+	if t := r.TruncationStrategy; t != nil && t.Type == TruncationStrategyLastMessages &&
+		(t.LastMessages == nil || *t.LastMessages < 1) {
+		return ErrRunInvalidTruncationStrategy
+	}
+	if f := r.ResponseFormat; f != nil && f.Type == ChatCompletionResponseFormatTypeJSONSchema && f.JSONSchema == nil {
+		return ErrRunInvalidResponseFormat
+	}
+	return nil
+}
+
 // ReponseFormat specifies the format the model must output.
 // https://platform.openai.com/docs/api-reference/runs/createRun#runs-createrun-response_format.
 // Type can either be text or json_object.
@@ -149,11 +223,16 @@ type RunModifyRequest struct {
 type RunList struct {
 	Runs []Run `json:"data"`
 
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+
 	httpHeader
 }
 
 type SubmitToolOutputsRequest struct {
 	ToolOutputs []ToolOutput `json:"tool_outputs"`
+	Stream      bool         `json:"stream,omitempty"` // Set by SubmitToolOutputsStream.
 }
 
 type ToolOutput struct {
@@ -161,6 +240,41 @@ type ToolOutput struct {
 	Output     any    `json:"output"`
 }
 
+var (
+	// ErrToolOutputMissing is returned by SubmitToolOutputsRequest.Validate when
+	// a tool call required by the run has no output.
+	ErrToolOutputMissing = errors.New("missing output for a required tool call")
+	// ErrToolOutputUnexpected is returned by SubmitToolOutputsRequest.Validate
+	// for an output whose tool call the run does not require, or that is given
+	// twice.
+	ErrToolOutputUnexpected = errors.New("unexpected tool output")
+)
+
+// Validate checks that r has exactly one output for each of the tool calls
+// listed in the required action of run.
+func (r SubmitToolOutputsRequest) Validate(run Run) error {
+	required := make(map[string]bool)
+	if run.RequiredAction != nil && run.RequiredAction.SubmitToolOutputs != nil {
+		for _, call := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+			required[call.ID] = true
+		}
+	}
+	for _, output := range r.ToolOutputs {
+		if !required[output.ToolCallID] {
+			return fmt.Errorf("%w: %q", ErrToolOutputUnexpected, output.ToolCallID)
+		}
+		delete(required, output.ToolCallID)
+	}
+	if run.RequiredAction != nil && run.RequiredAction.SubmitToolOutputs != nil {
+		for _, call := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+			if required[call.ID] {
+				return fmt.Errorf("%w: %q", ErrToolOutputMissing, call.ID)
+			}
+		}
+	}
+	return nil
+}
+
 type CreateThreadAndRunRequest struct {
 	RunRequest
 	Thread ThreadRequest `json:"thread"`
@@ -207,12 +321,122 @@ type StepDetails struct {
 	Type            RunStepType                 `json:"type"`
 	MessageCreation *StepDetailsMessageCreation `json:"message_creation,omitempty"`
 	ToolCalls       []ToolCall                  `json:"tool_calls,omitempty"`
+	// RunStepToolCalls are the tool calls of ToolCalls with all their details,
+	// including the outputs of code interpreter, file search and function calls.
+	RunStepToolCalls []RunStepToolCall `json:"-"`
+}
+
+func (d *StepDetails) UnmarshalJSON(data []byte) error {
+	type stepDetails StepDetails
+	if err := json.Unmarshal(data, (*stepDetails)(d)); err != nil {
+		return err
+	}
+	var toolCalls struct {
+		ToolCalls []RunStepToolCall `json:"tool_calls"`
+	}
+	if err := json.Unmarshal(data, &toolCalls); err != nil {
+		return err
+	}
+	d.RunStepToolCalls = toolCalls.ToolCalls
+	return nil
 }
 
 type StepDetailsMessageCreation struct {
 	MessageID string `json:"message_id"`
 }
 
+type RunStepToolCallType string
+
+const (
+	RunStepToolCallTypeCodeInterpreter RunStepToolCallType = "code_interpreter"
+	RunStepToolCallTypeFileSearch      RunStepToolCallType = "file_search"
+	RunStepToolCallTypeFunction        RunStepToolCallType = "function"
+)
+
+// RunStepToolCall is a tool call of a run step. The field matching its Type is
+// set.
+type RunStepToolCall struct {
+	ID              string                  `json:"id"`
+	Type            RunStepToolCallType     `json:"type"`
+	CodeInterpreter *RunStepCodeInterpreter `json:"code_interpreter,omitempty"`
+	FileSearch      *RunStepFileSearch      `json:"file_search,omitempty"`
+	Function        *RunStepFunctionCall    `json:"function,omitempty"`
+}
+
+type RunStepCodeInterpreter struct {
+	Input   string                         `json:"input"`
+	Outputs []RunStepCodeInterpreterOutput `json:"outputs"`
+}
+
+// RunStepCodeInterpreterOutput is an output of the code interpreter: the logs
+// of the code when Type is "logs", or an image file it created when Type is
+// "image".
+type RunStepCodeInterpreterOutput struct {
+	Type  string                       `json:"type"`
+	Logs  string                       `json:"logs,omitempty"`
+	Image *RunStepCodeInterpreterImage `json:"image,omitempty"`
+}
+
+type RunStepCodeInterpreterImage struct {
+	FileID string `json:"file_id"`
+}
+
+// RunStepFileSearch holds the results of a file search. They are only returned
+// when requested with RunStepIncludeFileSearchResultContent.
+type RunStepFileSearch struct {
+	RankingOptions *RunStepFileSearchRankingOptions `json:"ranking_options,omitempty"`
+	Results        []RunStepFileSearchResult        `json:"results,omitempty"`
+}
+
+type RunStepFileSearchRankingOptions struct {
+	Ranker         string  `json:"ranker"`
+	ScoreThreshold float64 `json:"score_threshold"`
+}
+
+type RunStepFileSearchResult struct {
+	FileID   string                           `json:"file_id"`
+	FileName string                           `json:"file_name"`
+	Score    float64                          `json:"score"`
+	Content  []RunStepFileSearchResultContent `json:"content,omitempty"`
+}
+
+type RunStepFileSearchResultContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// RunStepFunctionCall is a function call of a run step. Output is nil until the
+// output of the call is submitted.
+type RunStepFunctionCall struct {
+	Name      string  `json:"name"`
+	Arguments string  `json:"arguments"`
+	Output    *string `json:"output"`
+}
+
+// RunStepInclude is additional data to include in the run steps returned.
+type RunStepInclude string
+
+const (
+	// RunStepIncludeFileSearchResultContent includes the content of the chunks
+	// retrieved by file searches.
+	RunStepIncludeFileSearchResultContent RunStepInclude = "step_details.tool_calls[*].file_search.results[*].content"
+)
+
+// ListRunStepsParams pages the run steps listed by ListRunStepsWithParams. Zero
+// values are left to the API defaults.
+type ListRunStepsParams struct {
+	// Limit is the number of steps per page, between 1 and 100.
+	Limit int
+	// Order sorts the steps by creation time, "asc" or "desc".
+	Order string
+	// After is the ID of the step after which the page starts.
+	After string
+	// Before is the ID of the step before which the page ends.
+	Before string
+	// Include is the additional data to include in the steps.
+	Include []RunStepInclude
+}
+
 // RunStepList is a list of steps.
 type RunStepList struct {
 	RunSteps []RunStep `json:"data"`
@@ -237,6 +461,9 @@ func (c *Client) CreateRun(
 	threadID string,
 	request RunRequest,
 ) (response Run, err error) {
+	if err = request.validate(); err != nil {
+		return
+	}
 	urlSuffix := fmt.Sprintf("/threads/%s/runs", threadID)
 	req, err := c.newRequest(
 		ctx,
@@ -300,26 +527,7 @@ func (c *Client) ListRuns(
 	threadID string,
 	pagination Pagination,
 ) (response RunList, err error) {
-	urlValues := url.Values{}
-	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
-	}
-	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
-	}
-	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
-	}
-	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	urlSuffix := fmt.Sprintf("/threads/%s/runs%s", threadID, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs%s", threadID, encodeQuery(paginationValues(pagination)))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
@@ -377,6 +585,9 @@ func (c *Client) CancelRun(
 func (c *Client) CreateThreadAndRun(
 	ctx context.Context,
 	request CreateThreadAndRunRequest) (response Run, err error) {
+	if err = request.validate(); err != nil {
+		return
+	}
 	urlSuffix := "/threads/runs"
 	req, err := c.newRequest(
 		ctx,
@@ -399,7 +610,21 @@ func (c *Client) RetrieveRunStep(
 	runID string,
 	stepID string,
 ) (response RunStep, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps/%s", threadID, runID, stepID)
+	return c.RetrieveRunStepWithInclude(ctx, threadID, runID, stepID)
+}
+
+// RetrieveRunStepWithInclude retrieves a run step with the additional data of
+// include.
+func (c *Client) RetrieveRunStepWithInclude(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	stepID string,
+	include ...RunStepInclude,
+) (response RunStep, err error) {
+	urlValues := url.Values{}
+	addRunStepIncludes(urlValues, include)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps/%s%s", threadID, runID, stepID, encodeQuery(urlValues))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
@@ -420,26 +645,45 @@ func (c *Client) ListRunSteps(
 	runID string,
 	pagination Pagination,
 ) (response RunStepList, err error) {
-	urlValues := url.Values{}
+	var params ListRunStepsParams
 	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
+		params.Limit = *pagination.Limit
 	}
 	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
+		params.Order = *pagination.Order
 	}
 	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
+		params.After = *pagination.After
 	}
 	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
+		params.Before = *pagination.Before
 	}
+	return c.ListRunStepsWithParams(ctx, threadID, runID, params)
+}
 
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
+// ListRunStepsWithParams lists a page of the steps of a run.
+func (c *Client) ListRunStepsWithParams(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	params ListRunStepsParams,
+) (response RunStepList, err error) {
+	urlValues := url.Values{}
+	if params.Limit > 0 {
+		urlValues.Add("limit", fmt.Sprintf("%d", params.Limit))
+	}
+	if params.Order != "" {
+		urlValues.Add("order", params.Order)
+	}
+	if params.After != "" {
+		urlValues.Add("after", params.After)
+	}
+	if params.Before != "" {
+		urlValues.Add("before", params.Before)
 	}
+	addRunStepIncludes(urlValues, params.Include)
 
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps%s", threadID, runID, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps%s", threadID, runID, encodeQuery(urlValues))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
@@ -452,3 +696,40 @@ func (c *Client) ListRunSteps(
 	err = c.sendRequest(req, &response)
 	return
 }
+
+func addRunStepIncludes(urlValues url.Values, include []RunStepInclude) {
+	for _, i := range include {
+		urlValues.Add("include[]", string(i))
+	}
+}
+
+// WaitForRun polls a run until it is done or requires action, and returns it.
+// A run that requires action is returned with ErrRunRequiresAction: submit the
+// outputs of its required tool calls with SubmitToolOutputs, then wait again. A
+// failed run is returned with an error wrapping ErrRunFailed; cancelled, expired
+// and incomplete runs are returned without error.
+func (c *Client) WaitForRun(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	opts PollOptions,
+) (run Run, err error) {
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.RetrieveRun(ctx, threadID, runID)
+		if err != nil {
+			return false, err
+		}
+		run = polled
+		switch run.Status {
+		case RunStatusRequiresAction:
+			return true, ErrRunRequiresAction
+		case RunStatusFailed:
+			if run.LastError == nil {
+				return true, fmt.Errorf("%w: no reason given", ErrRunFailed)
+			}
+			return true, fmt.Errorf("%w: %s (code: %s)", ErrRunFailed, run.LastError.Message, run.LastError.Code)
+		}
+		return run.Status.Done(), nil
+	})
+	return
+}
@@ -9,7 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 )
 
 // TestAssistant Tests the assistant endpoint of the API using the mocked server.
@@ -235,3 +237,198 @@ func TestRun(t *testing.T) {
 	)
 	checks.NoError(t, err, "ListRunSteps error")
 }
+
+func TestSubmitToolOutputsRequestValidate(t *testing.T) {
+	run := openai.Run{RequiredAction: &openai.RunRequiredAction{
+		Type: openai.RequiredActionTypeSubmitToolOutputs,
+		SubmitToolOutputs: &openai.SubmitToolOutputs{ToolCalls: []openai.ToolCall{
+			{ID: "call_1", Type: openai.ToolTypeFunction}, {ID: "call_2", Type: openai.ToolTypeFunction},
+		}},
+	}}
+
+	request := openai.SubmitToolOutputsRequest{ToolOutputs: []openai.ToolOutput{
+		{ToolCallID: "call_2", Output: "b"}, {ToolCallID: "call_1", Output: "a"},
+	}}
+	checks.NoError(t, request.Validate(run), "outputs for every call should be valid")
+
+	request.ToolOutputs = request.ToolOutputs[:1]
+	checks.ErrorIs(t, request.Validate(run), openai.ErrToolOutputMissing, "missing outputs should fail")
+
+	request.ToolOutputs = append(request.ToolOutputs, openai.ToolOutput{ToolCallID: "call_2"})
+	checks.ErrorIs(t, request.Validate(run), openai.ErrToolOutputUnexpected, "duplicate outputs should fail")
+
+	request.ToolOutputs = []openai.ToolOutput{{ToolCallID: "call_3"}}
+	checks.ErrorIs(t, request.Validate(run), openai.ErrToolOutputUnexpected, "unknown outputs should fail")
+}
+
+func TestWaitForRun(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	polls := []string{
+		`"status":"queued"`,
+		`"status":"in_progress"`,
+		`"status":"requires_action","required_action":{"type":"submit_tool_outputs",` +
+			`"submit_tool_outputs":{"tool_calls":[{"id":"call_abc123","type":"function"}]}}`,
+		`"status":"in_progress"`,
+		`"status":"incomplete","incomplete_details":{"reason":"max_completion_tokens"},` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":20,"total_tokens":30}`,
+	}
+	poll := 0
+	server.RegisterHandler("/v1/threads/thread_abc123/runs/run_abc123", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"id":"run_abc123","thread_id":"thread_abc123",%s}`, polls[poll])
+		poll++
+	})
+	ctx := context.Background()
+	opts := openai.PollOptions{Interval: time.Millisecond}
+
+	run, err := client.WaitForRun(ctx, "thread_abc123", "run_abc123", opts)
+	checks.ErrorIs(t, err, openai.ErrRunRequiresAction, "the wait should stop when the run requires action")
+	if poll != 3 || run.RequiredAction.SubmitToolOutputs.ToolCalls[0].ID != "call_abc123" {
+		t.Errorf("unexpected run %+v after %d polls", run, poll)
+	}
+
+	run, err = client.WaitForRun(ctx, "thread_abc123", "run_abc123", opts)
+	checks.NoError(t, err, "incomplete runs should not fail")
+	if run.Status != openai.RunStatusIncomplete || run.IncompleteDetails.Reason != "max_completion_tokens" ||
+		run.Usage.TotalTokens != 30 {
+		t.Errorf("unexpected run %+v", run)
+	}
+}
+
+func TestWaitForRunFailed(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/threads/thread_abc123/runs/run_abc123", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"run_abc123","status":"failed",`+
+			`"last_error":{"code":"rate_limit_exceeded","message":"You exceeded your quota."}}`)
+	})
+
+	run, err := client.WaitForRun(context.Background(), "thread_abc123", "run_abc123", openai.PollOptions{})
+	checks.ErrorIs(t, err, openai.ErrRunFailed, "failed runs should return ErrRunFailed")
+	if err.Error() != "run failed: You exceeded your quota. (code: rate_limit_exceeded)" {
+		t.Errorf("unexpected error %q", err)
+	}
+	if run.Status != openai.RunStatusFailed || !run.Status.Done() || openai.RunStatusRequiresAction.Done() {
+		t.Errorf("unexpected status %q", run.Status)
+	}
+}
+
+func TestListRunStepsWithParams(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var query url.Values
+	path := "/v1/threads/thread_abc123/runs/run_abc123/steps"
+	server.RegisterHandler(path, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"object":"list","data":[`+
+			`{"id":"step_1","type":"message_creation","step_details":{"type":"message_creation",`+
+			`"message_creation":{"message_id":"msg_abc123"}}},`+
+			`{"id":"step_2","type":"tool_calls","step_details":{"type":"tool_calls","tool_calls":[`+
+			`{"id":"call_1","type":"code_interpreter","code_interpreter":{"input":"print(1)","outputs":[`+
+			`{"type":"logs","logs":"1"},{"type":"image","image":{"file_id":"file-img"}}]}},`+
+			`{"id":"call_2","type":"file_search","file_search":`+
+			`{"ranking_options":{"ranker":"auto","score_threshold":0.5},"results":[{"file_id":"file-doc",`+
+			`"file_name":"doc.md","score":0.9,"content":[{"type":"text","text":"chunk"}]}]}},`+
+			`{"id":"call_3","type":"function","function":{"name":"get_weather","arguments":"{}","output":"sunny"}}`+
+			`]}}],"first_id":"step_1","last_id":"step_2","has_more":false}`)
+	})
+
+	steps, err := client.ListRunStepsWithParams(context.Background(), "thread_abc123", "run_abc123",
+		openai.ListRunStepsParams{
+			Limit:   2,
+			Order:   "asc",
+			After:   "step_0",
+			Include: []openai.RunStepInclude{openai.RunStepIncludeFileSearchResultContent},
+		})
+	checks.NoError(t, err, "ListRunStepsWithParams error")
+
+	if query.Get("limit") != "2" || query.Get("order") != "asc" || query.Get("after") != "step_0" ||
+		query.Has("before") || query.Get("include[]") != string(openai.RunStepIncludeFileSearchResultContent) {
+		t.Errorf("unexpected query %v", query)
+	}
+	if len(steps.RunSteps) != 2 || steps.RunSteps[0].StepDetails.MessageCreation.MessageID != "msg_abc123" {
+		t.Fatalf("unexpected steps %+v", steps.RunSteps)
+	}
+	details := steps.RunSteps[1].StepDetails
+	if len(details.ToolCalls) != 3 || len(details.RunStepToolCalls) != 3 {
+		t.Fatalf("unexpected tool calls %+v", details)
+	}
+	code := details.RunStepToolCalls[0].CodeInterpreter
+	if code.Input != "print(1)" || code.Outputs[0].Logs != "1" || code.Outputs[1].Image.FileID != "file-img" {
+		t.Errorf("unexpected code interpreter call %+v", code)
+	}
+	search := details.RunStepToolCalls[1].FileSearch
+	if search.RankingOptions.ScoreThreshold != 0.5 || search.Results[0].Content[0].Text != "chunk" {
+		t.Errorf("unexpected file search call %+v", search)
+	}
+	function := details.RunStepToolCalls[2].Function
+	if function.Name != "get_weather" || function.Output == nil || *function.Output != "sunny" {
+		t.Errorf("unexpected function call %+v", function)
+	}
+}
+
+func TestCreateRunOverrides(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request map[string]any
+	server.RegisterHandler("/v1/threads/thread_abc123/runs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"run_abc123","object":"thread.run","status":"queued"}`)
+	})
+	ctx := context.Background()
+	temperature := float32(0)
+	toolChoice, parallelToolCalls := openai.ToolChoiceRequired(), false
+	schema, err := openai.GenerateJSONSchemaResponseFormat("answer", struct {
+		Answer string `json:"answer"`
+	}{})
+	checks.NoError(t, err, "GenerateJSONSchemaResponseFormat error")
+
+	_, err = client.CreateRun(ctx, "thread_abc123", openai.RunRequest{
+		AssistantID:            "asst_abc123",
+		Temperature:            &temperature,
+		AdditionalInstructions: "Answer in one word.",
+		AdditionalMessages: []openai.ThreadMessage{
+			{Role: openai.ThreadMessageRoleUser, Content: "What color is the sky?"},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type:       openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: schema,
+		},
+		TruncationStrategy: openai.NewLastMessagesTruncationStrategy(5),
+		ToolChoice:         &toolChoice,
+		ParallelToolCalls:  &parallelToolCalls,
+	})
+	checks.NoError(t, err, "CreateRun error")
+
+	format, _ := request["response_format"].(map[string]any)
+	truncation, _ := request["truncation_strategy"].(map[string]any)
+	if request["temperature"] != 0.0 || format["type"] != "json_schema" || format["json_schema"] == nil ||
+		truncation["type"] != "last_messages" || truncation["last_messages"] != 5.0 ||
+		request["tool_choice"] != "required" || request["parallel_tool_calls"] != false {
+		t.Errorf("unexpected request %v", request)
+	}
+
+	request = nil
+	_, err = client.CreateRun(ctx, "thread_abc123", openai.RunRequest{
+		AssistantID:        "asst_abc123",
+		AdditionalMessages: []openai.ThreadMessage{{Role: "system", Content: "Be brief."}},
+	})
+	checks.ErrorIs(t, err, openai.ErrRunAdditionalMessageRole, "system messages should fail")
+	_, err = client.CreateThreadAndRun(ctx, openai.CreateThreadAndRunRequest{RunRequest: openai.RunRequest{
+		AssistantID:        "asst_abc123",
+		TruncationStrategy: &openai.ThreadTruncationStrategy{Type: openai.TruncationStrategyLastMessages},
+	}})
+	checks.ErrorIs(t, err, openai.ErrRunInvalidTruncationStrategy, "last_messages without a count should fail")
+	_, err = client.CreateRunStream(ctx, "thread_abc123", openai.RunRequest{
+		AssistantID:    "asst_abc123",
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONSchema},
+	})
+	checks.ErrorIs(t, err, openai.ErrRunInvalidResponseFormat, "json_schema without a schema should fail")
+	_, err = client.CreateThreadAndRun(ctx, openai.CreateThreadAndRunRequest{
+		Thread: openai.ThreadRequest{Messages: []openai.ThreadMessage{{Role: openai.ThreadMessageRoleUser}}},
+	})
+	checks.ErrorIs(t, err, openai.ErrRunAssistantIDRequired, "runs without an assistant should fail")
+	if request != nil {
+		t.Errorf("invalid runs should not be sent, got %v", request)
+	}
+}
@@ -2,9 +2,20 @@ package openai
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"os"
 )
 
+// Limits of CreateSpeechRequest.Speed.
+const (
+	MinSpeechSpeed = 0.25
+	MaxSpeechSpeed = 4.0
+)
+
+var ErrInvalidSpeechSpeed = errors.New("speech speed must be between 0.25 and 4.0")
+
 type SpeechModel string
 
 const (
@@ -49,7 +60,15 @@ type CreateSpeechRequest struct {
 	Speed          float64              `json:"speed,omitempty"`           // Optional, default to 1.0
 }
 
+// CreateSpeech generates audio reading request.Input aloud. The audio is streamed
+// from the response body, which the caller must close; its Content-Type tells
+// the format it is in.
 func (c *Client) CreateSpeech(ctx context.Context, request CreateSpeechRequest) (response RawResponse, err error) {
+	if request.Speed != 0 && (request.Speed < MinSpeechSpeed || request.Speed > MaxSpeechSpeed) {
+		err = ErrInvalidSpeechSpeed
+		return
+	}
+
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
@@ -63,3 +82,33 @@ func (c *Client) CreateSpeech(ctx context.Context, request CreateSpeechRequest)
 
 	return c.sendRequestRaw(req)
 }
+
+// WriteSpeech generates audio like CreateSpeech and copies it to w as it is
+// received. It returns the Content-Type of the audio.
+func (c *Client) WriteSpeech(ctx context.Context, request CreateSpeechRequest, w io.Writer) (string, error) {
+	response, err := c.CreateSpeech(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Close()
+
+	_, err = io.Copy(w, response)
+	return response.ContentType(), err
+}
+
+// SaveSpeech generates audio like CreateSpeech and writes it to the file at
+// path, which is removed if the audio cannot be fully received.
+func (c *Client) SaveSpeech(ctx context.Context, request CreateSpeechRequest, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteSpeech(ctx, request, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return err
+}
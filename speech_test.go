@@ -1,6 +1,7 @@
 package openai_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -90,7 +91,66 @@ func TestSpeechIntegration(t *testing.T) {
 		checks.NoError(t, err, "ReadAll error")
 
 		// save buf to file as mp3
-		err = os.WriteFile("test.mp3", buf, 0644)
+		err = os.WriteFile(filepath.Join(t.TempDir(), "test.mp3"), buf, 0644)
 		checks.NoError(t, err, "Create error")
 	})
 }
+
+func TestSpeechOutput(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	audio := []byte("ID3 fake opus audio")
+	server.RegisterHandler("/v1/audio/speech", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.CreateSpeechRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.ResponseFormat != openai.SpeechResponseFormatOpus || request.Speed != 1.5 ||
+			request.Instructions != "Whisper." {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write(audio)
+	})
+	request := openai.CreateSpeechRequest{
+		Model:          openai.TTSModelGPT4oMini,
+		Input:          "Hello!",
+		Voice:          openai.VoiceNova,
+		ResponseFormat: openai.SpeechResponseFormatOpus,
+		Speed:          1.5,
+		Instructions:   "Whisper.",
+	}
+
+	res, err := client.CreateSpeech(context.Background(), request)
+	checks.NoError(t, err, "CreateSpeech error")
+	if res.ContentType() != "audio/ogg" {
+		t.Errorf("unexpected content type %q", res.ContentType())
+	}
+	res.Close()
+
+	var buf bytes.Buffer
+	contentType, err := client.WriteSpeech(context.Background(), request, &buf)
+	checks.NoError(t, err, "WriteSpeech error")
+	if contentType != "audio/ogg" || !bytes.Equal(buf.Bytes(), audio) {
+		t.Errorf("unexpected audio %q of type %q", buf.Bytes(), contentType)
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.opus")
+	checks.NoError(t, client.SaveSpeech(context.Background(), request, path), "SaveSpeech error")
+	saved, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	if !bytes.Equal(saved, audio) {
+		t.Errorf("unexpected saved audio %q", saved)
+	}
+
+	for _, speed := range []float64{0.2, 4.5, -1} {
+		request.Speed = speed
+		_, err = client.CreateSpeech(context.Background(), request)
+		checks.ErrorIs(t, err, openai.ErrInvalidSpeechSpeed, "out of range speed should be rejected")
+	}
+	path = filepath.Join(t.TempDir(), "rejected.opus")
+	checks.ErrorIs(t, client.SaveSpeech(context.Background(), request, path), openai.ErrInvalidSpeechSpeed,
+		"SaveSpeech should return the request error")
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file of failed speech should be removed, got %v", err)
+	}
+}
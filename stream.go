@@ -3,13 +3,82 @@ package openai
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 )
 
 var (
 	ErrTooManyEmptyStreamMessages = errors.New("stream has sent too many empty messages")
+	// ErrStreamConnectTimeout is returned when a stream is not established within
+	// ClientConfig.StreamConnectTimeout.
+	ErrStreamConnectTimeout = errors.New("stream connect timeout")
+	// ErrStreamIdleTimeout is returned by Recv when no event is received on a
+	// stream for ClientConfig.StreamIdleTimeout.
+	ErrStreamIdleTimeout = errors.New("stream idle timeout")
+	// ErrStreamInterrupted is returned by Recv when the connection of a stream is
+	// closed before the stream ended, which is told apart from the io.EOF ending
+	// it normally. The error wraps the cause, io.ErrUnexpectedEOF for connections
+	// closed cleanly.
+	ErrStreamInterrupted = errors.New("stream interrupted")
+	// ErrStreamEventTooLarge is returned by Recv when a line or event of a stream
+	// is larger than ClientConfig.StreamMaxEventSize.
+	ErrStreamEventTooLarge = errors.New("stream event too large")
 )
 
+// defaultEventName is the name of the events without event: field.
+const defaultEventName = "message"
+
+// RawStreamEvent is an event of a stream that Recv could not decode, returned as
+// its error for streams created with WithRawStreamEvents: an event of a type the
+// stream does not have, such as the pings of some gateways, or whose data is
+// not a chunk. The stream goes on after it:
+//
+//	chunk, err := stream.Recv()
+//	var raw *openai.RawStreamEvent
+//	if errors.As(err, &raw) {
+//		log.Printf("skipped %s event: %s", raw.Event, raw.Data)
+//		continue
+//	}
+type RawStreamEvent struct {
+	// Event is the event: field of the event, empty if it had none.
+	Event string
+	Data  []byte
+	// Err is the error decoding Data, nil for events of unknown types.
+	Err error
+}
+
+func (e *RawStreamEvent) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("undecodable stream event %q: %v", e.Event, e.Err)
+	}
+	return fmt.Sprintf("unknown stream event %q", e.Event)
+}
+
+func (e *RawStreamEvent) Unwrap() error {
+	return e.Err
+}
+
+// DefaultStreamMaxEventSize is the default size limit of the events of streams,
+// well above the largest chunks sent, such as those of partial images.
+const DefaultStreamMaxEventSize = 8 << 20
+
+// streamInterruptedError is an ErrStreamInterrupted with its cause.
+type streamInterruptedError struct {
+	cause error
+}
+
+func (e *streamInterruptedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrStreamInterrupted, e.cause)
+}
+
+func (e *streamInterruptedError) Is(target error) bool {
+	return target == ErrStreamInterrupted
+}
+
+func (e *streamInterruptedError) Unwrap() error {
+	return e.cause
+}
+
 type CompletionStream struct {
 	*streamReader[CompletionResponse]
 }
@@ -48,6 +117,7 @@ func (c *Client) CreateCompletionStream(
 	if err != nil {
 		return
 	}
+	resp.choices = request.N
 	stream = &CompletionStream{
 		streamReader: resp,
 	}
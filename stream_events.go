@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrStreamEventsInUse is returned by the Recv methods of streams consumed
+// through their Events channel.
+var ErrStreamEventsInUse = errors.New("stream is consumed through Events")
+
+// streamEvents is the state of a stream consumed through Events.
+type streamEvents[T streamable] struct {
+	ch chan T
+	// done is closed after ch, once err is set.
+	done     chan struct{}
+	err      error
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func (e *streamEvents[T]) stop() {
+	e.stopOnce.Do(func() { close(e.stopped) })
+}
+
+// Events returns a channel receiving the events of the stream, as an
+// alternative to calling Recv, and closed when the stream ends. Err then
+// returns the error that ended it, if any. Events returned by Recv as a
+// *RawStreamEvent, for streams created with WithRawStreamEvents, are skipped.
+//
+// The stream is read by a goroutine only as fast as the events are received,
+// ClientConfig.StreamEventsBuffer events ahead at most, so that a slow consumer
+// slows down the server instead of the events piling up in memory. Once Events
+// is called, Recv returns ErrStreamEventsInUse; calling Events again returns
+// the same channel. Canceling the context of the stream or closing it closes
+// the channel and ends the goroutine, whether or not the channel is drained.
+//
+//	for chunk := range stream.Events() {
+//		...
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+func (stream *streamReader[T]) Events() <-chan T {
+	return stream.startEvents(stream.recvContext)
+}
+
+// Err returns the error that ended the stream consumed through Events, once
+// its channel is closed. It returns nil while the stream goes on, and after it
+// ended normally.
+func (stream *streamReader[T]) Err() error {
+	if stream.events == nil {
+		return nil
+	}
+	select {
+	case <-stream.events.done:
+		return stream.events.err
+	default:
+		return nil
+	}
+}
+
+// startEvents starts sending the events received with recv on the channel of
+// Events.
+func (stream *streamReader[T]) startEvents(recv func(context.Context) (T, error)) <-chan T {
+	if stream.events != nil {
+		return stream.events.ch
+	}
+	buffer := stream.eventsBuffer
+	if buffer < 0 {
+		buffer = 0
+	}
+	stream.events = &streamEvents[T]{
+		ch:      make(chan T, buffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go stream.sendEvents(stream.events, recv)
+	return stream.events.ch
+}
+
+func (stream *streamReader[T]) sendEvents(events *streamEvents[T], recv func(context.Context) (T, error)) {
+	defer func() {
+		close(events.ch)
+		close(events.done)
+	}()
+	var ctxDone <-chan struct{}
+	if stream.response != nil && stream.response.Request != nil {
+		ctxDone = stream.response.Request.Context().Done()
+	}
+	for {
+		// Reads end with an error when the stream is closed or its context
+		// is done.
+		event, err := recv(context.Background())
+		var raw *RawStreamEvent
+		if errors.As(err, &raw) {
+			// Events Recv could not decode have no value to send.
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				events.err = err
+			}
+			return
+		}
+		select {
+		case events.ch <- event:
+		case <-events.stopped:
+			return
+		case <-ctxDone:
+			events.err = fmt.Errorf("stream aborted: %w", stream.contextErr())
+			return
+		}
+	}
+}
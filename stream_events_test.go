@@ -0,0 +1,213 @@
+package openai_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+var chatStreamRequest = openai.ChatCompletionRequest{
+	Model:    openai.GPT4oMini,
+	Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+}
+
+func chatStreamChunk(content string) string {
+	return `data: {"id":"1","choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"
+}
+
+func TestChatCompletionStreamEvents(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chatStreamChunk("a") + chatStreamChunk("b") + chatStreamChunk("c") + "data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	events := stream.Events()
+	if stream.Events() != events {
+		t.Error("Events returned another channel")
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, openai.ErrStreamEventsInUse, "Recv should fail once Events is used")
+
+	var content string
+	for chunk := range events {
+		content += chunk.Choices[0].Delta.Content
+	}
+	if content != "abc" {
+		t.Errorf("received %q, want abc", content)
+	}
+	checks.NoError(t, stream.Err(), "stream ended with an error")
+}
+
+func TestChatCompletionStreamEventsSkipsRawEvents(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chatStreamChunk("a") + "event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+			chatStreamChunk("b") + "data: [DONE]\n\n"))
+	})
+
+	stream, err := client.WithOptions(openai.WithRawStreamEvents()).
+		CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var content string
+	for chunk := range stream.Events() {
+		content += chunk.Choices[0].Delta.Content
+	}
+	if content != "ab" {
+		t.Errorf("received %q, want ab", content)
+	}
+	checks.NoError(t, stream.Err(), "the raw event should not end the stream")
+}
+
+func TestChatCompletionStreamEventsError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chatStreamChunk("a") +
+			`data: {"error":{"message":"Rate limit reached","code":"rate_limit_exceeded"}}` + "\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	received := 0
+	for range stream.Events() {
+		received++
+	}
+	if received != 1 {
+		t.Errorf("received %d events, want 1", received)
+	}
+	if !openai.IsRateLimit(stream.Err()) {
+		t.Errorf("expected the rate limit error after the channel closed, got %v", stream.Err())
+	}
+}
+
+// endlessStream is the body of a stream that never ends, sending a chunk per
+// read.
+type endlessStream struct {
+	reads int32
+}
+
+func (s *endlessStream) Read(p []byte) (int, error) {
+	n := atomic.AddInt32(&s.reads, 1)
+	return copy(p, chatStreamChunk(fmt.Sprint(n))), nil
+}
+
+type endlessStreamDoer struct {
+	body *endlessStream
+}
+
+func (d endlessStreamDoer) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/event-stream"}},
+		Body:       io.NopCloser(d.body),
+		Request:    req,
+	}, nil
+}
+
+func TestChatCompletionStreamEventsBackpressure(t *testing.T) {
+	body := &endlessStream{}
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.HTTPClient = endlessStreamDoer{body: body}
+	config.StreamEventsBuffer = 4
+	client := openai.NewClientWithConfig(config)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	events := stream.Events()
+	time.Sleep(50 * time.Millisecond)
+	// The buffer, the event being sent and the one being read.
+	if reads := atomic.LoadInt32(&body.reads); reads > 4+2 {
+		t.Errorf("read %d chunks without consumer, want at most 6", reads)
+	}
+
+	<-events
+	checks.NoError(t, stream.Close(), "Close error")
+	for range events {
+	}
+}
+
+func TestChatCompletionStreamEventsCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	client, server, teardown := setupOpenAITestServer()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte(chatStreamChunk(strings.Repeat("a", i))))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.CreateChatCompletionStream(ctx, chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	events := stream.Events()
+	<-events
+	// The consumer stops receiving, and the stream is canceled.
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for stream.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	checks.ErrorIs(t, stream.Err(), context.Canceled, "stream should end with the error of its context")
+	if _, ok := <-events; ok {
+		t.Error("events channel not closed after the cancellation")
+	}
+	stream.Close()
+	teardown()
+
+	deadline = time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("%d goroutines leaked:\n%s", after-before, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+func TestChatCompletionStreamEventsErrBeforeEnd(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(chatStreamChunk("a")))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	events := stream.Events()
+	<-events
+	if err = stream.Err(); err != nil {
+		t.Errorf("Err returned %v while the stream goes on", err)
+	}
+	stream.Close()
+	for range events {
+	}
+}
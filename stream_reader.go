@@ -3,101 +3,465 @@ package openai
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
-var (
-	headerData  = regexp.MustCompile(`^data:\s*`)
-	errorPrefix = regexp.MustCompile(`^data:\s*{"error":`)
-)
-
 type streamable interface {
-	ChatCompletionStreamResponse | CompletionResponse
+	ChatCompletionStreamResponse | CompletionResponse | TranscriptionStreamEvent | ImageStreamEvent |
+		FineTuningJobEvent | AssistantStreamEvent | ResponseStreamEvent
+}
+
+// streamRead is the result of reading an event of a stream.
+type streamRead struct {
+	data []byte
+	err  error
 }
 
 type streamReader[T streamable] struct {
 	emptyMessagesLimit uint
+	emptyMessagesCount uint
+	maxEventSize       int
 	isFinished         bool
 
 	reader         *bufio.Reader
 	response       *http.Response
 	errAccumulator utils.ErrorAccumulator
 	unmarshaler    utils.Unmarshaler
+	tracker        *callTracker
+	cancel         context.CancelFunc
+	usage          *Usage
+	fingerprint    string
+	keepRawJSON    bool
+	rawEvents      bool
+	// event is the event: field of the last event returned, for streams that
+	// name their events.
+	event string
+	// lines are the lines read but not processed yet, and readErr the error
+	// that ended the stream after them, with its unterminated last line.
+	lines    [][]byte
+	readErr  error
+	lastLine []byte
+	// pending receives the result of the read a RecvContext call stopped
+	// waiting for.
+	pending chan streamRead
+	// events is set once the stream is consumed through Events.
+	events       *streamEvents[T]
+	eventsBuffer int
+	// completed tells that the last event of the stream was received, so that
+	// the connection closing afterwards is its normal end even without [DONE].
+	completed bool
+	// choices is the number of choices requested, which must all finish for
+	// the stream to be completed, and finished holds the indices of those
+	// that did.
+	choices  int
+	finished map[int]bool
+	// accumulator accumulates the chunks of chat completion streams.
+	accumulator *ChatCompletionStreamAccumulator
 
 	httpHeader
 }
 
 func (stream *streamReader[T]) Recv() (response T, err error) {
-	rawLine, err := stream.RecvRaw()
+	return stream.RecvContext(context.Background())
+}
+
+// RecvContext is Recv returning early with the error of ctx when it is done
+// before the next event arrives. The stream is left as is: the event being
+// received is returned by the next call to Recv or RecvContext, so that reads
+// can time out without losing the stream. Until then, the stream must not be
+// used but to Close it, which ends the read.
+//
+// The context the stream was created with still bounds the whole stream: when
+// it is done, Recv returns an error wrapping its error and the stream ends.
+func (stream *streamReader[T]) RecvContext(ctx context.Context) (response T, err error) {
+	if stream.events != nil {
+		return response, ErrStreamEventsInUse
+	}
+	return stream.recvContext(ctx)
+}
+
+func (stream *streamReader[T]) recvContext(ctx context.Context) (response T, err error) {
+	rawLine, err := stream.recvRawContext(ctx)
 	if err != nil {
 		return
 	}
+	if stream.rawEvents && stream.event != "" && stream.event != defaultEventName {
+		// The chunks of these streams are not named.
+		return response, &RawStreamEvent{Event: stream.event, Data: rawLine}
+	}
 
 	err = stream.unmarshaler.Unmarshal(rawLine, &response)
 	if err != nil {
+		if stream.rawEvents {
+			return response, &RawStreamEvent{Event: stream.event, Data: rawLine, Err: err}
+		}
 		return
 	}
+	stream.inspect(&response)
+	if raw, ok := any(&response).(rawJSONSetter); ok && stream.keepRawJSON {
+		raw.setRawJSON(rawLine)
+	}
 	return response, nil
 }
 
+// GetUsage returns the token usage of the request, received in the last chunk of
+// streams created with StreamOptions.IncludeUsage. That chunk has no choices and
+// usually follows the one with the finish reason, so the usage is only known once
+// Recv has returned io.EOF; it is nil until then, and for streams without it.
+// Chunks read with RecvRaw are not inspected.
+func (stream *streamReader[T]) GetUsage() *Usage {
+	return stream.usage
+}
+
+// GetSystemFingerprint returns the system fingerprint of the last chunk received
+// by Recv that had one. It is sent with every chunk, so comparing it to the one
+// of earlier responses tells when the backend configuration changed, for example
+// in the middle of a conversation using Seed.
+func (stream *streamReader[T]) GetSystemFingerprint() string {
+	return stream.fingerprint
+}
+
+// inspect records the fields of a received chunk that are exposed on the stream.
+func (stream *streamReader[T]) inspect(response any) {
+	var (
+		usage       *Usage
+		fingerprint string
+	)
+	switch r := response.(type) {
+	case *ChatCompletionStreamResponse:
+		usage, fingerprint = r.Usage, r.SystemFingerprint
+		for _, choice := range r.Choices {
+			if choice.FinishReason != "" {
+				stream.finishChoice(choice.Index)
+			}
+		}
+		if stream.accumulator != nil {
+			stream.accumulator.Add(*r)
+		}
+	case *CompletionResponse:
+		usage, fingerprint = r.Usage, r.SystemFingerprint
+		for _, choice := range r.Choices {
+			if choice.FinishReason != "" {
+				stream.finishChoice(choice.Index)
+			}
+		}
+	case *TranscriptionStreamEvent:
+		stream.completed = r.Type == TranscriptionStreamEventDone
+	case *ImageStreamEvent:
+		stream.completed = r.Type == ImageStreamEventCompleted
+	case *FineTuningJobEvent:
+		// Fine-tuning events have no last event: the stream ends when the
+		// server closes it.
+		stream.completed = true
+	}
+	if usage != nil {
+		stream.usage = usage
+	}
+	if fingerprint != "" {
+		stream.fingerprint = fingerprint
+	}
+}
+
+// finishChoice records that the choice with the given index finished, the
+// stream being completed once all the choices requested did.
+func (stream *streamReader[T]) finishChoice(index int) {
+	if stream.finished == nil {
+		stream.finished = make(map[int]bool)
+	}
+	stream.finished[index] = true
+	stream.completed = len(stream.finished) >= stream.choices
+}
+
 func (stream *streamReader[T]) RecvRaw() ([]byte, error) {
+	if stream.events != nil {
+		return nil, ErrStreamEventsInUse
+	}
+	return stream.recvRawContext(context.Background())
+}
+
+// recvRawContext is RecvRaw returning early with the error of ctx when it is
+// done first. The read then goes on in the background, and the next call
+// returns its result.
+func (stream *streamReader[T]) recvRawContext(ctx context.Context) ([]byte, error) {
+	if stream.pending == nil {
+		if ctx.Done() == nil {
+			return stream.recvRaw()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pending := make(chan streamRead, 1)
+		go func() {
+			data, err := stream.recvRaw()
+			pending <- streamRead{data: data, err: err}
+		}()
+		stream.pending = pending
+	}
+	select {
+	case read := <-stream.pending:
+		stream.pending = nil
+		return read.data, read.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (stream *streamReader[T]) recvRaw() ([]byte, error) {
 	if stream.isFinished {
 		return nil, io.EOF
 	}
 
-	return stream.processLines()
+	line, err := stream.processLines()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			stream.tracker.finish(nil, nil)
+			reportStreamUsage(stream.response, stream.usage)
+		} else {
+			stream.tracker.finish(nil, err)
+		}
+		return nil, err
+	}
+	stream.tracker.event(line)
+	return line, nil
 }
 
-//nolint:gocognit
+// processLines reads the next event of the stream and returns its data. Events
+// follow the server-sent events format: their data: lines are joined with
+// newlines, their event: field names them, and they end with a blank line.
+// Comments are ignored and lines may end with \n, \r\n or \r. The other lines,
+// such as those of an error sent as plain JSON instead of events, are
+// accumulated to be decoded as an error when the stream ends.
 func (stream *streamReader[T]) processLines() ([]byte, error) {
 	var (
-		emptyMessagesCount uint
-		hasErrorPrefix     bool
+		data    []byte
+		hasData bool
+		event   string
 	)
-
 	for {
-		rawLine, readErr := stream.reader.ReadBytes('\n')
-		if readErr != nil || hasErrorPrefix {
-			respErr := stream.unmarshalError()
-			if respErr != nil {
-				return nil, fmt.Errorf("error, %w", respErr.Error)
-			}
+		line, readErr := stream.readLine()
+		if errors.Is(readErr, ErrStreamEventTooLarge) {
 			return nil, readErr
 		}
-
-		noSpaceLine := bytes.TrimSpace(rawLine)
-		if errorPrefix.Match(noSpaceLine) {
-			hasErrorPrefix = true
+		if readErr != nil {
+			return stream.processLastLine(line, data, hasData, event, readErr)
+		}
+		line = bytes.TrimLeft(line, " \t")
+		if len(line) == 0 && hasData {
+			stream.emptyMessagesCount++
+			return stream.dispatch(event, data)
+		}
+		if len(line) > 0 && line[0] == ':' {
+			// A comment, such as the keepalives of proxies.
+			continue
 		}
-		if !headerData.Match(noSpaceLine) || hasErrorPrefix {
-			if hasErrorPrefix {
-				noSpaceLine = headerData.ReplaceAll(noSpaceLine, nil)
+
+		field, value := parseField(line)
+		switch field {
+		case "data":
+			stream.emptyMessagesCount = 0
+			if hasData {
+				data = append(data, '\n')
+			}
+			data, hasData = append(data, value...), true
+			if len(data) > stream.maxSize() {
+				stream.isFinished = true
+				return nil, fmt.Errorf("%w: over %d bytes", ErrStreamEventTooLarge, stream.maxSize())
 			}
-			writeErr := stream.errAccumulator.Write(noSpaceLine)
-			if writeErr != nil {
-				return nil, writeErr
+			continue
+		case "event":
+			event = string(value)
+		case "id", "retry":
+		default:
+			if field == "" {
+				// A blank line ending an event without data.
+				event = ""
 			}
-			emptyMessagesCount++
-			if emptyMessagesCount > stream.emptyMessagesLimit {
-				return nil, ErrTooManyEmptyStreamMessages
+			if err := stream.errAccumulator.Write(line); err != nil {
+				return nil, err
 			}
+		}
+		stream.emptyMessagesCount++
+		if stream.emptyMessagesCount > stream.emptyMessagesLimit {
+			return nil, ErrTooManyEmptyStreamMessages
+		}
+	}
+}
 
-			continue
+// processLastLine ends the stream that failed with readErr after the
+// unterminated line, which completes the data of the pending event if any. The
+// event is returned first unless the connection closed in the middle of it, as
+// told by its data being cut, and readErr on the next call.
+func (stream *streamReader[T]) processLastLine(
+	line, data []byte,
+	hasData bool,
+	event string,
+	readErr error,
+) ([]byte, error) {
+	truncated := false
+	if line = bytes.TrimLeft(line, " \t"); len(line) > 0 && line[0] != ':' {
+		field, value := parseField(line)
+		switch field {
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data, hasData, truncated = append(data, value...), true, true
+		case "event", "id", "retry":
+		default:
+			_ = stream.errAccumulator.Write(line)
 		}
+	}
+	if hasData && (!truncated || isDone(data) || json.Valid(data)) {
+		return stream.dispatch(event, data)
+	}
+
+	if respErr := stream.unmarshalError(); respErr != nil && respErr.Error != nil {
+		stream.isFinished = true
+		respErr.Error.httpHeader = stream.httpHeader
+		return nil, fmt.Errorf("error, %w", respErr.Error)
+	}
+	return nil, stream.interrupted(readErr)
+}
+
+// dispatch returns the data of an event, io.EOF for the [DONE] event ending the
+// stream, or the error the event reports.
+func (stream *streamReader[T]) dispatch(event string, data []byte) ([]byte, error) {
+	stream.event = event
+	if isDone(data) {
+		stream.isFinished = true
+		return nil, io.EOF
+	}
+	if apiErr := decodeStreamError(data); apiErr != nil {
+		stream.isFinished = true
+		apiErr.httpHeader = stream.httpHeader
+		return nil, fmt.Errorf("error, %w", apiErr)
+	}
+	return data, nil
+}
+
+// isDone tells whether data is the [DONE] sentinel ending streams, whatever its
+// case.
+func isDone(data []byte) bool {
+	return bytes.EqualFold(bytes.TrimSpace(data), []byte("[DONE]"))
+}
+
+// parseField splits a line into its field name and value, without the space
+// that usually follows the colon. Lines without a colon are field names.
+func parseField(line []byte) (field string, value []byte) {
+	i := bytes.IndexByte(line, ':')
+	if i < 0 {
+		return string(line), nil
+	}
+	value = line[i+1:]
+	if len(value) > 0 && value[0] == ' ' {
+		value = value[1:]
+	}
+	return string(line[:i]), value
+}
 
-		noPrefixLine := headerData.ReplaceAll(noSpaceLine, nil)
-		if string(noPrefixLine) == "[DONE]" {
+// readLine returns the next line of the stream, without its line ending. When
+// the stream ends, it returns its unterminated last line, possibly empty, with
+// the error that ended it.
+func (stream *streamReader[T]) readLine() ([]byte, error) {
+	if len(stream.lines) == 0 && stream.readErr == nil {
+		chunk, err := stream.readChunk()
+		if errors.Is(err, ErrStreamEventTooLarge) {
 			stream.isFinished = true
-			return nil, io.EOF
+			return nil, err
 		}
+		stream.readErr = err
+		if err == nil {
+			chunk = bytes.TrimSuffix(bytes.TrimSuffix(chunk, []byte{'\n'}), []byte{'\r'})
+		}
+		// A lone \r ends a line too.
+		stream.lines = bytes.Split(chunk, []byte{'\r'})
+		if stream.readErr != nil {
+			stream.lastLine = stream.lines[len(stream.lines)-1]
+			stream.lines = stream.lines[:len(stream.lines)-1]
+		}
+	}
+	if len(stream.lines) > 0 {
+		line := stream.lines[0]
+		stream.lines = stream.lines[1:]
+		return line, nil
+	}
+	line := stream.lastLine
+	stream.lastLine = nil
+	return line, stream.readErr
+}
+
+// readChunk reads the stream up to the next \n, growing its buffer up to the
+// maximum size of events.
+func (stream *streamReader[T]) readChunk() ([]byte, error) {
+	var chunk []byte
+	for {
+		slice, err := stream.reader.ReadSlice('\n')
+		if len(chunk)+len(slice) > stream.maxSize() {
+			return nil, fmt.Errorf("%w: line over %d bytes", ErrStreamEventTooLarge, stream.maxSize())
+		}
+		chunk = append(chunk, slice...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return chunk, err
+		}
+	}
+}
+
+func (stream *streamReader[T]) maxSize() int {
+	if stream.maxEventSize > 0 {
+		return stream.maxEventSize
+	}
+	return DefaultStreamMaxEventSize
+}
+
+// decodeStreamError returns the error of an event whose data is an object with
+// an error, such as those sent when a stream fails after it started, or nil.
+func decodeStreamError(data []byte) *APIError {
+	if !bytes.Contains(data, []byte(`"error"`)) {
+		return nil
+	}
+	var event struct {
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(data, &event) != nil || event.Error == nil || event.Error.Message == "" {
+		return nil
+	}
+	return event.Error
+}
+
+// interrupted returns the error for the connection of the stream failing with
+// err: io.EOF if the stream had ended, an error wrapping that of the context of
+// the stream if it was done, and an ErrStreamInterrupted otherwise. Idle
+// timeouts are returned as they are.
+func (stream *streamReader[T]) interrupted(err error) error {
+	switch {
+	case errors.Is(err, io.EOF) && stream.completed:
+		return err
+	case stream.contextErr() != nil:
+		stream.isFinished = true
+		return fmt.Errorf("stream aborted: %w", stream.contextErr())
+	case errors.Is(err, ErrStreamIdleTimeout), errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, io.EOF):
+		err = io.ErrUnexpectedEOF
+	}
+	stream.isFinished = true
+	return &streamInterruptedError{cause: err}
+}
 
-		return noPrefixLine, nil
+// contextErr returns the error of the context of the request of the stream.
+func (stream *streamReader[T]) contextErr() error {
+	if stream.response == nil || stream.response.Request == nil {
+		return nil
 	}
+	return stream.response.Request.Context().Err()
 }
 
 func (stream *streamReader[T]) unmarshalError() (errResp *ErrorResponse) {
@@ -115,5 +479,13 @@ func (stream *streamReader[T]) unmarshalError() (errResp *ErrorResponse) {
 }
 
 func (stream *streamReader[T]) Close() error {
-	return stream.response.Body.Close()
+	if stream.events != nil {
+		stream.events.stop()
+	}
+	stream.tracker.finish(nil, nil)
+	err := stream.response.Body.Close()
+	if stream.cancel != nil {
+		stream.cancel()
+	}
+	return err
 }
@@ -4,6 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	utils "github.com/sashabaranov/go-openai/internal"
@@ -76,3 +80,178 @@ func TestStreamReaderRecvRaw(t *testing.T) {
 		t.Fatalf("Did not return raw line: %v", string(rawLine))
 	}
 }
+
+// sseEvent is an event read from a stream.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readSSEEvents returns the events of a stream, until the error that ended it.
+func readSSEEvents(body string, maxEventSize int) ([]sseEvent, error) {
+	stream := &streamReader[ChatCompletionStreamResponse]{
+		emptyMessagesLimit: defaultEmptyMessagesLimit,
+		maxEventSize:       maxEventSize,
+		reader:             bufio.NewReader(strings.NewReader(body)),
+		errAccumulator:     utils.NewErrorAccumulator(),
+		unmarshaler:        &utils.JSONUnmarshaler{},
+	}
+	var events []sseEvent
+	for {
+		data, err := stream.RecvRaw()
+		if err != nil {
+			return events, err
+		}
+		events = append(events, sseEvent{name: stream.event, data: string(data)})
+	}
+}
+
+func TestStreamReaderServerSentEvents(t *testing.T) {
+	largeImage := strings.Repeat("iVBORw0KGgo", 100000)
+	testCases := []struct {
+		name         string
+		body         string
+		maxEventSize int
+		want         []sseEvent
+		wantErr      error
+	}{
+		{
+			name: "openai",
+			body: "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n" +
+				"data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+				"data: [DONE]\n\n",
+			want: []sseEvent{
+				{data: `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}`},
+				{data: `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`},
+			},
+			wantErr: io.EOF,
+		},
+		{
+			name: "openai responses",
+			body: "event: response.created\ndata: {\"type\":\"response.created\",\"sequence_number\":0}\n\n" +
+				"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"Hi\"}\n\n",
+			want: []sseEvent{
+				{name: "response.created", data: `{"type":"response.created","sequence_number":0}`},
+				{name: "response.output_text.delta", data: `{"type":"response.output_text.delta","delta":"Hi"}`},
+			},
+			wantErr: ErrStreamInterrupted,
+		},
+		{
+			name: "azure",
+			body: "data: {\"choices\":[],\"created\":0,\"id\":\"\",\"model\":\"\",\"object\":\"\"," +
+				"\"prompt_filter_results\":[{\"prompt_index\":0,\"content_filter_results\":{}}]}\r\n\r\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"},\"index\":0}],\"id\":\"chatcmpl-1\"}\r\n\r\n" +
+				"data: [DONE]\r\n\r\n",
+			want: []sseEvent{
+				{data: `{"choices":[],"created":0,"id":"","model":"","object":"",` +
+					`"prompt_filter_results":[{"prompt_index":0,"content_filter_results":{}}]}`},
+				{data: `{"choices":[{"delta":{"content":"Hi"},"index":0}],"id":"chatcmpl-1"}`},
+			},
+			wantErr: io.EOF,
+		},
+		{
+			name: "openrouter processing comments",
+			body: ": OPENROUTER PROCESSING\n\n: OPENROUTER PROCESSING\n\n" +
+				"data: {\"id\":\"gen-1\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n",
+			want:    []sseEvent{{data: `{"id":"gen-1","choices":[{"delta":{"content":"Hi"}}]}`}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "vllm without space after the colon",
+			body:    "data:{\"id\":\"cmpl-1\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata:[DONE]\n\n",
+			want:    []sseEvent{{data: `{"id":"cmpl-1","choices":[{"delta":{"content":"Hi"}}]}`}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "id and retry fields",
+			body:    "retry: 3000\nid: 1\nevent: message\ndata: {}\n\ndata: [DONE]\n\n",
+			want:    []sseEvent{{name: "message", data: `{}`}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "multi-line data",
+			body:    "event: thread.message.delta\ndata: {\ndata:   \"id\": \"msg_1\"\ndata: }\n\ndata: [DONE]\n\n",
+			want:    []sseEvent{{name: "thread.message.delta", data: "{\n  \"id\": \"msg_1\"\n}"}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "carriage returns alone",
+			body:    "event: a\rdata: 1\r\rdata: 2\r\rdata: [DONE]\r\r",
+			want:    []sseEvent{{name: "a", data: "1"}, {data: "2"}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "event name reset after events",
+			body:    "event: a\n\ndata: 1\n\ndata: [DONE]\n\n",
+			want:    []sseEvent{{data: "1"}},
+			wantErr: io.EOF,
+		},
+		{
+			name:    "event larger than the read buffer",
+			body:    "event: image_generation.partial_image\ndata: {\"b64_json\":\"" + largeImage + "\"}\n\n",
+			want:    []sseEvent{{name: "image_generation.partial_image", data: `{"b64_json":"` + largeImage + `"}`}},
+			wantErr: ErrStreamInterrupted,
+		},
+		{
+			name:         "line larger than the maximum",
+			body:         "data: {}\n\ndata: " + strings.Repeat("a", 100) + "\n\n",
+			maxEventSize: 64,
+			want:         []sseEvent{{data: `{}`}},
+			wantErr:      ErrStreamEventTooLarge,
+		},
+		{
+			name:         "event larger than the maximum",
+			body:         strings.Repeat("data: "+strings.Repeat("a", 30)+"\n", 3) + "\n",
+			maxEventSize: 64,
+			wantErr:      ErrStreamEventTooLarge,
+		},
+		{
+			name: "error sent as plain JSON",
+			body: "{\r\n  \"error\": {\r\n    \"message\": \"Invalid API key\",\r\n" +
+				"    \"code\": \"invalid_api_key\"\r\n  }\r\n}\r\n",
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "plain JSON without error",
+			body:    "{} ",
+			wantErr: ErrStreamInterrupted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := readSSEEvents(tc.body, tc.maxEventSize)
+			checks.ErrorIs(t, err, tc.wantErr, "unexpected stream error")
+			if !reflect.DeepEqual(events, tc.want) {
+				t.Errorf("read events %q, want %q", events, tc.want)
+			}
+		})
+	}
+}
+
+func FuzzStreamReader(f *testing.F) {
+	f.Add("data: {\"a\":1}\n\ndata: [DONE]\n\n")
+	f.Add("event: response.created\ndata: {}\n\n: keepalive\n\ndata: 1\ndata: 2\n\n")
+	f.Add("data: {\"error\":{\"message\":\"failed\"}}\n\n")
+	f.Add("{\n\"error\": {\"message\": \"failed\"}\n}\n")
+	f.Add("id: 1\nretry: 10\ndata\n\ndata:\n\n")
+	f.Add("{} ")
+	f.Fuzz(func(t *testing.T, body string) {
+		events, err := readSSEEvents(body, 1<<10)
+		for _, event := range events {
+			if len(event.data) > 1<<10 {
+				t.Fatalf("read an event of %d bytes over the maximum", len(event.data))
+			}
+		}
+		if strings.Contains(body, "\r") || errors.Is(err, ErrStreamEventTooLarge) {
+			return
+		}
+		// Streams are read the same whatever their line endings.
+		crlfEvents, crlfErr := readSSEEvents(strings.ReplaceAll(body, "\n", "\r\n"), 1<<10)
+		if errors.Is(crlfErr, ErrStreamEventTooLarge) {
+			return
+		}
+		if !reflect.DeepEqual(events, crlfEvents) || fmt.Sprint(err) != fmt.Sprint(crlfErr) {
+			t.Errorf("read %q, %v with \\n and %q, %v with \\r\\n", events, err, crlfEvents, crlfErr)
+		}
+	})
+}
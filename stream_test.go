@@ -348,3 +348,56 @@ func compareResponseChoices(c1, c2 openai.CompletionChoice) bool {
 	}
 	return true
 }
+
+func TestCreateCompletionStreamUsage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		//nolint:lll
+		_, _ = w.Write([]byte(`data: {"id":"1","object":"text_completion","created":1598069254,"model":"gpt-3.5-turbo-instruct","choices":[{"text":"response1","index":0,"finish_reason":null}],"usage":null}
+
+data: {"id":"1","object":"text_completion","created":1598069254,"model":"gpt-3.5-turbo-instruct","choices":[{"text":"","index":0,"finish_reason":"length"}],"usage":null}
+
+data: {"id":"1","object":"text_completion","created":1598069254,"model":"gpt-3.5-turbo-instruct","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":10,"total_tokens":15},"system_fingerprint":"fp_1"}
+
+data: [DONE]
+
+`))
+	})
+
+	stream, err := client.CreateCompletionStream(context.Background(), openai.CompletionRequest{
+		Prompt:        "Ex falso quodlibet",
+		Model:         "gpt-3.5-turbo-instruct",
+		MaxTokens:     10,
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateCompletionStream returned error")
+	defer stream.Close()
+
+	var chunks int
+	for {
+		resp, streamErr := stream.Recv()
+		if errors.Is(streamErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, streamErr, "stream.Recv() failed")
+		chunks++
+		if chunks < 3 && stream.GetUsage() != nil {
+			t.Errorf("unexpected usage before the usage chunk: %+v", stream.GetUsage())
+		}
+		if chunks == 3 && len(resp.Choices) != 0 {
+			t.Errorf("unexpected choices in the usage chunk: %+v", resp.Choices)
+		}
+	}
+	if chunks != 3 {
+		t.Fatalf("expected 3 chunks, got %d", chunks)
+	}
+	usage := stream.GetUsage()
+	if usage == nil || usage.PromptTokens != 5 || usage.CompletionTokens != 10 || usage.TotalTokens != 15 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if stream.GetSystemFingerprint() != "fp_1" {
+		t.Errorf("unexpected system fingerprint %q", stream.GetSystemFingerprint())
+	}
+}
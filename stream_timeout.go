@@ -0,0 +1,127 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// withStreamConnectTimeout makes req fail if its response headers are not
+// received within ClientConfig.StreamConnectTimeout. stop must be called once
+// the response is received and reports whether the timeout expired first;
+// cancel releases the request context once the stream is done with.
+func (c *Client) withStreamConnectTimeout(req *http.Request) (
+	_ *http.Request,
+	stop func() bool,
+	cancel context.CancelFunc,
+) {
+	timeout := c.config.StreamConnectTimeout
+	if timeout <= 0 {
+		return req, func() bool { return false }, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	var expired int32
+	timer := time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&expired, 1)
+		cancel()
+	})
+	stop = func() bool {
+		timer.Stop()
+		return atomic.LoadInt32(&expired) == 1
+	}
+	return req.WithContext(ctx), stop, cancel
+}
+
+// idleTimeoutBody closes a stream body when a read waits longer than timeout for
+// the end of the next event. Only time spent inside Read counts, so a caller that
+// is slow to call Recv does not trip the timeout, and the timeout restarts at the
+// end of each event, so that a connection trickling bytes without completing
+// events still times out.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+
+	mu sync.Mutex
+	// remaining is the time left until the timeout, counted from the end of
+	// the last event.
+	remaining time.Duration
+	// generation identifies the read in progress, whose timer alone may close
+	// the body: a timer firing as its read returns is ignored.
+	generation uint64
+	expired    bool
+	// lineStart tells that the last byte read ended a line, so that a newline
+	// following it ends an event.
+	lineStart bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	return &idleTimeoutBody{ReadCloser: body, timeout: timeout, remaining: timeout}
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	if b.expired {
+		b.mu.Unlock()
+		return 0, ErrStreamIdleTimeout
+	}
+	b.generation++
+	generation, start := b.generation, time.Now()
+	timer := time.AfterFunc(b.remaining, func() { b.expire(generation) })
+	b.mu.Unlock()
+
+	n, err := b.ReadCloser.Read(p)
+	timer.Stop()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.generation++
+	if err != nil && b.expired {
+		return n, ErrStreamIdleTimeout
+	}
+	if b.endsEvent(p[:n]) {
+		b.remaining = b.timeout
+	} else {
+		b.remaining -= time.Since(start)
+	}
+	return n, err
+}
+
+// expire closes the body if the read of generation is still in progress.
+func (b *idleTimeoutBody) expire(generation uint64) {
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	b.expired = true
+	b.mu.Unlock()
+	b.ReadCloser.Close()
+}
+
+// endsEvent reports whether data holds the blank line ending an event, which
+// may start in the data read before.
+func (b *idleTimeoutBody) endsEvent(data []byte) bool {
+	ended := false
+	for _, c := range data {
+		switch c {
+		case '\n':
+			ended = ended || b.lineStart
+			b.lineStart = true
+		case '\r':
+		default:
+			b.lineStart = false
+		}
+	}
+	return ended
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.mu.Lock()
+	b.generation++
+	b.mu.Unlock()
+	return b.ReadCloser.Close()
+}
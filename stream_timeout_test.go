@@ -0,0 +1,140 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const timeoutTestEvent = `data: {"id":"1","choices":[{"index":0,"delta":{"content":"a"}}]}` + "\n\n"
+
+// stall blocks until the client goes away or the test would have failed anyway.
+// The request body is read first, so that the server notices the client leaving.
+func stall(r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string) {
+	_, _ = w.Write([]byte(event))
+	w.(http.Flusher).Flush()
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.StreamIdleTimeout = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, timeoutTestEvent)
+		stall(r)
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	checks.NoError(t, err, "first event should be received")
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, openai.ErrStreamIdleTimeout, "stalled stream should time out")
+}
+
+func TestStreamIdleTimeoutIgnoresSlowCaller(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.StreamIdleTimeout = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			writeEvent(w, timeoutTestEvent)
+			time.Sleep(20 * time.Millisecond)
+		}
+		writeEvent(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	for i := 0; i < 3; i++ {
+		// Pausing between calls does not count towards the idle timeout.
+		time.Sleep(80 * time.Millisecond)
+		_, err = stream.Recv()
+		checks.NoError(t, err, "slowly generated events should be received")
+	}
+}
+
+func TestStreamIdleTimeoutTricklingEvent(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.StreamIdleTimeout = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Bytes keep coming, but the event never ends.
+		for _, c := range timeoutTestEvent[:len(timeoutTestEvent)-2] {
+			writeEvent(w, string(c))
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, openai.ErrStreamIdleTimeout, "an event that never ends should time out")
+}
+
+func TestStreamConnectTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.StreamConnectTimeout = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(_ http.ResponseWriter, r *http.Request) {
+		stall(r)
+	})
+
+	_, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{})
+	if !errors.Is(err, openai.ErrStreamConnectTimeout) {
+		t.Fatalf("expected ErrStreamConnectTimeout, got %v", err)
+	}
+}
+
+func TestStreamConnectTimeoutDoesNotLimitStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *openai.ClientConfig) {
+		c.StreamConnectTimeout = 50 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, timeoutTestEvent)
+		time.Sleep(100 * time.Millisecond)
+		writeEvent(w, timeoutTestEvent)
+		writeEvent(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for i := 0; i < 2; i++ {
+		_, err = stream.Recv()
+		checks.NoError(t, err, "stream should outlive the connect timeout")
+	}
+}
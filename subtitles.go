@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SubtitleCue is a cue of an SRT or WebVTT transcript: a piece of text and the
+// time range during which it is shown.
+type SubtitleCue struct {
+	// Index is the SRT sequence number of the cue, or its position in the file
+	// when it has no numeric identifier.
+	Index int
+	Start time.Duration
+	End   time.Duration
+	// Text is the text of the cue, with its lines separated by "\n".
+	Text string
+}
+
+// ParseSubtitles parses an SRT or WebVTT transcript into its cues. The WEBVTT
+// header and the NOTE, STYLE and REGION blocks are ignored, and timestamps may
+// omit the hours. Malformed cues are skipped, with a warning describing each of
+// them.
+func ParseSubtitles(data string) (cues []SubtitleCue, warnings []string) {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for start := 0; start < len(lines); {
+		if strings.TrimSpace(lines[start]) == "" {
+			start++
+			continue
+		}
+		end := start
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		block := lines[start:end]
+		if !isSubtitleMetadataBlock(block[0]) {
+			cue, err := parseSubtitleCue(block, len(cues)+1)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: %v", start+1, err))
+			} else {
+				cues = append(cues, cue)
+			}
+		}
+		start = end
+	}
+	return cues, warnings
+}
+
+func isSubtitleMetadataBlock(firstLine string) bool {
+	for _, keyword := range []string{"WEBVTT", "NOTE", "STYLE", "REGION"} {
+		if firstLine == keyword || strings.HasPrefix(firstLine, keyword+" ") ||
+			strings.HasPrefix(firstLine, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSubtitleCue parses the lines of a cue block: an optional identifier, the
+// timing line and the text.
+func parseSubtitleCue(block []string, position int) (SubtitleCue, error) {
+	cue := SubtitleCue{Index: position}
+	if !strings.Contains(block[0], "-->") {
+		if index, err := strconv.Atoi(strings.TrimSpace(block[0])); err == nil {
+			cue.Index = index
+		}
+		block = block[1:]
+	}
+	if len(block) == 0 || !strings.Contains(block[0], "-->") {
+		return SubtitleCue{}, fmt.Errorf("cue has no timing line")
+	}
+
+	timing := strings.SplitN(block[0], "-->", 2)
+	var err error
+	if cue.Start, err = parseSubtitleTimestamp(timing[0]); err != nil {
+		return SubtitleCue{}, err
+	}
+	// WebVTT cue settings may follow the end timestamp.
+	endFields := strings.Fields(timing[1])
+	if len(endFields) == 0 {
+		return SubtitleCue{}, fmt.Errorf("cue has no end timestamp")
+	}
+	if cue.End, err = parseSubtitleTimestamp(endFields[0]); err != nil {
+		return SubtitleCue{}, err
+	}
+	if cue.End < cue.Start {
+		return SubtitleCue{}, fmt.Errorf("cue ends before it starts")
+	}
+	cue.Text = strings.Join(block[1:], "\n")
+	return cue, nil
+}
+
+// parseSubtitleTimestamp parses a [hh:]mm:ss,mmm (SRT) or [hh:]mm:ss.mmm (WebVTT)
+// timestamp.
+func parseSubtitleTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	clock, millis, ok := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	if !ok || len(millis) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	parts = append(parts, millis)
+
+	var values [4]int
+	offset := 4 - len(parts)
+	for i, part := range parts {
+		pos := offset + i
+		value, err := strconv.Atoi(part)
+		// Minutes and seconds are below 60, while hours are unbounded.
+		if err != nil || value < 0 || ((pos == 1 || pos == 2) && value > 59) {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		values[pos] = value
+	}
+	return time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute +
+		time.Duration(values[2])*time.Second + time.Duration(values[3])*time.Millisecond, nil
+}
+
+// FormatSRT renders cues as an SRT transcript. Cues with a zero Index are
+// numbered by their position.
+func FormatSRT(cues []SubtitleCue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		index := cue.Index
+		if index == 0 {
+			index = i + 1
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", index,
+			formatSubtitleTimestamp(cue.Start, ','), formatSubtitleTimestamp(cue.End, ','), cue.Text)
+	}
+	return b.String()
+}
+
+// FormatVTT renders cues as a WebVTT transcript.
+func FormatVTT(cues []SubtitleCue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+			formatSubtitleTimestamp(cue.Start, '.'), formatSubtitleTimestamp(cue.End, '.'), cue.Text)
+	}
+	return b.String()
+}
+
+func formatSubtitleTimestamp(d time.Duration, separator byte) string {
+	d = d.Round(time.Millisecond)
+	hours := d / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute / time.Second
+	millis := d % time.Second / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", hours, minutes, seconds, separator, millis)
+}
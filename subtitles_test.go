@@ -0,0 +1,62 @@
+package openai_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestParseSubtitlesSRT(t *testing.T) {
+	srt := "1\r\n00:00:00,000 --> 00:00:02,500\r\nHello there.\r\nGeneral Kenobi.\r\n\r\n" +
+		"2\n00:00:02,500 --> 00:00:61,000\nBad seconds.\n\n" +
+		"3\n01:02:03,004 --> 01:02:05,000\nLater.\n"
+	cues, warnings := openai.ParseSubtitles(srt)
+	want := []openai.SubtitleCue{
+		{Index: 1, Start: 0, End: 2500 * time.Millisecond, Text: "Hello there.\nGeneral Kenobi."},
+		{Index: 3, Start: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond,
+			End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Later."},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("unexpected cues %+v", cues)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "line 6:") {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+
+	if got := openai.FormatSRT(cues); got != "1\n00:00:00,000 --> 00:00:02,500\nHello there.\nGeneral Kenobi.\n\n"+
+		"3\n01:02:03,004 --> 01:02:05,000\nLater.\n\n" {
+		t.Errorf("unexpected SRT %q", got)
+	}
+}
+
+func TestParseSubtitlesVTT(t *testing.T) {
+	vtt := "WEBVTT - generated\n\nNOTE a comment\nspanning lines\n\nSTYLE\n::cue { color: red }\n\n" +
+		"00:01.000 --> 00:03.250 align:start\nShort timestamps.\n\n" +
+		"intro\n00:00:03.250 --> 00:00:04.000\n<v Speaker>Identified cue.\n\n" +
+		"no timing line here\n\n" +
+		"00:05.000 --> 00:04.000\nBackwards.\n"
+	cues, warnings := openai.ParseSubtitles(vtt)
+	want := []openai.SubtitleCue{
+		{Index: 1, Start: time.Second, End: 3250 * time.Millisecond, Text: "Short timestamps."},
+		{Index: 2, Start: 3250 * time.Millisecond, End: 4 * time.Second, Text: "<v Speaker>Identified cue."},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("unexpected cues %+v", cues)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+
+	rendered := openai.FormatVTT(cues)
+	if rendered != "WEBVTT\n\n00:00:01.000 --> 00:00:03.250\nShort timestamps.\n\n"+
+		"00:00:03.250 --> 00:00:04.000\n<v Speaker>Identified cue.\n\n" {
+		t.Errorf("unexpected VTT %q", rendered)
+	}
+	reparsed, warnings := openai.ParseSubtitles(rendered)
+	if !reflect.DeepEqual(reparsed, want) || warnings != nil {
+		t.Errorf("rendered VTT does not round trip: %+v %q", reparsed, warnings)
+	}
+}
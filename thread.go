@@ -75,6 +75,19 @@ const (
 	ChunkingStrategyTypeStatic ChunkingStrategyType = "static"
 )
 
+// NewStaticChunkingStrategy returns a static chunking strategy splitting files
+// into chunks of at most maxChunkSizeTokens tokens, between 100 and 4096, that
+// overlap by chunkOverlapTokens tokens, at most half of maxChunkSizeTokens.
+func NewStaticChunkingStrategy(maxChunkSizeTokens, chunkOverlapTokens int) *ChunkingStrategy {
+	return &ChunkingStrategy{
+		Type: ChunkingStrategyTypeStatic,
+		Static: &StaticChunkingStrategy{
+			MaxChunkSizeTokens: maxChunkSizeTokens,
+			ChunkOverlapTokens: chunkOverlapTokens,
+		},
+	}
+}
+
 type ModifyThreadRequest struct {
 	Metadata      map[string]any `json:"metadata"`
 	ToolResources *ToolResources `json:"tool_resources,omitempty"`
@@ -88,8 +101,10 @@ const (
 )
 
 type ThreadMessage struct {
-	Role        ThreadMessageRole  `json:"role"`
-	Content     string             `json:"content"`
+	Role    ThreadMessageRole `json:"role"`
+	Content string            `json:"content"`
+	// Deprecated: FileIDs is not supported by the v2 API. Set Attachments
+	// with NewThreadAttachments instead.
 	FileIDs     []string           `json:"file_ids,omitempty"`
 	Attachments []ThreadAttachment `json:"attachments,omitempty"`
 	Metadata    map[string]any     `json:"metadata,omitempty"`
@@ -104,6 +119,21 @@ type ThreadAttachmentTool struct {
 	Type string `json:"type"`
 }
 
+// NewThreadAttachments attaches each of the files to a message for the given
+// tools, code_interpreter or file_search. It converts the file IDs of v1
+// messages to v2 attachments.
+func NewThreadAttachments(fileIDs []string, tools ...AssistantToolType) []ThreadAttachment {
+	attachmentTools := make([]ThreadAttachmentTool, len(tools))
+	for i, tool := range tools {
+		attachmentTools[i] = ThreadAttachmentTool{Type: string(tool)}
+	}
+	attachments := make([]ThreadAttachment, len(fileIDs))
+	for i, fileID := range fileIDs {
+		attachments[i] = ThreadAttachment{FileID: fileID, Tools: attachmentTools}
+	}
+	return attachments
+}
+
 type ThreadDeleteResponse struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
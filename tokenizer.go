@@ -0,0 +1,274 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Tokenizer counts tokens client-side, before requests are sent, to check them
+// against context windows, truncate conversations or budget rate limits. The
+// tokenizer package adapts tiktoken implementations to it; by default the
+// client uses an EstimateTokenizer.
+type Tokenizer interface {
+	// CountTokens returns the number of tokens of text for model.
+	CountTokens(model, text string) (int, error)
+	// CountMessageTokens returns the number of prompt tokens of msgs for model,
+	// as computed by CountChatMessageTokens.
+	CountMessageTokens(model string, msgs []ChatCompletionMessage) (int, error)
+}
+
+// TextTokenCounter returns the number of tokens of text, for a given model.
+type TextTokenCounter func(text string) (int, error)
+
+// Tokens of the chat format, from the OpenAI cookbook's "How to count tokens
+// with tiktoken".
+const (
+	// tokensPerMessage are those of <|start|>{role/name}\n{content}<|end|>\n
+	// around the role, name and content of every message.
+	tokensPerMessage = 3
+	// tokensPerName are added when a message has a name.
+	tokensPerName = 1
+	// tokensPerReply are those of <|start|>assistant<|message|>, which prime
+	// every reply.
+	tokensPerReply = 3
+
+	// gpt-3.5-turbo-0301 wraps messages in one more token, and drops the role
+	// of messages with a name.
+	tokensPerMessage0301 = 4
+	tokensPerName0301    = -1
+)
+
+// Tokens of images, from the vision guide.
+const (
+	imageBaseTokens = 85
+	imageTileTokens = 170
+	imageTileSize   = 512
+	imageMaxSide    = 2048
+	imageShortSide  = 768
+	// imageDefaultSide is the side of the square images whose size is unknown
+	// are counted as.
+	imageDefaultSide = 1024
+)
+
+// CountChatMessageTokens returns the number of prompt tokens of msgs for model,
+// counting the text of their role, content, name, tool calls and tool call ID
+// with count:
+//
+//	3 + Σ (3 + tokens(role) + tokens(content) + [tokens(name) + 1] + tokens(tool calls))
+//
+// where the first 3 tokens prime the reply and every message is wrapped in 3
+// tokens (4 for gpt-3.5-turbo-0301, which counts -1 instead of 1 for names).
+// The tokens of a tool call are those of its function name and arguments.
+// Images count as given by ImageTokens, as if they were 1024x1024 when their
+// detail is not low, since their size is unknown. Audio is not counted.
+//
+// The count is exact for text with the tokenizer of the model, and approximate
+// for tool calls and images.
+func CountChatMessageTokens(count TextTokenCounter, model string, msgs []ChatCompletionMessage) (int, error) {
+	perMessage, perName := tokensPerMessage, tokensPerName
+	if model == GPT3Dot5Turbo0301 {
+		perMessage, perName = tokensPerMessage0301, tokensPerName0301
+	}
+
+	total := tokensPerReply
+	add := func(text string) error {
+		if text == "" {
+			return nil
+		}
+		n, err := count(text)
+		total += n
+		return err
+	}
+	for _, msg := range msgs {
+		total += perMessage
+		texts := []string{msg.Role, msg.Content, msg.ToolCallID}
+		if msg.Name != "" {
+			total += perName
+			texts = append(texts, msg.Name)
+		}
+		if msg.FunctionCall != nil {
+			texts = append(texts, msg.FunctionCall.Name, msg.FunctionCall.Arguments)
+		}
+		for _, call := range msg.ToolCalls {
+			texts = append(texts, call.Function.Name, call.Function.Arguments)
+		}
+		for _, part := range msg.MultiContent {
+			switch part.Type {
+			case ChatMessagePartTypeText:
+				texts = append(texts, part.Text)
+			case ChatMessagePartTypeImageURL:
+				var detail ImageURLDetail
+				if part.ImageURL != nil {
+					detail = part.ImageURL.Detail
+				}
+				total += ImageTokens(imageDefaultSide, imageDefaultSide, detail)
+			}
+		}
+		for _, text := range texts {
+			if err := add(text); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// Tokens of tool definitions, from the OpenAI cookbook's "How to count tokens
+// with tiktoken", for gpt-4o and later models. gpt-3.5-turbo and gpt-4 spend
+// 10 tokens per function instead of 7.
+const (
+	toolTokensPerFunction       = 7
+	toolTokensPerFunctionLegacy = 10
+	toolTokensForProperties     = 3
+	toolTokensPerProperty       = 3
+	toolTokensForEnum           = -3
+	toolTokensPerEnumItem       = 3
+	toolTokensEnd               = 12
+)
+
+// CountToolTokens returns the approximate number of prompt tokens the function
+// tools spend for model, counting with count the text of the lines
+//
+//	name:description
+//	property:type:description
+//
+// of every function and property of its parameters, with trailing periods of
+// descriptions removed, and of every enum value. Every function adds 7 tokens
+// (10 for gpt-3.5-turbo and gpt-4), its parameters 3, every property 3, every
+// enum value 3 minus 3 per enum, and the definitions 12 in total.
+func CountToolTokens(count TextTokenCounter, model string, tools []Tool) (int, error) {
+	perFunction := toolTokensPerFunction
+	if strings.HasPrefix(model, "gpt-3.5") ||
+		(strings.HasPrefix(model, "gpt-4") && !strings.HasPrefix(model, "gpt-4o") && !strings.HasPrefix(model, "gpt-4.")) {
+		perFunction = toolTokensPerFunctionLegacy
+	}
+
+	total := 0
+	var texts []string
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		total += perFunction
+		texts = append(texts, tool.Function.Name+":"+strings.TrimSuffix(tool.Function.Description, "."))
+
+		properties, err := toolProperties(tool.Function.Parameters)
+		if err != nil {
+			return 0, fmt.Errorf("parameters of %s: %w", tool.Function.Name, err)
+		}
+		if len(properties) > 0 {
+			total += toolTokensForProperties
+		}
+		for name, property := range properties {
+			total += toolTokensPerProperty
+			if len(property.Enum) > 0 {
+				total += toolTokensForEnum + toolTokensPerEnumItem*len(property.Enum)
+				for _, item := range property.Enum {
+					texts = append(texts, fmt.Sprint(item))
+				}
+			}
+			texts = append(texts, fmt.Sprintf("%s:%v:%s", name, property.Type, strings.TrimSuffix(property.Description, ".")))
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	total += toolTokensEnd
+	for _, text := range texts {
+		n, err := count(text)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+type toolProperty struct {
+	Type        any    `json:"type"`
+	Description string `json:"description"`
+	Enum        []any  `json:"enum"`
+}
+
+// toolProperties returns the properties of the JSON schema of the parameters of
+// a function.
+func toolProperties(parameters any) (map[string]toolProperty, error) {
+	if parameters == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+	var schema struct {
+		Properties map[string]toolProperty `json:"properties"`
+	}
+	if err = json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return schema.Properties, nil
+}
+
+// CountRequestTokens returns the number of prompt tokens of the messages and
+// tools of request, as counted by t and CountToolTokens.
+func CountRequestTokens(t Tokenizer, request ChatCompletionRequest) (int, error) {
+	total, err := t.CountMessageTokens(request.Model, request.Messages)
+	if err != nil {
+		return 0, err
+	}
+	tools, err := CountToolTokens(func(text string) (int, error) {
+		return t.CountTokens(request.Model, text)
+	}, request.Model, request.Tools)
+	return total + tools, err
+}
+
+// ImageTokens returns the number of tokens of an image of the given size: 85
+// for low detail, else 85 plus 170 per 512px tile of the image scaled to fit in
+// 2048x2048 and then to have its shortest side at most 768px long. Auto detail
+// is counted as high, the most it can cost.
+func ImageTokens(width, height int, detail ImageURLDetail) int {
+	if detail == ImageURLDetailLow {
+		return imageBaseTokens
+	}
+	w, h := float64(width), float64(height)
+	if longest := math.Max(w, h); longest > imageMaxSide {
+		w, h = w*imageMaxSide/longest, h*imageMaxSide/longest
+	}
+	if shortest := math.Min(w, h); shortest > imageShortSide {
+		w, h = w*imageShortSide/shortest, h*imageShortSide/shortest
+	}
+	tiles := math.Ceil(w/imageTileSize) * math.Ceil(h/imageTileSize)
+	return imageBaseTokens + imageTileTokens*int(tiles)
+}
+
+// EstimateTokenizer is a Tokenizer counting tokens with a TokenEstimator,
+// DefaultTokenEstimator if Estimator is nil, whatever the model. It is the
+// tokenizer of clients configured without one.
+type EstimateTokenizer struct {
+	Estimator TokenEstimator
+}
+
+func (t EstimateTokenizer) CountTokens(_, text string) (int, error) {
+	estimate := t.Estimator
+	if estimate == nil {
+		estimate = DefaultTokenEstimator
+	}
+	return estimate(text), nil
+}
+
+func (t EstimateTokenizer) CountMessageTokens(model string, msgs []ChatCompletionMessage) (int, error) {
+	return CountChatMessageTokens(func(text string) (int, error) {
+		return t.CountTokens(model, text)
+	}, model, msgs)
+}
+
+// Tokenizer returns the tokenizer of the client: ClientConfig.Tokenizer, or an
+// EstimateTokenizer.
+func (c *Client) Tokenizer() Tokenizer {
+	if c.config.Tokenizer != nil {
+		return c.config.Tokenizer
+	}
+	return EstimateTokenizer{}
+}
@@ -0,0 +1,77 @@
+// Package tokenizer adapts tiktoken implementations to the openai.Tokenizer
+// interface, to count tokens exactly as the models do. It depends on none of
+// them: the encoders of github.com/pkoukk/tiktoken-go, for example, are used
+// as
+//
+//	config.Tokenizer = tokenizer.NewTiktoken(func(model string) (tokenizer.Encoder, error) {
+//		return tiktoken.EncodingForModel(model)
+//	})
+//	client := openai.NewClientWithConfig(config)
+package tokenizer
+
+import (
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Encoder encodes text into tokens. The *Tiktoken of
+// github.com/pkoukk/tiktoken-go implements it.
+type Encoder interface {
+	Encode(text string, allowedSpecial, disallowedSpecial []string) []int
+}
+
+var _ openai.Tokenizer = (*Tiktoken)(nil)
+
+// EncoderForModel returns the encoder of a model.
+type EncoderForModel func(model string) (Encoder, error)
+
+// Tiktoken is an openai.Tokenizer counting the tokens of the encoders of
+// models. Encoders are loaded once per model. It is safe for concurrent use.
+type Tiktoken struct {
+	encoderForModel EncoderForModel
+
+	mu       sync.Mutex
+	encoders map[string]Encoder
+}
+
+// NewTiktoken returns a Tiktoken getting the encoders of models from
+// encoderForModel.
+func NewTiktoken(encoderForModel EncoderForModel) *Tiktoken {
+	return &Tiktoken{encoderForModel: encoderForModel, encoders: map[string]Encoder{}}
+}
+
+// CountTokens implements openai.Tokenizer.
+func (t *Tiktoken) CountTokens(model, text string) (int, error) {
+	encoder, err := t.encoder(model)
+	if err != nil {
+		return 0, err
+	}
+	return len(encoder.Encode(text, nil, nil)), nil
+}
+
+// CountMessageTokens implements openai.Tokenizer with
+// openai.CountChatMessageTokens.
+func (t *Tiktoken) CountMessageTokens(model string, msgs []openai.ChatCompletionMessage) (int, error) {
+	encoder, err := t.encoder(model)
+	if err != nil {
+		return 0, err
+	}
+	return openai.CountChatMessageTokens(func(text string) (int, error) {
+		return len(encoder.Encode(text, nil, nil)), nil
+	}, model, msgs)
+}
+
+func (t *Tiktoken) encoder(model string) (Encoder, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if encoder, ok := t.encoders[model]; ok {
+		return encoder, nil
+	}
+	encoder, err := t.encoderForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	t.encoders[model] = encoder
+	return encoder, nil
+}
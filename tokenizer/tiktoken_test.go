@@ -0,0 +1,50 @@
+package tokenizer_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/tokenizer"
+)
+
+// byteEncoder encodes every byte of the text as a token.
+type byteEncoder struct{}
+
+func (byteEncoder) Encode(text string, _, _ []string) []int {
+	return make([]int, len(text))
+}
+
+func TestTiktoken(t *testing.T) {
+	loads := 0
+	tiktoken := tokenizer.NewTiktoken(func(model string) (tokenizer.Encoder, error) {
+		loads++
+		if !strings.HasPrefix(model, "gpt-") {
+			return nil, errors.New("unknown model")
+		}
+		return byteEncoder{}, nil
+	})
+
+	tokens, err := tiktoken.CountTokens(openai.GPT4o, "hello")
+	checks.NoError(t, err, "CountTokens error")
+	if tokens != 5 {
+		t.Errorf("counted %d tokens, want 5", tokens)
+	}
+
+	tokens, err = tiktoken.CountMessageTokens(openai.GPT4o, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "hello"},
+	})
+	checks.NoError(t, err, "CountMessageTokens error")
+	// reply 3 + message 3 + "user" 4 + "hello" 5
+	if tokens != 15 {
+		t.Errorf("counted %d message tokens, want 15", tokens)
+	}
+	if loads != 1 {
+		t.Errorf("encoder loaded %d times, want once", loads)
+	}
+
+	_, err = tiktoken.CountTokens("davinci", "hello")
+	checks.HasError(t, err, "CountTokens should fail for models without encoder")
+}
@@ -0,0 +1,156 @@
+package openai_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// countWords counts a token per word, which makes the expected counts easy to
+// follow.
+func countWords(text string) (int, error) {
+	return len(strings.Fields(text)), nil
+}
+
+func TestCountChatMessageTokens(t *testing.T) {
+	msgs := []openai.ChatCompletionMessage{
+		// 3 + role 1 + content 3
+		{Role: openai.ChatMessageRoleSystem, Content: "You are helpful."},
+		// 3 + role 1 + content 2 + name 1 + 1
+		{Role: openai.ChatMessageRoleUser, Name: "alice", Content: "Hello there"},
+		// 3 + role 1 + function name 1 + arguments 1
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+			ID:       "call_1",
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		// 3 + role 1 + content 1 + tool call ID 1
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+	}
+
+	tokens, err := openai.CountChatMessageTokens(countWords, openai.GPT4o, msgs)
+	checks.NoError(t, err, "CountChatMessageTokens error")
+	if want := 3 + 7 + 8 + 6 + 6; tokens != want {
+		t.Errorf("counted %d tokens, want %d", tokens, want)
+	}
+
+	// gpt-3.5-turbo-0301 wraps messages in 4 tokens and counts -1 per name.
+	tokens, err = openai.CountChatMessageTokens(countWords, openai.GPT3Dot5Turbo0301, msgs)
+	checks.NoError(t, err, "CountChatMessageTokens error")
+	if want := 3 + 8 + 7 + 7 + 7; tokens != want {
+		t.Errorf("counted %d tokens for gpt-3.5-turbo-0301, want %d", tokens, want)
+	}
+
+	errCount := errors.New("count failed")
+	_, err = openai.CountChatMessageTokens(func(string) (int, error) { return 0, errCount }, openai.GPT4o, msgs)
+	checks.ErrorIs(t, err, errCount)
+}
+
+func TestCountChatMessageTokensImages(t *testing.T) {
+	msgs := []openai.ChatCompletionMessage{{
+		Role: openai.ChatMessageRoleUser,
+		MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL:    "https://example.com/a.png",
+				Detail: openai.ImageURLDetailLow,
+			}},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL: "https://example.com/b.png",
+			}},
+		},
+	}}
+
+	tokens, err := openai.CountChatMessageTokens(countWords, openai.GPT4o, msgs)
+	checks.NoError(t, err, "CountChatMessageTokens error")
+	// reply 3 + message 3 + role 1 + text 3 + low detail 85 + 1024x1024 765
+	if want := 3 + 3 + 1 + 3 + 85 + 765; tokens != want {
+		t.Errorf("counted %d tokens, want %d", tokens, want)
+	}
+}
+
+func TestImageTokens(t *testing.T) {
+	// The examples of the vision guide.
+	testCases := []struct {
+		width, height int
+		detail        openai.ImageURLDetail
+		tokens        int
+	}{
+		{1024, 1024, openai.ImageURLDetailHigh, 765},
+		{2048, 4096, openai.ImageURLDetailHigh, 1105},
+		{4096, 8192, openai.ImageURLDetailLow, 85},
+		{512, 512, openai.ImageURLDetailAuto, 255},
+	}
+	for _, tc := range testCases {
+		if tokens := openai.ImageTokens(tc.width, tc.height, tc.detail); tokens != tc.tokens {
+			t.Errorf("%dx%d %s image: %d tokens, want %d", tc.width, tc.height, tc.detail, tokens, tc.tokens)
+		}
+	}
+}
+
+func TestCountToolTokens(t *testing.T) {
+	tools := []openai.Tool{{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the current weather.",
+			Parameters: jsonschema.Definition{
+				Type: jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{
+					"location": {Type: jsonschema.String, Description: "The city and state."},
+					"unit":     {Type: jsonschema.String, Enum: []string{"celsius", "fahrenheit"}},
+				},
+			},
+		},
+	}}
+
+	// function 7 + "get_weather:Get the current weather" 4 + properties 3
+	// + location 3 + "location:string:The city and state" 4
+	// + unit 3 - enum 3 + 2 enum items 3 each + 1 word each + "unit:string:" 1
+	// + end 12
+	want := 7 + 4 + 3 + 3 + 4 + 3 - 3 + 2*(3+1) + 1 + 12
+	tokens, err := openai.CountToolTokens(countWords, openai.GPT4o, tools)
+	checks.NoError(t, err, "CountToolTokens error")
+	if tokens != want {
+		t.Errorf("counted %d tokens, want %d", tokens, want)
+	}
+
+	// gpt-4 spends 3 more tokens per function.
+	tokens, err = openai.CountToolTokens(countWords, openai.GPT4, tools)
+	checks.NoError(t, err, "CountToolTokens error")
+	if tokens != want+3 {
+		t.Errorf("counted %d tokens for gpt-4, want %d", tokens, want+3)
+	}
+
+	tokens, err = openai.CountToolTokens(countWords, openai.GPT4o, nil)
+	if err != nil || tokens != 0 {
+		t.Errorf("counted %d tokens without tools, error %v", tokens, err)
+	}
+}
+
+func TestClientTokenizer(t *testing.T) {
+	config := openai.DefaultConfig("token")
+	client := openai.NewClientWithConfig(config)
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "12345678"}},
+	}
+	// reply 3 + message 3 + "user" 1 + 8 characters 2
+	tokens, err := openai.CountRequestTokens(client.Tokenizer(), request)
+	checks.NoError(t, err, "CountRequestTokens error")
+	if tokens != 9 {
+		t.Errorf("estimated %d tokens, want 9", tokens)
+	}
+
+	config.Tokenizer = openai.EstimateTokenizer{Estimator: func(string) int { return 1 }}
+	client = openai.NewClientWithConfig(config)
+	tokens, err = openai.CountRequestTokens(client.Tokenizer(), request)
+	checks.NoError(t, err, "CountRequestTokens error")
+	if tokens != 8 {
+		t.Errorf("counted %d tokens with the configured tokenizer, want 8", tokens)
+	}
+}
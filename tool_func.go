@@ -0,0 +1,162 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// FunctionTool is a tool implemented by a Go function. Its definition is
+// generated from the function's argument type, and Invoke runs the function for
+// the tool calls the model makes.
+type FunctionTool struct {
+	Definition FunctionDefinition
+
+	fn       reflect.Value
+	argsType reflect.Type
+	argsPtr  bool
+	withCtx  bool
+	schema   *jsonschema.Definition
+}
+
+// NewToolFromFunc returns the tool for fn, which must take a struct, or a pointer
+// to one, optionally preceded by a context.Context, and return a value,
+// optionally followed by an error:
+//
+//	func(args Args) Result
+//	func(ctx context.Context, args *Args) (Result, error)
+//
+// The parameters schema is generated from the struct as described in
+// jsonschema.GenerateStrictSchemaForType, and the definition is strict unless
+// the struct cannot be described in strict mode.
+func NewToolFromFunc(name, description string, fn any) (*FunctionTool, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("tool %q: expected a function, got %T", name, fn)
+	}
+	if v.IsNil() {
+		return nil, fmt.Errorf("tool %q: function is nil", name)
+	}
+	t := v.Type()
+	tool := &FunctionTool{fn: v}
+
+	in := t.NumIn()
+	if in == 2 && t.In(0) == contextType {
+		tool.withCtx = true
+	} else if in != 1 {
+		return nil, fmt.Errorf("tool %q: function must take a single struct argument, got %s", name, t)
+	}
+	tool.argsType = t.In(in - 1)
+	if tool.argsType.Kind() == reflect.Ptr {
+		tool.argsType = tool.argsType.Elem()
+		tool.argsPtr = true
+	}
+	if tool.argsType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tool %q: function argument must be a struct, got %s", name, t.In(in-1))
+	}
+	switch {
+	case t.NumOut() == 1 && t.Out(0) != errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("tool %q: function must return a value and an optional error, got %s", name, t)
+	}
+
+	args := reflect.New(tool.argsType).Elem().Interface()
+	strict := true
+	schema, err := jsonschema.GenerateStrictSchemaForType(args)
+	if err != nil {
+		strict = false
+		schema, err = jsonschema.GenerateSchemaForType(args)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tool %q: %w", name, err)
+	}
+	tool.schema = schema
+	tool.Definition = FunctionDefinition{
+		Name:        name,
+		Description: description,
+		Strict:      strict,
+		Parameters:  schema,
+	}
+	return tool, nil
+}
+
+// Tool returns the tool to list in ChatCompletionRequest.Tools.
+func (t *FunctionTool) Tool() Tool {
+	definition := t.Definition
+	return Tool{Type: ToolTypeFunction, Function: &definition}
+}
+
+// Handler returns the function as a ToolHandler, to run it with a ToolRunner
+// along with other handlers.
+func (t *FunctionTool) Handler() ToolHandler {
+	return func(ctx context.Context, arguments string) (string, error) {
+		call := ToolCall{Type: ToolTypeFunction, Function: FunctionCall{Name: t.Definition.Name, Arguments: arguments}}
+		return t.InvokeContext(ctx, call)
+	}
+}
+
+// Invoke calls the function with the arguments of call and returns its result
+// encoded as JSON, to be sent back as the content of the tool message. Arguments
+// that do not match the schema are reported as an error, without calling the
+// function.
+func (t *FunctionTool) Invoke(call ToolCall) (string, error) {
+	return t.InvokeContext(context.Background(), call)
+}
+
+// InvokeContext is like Invoke, passing ctx to functions that take a context.
+func (t *FunctionTool) InvokeContext(ctx context.Context, call ToolCall) (string, error) {
+	name := t.Definition.Name
+	if call.Function.Name != name {
+		return "", fmt.Errorf("tool %q cannot handle a call to %q", name, call.Function.Name)
+	}
+
+	arguments := []byte(call.Function.Arguments)
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		arguments = []byte("{}")
+	}
+	var data any
+	if err := json.Unmarshal(arguments, &data); err != nil {
+		return "", fmt.Errorf("tool %q: arguments are not valid JSON: %w", name, err)
+	}
+	if !jsonschema.Validate(*t.schema, data) {
+		return "", fmt.Errorf("tool %q: arguments do not match its parameters schema: %s", name, arguments)
+	}
+	args := reflect.New(t.argsType)
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(args.Interface()); err != nil {
+		return "", fmt.Errorf("tool %q: decoding arguments: %w", name, err)
+	}
+
+	in := make([]reflect.Value, 0, 2)
+	if t.withCtx {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	if t.argsPtr {
+		in = append(in, args)
+	} else {
+		in = append(in, args.Elem())
+	}
+	out := t.fn.Call(in)
+	if len(out) == 2 {
+		if err, _ := out[1].Interface().(error); err != nil {
+			return "", fmt.Errorf("tool %q: %w", name, err)
+		}
+	}
+
+	content, err := json.Marshal(out[0].Interface())
+	if err != nil {
+		return "", fmt.Errorf("tool %q: encoding result: %w", name, err)
+	}
+	return string(content), nil
+}
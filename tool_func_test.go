@@ -0,0 +1,152 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type weatherArgs struct {
+	Location string `json:"location" description:"City name"`
+	Unit     string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+}
+
+type weatherReport struct {
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit"`
+}
+
+func getWeather(args weatherArgs) weatherReport {
+	unit := args.Unit
+	if unit == "" {
+		unit = "celsius"
+	}
+	return weatherReport{Location: args.Location, Temperature: 21.5, Unit: unit}
+}
+
+func weatherCall(arguments string) openai.ToolCall {
+	return openai.ToolCall{
+		ID:       "call_1",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "get_weather", Arguments: arguments},
+	}
+}
+
+func TestNewToolFromFunc(t *testing.T) {
+	tool, err := openai.NewToolFromFunc("get_weather", "Current weather", getWeather)
+	checks.NoError(t, err, "NewToolFromFunc error")
+
+	definition := tool.Tool()
+	if definition.Type != openai.ToolTypeFunction || definition.Function.Name != "get_weather" ||
+		definition.Function.Description != "Current weather" || !definition.Function.Strict {
+		t.Fatalf("unexpected tool %+v", definition.Function)
+	}
+	parameters, err := json.Marshal(definition.Function.Parameters)
+	checks.NoError(t, err, "marshal error")
+	for _, want := range []string{`"location":{"type":"string","description":"City name"}`, `"enum":["celsius","fahrenheit"]`} {
+		if !strings.Contains(string(parameters), want) {
+			t.Errorf("parameters %s are missing %s", parameters, want)
+		}
+	}
+
+	content, err := tool.Invoke(weatherCall(`{"location":"Paris","unit":null}`))
+	checks.NoError(t, err, "Invoke error")
+	if content != `{"location":"Paris","temperature":21.5,"unit":"celsius"}` {
+		t.Fatalf("unexpected content %s", content)
+	}
+}
+
+func TestToolInvokeRejectsInvalidArguments(t *testing.T) {
+	called := false
+	tool, err := openai.NewToolFromFunc("get_weather", "", func(args weatherArgs) weatherReport {
+		called = true
+		return getWeather(args)
+	})
+	checks.NoError(t, err, "NewToolFromFunc error")
+
+	for _, arguments := range []string{
+		`{"location":`,
+		`{"location":42,"unit":null}`,
+		`{"location":"Paris","unit":"kelvin"}`,
+		`{"location":"Paris","unit":null,"extra":true}`,
+	} {
+		if _, err = tool.Invoke(weatherCall(arguments)); err == nil {
+			t.Errorf("expected an error for arguments %s", arguments)
+		}
+	}
+	if called {
+		t.Fatal("the function must not run with invalid arguments")
+	}
+
+	call := weatherCall(`{}`)
+	call.Function.Name = "other"
+	_, err = tool.Invoke(call)
+	checks.HasError(t, err, "expected a name mismatch error")
+}
+
+func TestToolInvokeContextAndError(t *testing.T) {
+	type ctxKey struct{}
+	errNotFound := errors.New("city not found")
+	tool, err := openai.NewToolFromFunc("get_weather", "",
+		func(ctx context.Context, args *weatherArgs) (*weatherReport, error) {
+			if ctx.Value(ctxKey{}) != "trace" {
+				t.Error("context was not passed to the function")
+			}
+			if args.Location == "Atlantis" {
+				return nil, errNotFound
+			}
+			report := getWeather(*args)
+			return &report, nil
+		})
+	checks.NoError(t, err, "NewToolFromFunc error")
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace")
+	_, err = tool.InvokeContext(ctx, weatherCall(`{"location":"Atlantis","unit":null}`))
+	checks.ErrorIs(t, err, errNotFound, "expected the function error")
+	content, err := tool.InvokeContext(ctx, weatherCall(`{"location":"Oslo","unit":"fahrenheit"}`))
+	checks.NoError(t, err, "InvokeContext error")
+	if !strings.Contains(content, `"unit":"fahrenheit"`) {
+		t.Fatalf("unexpected content %s", content)
+	}
+}
+
+func TestNewToolFromFuncErrors(t *testing.T) {
+	for name, fn := range map[string]any{
+		"not a function":  "get_weather",
+		"no argument":     func() string { return "" },
+		"not a struct":    func(string) string { return "" },
+		"no result":       func(weatherArgs) {},
+		"only error":      func(weatherArgs) error { return nil },
+		"second not err":  func(weatherArgs) (string, string) { return "", "" },
+		"unsupported arg": func(struct{ C chan int }) string { return "" },
+		"nil function":    (func(weatherArgs) string)(nil),
+	} {
+		if _, err := openai.NewToolFromFunc("tool", "", fn); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNewToolFromFuncNonStrict(t *testing.T) {
+	type labelArgs struct {
+		Labels map[string]string `json:"labels"`
+	}
+	tool, err := openai.NewToolFromFunc("label", "", func(args labelArgs) int { return len(args.Labels) })
+	checks.NoError(t, err, "NewToolFromFunc error")
+	if tool.Definition.Strict {
+		t.Fatal("maps cannot be described in strict mode")
+	}
+	content, err := tool.Invoke(openai.ToolCall{Function: openai.FunctionCall{
+		Name: "label", Arguments: `{"labels":{"a":"1","b":"2"}}`,
+	}})
+	checks.NoError(t, err, "Invoke error")
+	if content != "2" {
+		t.Fatalf("unexpected content %s", content)
+	}
+}
@@ -0,0 +1,258 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultMaxToolIterations and DefaultToolConcurrency are the limits of a
+// ToolRunner without MaxIterations and MaxConcurrency.
+const (
+	DefaultMaxToolIterations = 10
+	DefaultToolConcurrency   = 4
+)
+
+var (
+	// ErrMaxToolIterations is returned by ToolRunner.Run when the model still
+	// calls tools after MaxIterations requests.
+	ErrMaxToolIterations = errors.New("maximum tool iterations reached")
+	// ErrUnknownTool is the error of the calls of tools that have no handler.
+	ErrUnknownTool = errors.New("unknown tool")
+	// ErrToolPanicked is the error of the calls whose handler panicked.
+	ErrToolPanicked = errors.New("tool panicked")
+)
+
+// EmptyToolOutput is the output sent to the model for a call whose handler
+// returned no output, since tool messages cannot be empty.
+const EmptyToolOutput = "(no output)"
+
+// ToolHandler runs a function tool called by a model with the JSON arguments
+// of the call, and returns its output for the model.
+type ToolHandler func(ctx context.Context, arguments string) (string, error)
+
+// ToolRunner drives the tool-calling loop of chat completions: it sends the
+// request, runs the tools the model calls with their handlers, sends their
+// results back, and repeats until the model answers without calling tools.
+//
+//	weather, err := openai.NewToolFromFunc("get_weather", "Get the weather", getWeather)
+//	...
+//	runner := &openai.ToolRunner{
+//		Client:        client,
+//		FunctionTools: []*openai.FunctionTool{weather},
+//	}
+//	result, err := runner.Run(ctx, request)
+//	...
+//	answer := result.Messages[len(result.Messages)-1].Content
+type ToolRunner struct {
+	Client *Client
+	// Tools are the handlers of the function tools of the requests, by name.
+	Tools map[string]ToolHandler
+	// FunctionTools are tools implemented by Go functions, which handle the
+	// calls to their names. Their definitions are added to the tools of the
+	// requests that do not list them already.
+	FunctionTools []*FunctionTool
+	// MaxIterations is the maximum number of requests of a run. Zero uses
+	// DefaultMaxToolIterations.
+	MaxIterations int
+	// MaxConcurrency is the maximum number of tool calls of a reply run in
+	// parallel. Zero uses DefaultToolConcurrency.
+	MaxConcurrency int
+
+	// AbortOnToolError ends the run with the error of a handler instead of
+	// sending it to the model as the output of the call.
+	AbortOnToolError bool
+	// ToolErrorOutput returns the output sent to the model for a call whose
+	// handler failed. Nil sends "error: " followed by the error.
+	ToolErrorOutput func(call ToolCall, err error) string
+
+	// Stream streams the responses, calling OnChunk with every chunk.
+	Stream  bool
+	OnChunk func(chunk ChatCompletionStreamResponse)
+	// OnIteration is called with every response of the model, before its tool
+	// calls are run. Returning an error ends the run with it.
+	OnIteration func(iteration int, response ChatCompletionResponse) error
+}
+
+// ToolRunResult is the outcome of a ToolRunner run.
+type ToolRunResult struct {
+	// Messages are the messages added to the conversation: the replies of the
+	// model and the results of the tools, ending with the final answer.
+	Messages []ChatCompletionMessage
+	// Response is the last response of the model.
+	Response ChatCompletionResponse
+	// Usage is the token usage of all the requests, when reported.
+	Usage      Usage
+	Iterations int
+}
+
+// Run runs the tool-calling loop from request, and returns the messages it
+// added to the conversation with the final answer of the model. The result
+// holds the messages added so far when an error ends the run, which wraps
+// ErrMaxToolIterations when the model still calls tools after MaxIterations
+// requests.
+func (r *ToolRunner) Run(ctx context.Context, request ChatCompletionRequest) (*ToolRunResult, error) {
+	maxIterations := r.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxToolIterations
+	}
+	request.Tools = r.withFunctionTools(request.Tools)
+	messages := request.Messages
+	result := &ToolRunResult{}
+	for result.Iterations < maxIterations {
+		request.Messages = append(messages[:len(messages):len(messages)], result.Messages...)
+		response, err := r.complete(ctx, request)
+		if err != nil {
+			return result, err
+		}
+		result.Iterations++
+		result.Response = response
+		result.Usage.Add(response.Usage)
+		if len(response.Choices) == 0 {
+			return result, errors.New("response has no choices")
+		}
+		reply := response.Choices[0].Message
+		result.Messages = append(result.Messages, reply)
+		if r.OnIteration != nil {
+			if err = r.OnIteration(result.Iterations, response); err != nil {
+				return result, err
+			}
+		}
+		if len(reply.ToolCalls) == 0 {
+			return result, nil
+		}
+
+		outputs, err := r.callTools(ctx, reply.ToolCalls)
+		if err != nil {
+			return result, err
+		}
+		result.Messages = append(result.Messages, outputs...)
+	}
+	return result, fmt.Errorf("%w: %d", ErrMaxToolIterations, maxIterations)
+}
+
+// withFunctionTools returns tools with the definitions of the FunctionTools it
+// does not list.
+func (r *ToolRunner) withFunctionTools(tools []Tool) []Tool {
+	listed := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if tool.Function != nil {
+			listed[tool.Function.Name] = true
+		}
+	}
+	tools = tools[:len(tools):len(tools)]
+	for _, tool := range r.FunctionTools {
+		if !listed[tool.Definition.Name] {
+			tools = append(tools, tool.Tool())
+		}
+	}
+	return tools
+}
+
+// complete returns the response to request, streamed if configured.
+func (r *ToolRunner) complete(ctx context.Context, request ChatCompletionRequest) (ChatCompletionResponse, error) {
+	if !r.Stream {
+		request.Stream = false
+		return r.Client.CreateChatCompletion(ctx, request)
+	}
+
+	stream, err := r.Client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer stream.Close()
+	var acc ChatCompletionStreamAccumulator
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return acc.Response(), nil
+		}
+		if err != nil {
+			return ChatCompletionResponse{}, err
+		}
+		acc.Add(chunk)
+		if r.OnChunk != nil {
+			r.OnChunk(chunk)
+		}
+	}
+}
+
+// callTools runs the tool calls of a reply in parallel, and returns their
+// results in the order of the calls.
+func (r *ToolRunner) callTools(ctx context.Context, calls []ToolCall) ([]ChatCompletionMessage, error) {
+	concurrency := r.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultToolConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		slots   = make(chan struct{}, concurrency)
+		outputs = make([]ChatCompletionMessage, len(calls))
+		errs    = make([]error, len(calls))
+	)
+	for i, call := range calls {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, call ToolCall) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			output, err := r.callTool(ctx, call)
+			if err != nil && r.AbortOnToolError {
+				errs[i] = fmt.Errorf("tool %s: %w", call.Function.Name, err)
+				cancel()
+				return
+			}
+			if err != nil {
+				output = r.errorOutput(call, err)
+			}
+			if output == "" {
+				output = EmptyToolOutput
+			}
+			outputs[i] = ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: call.ID, Content: output}
+		}(i, call)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
+
+// callTool runs the handler of call, returning a panic of the handler as an
+// error wrapping ErrToolPanicked.
+func (r *ToolRunner) callTool(ctx context.Context, call ToolCall) (output string, err error) {
+	handler, ok := r.Tools[call.Function.Name]
+	if !ok {
+		for _, tool := range r.FunctionTools {
+			if tool.Definition.Name == call.Function.Name {
+				handler, ok = tool.Handler(), true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTool, call.Function.Name)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			output, err = "", fmt.Errorf("%w: %v", ErrToolPanicked, p)
+		}
+	}()
+	return handler(ctx, call.Function.Arguments)
+}
+
+func (r *ToolRunner) errorOutput(call ToolCall, err error) string {
+	if r.ToolErrorOutput != nil {
+		return r.ToolErrorOutput(call, err)
+	}
+	return "error: " + err.Error()
+}
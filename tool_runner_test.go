@@ -0,0 +1,261 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// toolCallsReply returns an assistant message calling the tools named.
+func toolCallsReply(names ...string) openai.ChatCompletionMessage {
+	reply := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	for i, name := range names {
+		reply.ToolCalls = append(reply.ToolCalls, openai.ToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: name, Arguments: fmt.Sprintf(`{"n":%d}`, i)},
+		})
+	}
+	return reply
+}
+
+// registerToolModel registers a chat completions handler replying with the
+// messages of replies in turn, and returns the requests it received.
+func registerToolModel(
+	server *test.ServerTest,
+	replies ...openai.ChatCompletionMessage,
+) *[]openai.ChatCompletionRequest {
+	var requests []openai.ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		reply := replies[(len(requests)-1)%len(replies)]
+		if !request.Stream {
+			resBytes, _ := json.Marshal(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: reply}},
+				Usage:   openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			})
+			fmt.Fprintln(w, string(resBytes))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		reply.ToolCalls = append([]openai.ToolCall(nil), reply.ToolCalls...)
+		for i := range reply.ToolCalls {
+			index := i
+			reply.ToolCalls[i].Index = &index
+		}
+		chunk, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+			Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{
+				Role:      reply.Role,
+				Content:   reply.Content,
+				ToolCalls: reply.ToolCalls,
+			}}},
+		})
+		fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", chunk)
+	})
+	return &requests
+}
+
+func echoTool(_ context.Context, arguments string) (string, error) {
+	return arguments, nil
+}
+
+func TestToolRunner(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	requests := registerToolModel(server,
+		toolCallsReply("slow", "fast", "slow", "fast"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "done"},
+	)
+
+	var running, maxRunning int32
+	handler := func(delay time.Duration) openai.ToolHandler {
+		return func(_ context.Context, arguments string) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(delay)
+			return arguments, nil
+		}
+	}
+	var iterations []int
+	runner := &openai.ToolRunner{
+		Client:         client,
+		Tools:          map[string]openai.ToolHandler{"slow": handler(50 * time.Millisecond), "fast": handler(0)},
+		MaxConcurrency: 2,
+		OnIteration: func(iteration int, _ openai.ChatCompletionResponse) error {
+			iterations = append(iterations, iteration)
+			return nil
+		},
+	}
+	result, err := runner.Run(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "Run error")
+
+	if len(result.Messages) != 6 || result.Messages[5].Content != "done" {
+		t.Fatalf("unexpected transcript %+v", result.Messages)
+	}
+	// The tool results follow the order of the calls, whatever their delays.
+	for i, msg := range result.Messages[1:5] {
+		if msg.Role != openai.ChatMessageRoleTool || msg.ToolCallID != fmt.Sprintf("call_%d", i) ||
+			msg.Content != fmt.Sprintf(`{"n":%d}`, i) {
+			t.Errorf("unexpected tool result %d: %+v", i, msg)
+		}
+	}
+	if maxRunning != 2 {
+		t.Errorf("ran %d tools at once, want 2", maxRunning)
+	}
+	if result.Iterations != 2 || len(iterations) != 2 || result.Usage.TotalTokens != 30 {
+		t.Errorf("unexpected result %d iterations, %v, usage %+v", result.Iterations, iterations, result.Usage)
+	}
+	if len(*requests) != 2 || len((*requests)[1].Messages) != 6 {
+		t.Errorf("unexpected requests %+v", *requests)
+	}
+	if len(chatStreamRequest.Messages) != 1 {
+		t.Error("Run modified the messages of the request")
+	}
+}
+
+func TestToolRunnerToolErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	errTool := errors.New("no network")
+	tools := map[string]openai.ToolHandler{"fail": func(context.Context, string) (string, error) {
+		return "", errTool
+	}}
+
+	registerToolModel(server,
+		toolCallsReply("fail", "missing"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "sorry"},
+	)
+	runner := &openai.ToolRunner{Client: client, Tools: tools}
+	result, err := runner.Run(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "Run error")
+	if len(result.Messages) != 4 || result.Messages[1].Content != "error: no network" ||
+		!strings.HasPrefix(result.Messages[2].Content, "error: unknown tool") {
+		t.Errorf("expected the errors sent to the model, got %+v", result.Messages)
+	}
+
+	registerToolModel(server,
+		toolCallsReply("fail"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "sorry"},
+	)
+	runner.ToolErrorOutput = func(call openai.ToolCall, err error) string {
+		return call.Function.Name + " failed: " + err.Error()
+	}
+	result, err = runner.Run(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "Run error")
+	if result.Messages[1].Content != "fail failed: no network" {
+		t.Errorf("unexpected tool output %q", result.Messages[1].Content)
+	}
+
+	registerToolModel(server,
+		toolCallsReply("missing"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "sorry"},
+	)
+	runner.AbortOnToolError = true
+	result, err = runner.Run(context.Background(), chatStreamRequest)
+	checks.ErrorIs(t, err, openai.ErrUnknownTool, "Run should abort on the unknown tool")
+	if len(result.Messages) != 1 {
+		t.Errorf("unexpected transcript %+v", result.Messages)
+	}
+}
+
+func TestToolRunnerMaxIterations(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	requests := registerToolModel(server, toolCallsReply("echo"))
+	runner := &openai.ToolRunner{
+		Client:        client,
+		Tools:         map[string]openai.ToolHandler{"echo": echoTool},
+		MaxIterations: 3,
+	}
+	result, err := runner.Run(context.Background(), chatStreamRequest)
+	checks.ErrorIs(t, err, openai.ErrMaxToolIterations, "Run should stop after MaxIterations")
+	if len(*requests) != 3 || result.Iterations != 3 || len(result.Messages) != 6 {
+		t.Errorf("unexpected run: %d requests, result %+v", len(*requests), result)
+	}
+
+	errStop := errors.New("stop")
+	runner.OnIteration = func(int, openai.ChatCompletionResponse) error { return errStop }
+	_, err = runner.Run(context.Background(), chatStreamRequest)
+	checks.ErrorIs(t, err, errStop, "Run should stop with the error of OnIteration")
+}
+
+func TestToolRunnerStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	requests := registerToolModel(server,
+		toolCallsReply("echo", "echo"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "done"},
+	)
+	chunks := 0
+	runner := &openai.ToolRunner{
+		Client:  client,
+		Tools:   map[string]openai.ToolHandler{"echo": echoTool},
+		Stream:  true,
+		OnChunk: func(openai.ChatCompletionStreamResponse) { chunks++ },
+	}
+	result, err := runner.Run(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "Run error")
+	if len(result.Messages) != 4 || len(result.Messages[0].ToolCalls) != 2 ||
+		result.Messages[2].Content != `{"n":1}` || result.Messages[3].Content != "done" {
+		t.Errorf("unexpected transcript %+v", result.Messages)
+	}
+	if chunks != 2 || !(*requests)[0].Stream || len((*requests)[1].Messages) != 4 {
+		t.Errorf("unexpected stream: %d chunks, requests %+v", chunks, *requests)
+	}
+}
+
+func TestToolRunnerFunctionTools(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	requests := registerToolModel(server,
+		toolCallsReply("double", "silent", "crash"),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "done"},
+	)
+	double, err := openai.NewToolFromFunc("double", "Double n", func(args struct {
+		N int `json:"n"`
+	}) int {
+		return 2 * args.N
+	})
+	checks.NoError(t, err, "NewToolFromFunc error")
+	runner := &openai.ToolRunner{
+		Client:        client,
+		FunctionTools: []*openai.FunctionTool{double},
+		Tools: map[string]openai.ToolHandler{
+			"silent": func(context.Context, string) (string, error) { return "", nil },
+			"crash":  func(context.Context, string) (string, error) { panic("boom") },
+		},
+	}
+	result, err := runner.Run(context.Background(), chatStreamRequest)
+	checks.NoError(t, err, "Run error")
+
+	if len(result.Messages) != 5 || result.Messages[1].Content != "0" ||
+		result.Messages[2].Content != openai.EmptyToolOutput || result.Messages[3].Content != "error: tool panicked: boom" {
+		t.Errorf("unexpected transcript %+v", result.Messages)
+	}
+	tools := (*requests)[0].Tools
+	if len(tools) != 1 || tools[0].Function.Name != "double" || len(chatStreamRequest.Tools) != 0 {
+		t.Errorf("expected the function tool to be sent, got %+v", tools)
+	}
+
+	runner.AbortOnToolError = true
+	_, err = runner.Run(context.Background(), chatStreamRequest)
+	checks.ErrorIs(t, err, openai.ErrToolPanicked, "Run should abort on the panic")
+}
@@ -0,0 +1,293 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Tracer starts a span for every API call made by the client. It mirrors the
+// small part of the OpenTelemetry trace API the client needs, so that this
+// module does not depend on OpenTelemetry; an adapter wraps a trace.Tracer
+// obtained from a TracerProvider:
+//
+//	type otelTracer struct{ tracer trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, openai.Span) {
+//		ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+//
+// The context returned by Start is used for the HTTP request, so spans created
+// by an instrumented HTTP client are children of the API call span.
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a single traced API call.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	AddEvent(name string, attributes ...Attribute)
+	// RecordError records err and marks the span as failed.
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute. Value is a string, int, bool, float64 or
+// []string.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span attributes, following the OpenTelemetry semantic conventions for
+// generative AI where they define one.
+const (
+	attributeGenAISystem        = "gen_ai.system"
+	attributeGenAIOperationName = "gen_ai.operation.name"
+	attributeGenAIRequestModel  = "gen_ai.request.model"
+	attributeGenAIResponseModel = "gen_ai.response.model"
+	attributeGenAIResponseID    = "gen_ai.response.id"
+	attributeGenAIFinishReasons = "gen_ai.response.finish_reasons"
+	attributeGenAIInputTokens   = "gen_ai.usage.input_tokens"
+	attributeGenAIOutputTokens  = "gen_ai.usage.output_tokens"
+	attributeHTTPStatusCode     = "http.response.status_code"
+	attributeHTTPBodySize       = "http.response.body.size"
+	attributeRequestStream      = "openai.request.stream"
+	attributeRequestID          = "openai.request.id"
+	attributeTimeToFirstToken   = "openai.time_to_first_token"
+)
+
+// spanEventFirstToken is added to streaming spans when the first event is
+// received.
+const spanEventFirstToken = "gen_ai.first_token"
+
+// operationActions are the trailing path segments that name an action on a
+// resource rather than a collection.
+var operationActions = map[string]bool{
+	"cancel":              true,
+	"complete":            true,
+	"submit_tool_outputs": true,
+}
+
+// operationName returns the span name of an API call, in the resource.method
+// form used by the official SDKs: chat.completions.create, models.list,
+// files.delete.
+func operationName(method, endpoint string) string {
+	var resources []string
+	lastIsID := false
+	for _, segment := range strings.Split(strings.Trim(endpoint, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		lastIsID = !isResourceSegment(segment)
+		if !lastIsID {
+			resources = append(resources, segment)
+		}
+	}
+	if len(resources) == 0 {
+		return strings.ToLower(method)
+	}
+
+	last := resources[len(resources)-1]
+	switch {
+	case !lastIsID && operationActions[last]:
+		return strings.Join(resources, ".")
+	case !lastIsID && last == "content" && method == http.MethodGet:
+		return strings.Join(resources, ".") + ".retrieve"
+	}
+
+	var verb string
+	switch method {
+	case http.MethodGet:
+		verb = "list"
+		if lastIsID {
+			verb = "retrieve"
+		}
+	case http.MethodPost:
+		verb = "create"
+		if lastIsID {
+			verb = "update"
+		}
+	case http.MethodDelete:
+		verb = "delete"
+	default:
+		verb = strings.ToLower(method)
+	}
+	return strings.Join(resources, ".") + "." + verb
+}
+
+// isResourceSegment reports whether a path segment names a resource rather than
+// an object ID. Resource names are lower case words joined by underscores;
+// object IDs carry digits or dashes.
+func isResourceSegment(segment string) bool {
+	for _, r := range segment {
+		if (r < 'a' || r > 'z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestStreams reports whether req asks for a streamed response.
+func requestStreams(req *http.Request) bool {
+	return req.Header.Get("Accept") == "text/event-stream"
+}
+
+// callResponse holds the parts of a response or stream event recorded on
+// spans.
+type callResponse struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Usage   *Usage       `json:"usage"`
+	Choices []callChoice `json:"choices"`
+}
+
+type callChoice struct {
+	FinishReason string `json:"finish_reason"`
+}
+
+// decodedCallResponse returns the parts of the decoded response v recorded on
+// spans, taken from its fields named as those of callResponse. The usages of
+// model responses and images are mapped to a Usage.
+func decodedCallResponse(v any) *callResponse {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	resp := &callResponse{}
+	if f := rv.FieldByName("ID"); f.Kind() == reflect.String {
+		resp.ID = f.String()
+	}
+	if f := rv.FieldByName("Model"); f.Kind() == reflect.String {
+		resp.Model = f.String()
+	}
+	if f := rv.FieldByName("Usage"); f.IsValid() && f.CanInterface() {
+		switch usage := f.Interface().(type) {
+		case Usage:
+			if usage != (Usage{}) {
+				resp.Usage = &usage
+			}
+		case *Usage:
+			resp.Usage = usage
+		case *ResponseUsage:
+			if usage != nil {
+				resp.Usage = &Usage{
+					PromptTokens:     usage.InputTokens,
+					CompletionTokens: usage.OutputTokens,
+					TotalTokens:      usage.TotalTokens,
+				}
+			}
+		case ImageResponseUsage:
+			if usage.TotalTokens != 0 {
+				resp.Usage = &Usage{
+					PromptTokens:     usage.InputTokens,
+					CompletionTokens: usage.OutputTokens,
+					TotalTokens:      usage.TotalTokens,
+				}
+			}
+		}
+	}
+	if f := rv.FieldByName("Choices"); f.Kind() == reflect.Slice {
+		for i := 0; i < f.Len(); i++ {
+			choice := reflect.Indirect(f.Index(i))
+			if choice.Kind() != reflect.Struct {
+				continue
+			}
+			if reason := choice.FieldByName("FinishReason"); reason.Kind() == reflect.String {
+				resp.Choices = append(resp.Choices, callChoice{FinishReason: reason.String()})
+			}
+		}
+	}
+	return resp
+}
+
+// startSpan starts the span of the call, returning the context to send the
+// request with.
+func (t *callTracker) startSpan(ctx context.Context, tracer Tracer, req *http.Request) context.Context {
+	stream := requestStreams(req)
+	operation := operationName(req.Method, t.endpoint)
+	ctx, t.span = tracer.Start(ctx, operation)
+	attributes := []Attribute{
+		{attributeGenAISystem, "openai"},
+		{attributeGenAIOperationName, operation},
+		{attributeRequestStream, stream},
+	}
+	if t.model != "" {
+		attributes = append(attributes, Attribute{attributeGenAIRequestModel, t.model})
+	}
+	t.span.SetAttributes(attributes...)
+	return ctx
+}
+
+// record takes the span attributes from a stream event.
+func (t *callTracker) record(data []byte) {
+	var resp callResponse
+	if json.Unmarshal(data, &resp) != nil {
+		return
+	}
+	t.apply(resp)
+}
+
+// apply takes the span attributes from the parts of a response.
+func (t *callTracker) apply(resp callResponse) {
+	if resp.ID != "" {
+		t.responseID = resp.ID
+	}
+	if resp.Model != "" {
+		t.responseModel = resp.Model
+	}
+	if resp.Usage != nil {
+		t.usage = resp.Usage
+	}
+	for _, choice := range resp.Choices {
+		if choice.FinishReason != "" {
+			t.finishReasons = append(t.finishReasons, choice.FinishReason)
+		}
+	}
+}
+
+// firstEvent marks the arrival of the first stream event on the span.
+func (t *callTracker) firstEvent() {
+	ttft := time.Since(t.start)
+	t.span.AddEvent(spanEventFirstToken, Attribute{attributeTimeToFirstToken, ttft.Seconds()})
+}
+
+// endSpan sets the final attributes of the span and ends it.
+func (t *callTracker) endSpan(err error) {
+	var attributes []Attribute
+	if t.status != 0 {
+		attributes = append(attributes, Attribute{attributeHTTPStatusCode, t.status})
+	}
+	if t.bodySize > 0 {
+		attributes = append(attributes, Attribute{attributeHTTPBodySize, t.bodySize})
+	}
+	if t.requestID != "" {
+		attributes = append(attributes, Attribute{attributeRequestID, t.requestID})
+	}
+	if t.responseID != "" {
+		attributes = append(attributes, Attribute{attributeGenAIResponseID, t.responseID})
+	}
+	if t.responseModel != "" {
+		attributes = append(attributes, Attribute{attributeGenAIResponseModel, t.responseModel})
+	}
+	if len(t.finishReasons) > 0 {
+		attributes = append(attributes, Attribute{attributeGenAIFinishReasons, t.finishReasons})
+	}
+	if t.usage != nil {
+		attributes = append(attributes,
+			Attribute{attributeGenAIInputTokens, t.usage.PromptTokens},
+			Attribute{attributeGenAIOutputTokens, t.usage.CompletionTokens},
+		)
+	}
+	if len(attributes) > 0 {
+		t.span.SetAttributes(attributes...)
+	}
+	if err != nil {
+		t.span.RecordError(err)
+	}
+	t.span.End()
+}
@@ -0,0 +1,213 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type spanContextKey struct{}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]any
+	events     []string
+	err        error
+	ended      int
+}
+
+func (s *recordingSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) AddEvent(name string, _ ...Attribute) { s.events = append(s.events, name) }
+func (s *recordingSpan) RecordError(err error)                { s.err = err }
+func (s *recordingSpan) End()                                 { s.ended++ }
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{name: name, attributes: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+func TestOperationName(t *testing.T) {
+	cases := []struct {
+		method, endpoint, want string
+	}{
+		{http.MethodPost, "/chat/completions", "chat.completions.create"},
+		{http.MethodPost, "/embeddings", "embeddings.create"},
+		{http.MethodGet, "/models", "models.list"},
+		{http.MethodGet, "/models/gpt-4", "models.retrieve"},
+		{http.MethodDelete, "/files/file-abc123", "files.delete"},
+		{http.MethodGet, "/files/file-abc123/content", "files.content.retrieve"},
+		{http.MethodPost, "/fine_tuning/jobs/ftjob-1/cancel", "fine_tuning.jobs.cancel"},
+		{http.MethodGet, "/fine_tuning/jobs/ftjob-1/events", "fine_tuning.jobs.events.list"},
+		{http.MethodPost, "/threads/thread_1/runs/run_1/submit_tool_outputs", "threads.runs.submit_tool_outputs"},
+		{http.MethodPost, "/assistants/asst_1", "assistants.update"},
+	}
+	for _, c := range cases {
+		if got := operationName(c.method, c.endpoint); got != c.want {
+			t.Errorf("operationName(%s, %s) = %q, want %q", c.method, c.endpoint, got, c.want)
+		}
+	}
+}
+
+func TestDecodedCallResponse(t *testing.T) {
+	cases := []struct {
+		v    any
+		want *callResponse
+	}{
+		{&ChatCompletionResponse{
+			ID:      "chatcmpl-1",
+			Model:   GPT4,
+			Choices: []ChatCompletionChoice{{FinishReason: FinishReasonStop}},
+			Usage:   Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+		}, &callResponse{
+			ID:      "chatcmpl-1",
+			Model:   GPT4,
+			Usage:   &Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+			Choices: []callChoice{{FinishReason: "stop"}},
+		}},
+		{&ModelResponse{ID: "resp_1", Usage: &ResponseUsage{InputTokens: 3, OutputTokens: 4, TotalTokens: 7}},
+			&callResponse{ID: "resp_1", Usage: &Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}}},
+		{&ModelsList{}, &callResponse{}},
+		{new(string), nil},
+	}
+	for _, c := range cases {
+		if got := decodedCallResponse(c.v); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("decodedCallResponse(%T) = %+v, want %+v", c.v, got, c.want)
+		}
+	}
+}
+
+func TestTracingRequestSpan(t *testing.T) {
+	body := `{"id":"chatcmpl-1","model":"gpt-4-0613",` +
+		`"choices":[{"index":0,"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`
+	tracer := &recordingTracer{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.Tracer = tracer
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-Id", "req_123")
+		_, _ = w.Write([]byte(body))
+	})
+	var spanSeen bool
+	client.config.Middlewares = []Middleware{func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			spanSeen = req.Context().Value(spanContextKey{}) != nil
+			return next(req)
+		}
+	}}
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "chat.completions.create" || span.ended != 1 || span.err != nil {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	want := map[string]any{
+		attributeGenAISystem:        "openai",
+		attributeGenAIOperationName: "chat.completions.create",
+		attributeGenAIRequestModel:  GPT4,
+		attributeGenAIResponseModel: "gpt-4-0613",
+		attributeGenAIResponseID:    "chatcmpl-1",
+		attributeGenAIFinishReasons: []string{"stop"},
+		attributeGenAIInputTokens:   5,
+		attributeGenAIOutputTokens:  2,
+		attributeHTTPStatusCode:     http.StatusOK,
+		attributeHTTPBodySize:       int64(len(body)),
+		attributeRequestStream:      false,
+		attributeRequestID:          "req_123",
+	}
+	if !reflect.DeepEqual(span.attributes, want) {
+		t.Fatalf("unexpected attributes:\n got %v\nwant %v", span.attributes, want)
+	}
+	if !spanSeen {
+		t.Fatal("the request was not sent with the span context")
+	}
+}
+
+func TestTracingStreamSpan(t *testing.T) {
+	tracer := &recordingTracer{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.Tracer = tracer
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"a"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{Model: GPT4})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+
+	span := tracer.spans[0]
+	if span.ended != 0 {
+		t.Fatal("stream span ended before the stream")
+	}
+	if !reflect.DeepEqual(span.events, []string{spanEventFirstToken}) {
+		t.Fatalf("unexpected events %v", span.events)
+	}
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+
+	if span.ended != 1 {
+		t.Fatalf("expected the span to end once, got %d", span.ended)
+	}
+	if span.attributes[attributeRequestStream] != true ||
+		!reflect.DeepEqual(span.attributes[attributeGenAIFinishReasons], []string{"length"}) {
+		t.Fatalf("unexpected attributes %v", span.attributes)
+	}
+}
+
+func TestTracingErrorSpan(t *testing.T) {
+	tracer := &recordingTracer{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(c *ClientConfig) {
+		c.Tracer = tracer
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"slow down"}}`))
+	})
+
+	_, err := client.ListModels(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	span := tracer.spans[0]
+	if span.name != "models.list" || !errors.Is(span.err, err) || span.ended != 1 {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	if span.attributes[attributeHTTPStatusCode] != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status attribute %v", span.attributes[attributeHTTPStatusCode])
+	}
+}
@@ -0,0 +1,361 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // the API checks uploads with an MD5 checksum
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+const uploadsSuffix = "/uploads"
+
+const (
+	// MaxUploadPartSize is the maximum size of a part of an upload.
+	MaxUploadPartSize = 64 << 20
+	// DefaultUploadConcurrency is the number of parts UploadLargeFile sends at once.
+	DefaultUploadConcurrency = 4
+
+	// uploadCancelTimeout bounds the cancellation of a failed upload.
+	uploadCancelTimeout = 30 * time.Second
+)
+
+var (
+	// ErrUploadSizeMismatch is returned by UploadLargeFile when the reader has
+	// more or less data than UploadRequest.Bytes.
+	ErrUploadSizeMismatch = errors.New("uploaded data does not match the upload size")
+	// ErrUploadNoFile is returned by UploadLargeFile when the completed upload
+	// has no file.
+	ErrUploadNoFile = errors.New("completed upload has no file")
+)
+
+// Statuses of an Upload.
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusCompleted = "completed"
+	UploadStatusCancelled = "cancelled"
+	UploadStatusExpired   = "expired"
+)
+
+// UploadRequest creates an upload, to which the parts of a large file are added.
+type UploadRequest struct {
+	// Filename is the name of the file to create.
+	Filename string `json:"filename"`
+	// Purpose is the intended purpose of the file.
+	Purpose PurposeType `json:"purpose"`
+	// Bytes is the size of the file.
+	Bytes int64 `json:"bytes"`
+	// MimeType is the MIME type of the file, such as text/jsonl for batches.
+	MimeType string `json:"mime_type"`
+}
+
+// Upload is an upload of a file in parts. Once completed, File is the uploaded
+// file.
+type Upload struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+	File      *File  `json:"file,omitempty"`
+
+	httpHeader
+}
+
+// UploadPart is a part added to an upload.
+type UploadPart struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	UploadID  string `json:"upload_id"`
+
+	httpHeader
+}
+
+// CompleteUploadRequest completes an upload.
+type CompleteUploadRequest struct {
+	// PartIDs are the IDs of the parts, in the order of the file.
+	PartIDs []string `json:"part_ids"`
+	// MD5 is the optional hex MD5 checksum of the file, checked by the API.
+	MD5 string `json:"md5,omitempty"`
+}
+
+// CreateUpload creates an upload, which expires after an hour if it is not
+// completed.
+func (c *Client) CreateUpload(ctx context.Context, request UploadRequest) (response Upload, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(uploadsSuffix), withBody(request))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// AddUploadPart adds a part of at most MaxUploadPartSize bytes to an upload.
+// Parts may be added in any order and concurrently; their order is given when
+// the upload is completed.
+func (c *Client) AddUploadPart(ctx context.Context, uploadID string, data io.Reader) (response UploadPart, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/parts", uploadsSuffix, uploadID)
+	err = c.sendMultipartRequest(ctx, c.fullURL(urlSuffix), shouldStreamUpload(data), nil, uploadPartForm(data), &response)
+	return
+}
+
+// uploadPartForm builds the form of a part with data.
+func uploadPartForm(data io.Reader) func(utils.FormBuilder) error {
+	return func(builder utils.FormBuilder) error {
+		if err := builder.CreateFormFileReader("data", data, "part"); err != nil {
+			return err
+		}
+		return builder.Close()
+	}
+}
+
+// CompleteUpload completes an upload, creating its file from the parts.
+func (c *Client) CompleteUpload(
+	ctx context.Context,
+	uploadID string,
+	request CompleteUploadRequest,
+) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/complete", uploadsSuffix, uploadID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CancelUpload cancels an upload. No parts can be added to it afterwards.
+func (c *Client) CancelUpload(ctx context.Context, uploadID string) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/cancel", uploadsSuffix, uploadID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// UploadOptions configures how UploadLargeFile uploads a file.
+type UploadOptions struct {
+	// PartSize is the size of the parts, at most MaxUploadPartSize, which is used
+	// when it is zero.
+	PartSize int
+	// MaxConcurrency is the maximum number of parts sent at once, or
+	// DefaultUploadConcurrency when zero. A part is held in memory while it is
+	// sent, so up to MaxConcurrency+2 parts are buffered, with the one read.
+	MaxConcurrency int
+	// MD5 sends the MD5 checksum of the file, computed as it is read, for the API
+	// to check.
+	MD5 bool
+}
+
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.PartSize <= 0 || o.PartSize > MaxUploadPartSize {
+		o.PartSize = MaxUploadPartSize
+	}
+	if o.MaxConcurrency <= 0 {
+		o.MaxConcurrency = DefaultUploadConcurrency
+	}
+	return o
+}
+
+// uploadPartRequest is the request adding a part of a file read by
+// UploadLargeFile.
+type uploadPartRequest struct {
+	index int
+	req   *http.Request
+}
+
+// UploadLargeFile uploads the content of r as a file through the uploads API,
+// for files too large for CreateFile. The content is split into parts of
+// opts.PartSize bytes, read one at a time and sent concurrently. Failed parts
+// are retried with the same data as configured by ClientConfig.MaxRetries, so
+// parts are always assembled in the order of r.
+//
+// request.Bytes may be zero for files, and readers with a Len or Size method.
+// Other readers without it are read until EOF into a temporary file first, as
+// the API needs the size to create the upload. If the upload fails, it is
+// cancelled.
+func (c *Client) UploadLargeFile(
+	ctx context.Context,
+	r io.Reader,
+	request UploadRequest,
+	opts UploadOptions,
+) (File, error) {
+	if request.Bytes <= 0 {
+		request.Bytes = utils.ReaderSize(r)
+	}
+	if request.Bytes < 0 {
+		spooled, err := spoolUpload(r)
+		if err != nil {
+			return File{}, err
+		}
+		defer func() {
+			spooled.Close()
+			os.Remove(spooled.Name())
+		}()
+		r, request.Bytes = spooled, utils.ReaderSize(spooled)
+	}
+	opts = opts.withDefaults()
+
+	upload, err := c.CreateUpload(ctx, request)
+	if err != nil {
+		return File{}, err
+	}
+	partIDs, checksum, err := c.uploadParts(ctx, upload.ID, r, request.Bytes, opts)
+	if err != nil {
+		cancelCtx, cancel := context.WithTimeout(context.Background(), uploadCancelTimeout)
+		defer cancel()
+		_, _ = c.CancelUpload(cancelCtx, upload.ID)
+		return File{}, err
+	}
+
+	upload, err = c.CompleteUpload(ctx, upload.ID, CompleteUploadRequest{PartIDs: partIDs, MD5: checksum})
+	if err != nil {
+		return File{}, err
+	}
+	if upload.File == nil {
+		return File{}, fmt.Errorf("%w: upload %s is %s", ErrUploadNoFile, upload.ID, upload.Status)
+	}
+	return *upload.File, nil
+}
+
+// spoolUpload copies r into a temporary file, and returns it rewound.
+func spoolUpload(r io.Reader) (*os.File, error) {
+	file, err := os.CreateTemp("", "openai-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(file, r); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
+
+// uploadParts reads r into parts sent by a pool of workers, and returns the IDs
+// of the parts in order, and the MD5 checksum of r if opts.MD5 is set. The parts
+// are read into a single buffer, copied into the form of their request.
+func (c *Client) uploadParts(
+	ctx context.Context,
+	uploadID string,
+	r io.Reader,
+	size int64,
+	opts UploadOptions,
+) ([]string, string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		partIDs  []string
+		requests = make(chan uploadPartRequest)
+		url      = c.fullURL(fmt.Sprintf("%s/%s/parts", uploadsSuffix, uploadID))
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	for w := 0; w < opts.MaxConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range requests {
+				var part UploadPart
+				if err := c.sendRequest(request.req, &part); err != nil {
+					fail(fmt.Errorf("uploading part %d: %w", request.index, err))
+					continue
+				}
+				mu.Lock()
+				partIDs[request.index] = part.ID
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var checksum hash.Hash
+	if opts.MD5 {
+		checksum = md5.New() //nolint:gosec // the API checks uploads with an MD5 checksum
+	}
+	data := make([]byte, opts.PartSize)
+	read, readErr := int64(0), error(nil)
+read:
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(r, data)
+		if n > 0 {
+			read += int64(n)
+			if read > size {
+				readErr = fmt.Errorf("%w: read more than %d bytes", ErrUploadSizeMismatch, size)
+				break
+			}
+			if checksum != nil {
+				checksum.Write(data[:n])
+			}
+			// A buffered form is sent again as is by the retries of the client.
+			req, _, buildErr := c.newMultipartRequest(ctx, url, false, nil, uploadPartForm(bytes.NewReader(data[:n])))
+			if buildErr != nil {
+				readErr = buildErr
+				break read
+			}
+			mu.Lock()
+			partIDs = append(partIDs, "")
+			mu.Unlock()
+			select {
+			case requests <- uploadPartRequest{index: index, req: req}:
+			case <-ctx.Done():
+				break read
+			}
+		}
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			break read
+		case err != nil:
+			readErr = err
+			break read
+		}
+	}
+	close(requests)
+	wg.Wait()
+
+	if readErr == nil && firstErr == nil && read != size {
+		readErr = fmt.Errorf("%w: read %d of %d bytes", ErrUploadSizeMismatch, read, size)
+	}
+	switch {
+	case firstErr != nil:
+		return nil, "", firstErr
+	case readErr != nil:
+		return nil, "", readErr
+	case ctx.Err() != nil:
+		return nil, "", ctx.Err()
+	}
+	if checksum != nil {
+		return partIDs, hex.EncodeToString(checksum.Sum(nil)), nil
+	}
+	return partIDs, "", nil
+}
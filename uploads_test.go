@@ -0,0 +1,210 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // the API checks uploads with an MD5 checksum
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// fakeUploads serves the uploads API for a single upload, assembling the file
+// from its parts when it is completed.
+type fakeUploads struct {
+	mu        sync.Mutex
+	request   openai.UploadRequest
+	parts     map[string][]byte
+	attempts  map[string]int
+	failFirst string // part data prefix failed once with a 500
+	reject    string // part data prefix rejected with a 400
+	cancelled bool
+	file      []byte
+}
+
+func newFakeUploads(server *test.ServerTest) *fakeUploads {
+	f := &fakeUploads{parts: map[string][]byte{}, attempts: map[string]int{}}
+	server.RegisterHandler("/v1/uploads", f.create)
+	server.RegisterHandler("/v1/uploads/upload-1/parts", f.addPart)
+	server.RegisterHandler("/v1/uploads/upload-1/complete", f.complete)
+	server.RegisterHandler("/v1/uploads/upload-1/cancel", f.cancel)
+	return f
+}
+
+func (f *fakeUploads) create(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_ = json.NewDecoder(r.Body).Decode(&f.request)
+	fmt.Fprintf(w, `{"id":"upload-1","object":"upload","bytes":%d,"status":"pending"}`, f.request.Bytes)
+}
+
+func (f *fakeUploads) addPart(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("data")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, _ := io.ReadAll(file)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := string(data[:1])
+	f.attempts[key]++
+	switch {
+	case f.reject != "" && strings.HasPrefix(string(data), f.reject):
+		http.Error(w, `{"error":{"message":"invalid part"}}`, http.StatusBadRequest)
+		return
+	case f.failFirst != "" && strings.HasPrefix(string(data), f.failFirst) && f.attempts[key] == 1:
+		http.Error(w, `{"error":{"message":"server error"}}`, http.StatusInternalServerError)
+		return
+	}
+	id := fmt.Sprintf("part-%d", len(f.parts))
+	f.parts[id] = data
+	fmt.Fprintf(w, `{"id":%q,"object":"upload.part","upload_id":"upload-1"}`, id)
+}
+
+func (f *fakeUploads) complete(w http.ResponseWriter, r *http.Request) {
+	var req openai.CompleteUploadRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var file []byte
+	for _, id := range req.PartIDs {
+		file = append(file, f.parts[id]...)
+	}
+	if sum := md5.Sum(file); req.MD5 != "" && req.MD5 != hex.EncodeToString(sum[:]) { //nolint:gosec // test checksum
+		http.Error(w, `{"error":{"message":"checksum mismatch"}}`, http.StatusBadRequest)
+		return
+	}
+	f.file = file
+	fmt.Fprintf(w, `{"id":"upload-1","object":"upload","status":"completed",`+
+		`"file":{"id":"file-1","object":"file","bytes":%d,"filename":%q,"purpose":%q}}`,
+		len(file), f.request.Filename, f.request.Purpose)
+}
+
+func (f *fakeUploads) cancel(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelled = true
+	fmt.Fprint(w, `{"id":"upload-1","object":"upload","status":"cancelled"}`)
+}
+
+// uploadTestData returns parts bytes of data, with each part of size bytes
+// filled with a distinct letter.
+func uploadTestData(parts, size int) []byte {
+	var data []byte
+	for i := 0; i < parts; i++ {
+		data = append(data, bytes.Repeat([]byte{byte('a' + i)}, size)...)
+	}
+	return data
+}
+
+func TestUploadLargeFile(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 1
+		config.RetryBackoff = func(int) time.Duration { return 0 }
+	})
+	defer teardown()
+	uploads := newFakeUploads(server)
+	uploads.failFirst = "c"
+
+	data := uploadTestData(10, 1024)
+	data = append(data, "tail"...)
+	// The reader hides its length, so the size has to be given.
+	reader := struct{ io.Reader }{bytes.NewReader(data)}
+	file, err := client.UploadLargeFile(context.Background(), reader, openai.UploadRequest{
+		Filename: "training.jsonl",
+		Purpose:  openai.PurposeFineTune,
+		Bytes:    int64(len(data)),
+		MimeType: "text/jsonl",
+	}, openai.UploadOptions{PartSize: 1024, MaxConcurrency: 3, MD5: true})
+	checks.NoError(t, err, "UploadLargeFile error")
+
+	if file.ID != "file-1" || file.Bytes != len(data) || file.FileName != "training.jsonl" {
+		t.Errorf("unexpected file %+v", file)
+	}
+	if !bytes.Equal(uploads.file, data) {
+		t.Error("the parts were not assembled in order")
+	}
+	if uploads.attempts["c"] != 2 {
+		t.Errorf("the failed part was sent %d times, want 2", uploads.attempts["c"])
+	}
+}
+
+func TestUploadLargeFileKnownSize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := newFakeUploads(server)
+
+	data := uploadTestData(3, 100)
+	_, err := client.UploadLargeFile(context.Background(), bytes.NewReader(data), openai.UploadRequest{
+		Filename: "batch.jsonl",
+		Purpose:  openai.PurposeBatch,
+	}, openai.UploadOptions{PartSize: 100})
+	checks.NoError(t, err, "UploadLargeFile error")
+	if uploads.request.Bytes != int64(len(data)) || !bytes.Equal(uploads.file, data) {
+		t.Errorf("uploaded %d of %d bytes", len(uploads.file), uploads.request.Bytes)
+	}
+}
+
+func TestUploadLargeFileSize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := newFakeUploads(server)
+	data := uploadTestData(3, 100)
+
+	// A reader of unknown length is read until EOF to learn its size.
+	_, err := client.UploadLargeFile(context.Background(), struct{ io.Reader }{bytes.NewReader(data)},
+		openai.UploadRequest{Filename: "batch.jsonl", Purpose: openai.PurposeBatch}, openai.UploadOptions{PartSize: 100})
+	checks.NoError(t, err, "UploadLargeFile error")
+	if uploads.request.Bytes != int64(len(data)) || !bytes.Equal(uploads.file, data) {
+		t.Errorf("uploaded %d of %d bytes", len(uploads.file), uploads.request.Bytes)
+	}
+
+	for _, size := range []int64{250, 350} {
+		uploads.cancelled = false
+		_, err = client.UploadLargeFile(context.Background(), bytes.NewReader(data),
+			openai.UploadRequest{Filename: "batch.jsonl", Purpose: openai.PurposeBatch, Bytes: size},
+			openai.UploadOptions{PartSize: 100})
+		if !errors.Is(err, openai.ErrUploadSizeMismatch) {
+			t.Errorf("expected ErrUploadSizeMismatch for %d bytes, got %v", size, err)
+		}
+		if !uploads.cancelled {
+			t.Errorf("the upload of %d bytes was not cancelled", size)
+		}
+	}
+}
+
+func TestUploadLargeFilePartError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := newFakeUploads(server)
+	uploads.reject = "b"
+
+	data := uploadTestData(4, 100)
+	_, err := client.UploadLargeFile(context.Background(), bytes.NewReader(data),
+		openai.UploadRequest{Filename: "batch.jsonl", Purpose: openai.PurposeBatch},
+		openai.UploadOptions{PartSize: 100, MaxConcurrency: 1})
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a 400 APIError, got %v", err)
+	}
+	if uploads.attempts["b"] != 1 {
+		t.Errorf("the rejected part was sent %d times, want 1", uploads.attempts["b"])
+	}
+	if !uploads.cancelled || uploads.file != nil {
+		t.Error("the failed upload was not cancelled")
+	}
+}
@@ -43,10 +43,11 @@ type VectorStoreExpires struct {
 
 // VectorStoreRequest provides the vector store request parameters.
 type VectorStoreRequest struct {
-	Name         string              `json:"name,omitempty"`
-	FileIDs      []string            `json:"file_ids,omitempty"`
-	ExpiresAfter *VectorStoreExpires `json:"expires_after,omitempty"`
-	Metadata     map[string]any      `json:"metadata,omitempty"`
+	Name             string              `json:"name,omitempty"`
+	FileIDs          []string            `json:"file_ids,omitempty"`
+	ExpiresAfter     *VectorStoreExpires `json:"expires_after,omitempty"`
+	ChunkingStrategy *ChunkingStrategy   `json:"chunking_strategy,omitempty"`
+	Metadata         map[string]any      `json:"metadata,omitempty"`
 }
 
 // VectorStoresList is a list of vector store.
@@ -67,18 +68,93 @@ type VectorStoreDeleteResponse struct {
 }
 
 type VectorStoreFile struct {
-	ID            string `json:"id"`
-	Object        string `json:"object"`
-	CreatedAt     int64  `json:"created_at"`
-	VectorStoreID string `json:"vector_store_id"`
-	UsageBytes    int    `json:"usage_bytes"`
-	Status        string `json:"status"`
+	ID               string                `json:"id"`
+	Object           string                `json:"object"`
+	CreatedAt        int64                 `json:"created_at"`
+	VectorStoreID    string                `json:"vector_store_id"`
+	UsageBytes       int                   `json:"usage_bytes"`
+	Status           VectorStoreFileStatus `json:"status"`
+	LastError        *VectorStoreFileError `json:"last_error,omitempty"`
+	ChunkingStrategy *ChunkingStrategy     `json:"chunking_strategy,omitempty"`
+	Attributes       map[string]any        `json:"attributes,omitempty"`
 
 	httpHeader
 }
 
+// VectorStoreFileStatus is the status of a vector store file or file batch.
+type VectorStoreFileStatus string
+
+const (
+	VectorStoreFileStatusInProgress VectorStoreFileStatus = "in_progress"
+	VectorStoreFileStatusCompleted  VectorStoreFileStatus = "completed"
+	VectorStoreFileStatusFailed     VectorStoreFileStatus = "failed"
+	VectorStoreFileStatusCancelled  VectorStoreFileStatus = "cancelled"
+)
+
+// VectorStoreFileError tells why a file could not be added to a vector store.
+type VectorStoreFileError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type VectorStoreFileRequest struct {
-	FileID string `json:"file_id"`
+	FileID           string            `json:"file_id"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+	// Attributes are the attributes of the file, filtered on by searches. Values
+	// are strings, numbers or booleans.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// ListVectorStoreFilesParams narrows and pages the files listed by
+// ListVectorStoreFilesWithParams and ListVectorStoreFilesInBatchWithParams.
+// Zero values are left to the API defaults.
+type ListVectorStoreFilesParams struct {
+	// Limit is the number of files per page, between 1 and 100.
+	Limit int
+	// Order sorts the files by creation time, "asc" or "desc".
+	Order string
+	// After is the ID of the file after which the page starts.
+	After string
+	// Before is the ID of the file before which the page ends.
+	Before string
+	// Filter only lists the files with this status.
+	Filter VectorStoreFileStatus
+}
+
+func (p ListVectorStoreFilesParams) encode() string {
+	urlValues := url.Values{}
+	if p.Limit > 0 {
+		urlValues.Add("limit", fmt.Sprintf("%d", p.Limit))
+	}
+	if p.Order != "" {
+		urlValues.Add("order", p.Order)
+	}
+	if p.After != "" {
+		urlValues.Add("after", p.After)
+	}
+	if p.Before != "" {
+		urlValues.Add("before", p.Before)
+	}
+	if p.Filter != "" {
+		urlValues.Add("filter", string(p.Filter))
+	}
+	return encodeQuery(urlValues)
+}
+
+func listVectorStoreFilesParams(pagination Pagination) (params ListVectorStoreFilesParams) {
+	if pagination.Limit != nil {
+		params.Limit = *pagination.Limit
+	}
+	if pagination.Order != nil {
+		params.Order = *pagination.Order
+	}
+	if pagination.After != nil {
+		params.After = *pagination.After
+	}
+	if pagination.Before != nil {
+		params.Before = *pagination.Before
+	}
+	return
 }
 
 type VectorStoreFilesList struct {
@@ -91,29 +167,33 @@ type VectorStoreFilesList struct {
 }
 
 type VectorStoreFileBatch struct {
-	ID            string               `json:"id"`
-	Object        string               `json:"object"`
-	CreatedAt     int64                `json:"created_at"`
-	VectorStoreID string               `json:"vector_store_id"`
-	Status        string               `json:"status"`
-	FileCounts    VectorStoreFileCount `json:"file_counts"`
+	ID            string                `json:"id"`
+	Object        string                `json:"object"`
+	CreatedAt     int64                 `json:"created_at"`
+	VectorStoreID string                `json:"vector_store_id"`
+	Status        VectorStoreFileStatus `json:"status"`
+	FileCounts    VectorStoreFileCount  `json:"file_counts"`
 
 	httpHeader
 }
 
 type VectorStoreFileBatchRequest struct {
-	FileIDs []string `json:"file_ids"`
+	FileIDs          []string          `json:"file_ids"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
 }
 
 // CreateVectorStore creates a new vector store.
 func (c *Client) CreateVectorStore(ctx context.Context, request VectorStoreRequest) (response VectorStore, err error) {
-	req, _ := c.newRequest(
+	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(vectorStoresSuffix),
 		withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion),
 	)
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -125,8 +205,11 @@ func (c *Client) RetrieveVectorStore(
 	vectorStoreID string,
 ) (response VectorStore, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -139,8 +222,11 @@ func (c *Client) ModifyVectorStore(
 	request VectorStoreRequest,
 ) (response VectorStore, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -152,8 +238,11 @@ func (c *Client) DeleteVectorStore(
 	vectorStoreID string,
 ) (response VectorStoreDeleteResponse, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -185,8 +274,11 @@ func (c *Client) ListVectorStores(
 	}
 
 	urlSuffix := fmt.Sprintf("%s%s", vectorStoresSuffix, encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -199,9 +291,12 @@ func (c *Client) CreateVectorStoreFile(
 	request VectorStoreFileRequest,
 ) (response VectorStoreFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -214,8 +309,11 @@ func (c *Client) RetrieveVectorStoreFile(
 	fileID string,
 ) (response VectorStoreFile, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -228,8 +326,11 @@ func (c *Client) DeleteVectorStoreFile(
 	fileID string,
 ) (err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
-	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, nil)
 	return
@@ -241,28 +342,22 @@ func (c *Client) ListVectorStoreFiles(
 	vectorStoreID string,
 	pagination Pagination,
 ) (response VectorStoreFilesList, err error) {
-	urlValues := url.Values{}
-	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
-	}
-	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
-	}
-	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
-	}
-	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
+	return c.ListVectorStoreFilesWithParams(ctx, vectorStoreID, listVectorStoreFilesParams(pagination))
+}
 
-	urlSuffix := fmt.Sprintf("%s/%s%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+// ListVectorStoreFilesWithParams lists a page of the files of a vector store,
+// optionally only those with a given status.
+func (c *Client) ListVectorStoreFilesWithParams(
+	ctx context.Context,
+	vectorStoreID string,
+	params ListVectorStoreFilesParams,
+) (response VectorStoreFilesList, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, params.encode())
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -275,9 +370,12 @@ func (c *Client) CreateVectorStoreFileBatch(
 	request VectorStoreFileBatchRequest,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -290,8 +388,11 @@ func (c *Client) RetrieveVectorStoreFileBatch(
 	batchID string,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix, batchID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -305,44 +406,74 @@ func (c *Client) CancelVectorStoreFileBatch(
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s", vectorStoresSuffix,
 		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/cancel")
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
 }
 
-// ListVectorStoreFiles Lists the currently available files for a vector store.
+// ListVectorStoreFilesInBatch lists the files of a vector store file batch.
 func (c *Client) ListVectorStoreFilesInBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
 	pagination Pagination,
 ) (response VectorStoreFilesList, err error) {
-	urlValues := url.Values{}
-	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
-	}
-	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
-	}
-	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
-	}
-	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
+	return c.ListVectorStoreFilesInBatchWithParams(ctx, vectorStoreID, batchID, listVectorStoreFilesParams(pagination))
+}
 
+// ListVectorStoreFilesInBatchWithParams lists a page of the files of a vector
+// store file batch, optionally only those with a given status.
+func (c *Client) ListVectorStoreFilesInBatchWithParams(
+	ctx context.Context,
+	vectorStoreID string,
+	batchID string,
+	params ListVectorStoreFilesParams,
+) (response VectorStoreFilesList, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s%s", vectorStoresSuffix,
-		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/files", encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/files", params.encode())
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
 		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// WaitForVectorStoreFileBatch polls a vector store file batch until none of its
+// files are in progress, and returns it. If onProgress is not nil, it is called
+// with the file counts of the batch whenever they change. Files that failed are
+// counted in FileCounts.Failed and listed by ListVectorStoreFilesInBatchWithParams
+// with the failed filter; they do not make the wait fail.
+func (c *Client) WaitForVectorStoreFileBatch(
+	ctx context.Context,
+	vectorStoreID string,
+	batchID string,
+	opts PollOptions,
+	onProgress func(VectorStoreFileCount),
+) (batch VectorStoreFileBatch, err error) {
+	var (
+		counts   VectorStoreFileCount
+		reported bool
+	)
+	err = poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		polled, err := c.RetrieveVectorStoreFileBatch(ctx, vectorStoreID, batchID)
+		if err != nil {
+			return false, err
+		}
+		batch = polled
+
+		if onProgress != nil && (!reported || counts != batch.FileCounts) {
+			counts, reported = batch.FileCounts, true
+			onProgress(counts)
+		}
+		return batch.Status != VectorStoreFileStatusInProgress, nil
+	})
+	return
+}
@@ -0,0 +1,196 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// VectorStoreFilter is a filter over the attributes of the files of a vector
+// store: a ComparisonFilter, or a CompoundFilter combining other filters.
+type VectorStoreFilter interface {
+	json.Marshaler
+	vectorStoreFilter()
+}
+
+// ComparisonFilterType is the operator of a ComparisonFilter.
+type ComparisonFilterType string
+
+const (
+	ComparisonFilterTypeEq  ComparisonFilterType = "eq"
+	ComparisonFilterTypeNe  ComparisonFilterType = "ne"
+	ComparisonFilterTypeGt  ComparisonFilterType = "gt"
+	ComparisonFilterTypeGte ComparisonFilterType = "gte"
+	ComparisonFilterTypeLt  ComparisonFilterType = "lt"
+	ComparisonFilterTypeLte ComparisonFilterType = "lte"
+)
+
+// ComparisonFilter compares the attribute Key of files to Value, a string,
+// number or boolean.
+type ComparisonFilter struct {
+	Type  ComparisonFilterType
+	Key   string
+	Value any
+}
+
+func (ComparisonFilter) vectorStoreFilter() {}
+
+func (f ComparisonFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  ComparisonFilterType `json:"type"`
+		Key   string               `json:"key"`
+		Value any                  `json:"value"`
+	}{f.Type, f.Key, f.Value})
+}
+
+// CompoundFilterType is the operator of a CompoundFilter.
+type CompoundFilterType string
+
+const (
+	CompoundFilterTypeAnd CompoundFilterType = "and"
+	CompoundFilterTypeOr  CompoundFilterType = "or"
+)
+
+// CompoundFilter matches the files matched by all, or any, of its Filters.
+type CompoundFilter struct {
+	Type    CompoundFilterType
+	Filters []VectorStoreFilter
+}
+
+func (CompoundFilter) vectorStoreFilter() {}
+
+func (f CompoundFilter) MarshalJSON() ([]byte, error) {
+	filters := f.Filters
+	if filters == nil {
+		filters = []VectorStoreFilter{}
+	}
+	return json.Marshal(struct {
+		Type    CompoundFilterType  `json:"type"`
+		Filters []VectorStoreFilter `json:"filters"`
+	}{f.Type, filters})
+}
+
+// FilterEq matches the files whose attribute key equals value.
+func FilterEq(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeEq, Key: key, Value: value}
+}
+
+// FilterNe matches the files whose attribute key does not equal value.
+func FilterNe(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeNe, Key: key, Value: value}
+}
+
+// FilterGt matches the files whose attribute key is greater than value.
+func FilterGt(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeGt, Key: key, Value: value}
+}
+
+// FilterGte matches the files whose attribute key is greater than or equal to
+// value.
+func FilterGte(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeGte, Key: key, Value: value}
+}
+
+// FilterLt matches the files whose attribute key is less than value.
+func FilterLt(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeLt, Key: key, Value: value}
+}
+
+// FilterLte matches the files whose attribute key is less than or equal to
+// value.
+func FilterLte(key string, value any) ComparisonFilter {
+	return ComparisonFilter{Type: ComparisonFilterTypeLte, Key: key, Value: value}
+}
+
+// FilterAnd matches the files matched by all of filters.
+func FilterAnd(filters ...VectorStoreFilter) CompoundFilter {
+	return CompoundFilter{Type: CompoundFilterTypeAnd, Filters: filters}
+}
+
+// FilterOr matches the files matched by any of filters.
+func FilterOr(filters ...VectorStoreFilter) CompoundFilter {
+	return CompoundFilter{Type: CompoundFilterTypeOr, Filters: filters}
+}
+
+// VectorStoreSearchRankingOptions tunes the ranking of search results.
+type VectorStoreSearchRankingOptions struct {
+	// Ranker is "auto" or a specific ranker, such as "default-2024-11-15".
+	Ranker string `json:"ranker,omitempty"`
+	// ScoreThreshold drops the results scoring below it, between 0 and 1.
+	ScoreThreshold *float64 `json:"score_threshold,omitempty"`
+}
+
+// VectorStoreSearchRequest is a search of the chunks of a vector store.
+type VectorStoreSearchRequest struct {
+	// Query is a string or a []string.
+	Query any `json:"query"`
+	// MaxNumResults is the number of results per page, between 1 and 50.
+	MaxNumResults  int                              `json:"max_num_results,omitempty"`
+	Filters        VectorStoreFilter                `json:"filters,omitempty"`
+	RankingOptions *VectorStoreSearchRankingOptions `json:"ranking_options,omitempty"`
+	// RewriteQuery lets the API rewrite the query for semantic search.
+	RewriteQuery bool `json:"rewrite_query,omitempty"`
+	// Page is the NextPage of the previous page of results.
+	Page string `json:"page,omitempty"`
+}
+
+// VectorStoreSearchResult is a file of a vector store matching a search, with
+// its chunks that matched.
+type VectorStoreSearchResult struct {
+	FileID     string                           `json:"file_id"`
+	FileName   string                           `json:"filename"`
+	Score      float64                          `json:"score"`
+	Attributes map[string]any                   `json:"attributes,omitempty"`
+	Content    []VectorStoreSearchResultContent `json:"content"`
+}
+
+type VectorStoreSearchResultContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// VectorStoreSearchResponse is a page of search results, ranked by score.
+type VectorStoreSearchResponse struct {
+	Object      string                    `json:"object"`
+	SearchQuery []string                  `json:"search_query"`
+	Data        []VectorStoreSearchResult `json:"data"`
+	HasMore     bool                      `json:"has_more"`
+	NextPage    *string                   `json:"next_page"`
+
+	httpHeader
+}
+
+// SearchVectorStore searches the chunks of a vector store, and returns a page of
+// the best matching results.
+func (c *Client) SearchVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreSearchRequest,
+) (response VectorStoreSearchResponse, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/search", vectorStoresSuffix, vectorStoreID)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBody(request),
+		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// SearchVectorStoreAll searches the chunks of a vector store and returns the
+// results of all the pages, following NextPage.
+func (c *Client) SearchVectorStoreAll(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreSearchRequest,
+) ([]VectorStoreSearchResult, error) {
+	return newListIterator(ctx, request.Page,
+		func(ctx context.Context, page string) ([]VectorStoreSearchResult, string, bool, error) {
+			request.Page = page
+			response, err := c.SearchVectorStore(ctx, vectorStoreID, request)
+			return response.Data, stringValue(response.NextPage), response.HasMore, err
+		}).all()
+}
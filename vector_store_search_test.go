@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestVectorStoreFilterJSON(t *testing.T) {
+	filter := openai.FilterAnd(
+		openai.FilterEq("team", "search"),
+		openai.FilterOr(openai.FilterGte("year", 2024), openai.FilterNe("draft", true)),
+	)
+	data, err := json.Marshal(filter)
+	checks.NoError(t, err, "Marshal error")
+	want := `{"type":"and","filters":[{"type":"eq","key":"team","value":"search"},` +
+		`{"type":"or","filters":[{"type":"gte","key":"year","value":2024},{"type":"ne","key":"draft","value":true}]}]}`
+	if string(data) != want {
+		t.Errorf("unexpected filter JSON %s", data)
+	}
+}
+
+func TestSearchVectorStoreAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var requests []map[string]any
+	server.RegisterHandler("/v1/vector_stores/vs_abc123/search", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &request)
+		requests = append(requests, request)
+		if len(requests) == 1 {
+			fmt.Fprint(w, `{"object":"vector_store.search_results.page","search_query":["return policy"],`+
+				`"data":[{"file_id":"file-1","filename":"policy.md","score":0.9,"attributes":{"team":"support"},`+
+				`"content":[{"type":"text","text":"Returns are accepted within 30 days."}]}],`+
+				`"has_more":true,"next_page":"page_2"}`)
+			return
+		}
+		fmt.Fprint(w, `{"object":"vector_store.search_results.page","search_query":["return policy"],`+
+			`"data":[{"file_id":"file-2","filename":"faq.md","score":0.7,"content":[]}],`+
+			`"has_more":false,"next_page":null}`)
+	})
+	threshold := 0.5
+
+	results, err := client.SearchVectorStoreAll(context.Background(), "vs_abc123", openai.VectorStoreSearchRequest{
+		Query:          "return policy",
+		MaxNumResults:  1,
+		Filters:        openai.FilterEq("team", "support"),
+		RankingOptions: &openai.VectorStoreSearchRankingOptions{Ranker: "auto", ScoreThreshold: &threshold},
+		RewriteQuery:   true,
+	})
+	checks.NoError(t, err, "SearchVectorStoreAll error")
+
+	if len(results) != 2 || results[0].FileName != "policy.md" || results[0].Attributes["team"] != "support" ||
+		results[0].Content[0].Text != "Returns are accepted within 30 days." || results[1].Score != 0.7 {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if len(requests) != 2 || requests[1]["page"] != "page_2" || requests[0]["rewrite_query"] != true {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+	filters, _ := requests[0]["filters"].(map[string]any)
+	if filters["type"] != "eq" || filters["key"] != "team" || filters["value"] != "support" {
+		t.Errorf("unexpected filters %v", requests[0]["filters"])
+	}
+}
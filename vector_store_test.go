@@ -7,9 +7,12 @@ import (
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 )
 
 // TestVectorStore Tests the vector store endpoint of the API using the mocked server.
@@ -347,3 +350,95 @@ func TestVectorStore(t *testing.T) {
 		checks.NoError(t, err, "CancelVectorStoreFileBatch error")
 	})
 }
+
+func TestListVectorStoreFilesWithParams(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var query url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"file-abc123","object":"vector_store.file",`+
+			`"vector_store_id":"vs_abc123","status":"failed","last_error":{"code":"unsupported_file",`+
+			`"message":"The file type is not supported."},"chunking_strategy":{"type":"static",`+
+			`"static":{"max_chunk_size_tokens":800,"chunk_overlap_tokens":400}}}],"has_more":false}`)
+	}
+	server.RegisterHandler("/v1/vector_stores/vs_abc123/files", handler)
+	server.RegisterHandler("/v1/vector_stores/vs_abc123/file_batches/vsfb_abc123/files", handler)
+	ctx := context.Background()
+	params := openai.ListVectorStoreFilesParams{Limit: 10, Filter: openai.VectorStoreFileStatusFailed}
+
+	files, err := client.ListVectorStoreFilesWithParams(ctx, "vs_abc123", params)
+	checks.NoError(t, err, "ListVectorStoreFilesWithParams error")
+	if query.Get("filter") != "failed" || query.Get("limit") != "10" || query.Has("order") {
+		t.Errorf("unexpected query %v", query)
+	}
+	file := files.VectorStoreFiles[0]
+	if file.Status != openai.VectorStoreFileStatusFailed || file.LastError.Code != "unsupported_file" ||
+		file.ChunkingStrategy.Static.MaxChunkSizeTokens != 800 {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	_, err = client.ListVectorStoreFilesInBatchWithParams(ctx, "vs_abc123", "vsfb_abc123", params)
+	checks.NoError(t, err, "ListVectorStoreFilesInBatchWithParams error")
+	if query.Get("filter") != "failed" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestWaitForVectorStoreFileBatch(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var request openai.VectorStoreFileBatchRequest
+	server.RegisterHandler("/v1/vector_stores/vs_abc123/file_batches", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"vsfb_abc123","status":"in_progress"}`)
+	})
+	polls := []string{
+		`"status":"in_progress","file_counts":{"in_progress":3,"completed":0,"failed":0,"cancelled":0,"total":3}`,
+		`"status":"in_progress","file_counts":{"in_progress":3,"completed":0,"failed":0,"cancelled":0,"total":3}`,
+		`"status":"in_progress","file_counts":{"in_progress":1,"completed":2,"failed":0,"cancelled":0,"total":3}`,
+		`"status":"completed","file_counts":{"in_progress":0,"completed":2,"failed":1,"cancelled":0,"total":3}`,
+	}
+	poll := 0
+	server.RegisterHandler("/v1/vector_stores/vs_abc123/file_batches/vsfb_abc123",
+		func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, `{"id":"vsfb_abc123",%s}`, polls[poll])
+			poll++
+		})
+	ctx := context.Background()
+
+	created, err := client.CreateVectorStoreFileBatch(ctx, "vs_abc123", openai.VectorStoreFileBatchRequest{
+		FileIDs:          []string{"file-1", "file-2", "file-3"},
+		ChunkingStrategy: openai.NewStaticChunkingStrategy(800, 400),
+	})
+	checks.NoError(t, err, "CreateVectorStoreFileBatch error")
+	if request.ChunkingStrategy == nil || request.ChunkingStrategy.Type != openai.ChunkingStrategyTypeStatic ||
+		request.ChunkingStrategy.Static.ChunkOverlapTokens != 400 {
+		t.Errorf("unexpected request %+v", request)
+	}
+
+	var progress []int
+	batch, err := client.WaitForVectorStoreFileBatch(ctx, "vs_abc123", created.ID,
+		openai.PollOptions{Interval: time.Millisecond}, func(counts openai.VectorStoreFileCount) {
+			progress = append(progress, counts.Completed)
+		})
+	checks.NoError(t, err, "WaitForVectorStoreFileBatch error")
+	if batch.Status != openai.VectorStoreFileStatusCompleted || batch.FileCounts.Failed != 1 || poll != len(polls) {
+		t.Errorf("unexpected batch %+v after %d polls", batch, poll)
+	}
+	if fmt.Sprint(progress) != "[0 2 2]" {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
+
+func TestVectorStoreTokenProviderError(t *testing.T) {
+	errVault := errors.New("vault unavailable")
+	config := openai.DefaultConfig("")
+	config.TokenProvider = func(context.Context) (string, error) {
+		return "", errVault
+	}
+	client := openai.NewClientWithConfig(config)
+
+	_, err := client.RetrieveVectorStore(context.Background(), "vs_abc123")
+	checks.ErrorIs(t, err, errVault, "RetrieveVectorStore should fail with the provider error")
+}